@@ -0,0 +1,363 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package keystore implements encrypted storage of secp256k1 private keys.
+//
+// Keys are stored as encrypted JSON files according to the Web3 Secret Storage specification.
+// See https://github.com/ethereum/wiki/wiki/Web3-Secret-Storage-Definition for more information.
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+var (
+	ErrLocked               = errors.New("authentication needed: password or unlock")
+	ErrNoMatch              = errors.New("no key for given address or file")
+	ErrAccountAlreadyExists = errors.New("account already exists")
+)
+
+// KeyStore manages a key storage directory on disk.
+// Keys stay encrypted on disk; decrypted keys are only kept in memory while the account is unlocked.
+type KeyStore struct {
+	dir     string
+	scryptN int
+	scryptP int
+
+	mu       sync.RWMutex
+	unlocked map[common.Address]*unlocked // Currently unlocked account (decrypted private keys)
+}
+
+type unlocked struct {
+	*Key
+	abort chan struct{}
+}
+
+// NewKeyStore creates a keystore for the given directory.
+// scryptN and scryptP are only used to encrypt new keys, existing keys carry their own parameters.
+func NewKeyStore(keydir string, scryptN, scryptP int) *KeyStore {
+	keydir, _ = filepath.Abs(keydir)
+	return &KeyStore{
+		dir:      keydir,
+		scryptN:  scryptN,
+		scryptP:  scryptP,
+		unlocked: map[common.Address]*unlocked{},
+	}
+}
+
+// Dir returns the directory holding the key files
+func (ks *KeyStore) Dir() string { return ks.dir }
+
+// keyFiles scans the keystore directory and returns the address of every key file found,
+// in the order the files are named (for the default naming scheme - creation order).
+// Missing directory is not an error: it is created with the first account.
+func (ks *KeyStore) keyFiles() ([]common.Address, map[common.Address]string, error) {
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, map[common.Address]string{}, nil
+		}
+		return nil, nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var addresses []common.Address
+	files := make(map[common.Address]string, len(entries))
+	for _, entry := range entries {
+		if skipKeyFile(entry) {
+			continue
+		}
+		path := filepath.Join(ks.dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		var key struct {
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal(content, &key); err != nil || !common.IsHexAddress(key.Address) {
+			continue // not a key file
+		}
+		addr := common.HexToAddress(key.Address)
+		if _, ok := files[addr]; ok {
+			continue // first file wins
+		}
+		files[addr] = path
+		addresses = append(addresses, addr)
+	}
+	return addresses, files, nil
+}
+
+// skipKeyFile ignores editor backups, hidden files and folders/symlinks.
+func skipKeyFile(fi os.DirEntry) bool {
+	// Skip editor backups and UNIX-style hidden files.
+	if strings.HasSuffix(fi.Name(), "~") || strings.HasPrefix(fi.Name(), ".") {
+		return true
+	}
+	// Skip misc special files, directories (yes, symlinks too).
+	if fi.IsDir() || !fi.Type().IsRegular() {
+		return true
+	}
+	return false
+}
+
+// Accounts returns the addresses of all key files present in the directory.
+func (ks *KeyStore) Accounts() ([]common.Address, error) {
+	addresses, _, err := ks.keyFiles()
+	return addresses, err
+}
+
+// HasAddress reports whether a key with the given address is present.
+func (ks *KeyStore) HasAddress(addr common.Address) bool {
+	_, files, err := ks.keyFiles()
+	if err != nil {
+		return false
+	}
+	_, ok := files[addr]
+	return ok
+}
+
+func (ks *KeyStore) find(addr common.Address) (string, error) {
+	_, files, err := ks.keyFiles()
+	if err != nil {
+		return "", err
+	}
+	path, ok := files[addr]
+	if !ok {
+		return "", ErrNoMatch
+	}
+	return path, nil
+}
+
+// NewAccount generates a new key and stores it into the key directory,
+// encrypting it with the passphrase.
+func (ks *KeyStore) NewAccount(passphrase string) (common.Address, error) {
+	key, err := newKey()
+	if err != nil {
+		return common.Address{}, err
+	}
+	defer zeroKey(key.PrivateKey)
+	if err := ks.storeNewKey(key, passphrase); err != nil {
+		return common.Address{}, err
+	}
+	return key.Address, nil
+}
+
+// ImportECDSA stores the given key into the key directory, encrypting it with the passphrase.
+func (ks *KeyStore) ImportECDSA(priv *ecdsa.PrivateKey, passphrase string) (common.Address, error) {
+	key, err := newKeyFromECDSA(priv)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if ks.HasAddress(key.Address) {
+		return common.Address{}, ErrAccountAlreadyExists
+	}
+	if err := ks.storeNewKey(key, passphrase); err != nil {
+		return common.Address{}, err
+	}
+	return key.Address, nil
+}
+
+func (ks *KeyStore) storeNewKey(key *Key, passphrase string) error {
+	keyjson, err := EncryptKey(key, passphrase, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(ks.dir, keyFileName(key.Address))
+	if err := writeKeyFile(path, keyjson); err != nil {
+		return err
+	}
+	// Verify that we can decrypt the file with the given password.
+	stored, err := ks.getDecryptedKey(key.Address, passphrase)
+	if err != nil {
+		return fmt.Errorf("stored key could not be verified: %w", err)
+	}
+	zeroKey(stored.PrivateKey)
+	return nil
+}
+
+func (ks *KeyStore) getDecryptedKey(addr common.Address, auth string) (*Key, error) {
+	path, err := ks.find(addr)
+	if err != nil {
+		return nil, err
+	}
+	keyjson, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := DecryptKey(keyjson, auth)
+	if err != nil {
+		return nil, err
+	}
+	// Make sure we're really operating on the requested key (no swap attacks)
+	if key.Address != addr {
+		return nil, fmt.Errorf("key content mismatch: have account %x, want %x", key.Address, addr)
+	}
+	return key, nil
+}
+
+// Unlock unlocks the given account indefinitely.
+func (ks *KeyStore) Unlock(addr common.Address, passphrase string) error {
+	return ks.TimedUnlock(addr, passphrase, 0)
+}
+
+// Lock removes the private key with the given address from memory.
+func (ks *KeyStore) Lock(addr common.Address) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if u, found := ks.unlocked[addr]; found {
+		if u.abort != nil {
+			close(u.abort)
+		}
+		zeroKey(u.PrivateKey)
+		delete(ks.unlocked, addr)
+	}
+	return nil
+}
+
+// TimedUnlock unlocks the given account with the passphrase. The account
+// stays unlocked for the duration of timeout. A timeout of 0 unlocks the account
+// until the program exits.
+//
+// If the account address is already unlocked for a duration, TimedUnlock extends or
+// shortens the active unlock timeout. If the address was previously unlocked
+// indefinitely the timeout is not altered.
+func (ks *KeyStore) TimedUnlock(addr common.Address, passphrase string, timeout time.Duration) error {
+	key, err := ks.getDecryptedKey(addr, passphrase)
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	u, found := ks.unlocked[addr]
+	if found {
+		if u.abort == nil {
+			// The address was unlocked indefinitely, so unlocking
+			// it with a timeout would be confusing.
+			zeroKey(key.PrivateKey)
+			return nil
+		}
+		// Terminate the expire goroutine and replace it below.
+		close(u.abort)
+		zeroKey(u.PrivateKey)
+	}
+	if timeout > 0 {
+		u = &unlocked{Key: key, abort: make(chan struct{})}
+		go ks.expire(addr, u, timeout)
+	} else {
+		u = &unlocked{Key: key}
+	}
+	ks.unlocked[addr] = u
+	return nil
+}
+
+func (ks *KeyStore) expire(addr common.Address, u *unlocked, timeout time.Duration) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-u.abort:
+		// just quit
+	case <-t.C:
+		ks.mu.Lock()
+		// only drop if it's still the same key instance that dropLater
+		// was launched with. we can check that using pointer equality
+		// because the map stores a new pointer every time the key is
+		// unlocked.
+		if ks.unlocked[addr] == u {
+			zeroKey(u.PrivateKey)
+			delete(ks.unlocked, addr)
+		}
+		ks.mu.Unlock()
+	}
+}
+
+// IsUnlocked reports whether the private key of the given address is currently held in memory.
+func (ks *KeyStore) IsUnlocked(addr common.Address) bool {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	_, found := ks.unlocked[addr]
+	return found
+}
+
+// SignHash calculates a ECDSA signature for the given hash. The produced
+// signature is in the [R || S || V] format where V is 0 or 1.
+func (ks *KeyStore) SignHash(addr common.Address, hash []byte) ([]byte, error) {
+	// Look up the key to sign with and abort if it cannot be found
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	unlockedKey, found := ks.unlocked[addr]
+	if !found {
+		return nil, ErrLocked
+	}
+	// Sign the hash using plain ECDSA operations
+	return crypto.Sign(hash, unlockedKey.PrivateKey)
+}
+
+// SignTx signs the given transaction with the requested account.
+func (ks *KeyStore) SignTx(addr common.Address, tx types.Transaction, chainID *big.Int) (types.Transaction, error) {
+	// Look up the key to sign with and abort if it cannot be found
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	unlockedKey, found := ks.unlocked[addr]
+	if !found {
+		return nil, ErrLocked
+	}
+	// Depending on the presence of the chain ID, sign with 2718 or homestead
+	signer := types.LatestSignerForChainID(chainID)
+	return types.SignTx(tx, *signer, unlockedKey.PrivateKey)
+}
+
+// SignHashWithPassphrase signs hash if the private key matching the given address
+// can be decrypted with the given passphrase. The produced signature is in the
+// [R || S || V] format where V is 0 or 1.
+func (ks *KeyStore) SignHashWithPassphrase(addr common.Address, passphrase string, hash []byte) (signature []byte, err error) {
+	key, err := ks.getDecryptedKey(addr, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key.PrivateKey)
+	return crypto.Sign(hash, key.PrivateKey)
+}
+
+// SignTxWithPassphrase signs the transaction if the private key matching the
+// given address can be decrypted with the given passphrase.
+func (ks *KeyStore) SignTxWithPassphrase(addr common.Address, passphrase string, tx types.Transaction, chainID *big.Int) (types.Transaction, error) {
+	key, err := ks.getDecryptedKey(addr, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key.PrivateKey)
+	// Depending on the presence of the chain ID, sign with or without replay protection.
+	signer := types.LatestSignerForChainID(chainID)
+	return types.SignTx(tx, *signer, key.PrivateKey)
+}
@@ -0,0 +1,136 @@
+package keystore
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+func TestKeyStoreAccounts(t *testing.T) {
+	dir := t.TempDir()
+	ks := NewKeyStore(dir, LightScryptN, LightScryptP)
+
+	accounts, err := ks.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 0 {
+		t.Fatalf("expected no accounts, got %d", len(accounts))
+	}
+	a1, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Junk in the directory must be ignored
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, err := ks.ImportECDSA(priv, "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a2 != crypto.PubkeyToAddress(priv.PublicKey) {
+		t.Fatalf("imported address mismatch")
+	}
+	if _, err := ks.ImportECDSA(priv, "bar"); !errors.Is(err, ErrAccountAlreadyExists) {
+		t.Fatalf("expected ErrAccountAlreadyExists, got %v", err)
+	}
+	accounts, err = ks.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 || accounts[0] != a1 || accounts[1] != a2 {
+		t.Fatalf("unexpected accounts %x, want [%x %x]", accounts, a1, a2)
+	}
+}
+
+func TestKeyStoreUnlockAndSign(t *testing.T) {
+	ks := NewKeyStore(t.TempDir(), LightScryptN, LightScryptP)
+	addr, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := crypto.Keccak256([]byte("hello"))
+
+	if _, err := ks.SignHash(addr, hash); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if err := ks.Unlock(addr, "bar"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+	if err := ks.Unlock(common.Address{1}, "foo"); !errors.Is(err, ErrNoMatch) {
+		t.Fatalf("expected ErrNoMatch, got %v", err)
+	}
+	if err := ks.Unlock(addr, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ks.SignHash(addr, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != addr {
+		t.Fatalf("signature recovers to a wrong address")
+	}
+
+	chainID := big.NewInt(1337)
+	tx := types.NewTransaction(0, common.Address{2}, uint256.NewInt(1), 21000, uint256.NewInt(1), nil)
+	signed, err := ks.SignTx(addr, tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := signed.Sender(*types.LatestSignerForChainID(chainID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != addr {
+		t.Fatalf("transaction sender mismatch: have %x, want %x", from, addr)
+	}
+
+	if err := ks.Lock(addr); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.SignHash(addr, hash); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked after Lock, got %v", err)
+	}
+	// Signing with the passphrase does not require the account to be unlocked
+	if _, err := ks.SignHashWithPassphrase(addr, "foo", hash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.SignTxWithPassphrase(addr, "bar", tx, chainID); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestKeyStoreTimedUnlock(t *testing.T) {
+	ks := NewKeyStore(t.TempDir(), LightScryptN, LightScryptP)
+	addr, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.TimedUnlock(addr, "foo", 100*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if !ks.IsUnlocked(addr) {
+		t.Fatal("account should be unlocked")
+	}
+	time.Sleep(250 * time.Millisecond)
+	if ks.IsUnlocked(addr) {
+		t.Fatal("account should be locked after the timeout")
+	}
+}
@@ -0,0 +1,64 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+// Test vectors from https://github.com/ethereum/wiki/wiki/Web3-Secret-Storage-Definition
+const (
+	pbkdf2TestVector   = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2","kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+	scryptTestVector   = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt","kdfparams":{"dklen":32,"n":262144,"r":1,"p":8,"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+	testVectorPassword = "testpassword"
+	testVectorKey      = "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+)
+
+func TestDecryptTestVectors(t *testing.T) {
+	for name, vector := range map[string]string{"pbkdf2": pbkdf2TestVector, "scrypt": scryptTestVector} {
+		t.Run(name, func(t *testing.T) {
+			key, err := DecryptKey([]byte(vector), testVectorPassword)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have := hex.EncodeToString(crypto.FromECDSA(key.PrivateKey)); have != testVectorKey {
+				t.Fatalf("wrong private key: have %s, want %s", have, testVectorKey)
+			}
+			if key.Id != "3198bc9c-6672-5ab3-d995-4942343ae5b6" {
+				t.Fatalf("wrong key id: %s", key.Id)
+			}
+			if _, err := DecryptKey([]byte(vector), "wrong"); !errors.Is(err, ErrDecrypt) {
+				t.Fatalf("expected ErrDecrypt for wrong password, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEncryptDecryptKey(t *testing.T) {
+	key, err := newKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyjson, err := EncryptKey(key, "foo", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptKey(keyjson, "bar"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt for wrong password, got %v", err)
+	}
+	decrypted, err := DecryptKey(keyjson, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.Address != key.Address {
+		t.Fatalf("address mismatch: have %x, want %x", decrypted.Address, key.Address)
+	}
+	if decrypted.PrivateKey.D.Cmp(key.PrivateKey.D) != 0 {
+		t.Fatal("private key mismatch")
+	}
+	if decrypted.Id != key.Id {
+		t.Fatalf("id mismatch: have %s, want %s", decrypted.Id, key.Id)
+	}
+}
@@ -36,6 +36,10 @@ type Downloader struct {
 	stats     AggStats
 
 	folder storage.ClientImplCloser
+
+	webSeeds *WebSeeds // nil - no web seeds configured
+	ctx      context.Context
+	stop     context.CancelFunc
 }
 
 type AggStats struct {
@@ -97,6 +101,12 @@ func New(cfg *downloadercfg.Cfg) (*Downloader, error) {
 
 		statsLock: &sync.RWMutex{},
 	}
+	d.ctx, d.stop = context.WithCancel(context.Background())
+	if len(cfg.WebSeeds) > 0 {
+		if d.webSeeds, err = NewWebSeeds(cfg.WebSeeds, NewWebSeedsClient()); err != nil {
+			return nil, err
+		}
+	}
 	if err := d.addSegments(); err != nil {
 		return nil, err
 	}
@@ -139,6 +149,16 @@ func (d *Downloader) ReCalcStats(interval time.Duration) {
 
 		stats.Completed = stats.Completed && t.Complete.Bool()
 	}
+	filesTotal := int32(len(torrents))
+	if d.webSeeds != nil {
+		webSeeds := d.webSeeds.Stats()
+		filesTotal += webSeeds.FilesTotal
+		stats.MetadataReady += webSeeds.FilesTotal
+		stats.BytesCompleted += webSeeds.BytesCompleted
+		stats.BytesTotal += webSeeds.BytesTotal
+		stats.BytesDownload += webSeeds.BytesDownload
+		stats.Completed = stats.Completed && webSeeds.FilesTotal == 0
+	}
 
 	stats.DownloadRate = (stats.BytesDownload - prevStats.BytesDownload) / uint64(interval.Seconds())
 	stats.UploadRate = (stats.BytesUpload - prevStats.BytesUpload) / uint64(interval.Seconds())
@@ -152,7 +172,7 @@ func (d *Downloader) ReCalcStats(interval time.Duration) {
 		}
 	}
 	stats.PeersUnique = int32(len(peers))
-	stats.FilesTotal = int32(len(torrents))
+	stats.FilesTotal = filesTotal
 
 	if !prevStats.Completed && stats.Completed {
 		d.onComplete()
@@ -173,7 +193,7 @@ func copyFromTmp(snapDir string) error {
 		return err
 	}
 	for _, p := range paths {
-		if p.Name() == "." || p.Name() == ".." || p.Name() == "tmp" {
+		if p.Name() == "." || p.Name() == ".." || p.Name() == "tmp" || p.Name() == webSeedsDir {
 			continue
 		}
 		src := filepath.Join(tmpDir, p.Name())
@@ -296,6 +316,46 @@ func (d *Downloader) addSegments() error {
 	return nil
 }
 
+// FetchFromWebSeeds - fetches the file and its .torrent file from the web seeds in background, then adds them to the
+// torrent client to seed. Calls fallback if none of the web seeds has the file.
+func (d *Downloader) FetchFromWebSeeds(name string, infoHash metainfo.Hash, fallback func()) {
+	if !d.webSeeds.track(name) { // already in progress
+		return
+	}
+	snapDir := d.SnapDir()
+	go func() {
+		defer d.webSeeds.untrack(name)
+		if err := d.fetchFromWebSeeds(snapDir, name, infoHash); err != nil {
+			if d.ctx.Err() != nil {
+				return
+			}
+			log.Warn("[snapshots] webseeds failed, falling back to BitTorrent", "file", name, "err", err)
+			fallback()
+		}
+	}()
+}
+
+func (d *Downloader) fetchFromWebSeeds(snapDir, name string, infoHash metainfo.Hash) error {
+	if common.FileExist(filepath.Join(snapDir, name)) { // added by addSegments if it has .torrent file
+		return nil
+	}
+	mi, err := d.webSeeds.FetchTorrentFile(d.ctx, snapDir, name, infoHash)
+	if err != nil {
+		return fmt.Errorf("fetch .torrent: %w", err)
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return err
+	}
+	if err := d.webSeeds.Fetch(d.ctx, snapDir, &info); err != nil {
+		return err
+	}
+	if _, err := AddSegment(name, snapDir, d.Torrent()); err != nil {
+		return fmt.Errorf("AddSegment: %w", err)
+	}
+	return nil
+}
+
 func (d *Downloader) Stats() AggStats {
 	d.statsLock.RLock()
 	defer d.statsLock.RUnlock()
@@ -303,6 +363,7 @@ func (d *Downloader) Stats() AggStats {
 }
 
 func (d *Downloader) Close() {
+	d.stop()
 	d.torrentClient.Close()
 	if err := d.folder.Close(); err != nil {
 		log.Warn("[Snapshots] folder.close", "err", err)
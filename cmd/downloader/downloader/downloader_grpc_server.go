@@ -56,6 +56,18 @@ func (s *GrpcServer) Download(ctx context.Context, request *proto_downloader.Dow
 			continue
 		}
 
+		if s.d.webSeeds != nil {
+			if _, ok := torrentClient.Torrent(Proto2InfoHash(it.TorrentHash)); !ok {
+				hash := it.TorrentHash
+				s.d.FetchFromWebSeeds(it.Path, Proto2InfoHash(hash), func() {
+					if _, err := createMagnetLinkWithInfoHash(hash, torrentClient, snapDir); err != nil {
+						log.Warn("[snapshots] add magnet link", "err", err)
+					}
+				})
+				continue
+			}
+		}
+
 		_, err := createMagnetLinkWithInfoHash(it.TorrentHash, torrentClient, snapDir)
 		if err != nil {
 			return nil, err
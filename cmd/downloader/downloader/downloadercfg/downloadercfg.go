@@ -26,6 +26,7 @@ const DefaultNetworkChunkSize = DefaultPieceSize
 type Cfg struct {
 	*torrent.ClientConfig
 	DownloadSlots int
+	WebSeeds      []string // base URLs of HTTP(S) mirrors of the snapshots dir, files are fetched from them if set
 }
 
 func Default() *torrent.ClientConfig {
@@ -54,7 +55,7 @@ func Default() *torrent.ClientConfig {
 	return torrentConfig
 }
 
-func New(snapDir string, verbosity lg.Level, dbg bool, natif nat.Interface, downloadRate, uploadRate datasize.ByteSize, port, connsPerFile, downloadSlots int) (*Cfg, error) {
+func New(snapDir string, verbosity lg.Level, dbg bool, natif nat.Interface, downloadRate, uploadRate datasize.ByteSize, port, connsPerFile, downloadSlots int, webSeeds []string) (*Cfg, error) {
 	torrentConfig := Default()
 	// We would-like to reduce amount of goroutines in Erigon, so reducing next params
 	torrentConfig.EstablishedConnsPerTorrent = connsPerFile // default: 50
@@ -120,5 +121,5 @@ func New(snapDir string, verbosity lg.Level, dbg bool, natif nat.Interface, down
 	torrentConfig.Logger = lg.Default.FilterLevel(verbosity)
 	torrentConfig.Logger.Handlers = []lg.Handler{adapterHandler{}}
 
-	return &Cfg{ClientConfig: torrentConfig, DownloadSlots: downloadSlots}, nil
+	return &Cfg{ClientConfig: torrentConfig, DownloadSlots: downloadSlots, WebSeeds: webSeeds}, nil
 }
@@ -262,6 +262,7 @@ func mmapFile(name string) (mm mmap.MMap, err error) {
 
 func verifyTorrent(info *metainfo.Info, root string, consumer func(i int, good bool) error) error {
 	span := new(mmap_span.MMapSpan)
+	defer span.Close()
 	for _, file := range info.UpvertedFiles() {
 		filename := filepath.Join(append([]string{root, info.Name}, file.Path...)...)
 		mm, err := mmapFile(filename)
@@ -275,13 +276,10 @@ func verifyTorrent(info *metainfo.Info, root string, consumer func(i int, good b
 	}
 	span.InitIndex()
 	for i, numPieces := 0, info.NumPieces(); i < numPieces; i += 1 {
-		p := info.Piece(i)
-		hash := sha1.New() //nolint:gosec
-		_, err := io.Copy(hash, io.NewSectionReader(span, p.Offset(), p.Length()))
+		good, err := pieceHashMatches(span, info.Piece(i))
 		if err != nil {
 			return err
 		}
-		good := bytes.Equal(hash.Sum(nil), p.Hash().Bytes())
 		if err := consumer(i, good); err != nil {
 			return err
 		}
@@ -289,6 +287,14 @@ func verifyTorrent(info *metainfo.Info, root string, consumer func(i int, good b
 	return nil
 }
 
+func pieceHashMatches(r io.ReaderAt, p metainfo.Piece) (bool, error) {
+	hash := sha1.New() //nolint:gosec
+	if _, err := io.Copy(hash, io.NewSectionReader(r, p.Offset(), p.Length())); err != nil {
+		return false, err
+	}
+	return bytes.Equal(hash.Sum(nil), p.Hash().Bytes()), nil
+}
+
 // AddTorrentFile - adding .torrent file to torrentClient (and checking their hashes), if .torrent file
 // added first time - pieces verification process will start (disk IO heavy) - Progress
 // kept in `piece completion storage` (surviving reboot). Once it done - no disk IO needed again.
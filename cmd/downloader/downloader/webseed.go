@@ -0,0 +1,354 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/log/v3"
+	"go.uber.org/atomic"
+)
+
+// webSeedsDir - files are fetched into this sub-dir of snapshots dir, and moved from it only after verification
+const webSeedsDir = "webseeds"
+
+// webSeedAttempts - of every mirror, each attempt continues from the last verified piece
+const webSeedAttempts = 3
+
+// webSeedIdleTimeout - an attempt is abandoned when the mirror sends no data for this long, to fail over to the next one
+const webSeedIdleTimeout = time.Minute
+
+var errWebSeedBadPiece = errors.New("piece hash mismatch")
+
+// WebSeeds - fetches snapshot files over HTTP(S) from mirrors, useful for networks where BitTorrent traffic is blocked.
+// Every file is fetched by ranged GETs - it's resumed after failures and restarts, from the next mirror if one fails -
+// and verified against the piece hashes of its .torrent file.
+type WebSeeds struct {
+	urls        []string
+	client      *http.Client
+	idleTimeout time.Duration
+
+	lock  sync.RWMutex
+	files map[string]*webSeedFile // in progress, by file name
+
+	bytesDownload atomic.Uint64
+}
+
+type webSeedFile struct {
+	total     atomic.Int64 // 0 - until .torrent file is fetched
+	completed atomic.Int64
+}
+
+type WebSeedsStats struct {
+	FilesTotal                 int32
+	BytesCompleted, BytesTotal uint64
+	BytesDownload              uint64
+}
+
+func NewWebSeeds(urls []string, client *http.Client) (*WebSeeds, error) {
+	w := &WebSeeds{client: client, idleTimeout: webSeedIdleTimeout, files: map[string]*webSeedFile{}}
+	for _, s := range urls {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("webseed %q: %w", s, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("webseed %q: only http and https are supported", s)
+		}
+		w.urls = append(w.urls, strings.TrimSuffix(u.String(), "/"))
+	}
+	if len(w.urls) == 0 {
+		return nil, fmt.Errorf("no webseeds")
+	}
+	return w, nil
+}
+
+// NewWebSeedsClient - the http client of WebSeeds, it doesn't wait forever for a mirror to connect or to answer. There is
+// no limit on the whole request, the files are big - a stalled download is detected by WebSeeds itself.
+func NewWebSeedsClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 30 * time.Second,
+			IdleConnTimeout:       90 * time.Second,
+			MaxIdleConns:          16,
+		},
+	}
+}
+
+func (w *WebSeeds) Stats() (stats WebSeedsStats) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	for _, f := range w.files {
+		stats.FilesTotal++
+		stats.BytesTotal += uint64(f.total.Load())
+		stats.BytesCompleted += uint64(f.completed.Load())
+	}
+	stats.BytesDownload = w.bytesDownload.Load()
+	return stats
+}
+
+// FetchTorrentFile - returns .torrent file of given snapshot file, fetches it from the mirrors if snapDir doesn't have
+// it. The fetched file must have expected info hash.
+func (w *WebSeeds) FetchTorrentFile(ctx context.Context, snapDir, name string, infoHash metainfo.Hash) (*metainfo.MetaInfo, error) {
+	torrentFilePath := filepath.Join(snapDir, name+".torrent")
+	if common.FileExist(torrentFilePath) {
+		return metainfo.LoadFromFile(torrentFilePath)
+	}
+	var err error
+	for _, base := range w.urls {
+		var mi *metainfo.MetaInfo
+		mi, err = w.fetchTorrentFile(ctx, base+"/"+url.PathEscape(name)+".torrent", infoHash)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Debug("[snapshots] webseed .torrent", "file", name, "url", base, "err", err)
+			continue
+		}
+		info, err := mi.UnmarshalInfo()
+		if err != nil {
+			return nil, err
+		}
+		if info.Name != name {
+			return nil, fmt.Errorf("webseed .torrent of %s is for %s", name, info.Name)
+		}
+		if err := CreateTorrentFileIfNotExists(snapDir, &info, mi); err != nil {
+			return nil, err
+		}
+		return mi, nil
+	}
+	return nil, err
+}
+
+func (w *WebSeeds) fetchTorrentFile(ctx context.Context, fileURL string, infoHash metainfo.Hash) (*metainfo.MetaInfo, error) {
+	// .torrent files are small, the whole request must fit into the idle timeout
+	ctx, cancel := context.WithTimeout(ctx, w.idleTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	mi, err := metainfo.Load(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if got := mi.HashInfoBytes(); got != infoHash {
+		return nil, fmt.Errorf("info hash mismatch: got %x, expected %x", got, infoHash)
+	}
+	return mi, nil
+}
+
+// Fetch - fetches the file of info into snapDir. Mirrors are tried in turn, until the whole file is verified.
+// The file must be tracked, to be reported by Stats.
+func (w *WebSeeds) Fetch(ctx context.Context, snapDir string, info *metainfo.Info) error {
+	tracked := w.file(info.Name)
+	if tracked == nil {
+		return fmt.Errorf("%s is not tracked", info.Name)
+	}
+	tracked.total.Store(info.TotalLength())
+
+	stagingDir := filepath.Join(snapDir, webSeedsDir)
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return err
+	}
+	var err error
+Loop:
+	for _, base := range w.urls {
+		for i := 0; i < webSeedAttempts; i++ {
+			if err = w.fetchFrom(ctx, base+"/"+url.PathEscape(info.Name), stagingDir, info, tracked); err == nil {
+				break Loop
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Warn("[snapshots] webseed fetch", "file", info.Name, "url", base, "err", err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(stagingDir, info.Name), filepath.Join(snapDir, info.Name))
+}
+
+// track - reports the file as in progress until untrack, false - it's already in progress
+func (w *WebSeeds) track(name string) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if _, ok := w.files[name]; ok {
+		return false
+	}
+	w.files[name] = &webSeedFile{}
+	return true
+}
+
+func (w *WebSeeds) untrack(name string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	delete(w.files, name)
+}
+
+func (w *WebSeeds) file(name string) *webSeedFile {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return w.files[name]
+}
+
+// fetchFrom - one attempt to complete the staged file from one mirror
+func (w *WebSeeds) fetchFrom(ctx context.Context, fileURL, stagingDir string, info *metainfo.Info, tracked *webSeedFile) error {
+	f, err := os.OpenFile(filepath.Join(stagingDir, info.Name), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	offset, err := verifiedPrefix(f, info)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(offset); err != nil {
+		return err
+	}
+	tracked.completed.Store(offset)
+	total := info.TotalLength()
+	if offset < total {
+		// The attempt is cancelled once the mirror stalls, every write of the body restarts the timer
+		attemptCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stall := time.AfterFunc(w.idleTimeout, cancel)
+		defer stall.Stop()
+		stalled := func(err error) error {
+			if ctx.Err() == nil && attemptCtx.Err() != nil {
+				return fmt.Errorf("no data from the mirror for %s: %w", w.idleTimeout, err)
+			}
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(attemptCtx, http.MethodGet, fileURL, nil)
+		if err != nil {
+			return err
+		}
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+		resp, err := w.client.Do(req)
+		if err != nil {
+			return stalled(err)
+		}
+		defer resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+			if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+				return fmt.Errorf("unexpected Content-Range: %q", resp.Header.Get("Content-Range"))
+			}
+		case http.StatusOK: // mirror doesn't support ranges, start over
+			if err := f.Truncate(0); err != nil {
+				return err
+			}
+			offset = 0
+			tracked.completed.Store(0)
+		default:
+			return fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		n, err := io.Copy(&webSeedWriter{w: f, file: tracked, seeds: w, stall: stall}, io.LimitReader(resp.Body, total-offset))
+		if err != nil {
+			return stalled(err)
+		}
+		if offset+n != total {
+			return fmt.Errorf("%w: got %d bytes, expected %d", io.ErrUnexpectedEOF, offset+n, total)
+		}
+	}
+
+	var badPiece int
+	if err = verifyTorrent(info, stagingDir, func(i int, good bool) error {
+		if !good {
+			badPiece = i
+			return errWebSeedBadPiece
+		}
+		return nil
+	}); err != nil {
+		if !errors.Is(err, errWebSeedBadPiece) {
+			return err
+		}
+		p := info.Piece(badPiece)
+		if err := f.Truncate(p.Offset()); err != nil {
+			return err
+		}
+		tracked.completed.Store(p.Offset())
+		return fmt.Errorf("%w, piece: %d", errWebSeedBadPiece, badPiece)
+	}
+	return f.Sync()
+}
+
+// verifiedPrefix - size of the leading pieces of the file, which are complete and have expected hashes
+func verifiedPrefix(f *os.File, info *metainfo.Info) (int64, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	var verified int64
+	for i := 0; i < info.NumPieces(); i++ {
+		p := info.Piece(i)
+		if p.Offset()+p.Length() > fi.Size() {
+			break
+		}
+		good, err := pieceHashMatches(f, p)
+		if err != nil {
+			return 0, err
+		}
+		if !good {
+			break
+		}
+		verified = p.Offset() + p.Length()
+	}
+	return verified, nil
+}
+
+type webSeedWriter struct {
+	w     io.Writer
+	file  *webSeedFile
+	seeds *WebSeeds
+	stall *time.Timer
+}
+
+func (w *webSeedWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.file.completed.Add(int64(n))
+	w.seeds.bytesDownload.Add(uint64(n))
+	if n > 0 {
+		w.stall.Reset(w.seeds.idleTimeout)
+	}
+	return n, err
+}
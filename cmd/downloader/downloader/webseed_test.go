@@ -0,0 +1,169 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync/snap"
+	"github.com/stretchr/testify/require"
+)
+
+const webSeedTestPieceLength = 64
+
+// createWebSeedMirror - creates dir with .seg file and its .torrent file, as mirrors serve them
+func createWebSeedMirror(t *testing.T) (dir string, data []byte, mi *metainfo.MetaInfo, info *metainfo.Info) {
+	t.Helper()
+	dir = t.TempDir()
+	name := snap.SegmentFileName(0, 500_000, snap.Headers)
+	data = make([]byte, 20*webSeedTestPieceLength+10)
+	rand.New(rand.NewSource(1)).Read(data)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+
+	info = &metainfo.Info{PieceLength: webSeedTestPieceLength}
+	require.NoError(t, info.BuildFromFilePath(filepath.Join(dir, name)))
+	require.NoError(t, createTorrentFileFromInfo(dir, info, nil))
+	mi, err := metainfo.LoadFromFile(filepath.Join(dir, name+".torrent"))
+	require.NoError(t, err)
+	return dir, data, mi, info
+}
+
+func TestWebSeedsFetch(t *testing.T) {
+	mirrorDir, data, mi, info := createWebSeedMirror(t)
+	name := info.Name
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+	var lock sync.Mutex
+	var ranges []string
+	fileServer := http.FileServer(http.Dir(mirrorDir))
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".seg") {
+			lock.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			lock.Unlock()
+		}
+		fileServer.ServeHTTP(w, r)
+	}))
+	defer mirror.Close()
+
+	w, err := NewWebSeeds([]string{broken.URL, mirror.URL + "/"}, mirror.Client())
+	require.NoError(t, err)
+
+	// staged by previous run: 3 good pieces and garbage after them
+	snapDir := t.TempDir()
+	stagingDir := filepath.Join(snapDir, webSeedsDir)
+	require.NoError(t, os.MkdirAll(stagingDir, 0755))
+	staged := append(common.CopyBytes(data[:3*webSeedTestPieceLength]), bytes.Repeat([]byte{0xff}, 10)...)
+	require.NoError(t, os.WriteFile(filepath.Join(stagingDir, name), staged, 0644))
+
+	ctx := context.Background()
+	require.True(t, w.track(name))
+	require.False(t, w.track(name))
+	defer w.untrack(name)
+
+	fetched, err := w.FetchTorrentFile(ctx, snapDir, name, mi.HashInfoBytes())
+	require.NoError(t, err)
+	require.Equal(t, mi.HashInfoBytes(), fetched.HashInfoBytes())
+	require.True(t, common.FileExist(filepath.Join(snapDir, name+".torrent")))
+
+	fetchedInfo, err := fetched.UnmarshalInfo()
+	require.NoError(t, err)
+	require.NoError(t, w.Fetch(ctx, snapDir, &fetchedInfo))
+	require.Equal(t, []string{"bytes=192-"}, ranges)
+
+	got, err := os.ReadFile(filepath.Join(snapDir, name))
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+	require.False(t, common.FileExist(filepath.Join(stagingDir, name)))
+
+	stats := w.Stats()
+	require.Equal(t, int32(1), stats.FilesTotal)
+	require.Equal(t, uint64(len(data)), stats.BytesTotal)
+	require.Equal(t, uint64(len(data)), stats.BytesCompleted)
+	require.Equal(t, uint64(len(data)-3*webSeedTestPieceLength), stats.BytesDownload)
+}
+
+func TestWebSeedsBadPiece(t *testing.T) {
+	mirrorDir, data, _, info := createWebSeedMirror(t)
+	name := info.Name
+
+	corrupted := common.CopyBytes(data)
+	corrupted[5*webSeedTestPieceLength+1] ^= 0xff
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorDir, name), corrupted, 0644))
+	mirror := httptest.NewServer(http.FileServer(http.Dir(mirrorDir)))
+	defer mirror.Close()
+
+	w, err := NewWebSeeds([]string{mirror.URL}, mirror.Client())
+	require.NoError(t, err)
+	snapDir := t.TempDir()
+	require.True(t, w.track(name))
+	defer w.untrack(name)
+
+	err = w.Fetch(context.Background(), snapDir, info)
+	require.True(t, errors.Is(err, errWebSeedBadPiece), err)
+	require.False(t, common.FileExist(filepath.Join(snapDir, name)))
+
+	// only verified pieces are kept to resume from
+	fi, err := os.Stat(filepath.Join(snapDir, webSeedsDir, name))
+	require.NoError(t, err)
+	require.Equal(t, int64(5*webSeedTestPieceLength), fi.Size())
+}
+
+func TestWebSeedsTorrentFileHashMismatch(t *testing.T) {
+	mirrorDir, _, _, info := createWebSeedMirror(t)
+	mirror := httptest.NewServer(http.FileServer(http.Dir(mirrorDir)))
+	defer mirror.Close()
+
+	w, err := NewWebSeeds([]string{mirror.URL}, mirror.Client())
+	require.NoError(t, err)
+	snapDir := t.TempDir()
+	_, err = w.FetchTorrentFile(context.Background(), snapDir, info.Name, metainfo.Hash{1})
+	require.Error(t, err)
+	require.False(t, common.FileExist(filepath.Join(snapDir, info.Name+".torrent")))
+
+	_, err = NewWebSeeds([]string{"ftp://mirror"}, mirror.Client())
+	require.Error(t, err)
+}
+
+func TestWebSeedsStalledMirror(t *testing.T) {
+	mirrorDir, data, _, info := createWebSeedMirror(t)
+
+	// sends the headers and a few bytes, then nothing until the client gives up
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data[:100])
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer stalled.Close()
+	mirror := httptest.NewServer(http.FileServer(http.Dir(mirrorDir)))
+	defer mirror.Close()
+
+	w, err := NewWebSeeds([]string{stalled.URL, mirror.URL}, NewWebSeedsClient())
+	require.NoError(t, err)
+	w.idleTimeout = 100 * time.Millisecond
+	require.True(t, w.track(info.Name))
+	defer w.untrack(info.Name)
+
+	snapDir := t.TempDir()
+	require.NoError(t, w.Fetch(context.Background(), snapDir, info))
+	got, err := os.ReadFile(filepath.Join(snapDir, info.Name))
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+}
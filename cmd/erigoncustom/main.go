@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	erigonapp "github.com/ledgerwatch/erigon/turbo/app"
 	erigoncli "github.com/ledgerwatch/erigon/turbo/cli"
+	"github.com/ledgerwatch/erigon/turbo/node"
+	"github.com/ledgerwatch/log/v3"
 
 	"github.com/urfave/cli"
 )
@@ -16,10 +21,9 @@ var flag = cli.StringFlag{
 	Value: "default-value",
 }
 
-// defining a custom bucket name
-const (
-	customBucketName = "ch.torquem.demo.tgcustom.CUSTOM_BUCKET" //nolint
-)
+// defining a custom stage name, its progress is saved under this name and shown by `integration print_stages`
+// and `eth_syncing`
+const customStage stages.SyncStage = "ch.torquem.demo.tgcustom.GREETING"
 
 // the regular main function
 func main() {
@@ -34,17 +38,44 @@ func main() {
 }
 
 // Erigon main function
-func runErigon(ctx *cli.Context) {
-	// running a node and initializing a custom bucket with all default settings
-	//eri := node.New(ctx, node.Params{
-	//	CustomBuckets: map[string]dbutils.BucketConfigItem{
-	//		customBucketName: {},
-	//	},
-	//})
-
-	//err := eri.Serve()
-
-	//if err != nil {
-	//	log.Error("error while serving a Erigon node", "err", err)
-	//}
+func runErigon(cliCtx *cli.Context) {
+	logger := log.New()
+	nodeCfg := node.NewNodConfigUrfave(cliCtx)
+	ethCfg := node.NewEthConfigUrfave(cliCtx, nodeCfg)
+
+	ethNode, err := node.New(nodeCfg, ethCfg, logger)
+	if err != nil {
+		log.Error("Erigon startup", "err", err)
+		return
+	}
+
+	// registering a custom stage, which runs after execution of blocks
+	greeting := cliCtx.String(flag.Name)
+	if err := ethNode.Backend().StagedSync().AddStage(&stagedsync.Stage{
+		ID:          customStage,
+		Description: "Greet executed blocks",
+		Forward: func(firstCycle bool, badBlockUnwind bool, s *stagedsync.StageState, u stagedsync.Unwinder, tx kv.RwTx) error {
+			to, err := s.ExecutionAt(tx)
+			if err != nil {
+				return err
+			}
+			if to > s.BlockNumber {
+				log.Info(fmt.Sprintf("[%s] %s", s.LogPrefix(), greeting), "from", s.BlockNumber+1, "to", to)
+			}
+			return s.Update(tx, to)
+		},
+		Unwind: func(firstCycle bool, u *stagedsync.UnwindState, s *stagedsync.StageState, tx kv.RwTx) error {
+			return u.Done(tx)
+		},
+		Prune: func(firstCycle bool, p *stagedsync.PruneState, tx kv.RwTx) error {
+			return p.Done(tx)
+		},
+	}, stages.Execution); err != nil {
+		log.Error("Erigon startup", "err", err)
+		return
+	}
+
+	if err := ethNode.Serve(); err != nil {
+		log.Error("error while serving an Erigon node", "err", err)
+	}
 }
@@ -278,7 +278,7 @@ func Main(ctx *cli.Context) error {
 		return err
 	}
 
-	reader, writer := MakePreState(chainConfig.Rules(0), tx, prestate.Pre)
+	reader, writer := MakePreState(chainConfig.Rules(0, 0), tx, prestate.Pre)
 	engine := ethash.NewFaker()
 
 	result, err := core.ExecuteBlockEphemerally(chainConfig, &vmConfig, getHash, engine, block, reader, writer, nil, nil, nil, true, getTracer)
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"math"
+
 	"github.com/spf13/cobra"
 
 	"github.com/ledgerwatch/erigon/cmd/utils"
@@ -19,6 +21,10 @@ var (
 	bucket                         string
 	datadirCli, toChaindata        string
 	migration                      string
+	dryRun                         bool
+	verifyFrom, verifyTo           uint64
+	verifySample                   uint64
+	verifyUnwind                   bool
 	integrityFast, integritySlow   bool
 	file                           string
 	HeimdallURL                    string
@@ -118,6 +124,17 @@ func withMigration(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&migration, "migration", "", "action to apply to given migration")
 }
 
+func withDryRun(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "apply in transaction which is rolled back, report touched tables")
+}
+
+func withVerifyStages(cmd *cobra.Command) {
+	cmd.Flags().Uint64Var(&verifyFrom, "from", 0, "first block to verify")
+	cmd.Flags().Uint64Var(&verifyTo, "to", math.MaxUint64, "last block to verify, progress of each stage by default")
+	cmd.Flags().Uint64Var(&verifySample, "sample", 1000, "re-execute every N-th block and hash every N-th state entry, 0 - disable such checks")
+	cmd.Flags().BoolVar(&verifyUnwind, "unwind", false, "unwind earliest diverged stage, and stages after it, to block before divergence")
+}
+
 func withTxTrace(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&txtrace, "txtrace", false, "enable tracing of transactions")
 }
@@ -61,7 +61,11 @@ func printStages(db kv.Tx, snapshots *snapshotsync.RoSnapshots) error {
 	w.Init(os.Stdout, 8, 8, 0, '\t', 0)
 	fmt.Fprintf(w, "Note: prune_at doesn't mean 'all data before were deleted' - it just mean stage.Prune function were run to this block. Because 1 stage may prune multiple data types to different prune distance.\n")
 	fmt.Fprint(w, "\n \t stage_at \t prune_at\n")
-	for _, stage := range stages.AllStages {
+	custom, err := stages.CustomStages(db)
+	if err != nil {
+		return err
+	}
+	for _, stage := range append(append([]stages.SyncStage{}, stages.AllStages...), custom...) {
 		if progress, err = stages.GetStageProgress(db, stage); err != nil {
 			return err
 		}
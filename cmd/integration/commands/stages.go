@@ -262,6 +262,52 @@ var cmdRemoveMigration = &cobra.Command{
 	},
 }
 
+var cmdListMigrations = &cobra.Command{
+	Use:   "list_migrations",
+	Short: "print all migrations of this version: applied or pending, and how they can be reverted",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, _ := common2.RootContext()
+		logger := log.New()
+		db := openDB(dbCfg(kv.ChainDB, logger, chaindata), false)
+		defer db.Close()
+		if err := listMigrations(db, ctx); err != nil {
+			log.Error("Error", "err", err)
+			return err
+		}
+		return nil
+	},
+}
+
+var cmdApplyMigrations = &cobra.Command{
+	Use:   "apply_migrations",
+	Short: "apply pending migrations until --migration (inclusive), all of them by default",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := log.New()
+		db := openDB(dbCfg(kv.ChainDB, logger, chaindata).Exclusive(), false)
+		defer db.Close()
+		if err := applyMigrations(db); err != nil {
+			log.Error("Error", "err", err)
+			return err
+		}
+		return nil
+	},
+}
+
+var cmdRevertMigration = &cobra.Command{
+	Use:   "revert_migration",
+	Short: "revert --migration by its Down function or restore its tables from backup, unlike remove_migration which only removes the mark",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := log.New()
+		db := openDB(dbCfg(kv.ChainDB, logger, chaindata).Exclusive(), false)
+		defer db.Close()
+		if err := migrations.NewMigrator(kv.ChainDB).Revert(db, datadirCli, migration); err != nil {
+			log.Error("Error", "err", err)
+			return err
+		}
+		return nil
+	},
+}
+
 var cmdRunMigrations = &cobra.Command{
 	Use:   "run_migrations",
 	Short: "",
@@ -416,6 +462,19 @@ func init() {
 	withHeimdall(cmdRemoveMigration)
 	rootCmd.AddCommand(cmdRemoveMigration)
 
+	withDataDir(cmdListMigrations)
+	rootCmd.AddCommand(cmdListMigrations)
+
+	withDataDir(cmdApplyMigrations)
+	withMigration(cmdApplyMigrations)
+	withDryRun(cmdApplyMigrations)
+	rootCmd.AddCommand(cmdApplyMigrations)
+
+	withDataDir(cmdRevertMigration)
+	withMigration(cmdRevertMigration)
+	must(cmdRevertMigration.MarkFlagRequired("migration"))
+	rootCmd.AddCommand(cmdRevertMigration)
+
 	withDataDir(cmdRunMigrations)
 	withChain(cmdRunMigrations)
 	withHeimdall(cmdRunMigrations)
@@ -1073,6 +1132,44 @@ func removeMigration(db kv.RwDB, ctx context.Context) error {
 	})
 }
 
+func listMigrations(db kv.RwDB, ctx context.Context) error {
+	return db.View(ctx, func(tx kv.Tx) error {
+		applied, err := migrations.AppliedMigrations(tx, false /* withPayload */)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations.NewMigrator(kv.ChainDB).Migrations {
+			status := "pending"
+			if _, ok := applied[m.Name]; ok {
+				status = "applied"
+			}
+			revert := "no"
+			if m.Down != nil {
+				revert = "down"
+			} else if len(m.Tables) > 0 {
+				revert = "backup"
+			}
+			log.Info("Migration", "name", m.Name, "status", status, "revert", revert)
+		}
+		return nil
+	})
+}
+
+func applyMigrations(db kv.RwDB) error {
+	migrator := migrations.NewMigrator(kv.ChainDB)
+	if !dryRun {
+		return migrator.ApplyUntil(db, datadirCli, migration)
+	}
+	changes, err := migrator.DryRun(db, datadirCli, migration)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		log.Info("[dry-run] Touched", "table", c.Table, "rows_before", c.RowsBefore, "rows_after", c.RowsAfter)
+	}
+	return nil
+}
+
 var openSnapshotOnce sync.Once
 var _allSnapshotsSingleton *snapshotsync.RoSnapshots
 
@@ -351,7 +351,7 @@ func syncBySmallSteps(db kv.RwDB, miningConfig params.MiningConfig, ctx context.
 		}
 
 		to := execAtBlock - unwind
-		stateStages.UnwindTo(to, common.Hash{})
+		stateStages.UnwindTo(to, common.Hash{}, nil)
 
 		if err := tx.Commit(); err != nil {
 			return err
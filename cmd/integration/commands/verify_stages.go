@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+
+	common2 "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/cmd/hack/tool"
+	"github.com/ledgerwatch/erigon/eth/integrity"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/spf13/cobra"
+)
+
+var cmdVerifyStages = &cobra.Command{
+	Use:     "verify_stages",
+	Short:   "check output of every stage against its input on blocks [--from, --to], report first divergence of each stage",
+	Example: "go run ./cmd/integration verify_stages --datadir=... --from=15000000 --to=15100000 --sample=100 --unwind",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, _ := common2.RootContext()
+		logger := log.New()
+		db := openDB(dbCfg(kv.ChainDB, logger, chaindata), false)
+		defer db.Close()
+
+		if err := verifyStages(db, ctx); err != nil {
+			log.Error("Error", "err", err)
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	withDataDir(cmdVerifyStages)
+	withVerifyStages(cmdVerifyStages)
+	withChain(cmdVerifyStages)
+	withHeimdall(cmdVerifyStages)
+	rootCmd.AddCommand(cmdVerifyStages)
+}
+
+func verifyStages(db kv.RwDB, ctx context.Context) error {
+	pm, engine, _, sync, _, _ := newSync(ctx, db, nil)
+	cfg := integrity.VerifyCfg{
+		ChainConfig: tool.ChainConfigFromDB(db),
+		BlockReader: getBlockReader(db),
+		Engine:      engine,
+		Prune:       pm,
+		Sample:      verifySample,
+	}
+
+	var divergences []*integrity.Divergence
+	if err := db.View(ctx, func(tx kv.Tx) (err error) {
+		divergences, err = integrity.VerifyStages(ctx, tx, cfg, verifyFrom, verifyTo)
+		return err
+	}); err != nil {
+		return err
+	}
+	if len(divergences) == 0 {
+		log.Info("[verify] All stages are consistent", "from", verifyFrom, "to", verifyTo)
+		return nil
+	}
+	for _, d := range divergences {
+		log.Warn("[verify] Divergence", "stage", d.Stage, "block", d.Block, "err", d.Err)
+	}
+	if !verifyUnwind {
+		return nil
+	}
+
+	// stages after earliest diverged one are unwound together with it
+	d := divergences[0]
+	var unwindTo uint64
+	if d.Block > 0 {
+		unwindTo = d.Block - 1
+	}
+	log.Info("[verify] Unwind", "stage", d.Stage, "to", unwindTo)
+	return db.Update(ctx, func(tx kv.RwTx) error {
+		return sync.UnwindFrom(d.Stage, unwindTo, db, tx)
+	})
+}
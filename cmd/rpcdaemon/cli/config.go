@@ -34,6 +34,7 @@ import (
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/common/paths"
 	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/ethdb/privateapi"
 	"github.com/ledgerwatch/erigon/internal/debug"
 	"github.com/ledgerwatch/erigon/node"
 	"github.com/ledgerwatch/erigon/node/nodecfg"
@@ -78,9 +79,20 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().StringSliceVar(&cfg.API, "http.api", []string{"eth", "erigon", "engine"}, "API's offered over the HTTP-RPC interface: eth,engine,erigon,web3,net,debug,trace,txpool,db,starknet. Supported methods: https://github.com/ledgerwatch/erigon/tree/devel/cmd/rpcdaemon")
 	rootCmd.PersistentFlags().Uint64Var(&cfg.Gascap, "rpc.gascap", 50000000, "Sets a cap on gas that can be used in eth_call/estimateGas")
 	rootCmd.PersistentFlags().Uint64Var(&cfg.MaxTraces, "trace.maxtraces", 200, "Sets a limit on traces that can be returned in trace_filter")
+	rootCmd.PersistentFlags().Uint64Var(&cfg.OtsMaxPageSize, "ots.search.max.pagesize", 25, "Sets a limit on the page size of ots_searchTransactionsBefore/After")
+	rootCmd.PersistentFlags().Uint64Var(&cfg.MaxGetProofRewindBlockCount, utils.RpcMaxGetProofRewindBlockCountFlag.Name, utils.RpcMaxGetProofRewindBlockCountFlag.Value, utils.RpcMaxGetProofRewindBlockCountFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&cfg.MaxGetLogsBlockRange, utils.RpcMaxGetLogsBlockRangeFlag.Name, utils.RpcMaxGetLogsBlockRangeFlag.Value, utils.RpcMaxGetLogsBlockRangeFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&cfg.MaxGetLogsResults, utils.RpcMaxGetLogsResultsFlag.Name, utils.RpcMaxGetLogsResultsFlag.Value, utils.RpcMaxGetLogsResultsFlag.Usage)
+	rootCmd.PersistentFlags().IntVar(&cfg.TraceWorkers, utils.RpcTraceWorkersFlag.Name, utils.RpcTraceWorkersFlag.Value, utils.RpcTraceWorkersFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&cfg.HealthMaxBlocksBehind, utils.RpcHealthMaxBlocksBehindFlag.Name, utils.RpcHealthMaxBlocksBehindFlag.Value, utils.RpcHealthMaxBlocksBehindFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&cfg.KeystoreDir, utils.KeyStoreDirFlag.Name, "", "Directory for the encrypted account keys used by eth_sendTransaction, eth_sign and personal_ methods (default: <datadir>/keystore if --datadir set)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.KeystoreLightKDF, utils.LightKDFFlag.Name, false, utils.LightKDFFlag.Usage)
+	rootCmd.PersistentFlags().BoolVar(&cfg.AllowInsecureUnlock, utils.InsecureUnlockAllowedFlag.Name, false, utils.InsecureUnlockAllowedFlag.Usage)
 	rootCmd.PersistentFlags().BoolVar(&cfg.WebsocketEnabled, "ws", false, "Enable Websockets")
 	rootCmd.PersistentFlags().BoolVar(&cfg.WebsocketCompression, "ws.compression", false, "Enable Websocket compression (RFC 7692)")
 	rootCmd.PersistentFlags().StringVar(&cfg.RpcAllowListFilePath, "rpc.accessList", "", "Specify granular (method-by-method) API allowlist")
+	rootCmd.PersistentFlags().StringVar(&cfg.RpcRateLimitFilePath, utils.RpcRateLimitFlag.Name, "", utils.RpcRateLimitFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&cfg.RpcRateLimitJWTPath, utils.RpcRateLimitJWTSecretFlag.Name, "", utils.RpcRateLimitJWTSecretFlag.Usage)
 	rootCmd.PersistentFlags().UintVar(&cfg.RpcBatchConcurrency, utils.RpcBatchConcurrencyFlag.Name, 2, utils.RpcBatchConcurrencyFlag.Usage)
 	rootCmd.PersistentFlags().BoolVar(&cfg.RpcStreamingDisable, utils.RpcStreamingDisableFlag.Name, false, utils.RpcStreamingDisableFlag.Usage)
 	rootCmd.PersistentFlags().IntVar(&cfg.DBReadConcurrency, "db.read.concurrency", runtime.GOMAXPROCS(-1), "Does limit amount of parallel db reads")
@@ -106,6 +118,12 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	if err := rootCmd.MarkPersistentFlagFilename("rpc.accessList", "json"); err != nil {
 		panic(err)
 	}
+	if err := rootCmd.MarkPersistentFlagFilename(utils.RpcRateLimitFlag.Name, "json"); err != nil {
+		panic(err)
+	}
+	if err := rootCmd.MarkPersistentFlagFilename(utils.RpcRateLimitJWTSecretFlag.Name); err != nil {
+		panic(err)
+	}
 	if err := rootCmd.MarkPersistentFlagDirname("datadir"); err != nil {
 		panic(err)
 	}
@@ -120,6 +138,9 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 				cfg.DataDir = paths.DefaultDataDir()
 			}
 			cfg.Dirs = datadir.New(cfg.DataDir)
+			if cfg.KeystoreDir == "" {
+				cfg.KeystoreDir = cfg.Dirs.Keystore
+			}
 		}
 		if cfg.TxPoolApiAddr == "" {
 			cfg.TxPoolApiAddr = cfg.PrivateApiAddr
@@ -216,7 +237,7 @@ func checkDbCompatibility(ctx context.Context, db kv.RoDB) error {
 }
 
 func EmbeddedServices(ctx context.Context, erigonDB kv.RoDB, stateCacheCfg kvcache.CoherentConfig, blockReader services.FullBlockReader, snapshots remotedbserver.Snapsthots, ethBackendServer remote.ETHBACKENDServer,
-	txPoolServer txpool.TxpoolServer, miningServer txpool.MiningServer,
+	txPoolServer txpool.TxpoolServer, miningServer txpool.MiningServer, cliqueServer *privateapi.CliqueServer,
 ) (
 	eth rpchelper.ApiBackend, txPool txpool.TxpoolClient, mining txpool.MiningClient, starknet *rpcservices.StarknetService, stateCache kvcache.Cache, ff *rpchelper.Filters, err error,
 ) {
@@ -231,7 +252,14 @@ func EmbeddedServices(ctx context.Context, erigonDB kv.RoDB, stateCacheCfg kvcac
 
 	directClient := direct.NewEthBackendClientDirect(ethBackendServer)
 
-	eth = rpcservices.NewRemoteBackend(directClient, erigonDB, blockReader)
+	remoteBackend := rpcservices.NewRemoteBackend(directClient, erigonDB, blockReader)
+	if engineV2, ok := ethBackendServer.(privateapi.EngineV2Server); ok {
+		remoteBackend.WithEngineV2(engineV2)
+	}
+	if cliqueServer != nil {
+		remoteBackend.WithClique(privateapi.NewCliqueClientDirect(cliqueServer))
+	}
+	eth = remoteBackend
 	txPool = direct.NewTxPoolClient(txPoolServer)
 	mining = direct.NewMiningClient(miningServer)
 	ff = rpchelper.New(ctx, eth, txPool, mining, func() {})
@@ -241,13 +269,13 @@ func EmbeddedServices(ctx context.Context, erigonDB kv.RoDB, stateCacheCfg kvcac
 // RemoteServices - use when RPCDaemon run as independent process. Still it can use --datadir flag to enable
 // `cfg.WithDatadir` (mode when it on 1 machine with Erigon)
 func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger, rootCancel context.CancelFunc) (
-	db kv.RoDB, borDb kv.RoDB,
+	db kv.RoDB, consensusDb kv.RoDB,
 	eth rpchelper.ApiBackend, txPool txpool.TxpoolClient, mining txpool.MiningClient,
 	starknet *rpcservices.StarknetService,
-	stateCache kvcache.Cache, blockReader services.FullBlockReader,
+	stateCache kvcache.Cache, blockReader services.FullBlockReader, snapshots *snapshotsync.RoSnapshots,
 	ff *rpchelper.Filters, err error) {
 	if !cfg.WithDatadir && cfg.PrivateApiAddr == "" {
-		return nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("either remote db or local db must be specified")
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("either remote db or local db must be specified")
 	}
 
 	// Do not change the order of these checks. Chaindata needs to be checked first, because PrivateApiAddr has default value which is not ""
@@ -258,33 +286,14 @@ func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger,
 		limiter := semaphore.NewWeighted(int64(cfg.DBReadConcurrency))
 		rwKv, err = kv2.NewMDBX(logger).RoTxsLimiter(limiter).Path(cfg.Dirs.Chaindata).Readonly().Open()
 		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, nil, ff, err
+			return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, err
 		}
 		if compatErr := checkDbCompatibility(ctx, rwKv); compatErr != nil {
-			return nil, nil, nil, nil, nil, nil, nil, nil, ff, compatErr
+			return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, compatErr
 		}
 		db = rwKv
 		stateCache = kvcache.NewDummy()
 		blockReader = snapshotsync.NewBlockReader()
-
-		// bor (consensus) specific db
-		var borKv kv.RoDB
-		borDbPath := filepath.Join(cfg.DataDir, "bor")
-		{
-			// ensure db exist
-			tmpDb, err := kv2.NewMDBX(logger).Path(borDbPath).Label(kv.ConsensusDB).Open()
-			if err != nil {
-				return nil, nil, nil, nil, nil, nil, nil, nil, ff, err
-			}
-			tmpDb.Close()
-		}
-		log.Trace("Creating consensus db", "path", borDbPath)
-		borKv, err = kv2.NewMDBX(logger).Path(borDbPath).Label(kv.ConsensusDB).Readonly().Open()
-		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, nil, ff, err
-		}
-		// Skip the compatibility check, until we have a schema in erigon-lib
-		borDb = borKv
 	} else {
 		if cfg.StateCache.KeysLimit > 0 {
 			stateCache = kvcache.New(cfg.StateCache)
@@ -314,13 +323,36 @@ func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger,
 			}
 			return nil
 		}); err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, nil, ff, err
+			return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, err
 		}
 		if cc == nil {
-			return nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("chain config not found in db. Need start erigon at least once on this db")
+			return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("chain config not found in db. Need start erigon at least once on this db")
 		}
 		cfg.Snap.Enabled = cfg.Snap.Enabled || cfg.Sync.UseSnapshots
 
+		// consensus specific db: snapshots of bor, clique and parlia, the same paths as Erigon uses for them
+		consensusDbPath := filepath.Join(cfg.DataDir, "bor")
+		switch {
+		case cc.Clique != nil:
+			consensusDbPath = filepath.Join(cfg.DataDir, "clique", "db")
+		case cc.Parlia != nil:
+			consensusDbPath = filepath.Join(cfg.DataDir, "parlia")
+		}
+		{
+			// ensure db exist
+			tmpDb, err := kv2.NewMDBX(logger).Path(consensusDbPath).Label(kv.ConsensusDB).Open()
+			if err != nil {
+				return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, err
+			}
+			tmpDb.Close()
+		}
+		log.Trace("Creating consensus db", "path", consensusDbPath)
+		consensusDb, err = kv2.NewMDBX(logger).Path(consensusDbPath).Label(kv.ConsensusDB).Readonly().Open()
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, err
+		}
+		// Skip the compatibility check, until we have a schema in erigon-lib
+
 		// if chain config has terminal total difficulty then rpc must have eth and engine APIs enableds
 		if cc.TerminalTotalDifficulty != nil {
 			hasEthApiEnabled := false
@@ -347,17 +379,17 @@ func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger,
 
 	creds, err := grpcutil.TLS(cfg.TLSCACert, cfg.TLSCertfile, cfg.TLSKeyFile)
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("open tls cert: %w", err)
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("open tls cert: %w", err)
 	}
 	conn, err := grpcutil.Connect(creds, cfg.PrivateApiAddr)
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("could not connect to execution service privateApi: %w", err)
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("could not connect to execution service privateApi: %w", err)
 	}
 
 	kvClient := remote.NewKVClient(conn)
 	remoteKv, err := remotedb.NewRemote(gointerfaces.VersionFromProto(remotedbserver.KvServiceAPIVersion), logger, kvClient).Open()
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("could not connect to remoteKv: %w", err)
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("could not connect to remoteKv: %w", err)
 	}
 
 	subscribeToStateChangesLoop(ctx, kvClient, stateCache)
@@ -366,6 +398,7 @@ func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger,
 	if cfg.WithDatadir {
 		if cfg.Snap.Enabled {
 			allSnapshots := snapshotsync.NewRoSnapshots(cfg.Snap, cfg.Dirs.Snap)
+			snapshots = allSnapshots
 			onNewSnapshot = func() {
 				go func() { // don't block events processing by network communication
 					reply, err := kvClient.Snapshots(ctx, &remote.SnapshotsRequest{}, grpc.WaitForReady(true))
@@ -390,14 +423,15 @@ func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger,
 	if !cfg.WithDatadir {
 		blockReader = snapshotsync.NewRemoteBlockReader(remote.NewETHBACKENDClient(conn))
 	}
-	remoteEth := rpcservices.NewRemoteBackend(remote.NewETHBACKENDClient(conn), db, blockReader)
+	remoteEth := rpcservices.NewRemoteBackend(remote.NewETHBACKENDClient(conn), db, blockReader).
+		WithClique(remote.NewCLIQUEClient(conn))
 	blockReader = remoteEth
 
 	txpoolConn := conn
 	if cfg.TxPoolApiAddr != cfg.PrivateApiAddr {
 		txpoolConn, err = grpcutil.Connect(creds, cfg.TxPoolApiAddr)
 		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("could not connect to txpool api: %w", err)
+			return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("could not connect to txpool api: %w", err)
 		}
 	}
 
@@ -427,17 +461,17 @@ func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger,
 	if cfg.StarknetGRPCAddress != "" {
 		starknetConn, err := grpcutil.Connect(creds, cfg.StarknetGRPCAddress)
 		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("could not connect to starknet api: %w", err)
+			return nil, nil, nil, nil, nil, nil, nil, nil, nil, ff, fmt.Errorf("could not connect to starknet api: %w", err)
 		}
 		starknet = rpcservices.NewStarknetService(starknetConn)
 	}
 
 	ff = rpchelper.New(ctx, eth, txPool, mining, onNewSnapshot)
 
-	return db, borDb, eth, txPool, mining, starknet, stateCache, blockReader, ff, err
+	return db, consensusDb, eth, txPool, mining, starknet, stateCache, blockReader, snapshots, ff, err
 }
 
-func StartRpcServer(ctx context.Context, cfg httpcfg.HttpCfg, rpcAPI []rpc.API) error {
+func StartRpcServer(ctx context.Context, cfg httpcfg.HttpCfg, rpcAPI []rpc.API, healthBackend *health.Backend) error {
 	var engineListener *http.Server
 	var engineSrv *rpc.Server
 	var engineHttpEndpoint string
@@ -454,6 +488,22 @@ func StartRpcServer(ctx context.Context, cfg httpcfg.HttpCfg, rpcAPI []rpc.API)
 	}
 	srv.SetAllowList(allowListForRPC)
 
+	rateLimitForRPC, err := parseRateLimitForRPC(cfg.RpcRateLimitFilePath)
+	if err != nil {
+		return err
+	}
+	if rateLimitForRPC != nil {
+		var jwtSecret []byte
+		if rateLimitForRPC.PerSubject.RequestsPerSecond > 0 {
+			if jwtSecret, err = readRateLimitJWTSecret(cfg.RpcRateLimitJWTPath); err != nil {
+				return err
+			}
+		}
+		if err = srv.SetRateLimit(rateLimitForRPC, jwtSecret); err != nil {
+			return fmt.Errorf("invalid %s: %w", cfg.RpcRateLimitFilePath, err)
+		}
+	}
+
 	var defaultAPIList []rpc.API
 	var engineAPI []rpc.API
 
@@ -491,7 +541,7 @@ func StartRpcServer(ctx context.Context, cfg httpcfg.HttpCfg, rpcAPI []rpc.API)
 		wsHandler = srv.WebsocketHandler([]string{"*"}, nil, cfg.WebsocketCompression)
 	}
 
-	apiHandler, err := createHandler(cfg, defaultAPIList, httpHandler, wsHandler, nil)
+	apiHandler, err := createHandler(cfg, defaultAPIList, healthBackend, httpHandler, wsHandler, nil)
 	if err != nil {
 		return err
 	}
@@ -504,7 +554,7 @@ func StartRpcServer(ctx context.Context, cfg httpcfg.HttpCfg, rpcAPI []rpc.API)
 		"ws.compression", cfg.WebsocketCompression, "grpc", cfg.GRPCServerEnabled}
 
 	if len(engineAPI) > 0 {
-		engineListener, engineSrv, engineHttpEndpoint, err = createEngineListener(cfg, engineAPI)
+		engineListener, engineSrv, engineHttpEndpoint, err = createEngineListener(cfg, engineAPI, healthBackend)
 		if err != nil {
 			return fmt.Errorf("could not start RPC api for engine: %w", err)
 		}
@@ -596,12 +646,15 @@ func obtainJWTSecret(cfg httpcfg.HttpCfg) ([]byte, error) {
 	return jwtSecret, nil
 }
 
-func createHandler(cfg httpcfg.HttpCfg, apiList []rpc.API, httpHandler http.Handler, wsHandler http.Handler, jwtSecret []byte) (http.Handler, error) {
+func createHandler(cfg httpcfg.HttpCfg, apiList []rpc.API, healthBackend *health.Backend, httpHandler http.Handler, wsHandler http.Handler, jwtSecret []byte) (http.Handler, error) {
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// adding a healthcheck here
 		if health.ProcessHealthcheckIfNeeded(w, r, apiList) {
 			return
 		}
+		if health.ProcessProbeIfNeeded(w, r, healthBackend, cfg.HealthMaxBlocksBehind) {
+			return
+		}
 		if cfg.WebsocketEnabled && wsHandler != nil && isWebsocket(r) {
 			wsHandler.ServeHTTP(w, r)
 			return
@@ -617,7 +670,7 @@ func createHandler(cfg httpcfg.HttpCfg, apiList []rpc.API, httpHandler http.Hand
 	return handler, nil
 }
 
-func createEngineListener(cfg httpcfg.HttpCfg, engineApi []rpc.API) (*http.Server, *rpc.Server, string, error) {
+func createEngineListener(cfg httpcfg.HttpCfg, engineApi []rpc.API, healthBackend *health.Backend) (*http.Server, *rpc.Server, string, error) {
 	engineHttpEndpoint := fmt.Sprintf("%s:%d", cfg.EngineHTTPListenAddress, cfg.EnginePort)
 
 	engineSrv := rpc.NewServer(cfg.RpcBatchConcurrency, cfg.TraceRequests, true)
@@ -644,7 +697,7 @@ func createEngineListener(cfg httpcfg.HttpCfg, engineApi []rpc.API) (*http.Serve
 
 	engineHttpHandler := node.NewHTTPHandlerStack(engineSrv, cfg.HttpCORSDomain, cfg.HttpVirtualHost, cfg.HttpCompression)
 
-	engineApiHandler, err := createHandler(cfg, engineApi, engineHttpHandler, wsHandler, jwtSecret)
+	engineApiHandler, err := createHandler(cfg, engineApi, healthBackend, engineHttpHandler, wsHandler, jwtSecret)
 	if err != nil {
 		return nil, nil, "", err
 	}
@@ -29,6 +29,8 @@ type HttpCfg struct {
 	WebsocketEnabled        bool
 	WebsocketCompression    bool
 	RpcAllowListFilePath    string
+	RpcRateLimitFilePath    string
+	RpcRateLimitJWTPath     string
 	RpcBatchConcurrency     uint
 	RpcStreamingDisable     bool
 	DBReadConcurrency       int
@@ -47,4 +49,15 @@ type HttpCfg struct {
 	TraceRequests           bool   // Always trace requests in INFO level
 	HTTPTimeouts            rpccfg.HTTPTimeouts
 	EngineTimeouts          rpccfg.HTTPTimeouts
+
+	MaxGetProofRewindBlockCount uint64 // Limit of blocks eth_getProof can go back from the head
+	OtsMaxPageSize              uint64 // Upper bound of the page size of ots_searchTransactionsBefore/After
+	MaxGetLogsBlockRange        uint64 // Limit of blocks an eth_getLogs query can span, 0 - no limit
+	MaxGetLogsResults           uint64 // Limit of logs an eth_getLogs query can return, 0 - no limit
+	TraceWorkers                int    // Goroutines tracing the transactions of a block in debug_traceBlockByNumber/Hash, <= 1 - sequential
+	HealthMaxBlocksBehind       uint64 // Lag behind the highest header the node is still reported ready with by /health/ready
+
+	KeystoreDir         string // Directory of the encrypted account keys, empty - no local accounts
+	KeystoreLightKDF    bool   // Use cheap scrypt parameters for new keys
+	AllowInsecureUnlock bool   // Serve personal_unlockAccount over HTTP
 }
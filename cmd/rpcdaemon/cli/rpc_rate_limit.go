@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ledgerwatch/erigon/cmd/utils"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+func parseRateLimitForRPC(path string) (*rpc.RateLimitConfig, error) {
+	path = strings.TrimSpace(path)
+	if path == "" { // no file is provided
+		return nil, nil
+	}
+
+	fileContents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rateLimit rpc.RateLimitConfig
+	if err = json.Unmarshal(fileContents, &rateLimit); err != nil {
+		return nil, err
+	}
+	return &rateLimit, nil
+}
+
+// readRateLimitJWTSecret reads the secret of the JWTs used by the perSubject rate limit. It is deliberately
+// separate from the Engine API secret: clients of the public API must never be able to sign Engine API tokens.
+func readRateLimitJWTSecret(path string) ([]byte, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("perSubject rate limit requires --%s", utils.RpcRateLimitJWTSecretFlag.Name)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	jwtSecret := common.FromHex(strings.TrimSpace(string(data)))
+	if len(jwtSecret) != 32 {
+		return nil, errors.New("invalid rate limit JWT secret, expected 32 hex encoded bytes")
+	}
+	return jwtSecret, nil
+}
@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/clique"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+)
+
+// CliqueAPI Clique specific routines
+type CliqueAPI interface {
+	GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*clique.Snapshot, error)
+	GetSnapshotAtHash(ctx context.Context, hash common.Hash) (*clique.Snapshot, error)
+	GetSigners(ctx context.Context, number *rpc.BlockNumber) ([]common.Address, error)
+	GetSignersAtHash(ctx context.Context, hash common.Hash) ([]common.Address, error)
+	Status(ctx context.Context) (*CliqueStatus, error)
+
+	// Signer voting, forwarded to the node
+	Proposals(ctx context.Context) (map[common.Address]bool, error)
+	Propose(ctx context.Context, address common.Address, auth bool) error
+	Discard(ctx context.Context, address common.Address) error
+}
+
+// CliqueImpl is implementation of the CliqueAPI interface
+type CliqueImpl struct {
+	*BaseAPI
+	db         kv.RoDB // the chain db
+	cliqueDb   kv.RoDB // the consensus db
+	ethBackend rpchelper.ApiBackend
+}
+
+// NewCliqueAPI returns CliqueImpl instance
+func NewCliqueAPI(base *BaseAPI, db kv.RoDB, cliqueDb kv.RoDB, eth rpchelper.ApiBackend) *CliqueImpl {
+	return &CliqueImpl{
+		BaseAPI:    base,
+		db:         db,
+		cliqueDb:   cliqueDb,
+		ethBackend: eth,
+	}
+}
+
+var errNotClique = errors.New("chain doesn't use clique consensus")
+
+// cliqueStatusBlocks - how many recent blocks clique_status looks at
+const cliqueStatusBlocks = 64
+
+// CliqueStatus is the result of clique_status
+type CliqueStatus struct {
+	InturnPercent float64                `json:"inturnPercent"`  // Percentage of in-turn blocks
+	SigningStatus map[common.Address]int `json:"sealerActivity"` // Number of blocks sealed by each signer
+	NumBlocks     uint64                 `json:"numBlocks"`      // Number of blocks looked at
+}
+
+// GetSnapshot retrieves the state snapshot at a given block.
+func (api *CliqueImpl) GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*clique.Snapshot, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	header, err := api.headerByNumber(tx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.snapshot(ctx, tx, header)
+}
+
+// GetSnapshotAtHash retrieves the state snapshot at a given block.
+func (api *CliqueImpl) GetSnapshotAtHash(ctx context.Context, hash common.Hash) (*clique.Snapshot, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	header, err := api._blockReader.HeaderByHash(ctx, tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.snapshot(ctx, tx, header)
+}
+
+// GetSigners retrieves the list of authorized signers at the specified block.
+func (api *CliqueImpl) GetSigners(ctx context.Context, number *rpc.BlockNumber) ([]common.Address, error) {
+	snap, err := api.GetSnapshot(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return snap.GetSigners(), nil
+}
+
+// GetSignersAtHash retrieves the list of authorized signers at the specified block.
+func (api *CliqueImpl) GetSignersAtHash(ctx context.Context, hash common.Hash) ([]common.Address, error) {
+	snap, err := api.GetSnapshotAtHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return snap.GetSigners(), nil
+}
+
+// Status returns the sealing activity of the signers in the last 64 blocks and the percentage of in-turn blocks.
+func (api *CliqueImpl) Status(ctx context.Context) (*CliqueStatus, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	header, err := api.headerByNumber(tx, nil)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := api.snapshot(ctx, tx, header)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &CliqueStatus{SigningStatus: make(map[common.Address]int)}
+	for _, signer := range snap.GetSigners() {
+		status.SigningStatus[signer] = 0
+	}
+	// the genesis has no seal
+	end, start := header.Number.Uint64(), uint64(1)
+	if end >= cliqueStatusBlocks {
+		start = end - cliqueStatusBlocks + 1
+	}
+	inturn := 0
+	for n := start; n <= end; n++ {
+		h, err := api._blockReader.HeaderByNumber(ctx, tx, n)
+		if err != nil {
+			return nil, err
+		}
+		if h == nil {
+			return nil, fmt.Errorf("missing block %d", n)
+		}
+		if h.Difficulty.Cmp(clique.DiffInTurn) == 0 {
+			inturn++
+		}
+		signer, err := cliqueAuthor(h)
+		if err != nil {
+			return nil, err
+		}
+		status.SigningStatus[signer]++
+		status.NumBlocks++
+	}
+	if status.NumBlocks > 0 {
+		status.InturnPercent = float64(100*inturn) / float64(status.NumBlocks)
+	}
+	return status, nil
+}
+
+// Proposals returns the current proposals the node tries to uphold and vote on.
+func (api *CliqueImpl) Proposals(ctx context.Context) (map[common.Address]bool, error) {
+	return api.ethBackend.CliqueProposals(ctx)
+}
+
+// Propose injects a new authorization proposal that the signer will attempt to push through.
+func (api *CliqueImpl) Propose(ctx context.Context, address common.Address, auth bool) error {
+	return api.ethBackend.CliquePropose(ctx, address, auth)
+}
+
+// Discard drops a currently running proposal, stopping the signer from casting further votes (either for or against).
+func (api *CliqueImpl) Discard(ctx context.Context, address common.Address) error {
+	return api.ethBackend.CliqueDiscard(ctx, address)
+}
+
+// headerByNumber - latest block if number is not given
+func (api *CliqueImpl) headerByNumber(tx kv.Tx, number *rpc.BlockNumber) (*types.Header, error) {
+	blockNumber := rpc.LatestBlockNumber
+	if number != nil {
+		blockNumber = *number
+	}
+	header, err := api.headerByRPCNumber(blockNumber, tx)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return header, nil
+}
+
+func (api *CliqueImpl) snapshot(ctx context.Context, tx kv.Tx, header *types.Header) (*clique.Snapshot, error) {
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	if chainConfig.Clique == nil || api.cliqueDb == nil {
+		return nil, errNotClique
+	}
+	cliqueTx, err := api.cliqueDb.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cliqueTx.Rollback()
+
+	chain := consensusChainReader{ctx: ctx, config: chainConfig, tx: tx, blockReader: api._blockReader}
+	return clique.ReadSnapshot(chainConfig.Clique, cliqueTx, chain, header.Number.Uint64(), header.Hash())
+}
+
+// cliqueAuthor extracts the Ethereum account address from a signed header.
+func cliqueAuthor(header *types.Header) (common.Address, error) {
+	if len(header.Extra) < clique.ExtraSeal {
+		return common.Address{}, errMissingSignature
+	}
+	signature := header.Extra[len(header.Extra)-clique.ExtraSeal:]
+
+	pubkey, err := crypto.Ecrecover(clique.SealHash(header).Bytes(), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	return signer, nil
+}
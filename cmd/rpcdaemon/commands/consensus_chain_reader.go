@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/services"
+)
+
+// consensusChainReader implements consensus.ChainHeaderReader on top of the chain db transaction and the block reader
+// (headers may be in the snapshot files), the consensus engines walk the chain with it while reconstructing their snapshots
+type consensusChainReader struct {
+	ctx         context.Context
+	config      *params.ChainConfig
+	tx          kv.Tx
+	blockReader services.FullBlockReader
+}
+
+func (cr consensusChainReader) Config() *params.ChainConfig { return cr.config }
+
+func (cr consensusChainReader) CurrentHeader() *types.Header {
+	return rawdb.ReadCurrentHeader(cr.tx)
+}
+
+func (cr consensusChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	h, _ := cr.blockReader.Header(cr.ctx, cr.tx, hash, number)
+	return h
+}
+
+func (cr consensusChainReader) GetHeaderByNumber(number uint64) *types.Header {
+	h, _ := cr.blockReader.HeaderByNumber(cr.ctx, cr.tx, number)
+	return h
+}
+
+func (cr consensusChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	h, _ := cr.blockReader.HeaderByHash(cr.ctx, cr.tx, hash)
+	return h
+}
+
+func (cr consensusChainReader) GetTd(hash common.Hash, number uint64) *big.Int {
+	td, _ := rawdb.ReadTd(cr.tx, hash, number)
+	return td
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/txpool"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon/accounts/keystore"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli/httpcfg"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
@@ -12,7 +13,7 @@ import (
 )
 
 // APIList describes the list of available RPC apis
-func APIList(db kv.RoDB, borDb kv.RoDB, eth rpchelper.ApiBackend, txPool txpool.TxpoolClient, mining txpool.MiningClient,
+func APIList(db kv.RoDB, consensusDb kv.RoDB, eth rpchelper.ApiBackend, txPool txpool.TxpoolClient, mining txpool.MiningClient,
 	starknet starknet.CAIROVMClient, filters *rpchelper.Filters, stateCache kvcache.Cache,
 	blockReader services.FullBlockReader, cfg httpcfg.HttpCfg) (list []rpc.API) {
 
@@ -21,18 +22,37 @@ func APIList(db kv.RoDB, borDb kv.RoDB, eth rpchelper.ApiBackend, txPool txpool.
 		base.EnableTevmExperiment()
 	}
 	ethImpl := NewEthAPI(base, db, eth, txPool, mining, cfg.Gascap)
+	if cfg.MaxGetProofRewindBlockCount > 0 {
+		ethImpl.MaxGetProofRewindBlockCount = cfg.MaxGetProofRewindBlockCount
+	}
+	logsLimits := LogsLimits{MaxBlockRange: cfg.MaxGetLogsBlockRange, MaxResults: cfg.MaxGetLogsResults}
+	ethImpl.LogsLimits = logsLimits
+	if cfg.KeystoreDir != "" {
+		scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+		if cfg.KeystoreLightKDF {
+			scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+		}
+		ethImpl.keystore = keystore.NewKeyStore(cfg.KeystoreDir, scryptN, scryptP)
+	}
 	erigonImpl := NewErigonAPI(base, db, eth)
+	erigonImpl.LogsLimits = logsLimits
 	starknetImpl := NewStarknetAPI(base, db, starknet, txPool)
 	txpoolImpl := NewTxPoolAPI(base, db, txPool)
 	netImpl := NewNetAPIImpl(eth)
 	debugImpl := NewPrivateDebugAPI(base, db, cfg.Gascap)
+	debugImpl.TraceWorkers = cfg.TraceWorkers
 	traceImpl := NewTraceAPI(base, db, &cfg)
 	web3Impl := NewWeb3APIImpl(eth)
 	dbImpl := NewDBAPIImpl() /* deprecated */
 	engineImpl := NewEngineAPI(base, db, eth)
 	adminImpl := NewAdminAPI(eth)
 	parityImpl := NewParityAPIImpl(db)
-	borImpl := NewBorAPI(base, db, borDb) // bor (consensus) specific
+	personalImpl := NewPersonalAPI(ethImpl)
+	personalImpl.AllowInsecureUnlock = cfg.AllowInsecureUnlock
+	borImpl := NewBorAPI(base, db, consensusDb)            // bor (consensus) specific
+	cliqueImpl := NewCliqueAPI(base, db, consensusDb, eth) // clique (consensus) specific
+	parliaImpl := NewParliaAPI(base, db, consensusDb)      // parlia (consensus) specific
+	otsImpl := NewOtterscanAPI(base, db, cfg.OtsMaxPageSize)
 
 	for _, enabledAPI := range cfg.API {
 		switch enabledAPI {
@@ -43,6 +63,12 @@ func APIList(db kv.RoDB, borDb kv.RoDB, eth rpchelper.ApiBackend, txPool txpool.
 				Service:   EthAPI(ethImpl),
 				Version:   "1.0",
 			})
+			list = append(list, rpc.API{
+				Namespace: "eth",
+				Public:    true,
+				Service:   NewEthSyncingAPI(base),
+				Version:   "1.0",
+			})
 		case "debug":
 			list = append(list, rpc.API{
 				Namespace: "debug",
@@ -113,6 +139,27 @@ func APIList(db kv.RoDB, borDb kv.RoDB, eth rpchelper.ApiBackend, txPool txpool.
 				Service:   BorAPI(borImpl),
 				Version:   "1.0",
 			})
+		case "clique":
+			list = append(list, rpc.API{
+				Namespace: "clique",
+				Public:    true,
+				Service:   CliqueAPI(cliqueImpl),
+				Version:   "1.0",
+			})
+		case "parlia":
+			list = append(list, rpc.API{
+				Namespace: "parlia",
+				Public:    true,
+				Service:   ParliaAPI(parliaImpl),
+				Version:   "1.0",
+			})
+		case "ots":
+			list = append(list, rpc.API{
+				Namespace: "ots",
+				Public:    true,
+				Service:   OtterscanAPI(otsImpl),
+				Version:   "1.0",
+			})
 		case "admin":
 			list = append(list, rpc.API{
 				Namespace: "admin",
@@ -127,6 +174,13 @@ func APIList(db kv.RoDB, borDb kv.RoDB, eth rpchelper.ApiBackend, txPool txpool.
 				Service:   ParityAPI(parityImpl),
 				Version:   "1.0",
 			})
+		case "personal":
+			list = append(list, rpc.API{
+				Namespace: "personal",
+				Public:    false,
+				Service:   PersonalAPI(personalImpl),
+				Version:   "1.0",
+			})
 		}
 	}
 
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 
@@ -17,7 +18,9 @@ import (
 	"github.com/ledgerwatch/erigon/eth/tracers"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/transactions"
 	"github.com/ledgerwatch/log/v3"
 )
@@ -36,6 +39,11 @@ type PrivateDebugAPI interface {
 	GetModifiedAccountsByHash(_ context.Context, startHash common.Hash, endHash *common.Hash) ([]common.Address, error)
 	TraceCall(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *tracers.TraceConfig, stream *jsoniter.Stream) error
 	AccountAt(ctx context.Context, blockHash common.Hash, txIndex uint64, account common.Address) (*AccountResult, error)
+	GetRawHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error)
+	GetRawBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error)
+	GetRawReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]hexutil.Bytes, error)
+	GetRawTransaction(ctx context.Context, hash common.Hash) (hexutil.Bytes, error)
+	GetBadBlocks(ctx context.Context) ([]*BadBlockArgs, error)
 }
 
 // PrivateDebugAPIImpl is implementation of the PrivateDebugAPI interface based on remote Db access
@@ -43,6 +51,8 @@ type PrivateDebugAPIImpl struct {
 	*BaseAPI
 	db     kv.RoDB
 	GasCap uint64
+
+	TraceWorkers int // debug_traceBlockByNumber/Hash trace the transactions of a block on that many goroutines if > 1
 }
 
 // NewPrivateDebugAPI returns PrivateDebugAPIImpl instance
@@ -271,3 +281,161 @@ type AccountResult struct {
 	Code     hexutil.Bytes  `json:"code"`
 	CodeHash common.Hash    `json:"codeHash"`
 }
+
+// GetRawHeader implements debug_getRawHeader. Returns the RLP encoding of the block header.
+func (api *PrivateDebugAPIImpl) GetRawHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	n, h, _, err := rpchelper.GetBlockNumber(blockNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	header, err := api._blockReader.Header(ctx, tx, h, n)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("header not found")
+	}
+	return rlp.EncodeToBytes(header)
+}
+
+// GetRawBlock implements debug_getRawBlock. Returns the RLP encoding of the block.
+func (api *PrivateDebugAPIImpl) GetRawBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	n, h, _, err := rpchelper.GetBlockNumber(blockNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	block, err := api.blockWithSenders(tx, h, n)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block not found")
+	}
+	return rlp.EncodeToBytes(block)
+}
+
+// GetRawReceipts implements debug_getRawReceipts. Returns the consensus encoding of the receipts of the block.
+func (api *PrivateDebugAPIImpl) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]hexutil.Bytes, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	n, h, _, err := rpchelper.GetBlockNumber(blockNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	block, err := api.blockWithSenders(tx, h, n)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block not found")
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := api.getReceipts(ctx, tx, chainConfig, block, block.Body().SendersFromTxs())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]hexutil.Bytes, len(receipts))
+	for i, receipt := range receipts {
+		// Blooms are not stored, derive them back from the logs
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		b, err := rlp.EncodeToBytes(receipt)
+		if err != nil {
+			return nil, err
+		}
+		if receipt.Type != types.LegacyTxType {
+			// Typed receipts are wrapped into an RLP string, the consensus encoding is the content of the string
+			if _, b, _, err = rlp.Split(b); err != nil {
+				return nil, err
+			}
+		}
+		result[i] = b
+	}
+	return result, nil
+}
+
+// GetRawTransaction implements debug_getRawTransaction. Returns the consensus encoding of the transaction.
+func (api *PrivateDebugAPIImpl) GetRawTransaction(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	blockNum, ok, err := api.txnLookup(ctx, tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+	for _, txn := range block.Transactions() {
+		if txn.Hash() == hash {
+			var buf bytes.Buffer
+			err = txn.MarshalBinary(&buf)
+			return buf.Bytes(), err
+		}
+	}
+	return nil, nil
+}
+
+// BadBlockArgs represents the entries in the list returned when bad blocks are queried.
+type BadBlockArgs struct {
+	Hash   common.Hash            `json:"hash"`
+	Block  map[string]interface{} `json:"block"`
+	RLP    hexutil.Bytes          `json:"rlp"`
+	Reason string                 `json:"reason"`
+}
+
+// GetBadBlocks implements debug_getBadBlocks. Returns the latest blocks rejected by the staged sync
+// and the validation errors they were rejected with.
+func (api *PrivateDebugAPIImpl) GetBadBlocks(ctx context.Context) ([]*BadBlockArgs, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	badBlocks, err := rawdb.ReadBadBlocks(tx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*BadBlockArgs, 0, len(badBlocks))
+	for _, badBlock := range badBlocks {
+		blockRlp, err := rlp.EncodeToBytes(badBlock.Block)
+		if err != nil {
+			return nil, err
+		}
+		blockJSON, err := ethapi.RPCMarshalBlock(badBlock.Block, true, true)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, &BadBlockArgs{
+			Hash:   badBlock.Block.Hash(),
+			Block:  blockJSON,
+			RLP:    blockRlp,
+			Reason: badBlock.Reason,
+		})
+	}
+	return results, nil
+}
@@ -5,15 +5,20 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/tracers"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/stretchr/testify/require"
 )
 
 var debugTraceTransactionTests = []struct {
@@ -85,6 +90,55 @@ func TestTraceBlockByNumber(t *testing.T) {
 	}
 }
 
+func TestTraceBlockParallel(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	baseApi := NewBaseApi(nil, kvcache.New(kvcache.DefaultCoherentConfig), snapshotsync.NewBlockReader(), false)
+	api := NewPrivateDebugAPI(baseApi, db, 0)
+	callTracer := "callTracer"
+	trace := func(ctx context.Context, blockNum rpc.BlockNumber, config *tracers.TraceConfig) (string, error) {
+		var buf bytes.Buffer
+		stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
+		err := api.TraceBlockByNumber(ctx, blockNum, config, stream)
+		require.NoError(t, stream.Flush())
+		return buf.String(), err
+	}
+
+	// Every transaction is traced on the same pre-state as when the block is replayed one transaction after another
+	for blockNum := rpc.BlockNumber(1); blockNum <= 10; blockNum++ {
+		for _, config := range []*tracers.TraceConfig{{}, {Tracer: &callTracer}} {
+			api.TraceWorkers = 0
+			sequential, err := trace(context.Background(), blockNum, config)
+			require.NoError(t, err)
+			api.TraceWorkers = 4
+			parallel, err := trace(context.Background(), blockNum, config)
+			require.NoError(t, err)
+			require.Equal(t, sequential, parallel, "block %d", blockNum)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := trace(ctx, 6, &tracers.TraceConfig{})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Block 7 deploys a contract in its first transaction, the tracer is slow enough to be stopped in the middle of it.
+	// The traces are checked on the sequential path (the default) and on the parallel one.
+	slowTracer := "{step: function(log, db) { for (var i = 0; i < 100000; i++) {} }, fault: function(log, db) {}, result: function(ctx, db) { return null; }}"
+	timeout := "1ms"
+	for _, workers := range []int{1, 4} {
+		api.TraceWorkers = workers
+		result, err := trace(context.Background(), 7, &tracers.TraceConfig{Tracer: &slowTracer, Timeout: &timeout})
+		require.Error(t, err)
+		require.True(t, json.Valid([]byte(result)), "workers %d: %s", workers, result)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		result, err = trace(ctx, 7, &tracers.TraceConfig{Tracer: &slowTracer})
+		cancel()
+		require.Error(t, err)
+		require.True(t, json.Valid([]byte(result)), "workers %d: %s", workers, result)
+	}
+}
+
 func TestTraceBlockByHash(t *testing.T) {
 	db := rpcdaemontest.CreateTestKV(t)
 	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
@@ -183,3 +237,52 @@ func TestTraceTransactionNoRefund(t *testing.T) {
 		}
 	}
 }
+
+func TestGetRawTransaction(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewPrivateDebugAPI(
+		NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), false),
+		db, 0)
+	for _, tt := range debugTraceTransactionTests {
+		raw, err := api.GetRawTransaction(context.Background(), common.HexToHash(tt.txHash))
+		require.NoError(t, err)
+		txn, err := types.UnmarshalTransactionFromBinary(raw)
+		require.NoError(t, err)
+		require.Equal(t, common.HexToHash(tt.txHash), txn.Hash())
+	}
+	raw, err := api.GetRawTransaction(context.Background(), common.Hash{})
+	require.NoError(t, err)
+	require.Nil(t, raw)
+}
+
+func TestGetRawBlock(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewPrivateDebugAPI(
+		NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), false),
+		db, 0)
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	rawHeader, err := api.GetRawHeader(context.Background(), latest)
+	require.NoError(t, err)
+	header := new(types.Header)
+	require.NoError(t, rlp.DecodeBytes(rawHeader, header))
+
+	rawBlock, err := api.GetRawBlock(context.Background(), rpc.BlockNumberOrHashWithHash(header.Hash(), true))
+	require.NoError(t, err)
+	block := new(types.Block)
+	require.NoError(t, rlp.DecodeBytes(rawBlock, block))
+	require.Equal(t, header.Hash(), block.Hash())
+
+	rawReceipts, err := api.GetRawReceipts(context.Background(), latest)
+	require.NoError(t, err)
+	require.Len(t, rawReceipts, len(block.Transactions()))
+	// The consensus encodings of the receipts have to add up to the receipts root of the header
+	require.Equal(t, header.ReceiptHash, types.DeriveSha(rawList(rawReceipts)))
+}
+
+type rawList []hexutil.Bytes
+
+func (l rawList) Len() int                           { return len(l) }
+func (l rawList) EncodeIndex(i int, w *bytes.Buffer) { w.Write(l[i]) }
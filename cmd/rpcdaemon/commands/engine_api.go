@@ -15,6 +15,7 @@ import (
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/ethdb/privateapi"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/log/v3"
 )
@@ -37,6 +38,24 @@ type ExecutionPayload struct {
 	Transactions  []hexutil.Bytes `json:"transactions"  gencodec:"required"`
 }
 
+// ExecutionPayloadV2 represents an execution payload with withdrawals (Shanghai)
+type ExecutionPayloadV2 struct {
+	ExecutionPayload
+	Withdrawals []*types.Withdrawal `json:"withdrawals"`
+}
+
+// GetPayloadV2Response is the result of engine_getPayloadV2
+type GetPayloadV2Response struct {
+	ExecutionPayload *ExecutionPayloadV2 `json:"executionPayload" gencodec:"required"`
+	BlockValue       *hexutil.Big        `json:"blockValue"       gencodec:"required"`
+}
+
+// ExecutionPayloadBodyV1 is the body of an execution payload, as returned by engine_getPayloadBodiesBy*V1
+type ExecutionPayloadBodyV1 struct {
+	Transactions []hexutil.Bytes     `json:"transactions" gencodec:"required"`
+	Withdrawals  []*types.Withdrawal `json:"withdrawals"  gencodec:"required"`
+}
+
 // PayloadAttributes represent the attributes required to start assembling a payload
 type ForkChoiceState struct {
 	HeadHash           common.Hash `json:"headBlockHash"             gencodec:"required"`
@@ -51,6 +70,12 @@ type PayloadAttributes struct {
 	SuggestedFeeRecipient common.Address `json:"suggestedFeeRecipient" gencodec:"required"`
 }
 
+// PayloadAttributesV2 represent the attributes required to start assembling a payload with withdrawals (Shanghai)
+type PayloadAttributesV2 struct {
+	PayloadAttributes
+	Withdrawals []*types.Withdrawal `json:"withdrawals"`
+}
+
 // TransitionConfiguration represents the correct configurations of the CL and the EL
 type TransitionConfiguration struct {
 	TerminalTotalDifficulty *hexutil.Big `json:"terminalTotalDifficulty" gencodec:"required"`
@@ -64,6 +89,33 @@ type EngineAPI interface {
 	NewPayloadV1(context.Context, *ExecutionPayload) (map[string]interface{}, error)
 	GetPayloadV1(ctx context.Context, payloadID hexutil.Bytes) (*ExecutionPayload, error)
 	ExchangeTransitionConfigurationV1(ctx context.Context, transitionConfiguration TransitionConfiguration) (TransitionConfiguration, error)
+	ForkchoiceUpdatedV2(ctx context.Context, forkChoiceState *ForkChoiceState, payloadAttributes *PayloadAttributesV2) (map[string]interface{}, error)
+	NewPayloadV2(context.Context, *ExecutionPayloadV2) (map[string]interface{}, error)
+	GetPayloadV2(ctx context.Context, payloadID hexutil.Bytes) (*GetPayloadV2Response, error)
+	ExchangeCapabilities(fromCl []string) []string
+	GetPayloadBodiesByHashV1(ctx context.Context, hashes []common.Hash) ([]*ExecutionPayloadBodyV1, error)
+	GetPayloadBodiesByRangeV1(ctx context.Context, start, count hexutil.Uint64) ([]*ExecutionPayloadBodyV1, error)
+}
+
+// The maximum number of payload bodies which can be requested at once.
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_getpayloadbodiesbyhashv1
+const maxPayloadBodiesRequest = 1024
+
+// engineCapabilities are the Engine API methods served by this node, see engine_exchangeCapabilities
+var engineCapabilities = []string{
+	"engine_forkchoiceUpdatedV1",
+	"engine_newPayloadV1",
+	"engine_getPayloadV1",
+	"engine_exchangeTransitionConfigurationV1",
+	"engine_getPayloadBodiesByHashV1",
+	"engine_getPayloadBodiesByRangeV1",
+}
+
+// engineCapabilitiesV2 are served only if the backend supports them, see ApiBackend.EngineV2Available
+var engineCapabilitiesV2 = []string{
+	"engine_forkchoiceUpdatedV2",
+	"engine_newPayloadV2",
+	"engine_getPayloadV2",
 }
 
 // EngineImpl is implementation of the EngineAPI interface
@@ -87,10 +139,27 @@ func convertPayloadStatus(x *remote.EnginePayloadStatus) map[string]interface{}
 	return json
 }
 
-func (e *EngineImpl) ForkchoiceUpdatedV1(ctx context.Context, forkChoiceState *ForkChoiceState, payloadAttributes *PayloadAttributes) (map[string]interface{}, error) {
-	log.Debug("Received ForkchoiceUpdated", "head", forkChoiceState.HeadHash, "safe", forkChoiceState.HeadHash, "finalized", forkChoiceState.FinalizedBlockHash,
-		"build", payloadAttributes != nil)
+// zeroPreMergeLatestValidHash replaces latestValidHash with zero hash if it points to a pre-merge block,
+// as required by the Engine API spec
+func (e *EngineImpl) zeroPreMergeLatestValidHash(ctx context.Context, payloadStatus map[string]interface{}) error {
+	tx, err := e.db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	latestValidHash := payloadStatus["latestValidHash"].(common.Hash)
+	isValidHashPos, err := rawdb.IsPosBlock(tx, latestValidHash)
+	if err != nil {
+		return err
+	}
+	if !isValidHashPos {
+		payloadStatus["latestValidHash"] = common.Hash{}
+	}
+	return nil
+}
 
+func convertForkChoiceRequest(forkChoiceState *ForkChoiceState, payloadAttributes *PayloadAttributes) *remote.EngineForkChoiceUpdatedRequest {
 	var prepareParameters *remote.EnginePayloadAttributes
 	if payloadAttributes != nil {
 		prepareParameters = &remote.EnginePayloadAttributes{
@@ -99,34 +168,52 @@ func (e *EngineImpl) ForkchoiceUpdatedV1(ctx context.Context, forkChoiceState *F
 			SuggestedFeeRecipient: gointerfaces.ConvertAddressToH160(payloadAttributes.SuggestedFeeRecipient),
 		}
 	}
-	reply, err := e.api.EngineForkchoiceUpdatedV1(ctx, &remote.EngineForkChoiceUpdatedRequest{
+	return &remote.EngineForkChoiceUpdatedRequest{
 		ForkchoiceState: &remote.EngineForkChoiceState{
 			HeadBlockHash:      gointerfaces.ConvertHashToH256(forkChoiceState.HeadHash),
 			SafeBlockHash:      gointerfaces.ConvertHashToH256(forkChoiceState.SafeBlockHash),
 			FinalizedBlockHash: gointerfaces.ConvertHashToH256(forkChoiceState.FinalizedBlockHash),
 		},
 		PayloadAttributes: prepareParameters,
-	})
+	}
+}
+
+func (e *EngineImpl) ForkchoiceUpdatedV1(ctx context.Context, forkChoiceState *ForkChoiceState, payloadAttributes *PayloadAttributes) (map[string]interface{}, error) {
+	log.Debug("Received ForkchoiceUpdated", "head", forkChoiceState.HeadHash, "safe", forkChoiceState.HeadHash, "finalized", forkChoiceState.FinalizedBlockHash,
+		"build", payloadAttributes != nil)
+
+	reply, err := e.api.EngineForkchoiceUpdatedV1(ctx, convertForkChoiceRequest(forkChoiceState, payloadAttributes))
 	if err != nil {
 		return nil, err
 	}
+	return e.convertForkChoiceReply(ctx, reply)
+}
 
+// ForkchoiceUpdatedV2 is like ForkchoiceUpdatedV1, but the payload attributes may carry withdrawals.
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_forkchoiceupdatedv2
+func (e *EngineImpl) ForkchoiceUpdatedV2(ctx context.Context, forkChoiceState *ForkChoiceState, payloadAttributes *PayloadAttributesV2) (map[string]interface{}, error) {
+	log.Debug("Received ForkchoiceUpdatedV2", "head", forkChoiceState.HeadHash, "safe", forkChoiceState.HeadHash, "finalized", forkChoiceState.FinalizedBlockHash,
+		"build", payloadAttributes != nil)
+
+	var attributes *PayloadAttributes
+	var withdrawals []*types.Withdrawal
+	if payloadAttributes != nil {
+		attributes = &payloadAttributes.PayloadAttributes
+		withdrawals = payloadAttributes.Withdrawals
+	}
+	reply, err := e.api.EngineForkchoiceUpdatedV2(ctx, convertForkChoiceRequest(forkChoiceState, attributes), withdrawals)
+	if err != nil {
+		return nil, err
+	}
+	return e.convertForkChoiceReply(ctx, reply)
+}
+
+func (e *EngineImpl) convertForkChoiceReply(ctx context.Context, reply *remote.EngineForkChoiceUpdatedReply) (map[string]interface{}, error) {
 	payloadStatus := convertPayloadStatus(reply.PayloadStatus)
 	if reply.PayloadStatus.Status == remote.EngineStatus_INVALID && payloadStatus["latestValidHash"] != nil {
-		tx, err := e.db.BeginRo(ctx)
-		if err != nil {
-			return nil, err
-		}
-
-		defer tx.Rollback()
-		latestValidHash := payloadStatus["latestValidHash"].(common.Hash)
-		isValidHashPos, err := rawdb.IsPosBlock(tx, latestValidHash)
-		if err != nil {
+		if err := e.zeroPreMergeLatestValidHash(ctx, payloadStatus); err != nil {
 			return nil, err
 		}
-		if !isValidHashPos {
-			payloadStatus["latestValidHash"] = common.Hash{}
-		}
 	}
 	json := map[string]interface{}{
 		"payloadStatus": payloadStatus,
@@ -145,6 +232,46 @@ func (e *EngineImpl) ForkchoiceUpdatedV1(ctx context.Context, forkChoiceState *F
 func (e *EngineImpl) NewPayloadV1(ctx context.Context, payload *ExecutionPayload) (map[string]interface{}, error) {
 	log.Debug("Received NewPayload", "height", uint64(payload.BlockNumber), "hash", payload.BlockHash)
 
+	req, err := convertPayloadToGrpc(payload)
+	if err != nil {
+		return nil, err
+	}
+	res, err := e.api.EngineNewPayloadV1(ctx, req)
+	if err != nil {
+		log.Warn("NewPayload", "err", err)
+		return nil, err
+	}
+	return e.convertNewPayloadReply(ctx, res)
+}
+
+// NewPayloadV2 processes new payloads (blocks) from the beacon chain, which may carry withdrawals.
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_newpayloadv2
+func (e *EngineImpl) NewPayloadV2(ctx context.Context, payload *ExecutionPayloadV2) (map[string]interface{}, error) {
+	log.Debug("Received NewPayloadV2", "height", uint64(payload.BlockNumber), "hash", payload.BlockHash)
+
+	req, err := convertPayloadToGrpc(&payload.ExecutionPayload)
+	if err != nil {
+		return nil, err
+	}
+	res, err := e.api.EngineNewPayloadV2(ctx, req, payload.Withdrawals)
+	if err != nil {
+		log.Warn("NewPayloadV2", "err", err)
+		return nil, err
+	}
+	return e.convertNewPayloadReply(ctx, res)
+}
+
+func (e *EngineImpl) convertNewPayloadReply(ctx context.Context, res *remote.EnginePayloadStatus) (map[string]interface{}, error) {
+	payloadStatus := convertPayloadStatus(res)
+	if payloadStatus["latestValidHash"] != nil {
+		if err := e.zeroPreMergeLatestValidHash(ctx, payloadStatus); err != nil {
+			return nil, err
+		}
+	}
+	return payloadStatus, nil
+}
+
+func convertPayloadToGrpc(payload *ExecutionPayload) (*types2.ExecutionPayload, error) {
 	var baseFee *uint256.Int
 	if payload.BaseFeePerGas != nil {
 		var overflow bool
@@ -160,7 +287,7 @@ func (e *EngineImpl) NewPayloadV1(ctx context.Context, payload *ExecutionPayload
 	for i, transaction := range payload.Transactions {
 		transactions[i] = ([]byte)(transaction)
 	}
-	res, err := e.api.EngineNewPayloadV1(ctx, &types2.ExecutionPayload{
+	return &types2.ExecutionPayload{
 		ParentHash:    gointerfaces.ConvertHashToH256(payload.ParentHash),
 		Coinbase:      gointerfaces.ConvertAddressToH160(payload.FeeRecipient),
 		StateRoot:     gointerfaces.ConvertHashToH256(payload.StateRoot),
@@ -175,29 +302,7 @@ func (e *EngineImpl) NewPayloadV1(ctx context.Context, payload *ExecutionPayload
 		BaseFeePerGas: gointerfaces.ConvertUint256IntToH256(baseFee),
 		BlockHash:     gointerfaces.ConvertHashToH256(payload.BlockHash),
 		Transactions:  transactions,
-	})
-	if err != nil {
-		log.Warn("NewPayload", "err", err)
-		return nil, err
-	}
-	payloadStatus := convertPayloadStatus(res)
-	if payloadStatus["latestValidHash"] != nil {
-		tx, err := e.db.BeginRo(ctx)
-		if err != nil {
-			return nil, err
-		}
-
-		defer tx.Rollback()
-		latestValidHash := payloadStatus["latestValidHash"].(common.Hash)
-		isValidHashPos, err := rawdb.IsPosBlock(tx, latestValidHash)
-		if err != nil {
-			return nil, err
-		}
-		if !isValidHashPos {
-			payloadStatus["latestValidHash"] = common.Hash{}
-		}
-	}
-	return payloadStatus, nil
+	}, nil
 }
 
 func (e *EngineImpl) GetPayloadV1(ctx context.Context, payloadID hexutil.Bytes) (*ExecutionPayload, error) {
@@ -208,6 +313,29 @@ func (e *EngineImpl) GetPayloadV1(ctx context.Context, payloadID hexutil.Bytes)
 	if err != nil {
 		return nil, err
 	}
+	return convertPayloadFromGrpc(payload), nil
+}
+
+// GetPayloadV2 retrieves previously assembled payload together with its withdrawals and value.
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_getpayloadv2
+func (e *EngineImpl) GetPayloadV2(ctx context.Context, payloadID hexutil.Bytes) (*GetPayloadV2Response, error) {
+	decodedPayloadId := binary.BigEndian.Uint64(payloadID)
+	log.Info("Received GetPayloadV2", "payloadId", decodedPayloadId)
+
+	payload, err := e.api.EngineGetPayloadV2(ctx, decodedPayloadId)
+	if err != nil {
+		return nil, err
+	}
+	return &GetPayloadV2Response{
+		ExecutionPayload: &ExecutionPayloadV2{
+			ExecutionPayload: *convertPayloadFromGrpc(payload.ExecutionPayload),
+			Withdrawals:      payload.Withdrawals,
+		},
+		BlockValue: (*hexutil.Big)(payload.BlockValue.ToBig()),
+	}, nil
+}
+
+func convertPayloadFromGrpc(payload *types2.ExecutionPayload) *ExecutionPayload {
 	var bloom types.Bloom = gointerfaces.ConvertH2048ToBloom(payload.LogsBloom)
 
 	var baseFee *big.Int
@@ -235,7 +363,7 @@ func (e *EngineImpl) GetPayloadV1(ctx context.Context, payloadID hexutil.Bytes)
 		BaseFeePerGas: (*hexutil.Big)(baseFee),
 		BlockHash:     gointerfaces.ConvertH256ToHash(payload.BlockHash),
 		Transactions:  transactions,
-	}, nil
+	}
 }
 
 // Receives consensus layer's transition configuration and checks if the execution layer has the correct configuration.
@@ -286,6 +414,113 @@ func (e *EngineImpl) ExchangeTransitionConfigurationV1(ctx context.Context, beac
 	}, nil
 }
 
+// ExchangeCapabilities returns the Engine API methods supported by this node.
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/common.md#engine_exchangecapabilities
+func (e *EngineImpl) ExchangeCapabilities(fromCl []string) []string {
+	capabilities := engineCapabilities
+	if e.api != nil && e.api.EngineV2Available() {
+		capabilities = append(append(make([]string, 0, len(engineCapabilities)+len(engineCapabilitiesV2)), engineCapabilities...), engineCapabilitiesV2...)
+	}
+
+	missing := make([]string, 0)
+	for _, method := range fromCl {
+		supported := false
+		for _, ourMethod := range capabilities {
+			if method == ourMethod {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			missing = append(missing, method)
+		}
+	}
+	if len(missing) > 0 {
+		log.Debug("ExchangeCapabilities: consensus layer supports methods not implemented by us", "methods", missing)
+	}
+	return capabilities
+}
+
+// GetPayloadBodiesByHashV1 returns the bodies of the given blocks, nil for unknown ones.
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_getpayloadbodiesbyhashv1
+func (e *EngineImpl) GetPayloadBodiesByHashV1(ctx context.Context, hashes []common.Hash) ([]*ExecutionPayloadBodyV1, error) {
+	if len(hashes) > maxPayloadBodiesRequest {
+		return nil, &privateapi.TooLargeRequestErr
+	}
+
+	tx, err := e.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	bodies := make([]*ExecutionPayloadBodyV1, len(hashes))
+	for i, hash := range hashes {
+		block, err := e.blockByHashWithSenders(tx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if bodies[i], err = convertPayloadBody(block); err != nil {
+			return nil, err
+		}
+	}
+	return bodies, nil
+}
+
+// GetPayloadBodiesByRangeV1 returns the bodies of canonical blocks [start, start+count), nil for unknown ones.
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_getpayloadbodiesbyrangev1
+func (e *EngineImpl) GetPayloadBodiesByRangeV1(ctx context.Context, start, count hexutil.Uint64) ([]*ExecutionPayloadBodyV1, error) {
+	if start == 0 || count == 0 {
+		return nil, &privateapi.InvalidParamsErr
+	}
+	if count > maxPayloadBodiesRequest {
+		return nil, &privateapi.TooLargeRequestErr
+	}
+
+	tx, err := e.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	bodies := make([]*ExecutionPayloadBodyV1, 0, count)
+	for number := uint64(start); number < uint64(start+count); number++ {
+		hash, err := e._blockReader.CanonicalHash(ctx, tx, number)
+		if err != nil {
+			return nil, err
+		}
+		if hash == (common.Hash{}) {
+			// the response must not contain trailing nulls past the latest known block
+			break
+		}
+		block, err := e.blockWithSenders(tx, hash, number)
+		if err != nil {
+			return nil, err
+		}
+		body, err := convertPayloadBody(block)
+		if err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
+
+func convertPayloadBody(block *types.Block) (*ExecutionPayloadBodyV1, error) {
+	if block == nil {
+		return nil, nil
+	}
+	encodedTransactions, err := types.MarshalTransactionsBinary(block.Transactions())
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]hexutil.Bytes, len(encodedTransactions))
+	for i, transaction := range encodedTransactions {
+		transactions[i] = transaction
+	}
+	return &ExecutionPayloadBodyV1{Transactions: transactions, Withdrawals: block.Withdrawals()}, nil
+}
+
 // NewEngineAPI returns EngineImpl instance
 func NewEngineAPI(base *BaseAPI, db kv.RoDB, api rpchelper.ApiBackend) *EngineImpl {
 	return &EngineImpl{
@@ -1,12 +1,21 @@
 package commands
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcservices"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/ethdb/privateapi"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test case for https://github.com/ethereum/execution-apis/pull/217 responses
@@ -16,3 +25,55 @@ func TestZeroLatestValidHash(t *testing.T) {
 	assert.Equal(t, "INVALID", json["status"])
 	assert.Equal(t, common.Hash{}, json["latestValidHash"])
 }
+
+func TestGetPayloadBodies(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	ctx := context.Background()
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEngineAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), false), db, nil)
+
+	tx, err := db.BeginRo(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	head := rawdb.ReadCurrentHeader(tx)
+	block1, err := rawdb.ReadBlockByNumber(tx, 1)
+	require.NoError(t, err)
+
+	bodies, err := api.GetPayloadBodiesByHashV1(ctx, []common.Hash{block1.Hash(), {0x1}})
+	require.NoError(t, err)
+	require.Len(t, bodies, 2)
+	require.NotNil(t, bodies[0])
+	assert.Len(t, bodies[0].Transactions, block1.Transactions().Len())
+	assert.Nil(t, bodies[0].Withdrawals)
+	assert.Nil(t, bodies[1])
+
+	// trailing blocks past the head are not returned
+	bodies, err = api.GetPayloadBodiesByRangeV1(ctx, hexutil.Uint64(head.Number.Uint64()-1), 10)
+	require.NoError(t, err)
+	assert.Len(t, bodies, 2)
+
+	_, err = api.GetPayloadBodiesByRangeV1(ctx, 0, 1)
+	assert.Error(t, err)
+	_, err = api.GetPayloadBodiesByRangeV1(ctx, 1, maxPayloadBodiesRequest+1)
+	assert.Error(t, err)
+}
+
+func TestExchangeCapabilities(t *testing.T) {
+	// standalone rpcdaemon talks to Erigon over gRPC, which has no V2 methods
+	backend := rpcservices.NewRemoteBackend(nil, nil, nil)
+	api := NewEngineAPI(nil, nil, backend)
+	capabilities := api.ExchangeCapabilities([]string{"engine_newPayloadV2", "engine_newPayloadV9"})
+	assert.Contains(t, capabilities, "engine_newPayloadV1")
+	assert.Contains(t, capabilities, "engine_getPayloadBodiesByRangeV1")
+	assert.NotContains(t, capabilities, "engine_newPayloadV2")
+	assert.NotContains(t, capabilities, "engine_newPayloadV9")
+
+	// embedded rpcdaemon
+	backend.WithEngineV2(&privateapi.EthBackendServer{})
+	capabilities = api.ExchangeCapabilities([]string{"engine_newPayloadV2", "engine_newPayloadV9"})
+	assert.Contains(t, capabilities, "engine_newPayloadV1")
+	assert.Contains(t, capabilities, "engine_newPayloadV2")
+	assert.Contains(t, capabilities, "engine_forkchoiceUpdatedV2")
+	assert.Contains(t, capabilities, "engine_getPayloadV2")
+	assert.NotContains(t, capabilities, "engine_newPayloadV9")
+}
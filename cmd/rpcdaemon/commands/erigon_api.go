@@ -3,10 +3,12 @@ package commands
 import (
 	"context"
 
+	jsoniter "github.com/json-iterator/go"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/filters"
 	"github.com/ledgerwatch/erigon/p2p"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
@@ -25,10 +27,14 @@ type ErigonAPI interface {
 
 	// Receipt related (see ./erigon_receipts.go)
 	GetLogsByHash(ctx context.Context, hash common.Hash) ([][]*types.Log, error)
+	GetLogs(ctx context.Context, crit filters.FilterCriteria, cursor *LogsCursor, limit *hexutil.Uint64, stream *jsoniter.Stream) error
 	//GetLogsByNumber(ctx context.Context, number rpc.BlockNumber) ([][]*types.Log, error)
 
 	// WatchTheBurn / reward related (see ./erigon_issuance.go)
 	WatchTheBurn(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error)
+	BlockReward(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error)
+	UncleReward(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error)
+	TotalSupply(ctx context.Context, blockNr rpc.BlockNumber) (TotalSupply, error)
 
 	// CumulativeChainTraffic / related to chain traffic (see ./erigon_cumulative_index.go)
 	CumulativeChainTraffic(ctx context.Context, blockNr rpc.BlockNumber) (ChainTraffic, error)
@@ -42,6 +48,7 @@ type ErigonImpl struct {
 	*BaseAPI
 	db         kv.RoDB
 	ethBackend rpchelper.ApiBackend
+	LogsLimits LogsLimits
 }
 
 // NewErigonAPI returns ErigonImpl instance
@@ -6,91 +6,72 @@ import (
 	"math/big"
 
 	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common/hexutil"
-	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 )
 
-// BlockReward returns the block reward for this block
-// func (api *ErigonImpl) BlockReward(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error) {
-//	tx, err := api.db.Begin(ctx, ethdb.RO)
-//	if err != nil {
-//		return Issuance{}, err
-//	}
-//	defer tx.Rollback()
-//
-//	return api.rewardCalc(tx, blockNr, "block") // nolint goconst
-//}
-
-// UncleReward returns the uncle reward for this block
-// func (api *ErigonImpl) UncleReward(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error) {
-//	tx, err := api.db.Begin(ctx, ethdb.RO)
-//	if err != nil {
-//		return Issuance{}, err
-//	}
-//	defer tx.Rollback()
-//
-//	return api.rewardCalc(tx, blockNr, "uncle") // nolint goconst
-//}
-
-// Issuance implements erigon_issuance. Returns the total issuance (block reward plus uncle reward) for the given block.
-func (api *ErigonImpl) WatchTheBurn(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error) {
+// BlockReward implements erigon_blockReward. Returns the reward of the miner of the given block.
+func (api *ErigonImpl) BlockReward(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error) {
 	tx, err := api.db.BeginRo(ctx)
 	if err != nil {
 		return Issuance{}, err
 	}
 	defer tx.Rollback()
 
-	chainConfig, err := api.chainConfig(tx)
+	issuance, _, _, err := api.blockIssuance(ctx, tx, blockNr)
 	if err != nil {
 		return Issuance{}, err
 	}
-	if chainConfig.Ethash == nil {
-		// Clique for example has no issuance
-		return Issuance{}, nil
-	}
-	hash, err := rawdb.ReadCanonicalHash(tx, uint64(blockNr))
+	return Issuance{BlockReward: (*hexutil.Big)(issuance.BlockReward.ToBig()), Issuance: (*hexutil.Big)(issuance.BlockReward.ToBig())}, nil
+}
+
+// UncleReward implements erigon_uncleReward. Returns the rewards of the miners of the uncles of the given block.
+func (api *ErigonImpl) UncleReward(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error) {
+	tx, err := api.db.BeginRo(ctx)
 	if err != nil {
 		return Issuance{}, err
 	}
-	header := rawdb.ReadHeader(tx, hash, uint64(blockNr))
-	if header == nil {
-		return Issuance{}, fmt.Errorf("could not find block header")
-	}
+	defer tx.Rollback()
 
-	body := rawdb.ReadCanonicalBodyWithTransactions(tx, hash, uint64(blockNr))
+	issuance, _, _, err := api.blockIssuance(ctx, tx, blockNr)
+	if err != nil {
+		return Issuance{}, err
+	}
+	return Issuance{UncleReward: (*hexutil.Big)(issuance.UncleReward.ToBig()), Issuance: (*hexutil.Big)(issuance.UncleReward.ToBig())}, nil
+}
 
-	if body == nil {
-		return Issuance{}, fmt.Errorf("could not find block body")
+// WatchTheBurn implements erigon_watchTheBurn. Returns the issuance (block and uncle rewards, withdrawals) and the burnt
+// base fee of the given block, with the totals since genesis when the Issuance stage has computed them.
+func (api *ErigonImpl) WatchTheBurn(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return Issuance{}, err
 	}
+	defer tx.Rollback()
 
-	minerReward, uncleRewards := ethash.AccumulateRewards(chainConfig, header, body.Uncles)
-	issuance := minerReward
-	for _, r := range uncleRewards {
-		p := r // avoids warning?
-		issuance.Add(&issuance, &p)
+	issuance, header, _, err := api.blockIssuance(ctx, tx, blockNr)
+	if err != nil {
+		return Issuance{}, err
 	}
 
 	var ret Issuance
-	ret.BlockReward = (*hexutil.Big)(minerReward.ToBig())
-	ret.Issuance = (*hexutil.Big)(issuance.ToBig())
-	issuance.Sub(&issuance, &minerReward)
-	ret.UncleReward = (*hexutil.Big)(issuance.ToBig())
-	// Compute how much was burnt
-	if header.BaseFee != nil {
-		burnt := header.BaseFee
-		burnt.Mul(burnt, big.NewInt(int64(header.GasUsed)))
-		ret.Burnt = (*hexutil.Big)(burnt)
-	} else {
-		ret.Burnt = (*hexutil.Big)(big.NewInt(0))
-	}
+	ret.BlockReward = (*hexutil.Big)(issuance.BlockReward.ToBig())
+	ret.UncleReward = (*hexutil.Big)(issuance.UncleReward.ToBig())
+	ret.Withdrawals = (*hexutil.Big)(issuance.Withdrawals.ToBig())
+	ret.Issuance = (*hexutil.Big)(issuance.Issued().ToBig())
+	ret.Burnt = (*hexutil.Big)(issuance.Burnt.ToBig())
 	// Compute totalIssued, totalBurnt and the supply of eth
-	totalIssued, err := rawdb.ReadTotalIssued(tx, uint64(blockNr))
+	totalIssued, err := rawdb.ReadTotalIssued(tx, header.Number.Uint64())
 	if err != nil {
 		return Issuance{}, err
 	}
-	totalBurnt, err := rawdb.ReadTotalBurnt(tx, uint64(blockNr))
+	totalBurnt, err := rawdb.ReadTotalBurnt(tx, header.Number.Uint64())
 	if err != nil {
 		return Issuance{}, err
 	}
@@ -102,17 +83,31 @@ func (api *ErigonImpl) WatchTheBurn(ctx context.Context, blockNr rpc.BlockNumber
 	tips := big.NewInt(0)
 
 	if header.BaseFee != nil {
-		receipts, err := rawdb.ReadReceiptsByHash(tx, hash)
+		chainConfig, err := api.chainConfig(tx)
+		if err != nil {
+			return Issuance{}, err
+		}
+		block, err := api.blockWithSenders(tx, header.Hash(), header.Number.Uint64())
+		if err != nil {
+			return Issuance{}, err
+		}
+		if block == nil {
+			return Issuance{}, fmt.Errorf("could not find block %d", header.Number.Uint64())
+		}
+		receipts, err := api.getReceipts(ctx, tx, chainConfig, block, block.Body().SendersFromTxs())
 		if err != nil {
 			return Issuance{}, err
 		}
+		if len(receipts) != len(block.Transactions()) {
+			return Issuance{}, fmt.Errorf("block %d has %d transactions but %d receipts", header.Number.Uint64(), len(block.Transactions()), len(receipts))
+		}
 
 		baseFee, overflow := uint256.FromBig(header.BaseFee)
 		if overflow {
 			return Issuance{}, fmt.Errorf("baseFee overflow")
 		}
 
-		for i, transaction := range body.Transactions {
+		for i, transaction := range block.Transactions() {
 			tip := transaction.GetEffectiveGasTip(baseFee).ToBig()
 			tips.Add(tips, tip.Mul(tip, big.NewInt(int64(receipts[i].GasUsed))))
 		}
@@ -121,10 +116,83 @@ func (api *ErigonImpl) WatchTheBurn(ctx context.Context, blockNr rpc.BlockNumber
 	return ret, nil
 }
 
+// TotalSupply implements erigon_totalSupply. Returns the ether issued and burnt since genesis up to the given block,
+// as computed by the Issuance stage (--watch-the-burn).
+func (api *ErigonImpl) TotalSupply(ctx context.Context, blockNr rpc.BlockNumber) (TotalSupply, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return TotalSupply{}, err
+	}
+	defer tx.Rollback()
+
+	blockNum, _, _, err := rpchelper.GetBlockNumber(rpc.BlockNumberOrHashWithNumber(blockNr), tx, api.filters)
+	if err != nil {
+		return TotalSupply{}, err
+	}
+	progress, err := stages.GetStageProgress(tx, stages.Issuance)
+	if err != nil {
+		return TotalSupply{}, err
+	}
+	if blockNum > progress {
+		return TotalSupply{}, fmt.Errorf("issuance is computed up to block %d, is --watch-the-burn enabled?", progress)
+	}
+	totalIssued, err := rawdb.ReadTotalIssued(tx, blockNum)
+	if err != nil {
+		return TotalSupply{}, err
+	}
+	totalBurnt, err := rawdb.ReadTotalBurnt(tx, blockNum)
+	if err != nil {
+		return TotalSupply{}, err
+	}
+	return TotalSupply{
+		BlockNumber: hexutil.Uint64(blockNum),
+		TotalIssued: (*hexutil.Big)(totalIssued),
+		TotalBurnt:  (*hexutil.Big)(totalBurnt),
+		TotalSupply: (*hexutil.Big)(new(big.Int).Sub(totalIssued, totalBurnt)),
+	}, nil
+}
+
+// blockIssuance reads the canonical block and works out its issuance
+func (api *ErigonImpl) blockIssuance(ctx context.Context, tx kv.Tx, blockNr rpc.BlockNumber) (core.BlockIssuance, *types.Header, *types.Body, error) {
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return core.BlockIssuance{}, nil, nil, err
+	}
+	blockNum, hash, _, err := rpchelper.GetBlockNumber(rpc.BlockNumberOrHashWithNumber(blockNr), tx, api.filters)
+	if err != nil {
+		return core.BlockIssuance{}, nil, nil, err
+	}
+	header, err := api._blockReader.Header(ctx, tx, hash, blockNum)
+	if err != nil {
+		return core.BlockIssuance{}, nil, nil, err
+	}
+	if header == nil {
+		return core.BlockIssuance{}, nil, nil, fmt.Errorf("could not find block header")
+	}
+
+	body, err := api._blockReader.BodyWithTransactions(ctx, tx, hash, blockNum)
+	if err != nil {
+		return core.BlockIssuance{}, nil, nil, err
+	}
+	if body == nil {
+		return core.BlockIssuance{}, nil, nil, fmt.Errorf("could not find block body")
+	}
+	return core.CalcBlockIssuance(chainConfig, header, body.Uncles, body.Withdrawals), header, body, nil
+}
+
+// TotalSupply is the result of erigon_totalSupply
+type TotalSupply struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	TotalIssued *hexutil.Big   `json:"totalIssued"` // Total amount of wei created up to the block, genesis allocations included
+	TotalBurnt  *hexutil.Big   `json:"totalBurnt"`  // Total amount of wei burnt up to the block
+	TotalSupply *hexutil.Big   `json:"totalSupply"` // totalIssued - totalBurnt
+}
+
 // Issuance structure to return information about issuance
 type Issuance struct {
 	BlockReward *hexutil.Big `json:"blockReward"` // Block reward for given block
 	UncleReward *hexutil.Big `json:"uncleReward"` // Uncle reward for gived block
+	Withdrawals *hexutil.Big `json:"withdrawals"` // Amount of wei withdrawn from the beacon chain in the block
 	Issuance    *hexutil.Big `json:"issuance"`    // Total amount of wei created in the block
 	Burnt       *hexutil.Big `json:"burnt"`       // Total amount of wei burned in the block
 	TotalIssued *hexutil.Big `json:"totalIssued"` // Total amount of wei created in total so far
@@ -4,10 +4,22 @@ import (
 	"context"
 	"fmt"
 
+	jsoniter "github.com/json-iterator/go"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/filters"
 )
 
+// defaultLogsPageSize is the page size of erigon_getLogs when neither the client nor --rpc.getlogs.maxresults set one
+const defaultLogsPageSize = 1_000
+
+// LogsCursor is the position of a log in the chain, erigon_getLogs returns the logs starting from it
+type LogsCursor struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	LogIndex    hexutil.Uint64 `json:"logIndex"`
+}
+
 // GetLogsByHash implements erigon_getLogsByHash. Returns an array of arrays of logs generated by the transactions in the block given by the block's hash.
 func (api *ErigonImpl) GetLogsByHash(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
 	tx, err := api.db.BeginRo(ctx)
@@ -40,6 +52,95 @@ func (api *ErigonImpl) GetLogsByHash(ctx context.Context, hash common.Hash) ([][
 	return logs, nil
 }
 
+// GetLogs implements erigon_getLogs. Returns a page of at most limit logs matching the filter, starting from the cursor
+// (the beginning of the range by default), as {"logs": [...], "next": cursor}. The next cursor is null once the range
+// is exhausted. A page never scans more than --rpc.getlogs.maxblockrange blocks, so it may be short (even empty)
+// while there is more to read.
+func (api *ErigonImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria, cursor *LogsCursor, limit *hexutil.Uint64, stream *jsoniter.Stream) error {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		stream.WriteNil()
+		return err
+	}
+	defer tx.Rollback()
+
+	begin, end, err := logsRange(tx, crit)
+	if err != nil {
+		stream.WriteNil()
+		return err
+	}
+	var fromLogIndex uint
+	if cursor != nil {
+		if uint64(cursor.BlockNumber) < begin || uint64(cursor.BlockNumber) > end {
+			stream.WriteNil()
+			return fmt.Errorf("cursor block %d is out of range [%d, %d]", uint64(cursor.BlockNumber), begin, end)
+		}
+		begin, fromLogIndex = uint64(cursor.BlockNumber), uint(cursor.LogIndex)
+	}
+	pageSize := uint64(defaultLogsPageSize)
+	if api.LogsLimits.MaxResults > 0 {
+		pageSize = api.LogsLimits.MaxResults
+	}
+	if limit != nil && uint64(*limit) > 0 && uint64(*limit) < pageSize {
+		pageSize = uint64(*limit)
+	}
+	scanEnd := end
+	if api.LogsLimits.MaxBlockRange > 0 && end-begin >= api.LogsLimits.MaxBlockRange {
+		scanEnd = begin + api.LogsLimits.MaxBlockRange - 1
+	}
+
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	stream.WriteObjectStart()
+	stream.WriteObjectField("logs")
+	stream.WriteArrayStart()
+	var count uint64
+	var next *LogsCursor
+	err = api.walkLogs(ctx, tx, crit, begin, scanEnd, func(blockNumber uint64, logs []*types.Log) (bool, error) {
+		for _, log := range logs {
+			if blockNumber == begin && log.Index < fromLogIndex {
+				continue
+			}
+			if count == pageSize {
+				next = &LogsCursor{BlockNumber: hexutil.Uint64(blockNumber), LogIndex: hexutil.Uint64(log.Index)}
+				return false, nil
+			}
+			b, err := json.Marshal(log)
+			if err != nil {
+				return false, err
+			}
+			if count > 0 {
+				stream.WriteMore()
+			}
+			stream.Write(b)
+			count++
+		}
+		return true, stream.Flush()
+	})
+	stream.WriteArrayEnd()
+	if err != nil {
+		stream.WriteObjectEnd()
+		return err
+	}
+	if next == nil && scanEnd < end {
+		next = &LogsCursor{BlockNumber: hexutil.Uint64(scanEnd + 1)}
+	}
+	stream.WriteMore()
+	stream.WriteObjectField("next")
+	if next == nil {
+		stream.WriteNil()
+	} else {
+		b, err := json.Marshal(next)
+		if err != nil {
+			stream.WriteNil()
+			stream.WriteObjectEnd()
+			return err
+		}
+		stream.Write(b)
+	}
+	stream.WriteObjectEnd()
+	return nil
+}
+
 // GetLogsByNumber implements erigon_getLogsByHash. Returns all the logs that appear in a block given the block's hash.
 // func (api *ErigonImpl) GetLogsByNumber(ctx context.Context, number rpc.BlockNumber) ([][]*types.Log, error) {
 // 	tx, err := api.db.Begin(ctx, false)
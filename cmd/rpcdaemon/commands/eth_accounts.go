@@ -15,6 +15,21 @@ import (
 	"github.com/ledgerwatch/erigon/rpc"
 )
 
+// Accounts implements eth_accounts. Returns a list of addresses owned by the client: the accounts of the local keystore.
+func (api *APIImpl) Accounts(ctx context.Context) ([]common.Address, error) {
+	if api.keystore == nil {
+		return []common.Address{}, nil
+	}
+	accounts, err := api.keystore.Accounts()
+	if err != nil {
+		return nil, err
+	}
+	if accounts == nil {
+		accounts = []common.Address{}
+	}
+	return accounts, nil
+}
+
 // GetBalance implements eth_getBalance. Returns the balance of an account for a given address.
 func (api *APIImpl) GetBalance(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
 	tx, err1 := api.db.BeginRo(ctx)
@@ -11,19 +11,23 @@ import (
 	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpool"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/eth/tracers/logger"
 	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/transactions"
+	"github.com/ledgerwatch/erigon/turbo/trie"
 	"github.com/ledgerwatch/log/v3"
 	"google.golang.org/grpc"
 )
@@ -263,10 +267,150 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi.CallArgs,
 	return hexutil.Uint64(hi), nil
 }
 
-// GetProof not implemented
-func (api *APIImpl) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNr rpc.BlockNumber) (*interface{}, error) {
-	var stub interface{}
-	return &stub, fmt.Errorf(NotImplemented, "eth_getProof")
+// trieBlockFor - checks that the state trie of blockNr can be calculated and returns the block of the hashed state and
+// intermediate hashes, which correspond to the progress of IntermediateHashes stage. The trie of an earlier block is
+// rebuilt with the change sets of the blocks in between.
+func (api *APIImpl) trieBlockFor(tx kv.Tx, blockNr uint64) (uint64, error) {
+	trieBlock, err := stages.GetStageProgress(tx, stages.IntermediateHashes)
+	if err != nil {
+		return 0, err
+	}
+	if blockNr > trieBlock {
+		return 0, fmt.Errorf("state trie of block %d is not available yet, trie is at block %d", blockNr, trieBlock)
+	}
+	if trieBlock-blockNr > api.MaxGetProofRewindBlockCount {
+		return 0, fmt.Errorf("requested block is too old, block must be within %d blocks of the head block number (currently %d)", api.MaxGetProofRewindBlockCount, trieBlock)
+	}
+	if blockNr < trieBlock {
+		pm, err := prune.Get(tx)
+		if err != nil {
+			return 0, err
+		}
+		if pm.History.Enabled() && blockNr < pm.History.PruneTo(trieBlock) {
+			return 0, fmt.Errorf("history of block %d is pruned, proofs are available from block %d", blockNr, pm.History.PruneTo(trieBlock))
+		}
+	}
+	return trieBlock, nil
+}
+
+// GetProof implements eth_getProof. Returns the account and storage values of the specified account including the Merkle-proof (EIP-1186).
+// Proofs for blocks before the head are made by rolling back the hashed state with the change sets in memory,
+// so only blocks within MaxGetProofRewindBlockCount of the head are supported.
+func (api *APIImpl) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*ethapi.AccountResult, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	blockNr, _, _, err := rpchelper.GetCanonicalBlockNumber(blockNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	header, err := api._blockReader.HeaderByNumber(ctx, tx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %d not found", blockNr)
+	}
+
+	trieBlock, err := api.trieBlockFor(tx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := state.NewPlainState(tx, blockNr+1)
+	acc, err := reader.ReadAccountData(address)
+	if err != nil {
+		return nil, err
+	}
+
+	addrHash, err := common.HashData(address[:])
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]common.Hash, len(storageKeys))
+	keyHashes := make([]common.Hash, len(storageKeys))
+	for i, key := range storageKeys {
+		keys[i] = common.HexToHash(key)
+		if keyHashes[i], err = common.HashData(keys[i][:]); err != nil {
+			return nil, err
+		}
+	}
+
+	rl := trie.NewRetainList(0)
+	rl.AddKey(addrHash[:])
+	if acc != nil && acc.Incarnation > 0 {
+		for _, keyHash := range keyHashes {
+			rl.AddKey(dbutils.GenerateCompositeStorageKey(addrHash, acc.Incarnation, keyHash))
+		}
+	}
+	loader := trie.NewFlatDBTrieLoader("eth_getProof")
+	if err = loader.Reset(rl, nil, nil, false); err != nil {
+		return nil, err
+	}
+	loader.SetProofRetainer(rl)
+	if blockNr < trieBlock {
+		overlay, err := ethapi.NewStateOverlay(tx, blockNr, trieBlock, rl)
+		if err != nil {
+			return nil, err
+		}
+		loader.SetStreamReceiver(ethapi.NewStateOverlayReceiver(tx, overlay, loader.DefaultReceiver()))
+	}
+	root, err := loader.CalcTrieRoot(tx, []byte{}, ctx.Done())
+	if err != nil {
+		return nil, err
+	}
+	if root != header.Root {
+		return nil, fmt.Errorf("computed state root %x doesn't match root %x of block %d", root, header.Root, blockNr)
+	}
+
+	tr := loader.ProofTrie()
+	accountProof, err := tr.Prove(addrHash[:], 0, false)
+	if err != nil {
+		return nil, err
+	}
+	result := &ethapi.AccountResult{
+		Address:      address,
+		AccountProof: toHexSlice(accountProof),
+		Balance:      (*hexutil.Big)(new(big.Int)),
+		CodeHash:     trie.EmptyCodeHash,
+		StorageHash:  trie.EmptyRoot,
+		StorageProof: make([]ethapi.StorageResult, len(storageKeys)),
+	}
+	if acc != nil {
+		result.Balance = (*hexutil.Big)(acc.Balance.ToBig())
+		result.Nonce = hexutil.Uint64(acc.Nonce)
+		result.CodeHash = acc.CodeHash
+		if trieAcc, ok := tr.GetAccount(addrHash[:]); ok && trieAcc != nil {
+			result.StorageHash = trieAcc.Root
+		}
+	}
+	for i, key := range storageKeys {
+		value := new(big.Int)
+		var proof [][]byte
+		if acc != nil && acc.Incarnation > 0 {
+			enc, err := reader.ReadAccountStorage(address, acc.Incarnation, &keys[i])
+			if err != nil {
+				return nil, err
+			}
+			value.SetBytes(enc)
+			if proof, err = tr.Prove(append(addrHash[:], keyHashes[i][:]...), 64, true); err != nil {
+				return nil, err
+			}
+		}
+		result.StorageProof[i] = ethapi.StorageResult{Key: key, Value: (*hexutil.Big)(value), Proof: toHexSlice(proof)}
+	}
+	return result, nil
+}
+
+func toHexSlice(b [][]byte) []string {
+	r := make([]string, len(b))
+	for i := range b {
+		r[i] = hexutil.Encode(b[i])
+	}
+	return r
 }
 
 // accessListResult returns an optional accesslist
@@ -350,7 +494,7 @@ func (api *APIImpl) CreateAccessList(ctx context.Context, args ethapi.CallArgs,
 	}
 
 	// Retrieve the precompiles since they don't need to be added to the access list
-	precompiles := vm.ActivePrecompiles(chainConfig.Rules(blockNumber))
+	precompiles := vm.ActivePrecompiles(chainConfig.Rules(blockNumber, header.Time))
 
 	// Create an initial tracer
 	prevTracer := logger.NewAccessListTracer(nil, *args.From, to, precompiles)
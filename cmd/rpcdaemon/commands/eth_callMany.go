@@ -147,7 +147,7 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 
 	// Get a new instance of the EVM
 	signer := types.MakeSigner(chainConfig, blockNum)
-	rules := chainConfig.Rules(blockNum)
+	rules := chainConfig.Rules(blockNum, block.Time())
 
 	contractHasTEVM := func(contractHash common.Hash) (bool, error) { return false, nil }
 
@@ -21,13 +21,16 @@ import (
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
 	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
 	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 	"github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/ledgerwatch/erigon/turbo/trie"
 )
 
 func TestEstimateGas(t *testing.T) {
@@ -378,3 +381,106 @@ func prune(t *testing.T, db kv.RwDB, pruneTo uint64) {
 	err = tx.Commit()
 	assert.NoError(t, err)
 }
+
+func TestGetProof(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), false), db, nil, nil, nil, 5000000)
+	var address = common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+	var token = crypto.CreateAddress(address, 2) // deployed in block 3, slot 0 is totalSupply
+
+	tx, err := db.BeginRo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	head := rawdb.ReadCurrentHeader(tx)
+
+	// every block within the window - proof is built from the rolled back trie, which root must match the header
+	for blockNum := uint64(0); blockNum <= head.Number.Uint64(); blockNum++ {
+		header := rawdb.ReadHeaderByNumber(tx, blockNum)
+		for _, addr := range []common.Address{address, token} {
+			blockNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(blockNum))
+			result, err := api.GetProof(context.Background(), addr, []string{"0x0"}, blockNrOrHash)
+			if err != nil {
+				t.Fatalf("block %d: %v", blockNum, err)
+			}
+			verifyAccountResult(t, api, header.Root, addr, result, blockNrOrHash)
+		}
+	}
+
+	result, err := api.GetProof(context.Background(), token, []string{"0x0"}, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(head.Number.Uint64())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(10), result.StorageProof[0].Value.ToInt().Uint64())
+
+	api.MaxGetProofRewindBlockCount = 1
+	if _, err = api.GetProof(context.Background(), address, nil, rpc.BlockNumberOrHashWithNumber(0)); err == nil {
+		t.Errorf("expected error for the block outside of the window")
+	}
+}
+
+// verifyAccountResult - checks account and storage proofs of eth_getProof against the state root, and the returned
+// values against eth_getBalance, eth_getTransactionCount and eth_getStorageAt
+func verifyAccountResult(t *testing.T, api *APIImpl, root common.Hash, addr common.Address, result *ethapi.AccountResult, blockNrOrHash rpc.BlockNumberOrHash) {
+	t.Helper()
+	ctx := context.Background()
+	decode := func(hexProof []string) [][]byte {
+		proof := make([][]byte, len(hexProof))
+		for i, node := range hexProof {
+			var err error
+			if proof[i], err = hexutil.Decode(node); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return proof
+	}
+
+	enc, err := trie.VerifyProof(root, crypto.Keccak256(addr[:]), decode(result.AccountProof))
+	if err != nil {
+		t.Fatalf("account %x: %v", addr, err)
+	}
+	balance, err := api.GetBalance(ctx, addr, blockNrOrHash)
+	assert.NoError(t, err)
+	nonce, err := api.GetTransactionCount(ctx, addr, blockNrOrHash)
+	assert.NoError(t, err)
+	assert.Equal(t, balance.ToInt().String(), result.Balance.ToInt().String(), "account %x", addr)
+	assert.Equal(t, *nonce, result.Nonce, "account %x", addr)
+	if enc == nil { // account doesn't exist
+		assert.Equal(t, uint64(0), uint64(result.Nonce))
+		assert.Equal(t, 0, result.Balance.ToInt().Sign())
+		return
+	}
+	var acc accounts.Account
+	if err = acc.DecodeForHashing(enc); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, acc.Nonce, uint64(result.Nonce), "account %x", addr)
+	assert.Equal(t, acc.Balance.ToBig().String(), result.Balance.ToInt().String(), "account %x", addr)
+	assert.Equal(t, acc.Root, result.StorageHash, "account %x", addr)
+	assert.Equal(t, acc.CodeHash, result.CodeHash, "account %x", addr)
+
+	for _, sp := range result.StorageProof {
+		stored, err := api.GetStorageAt(ctx, addr, sp.Key, blockNrOrHash)
+		assert.NoError(t, err)
+		assert.Equal(t, common.HexToHash(stored).Big().String(), sp.Value.ToInt().String(), "account %x, slot %s", addr, sp.Key)
+		if acc.Root == trie.EmptyRoot { // no storage trie to prove against
+			assert.Empty(t, sp.Proof)
+			assert.Equal(t, 0, sp.Value.ToInt().Sign())
+			continue
+		}
+		loc := common.HexToHash(sp.Key)
+		value, err := trie.VerifyProof(acc.Root, crypto.Keccak256(loc[:]), decode(sp.Proof))
+		if err != nil {
+			t.Fatalf("account %x, slot %s: %v", addr, sp.Key, err)
+		}
+		var proven []byte
+		if value != nil {
+			if proven, _, err = rlp.SplitString(value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		assert.Equal(t, new(big.Int).SetBytes(proven).String(), sp.Value.ToInt().String(), "account %x, slot %s", addr, sp.Key)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/filters"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/log/v3"
@@ -259,3 +260,54 @@ func (api *APIImpl) Logs(ctx context.Context, crit filters.FilterCriteria) (*rpc
 
 	return rpcSub, nil
 }
+
+// EthSyncingAPI serves eth_subscribe("syncing"), APIImpl can't as its Syncing method is eth_syncing
+type EthSyncingAPI struct {
+	filters *rpchelper.Filters
+}
+
+// NewEthSyncingAPI returns EthSyncingAPI instance
+func NewEthSyncingAPI(base *BaseAPI) *EthSyncingAPI {
+	return &EthSyncingAPI{filters: base.filters}
+}
+
+// Syncing send a notification each time the progress of a stage of the staged sync changes.
+func (api *EthSyncingAPI) Syncing(ctx context.Context) (*rpc.Subscription, error) {
+	if api.filters == nil {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer debug.LogPanic()
+		progressCh := make(chan *stages.SyncProgress, 8)
+		id := api.filters.SubscribeSyncing(progressCh)
+		defer api.filters.UnsubscribeSyncing(id)
+
+		for {
+			select {
+			case progress, ok := <-progressCh:
+				if progress != nil {
+					err := notifier.Notify(rpcSub.ID, progress)
+					if err != nil {
+						log.Warn("error while notifying subscription", "err", err)
+						return
+					}
+				}
+				if !ok {
+					log.Warn("syncing channel was closed")
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -2,15 +2,21 @@ package commands
 
 import (
 	"testing"
+	"time"
 
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/txpool"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/filters"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 	"github.com/ledgerwatch/erigon/turbo/stages"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewFilters(t *testing.T) {
@@ -43,3 +49,44 @@ func TestNewFilters(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal(ok, true)
 }
+
+func TestGetFilterChangesRemovedLogs(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	ctx, conn := rpcdaemontest.CreateTestGrpcConn(t, stages.Mock(t))
+	mining := txpool.NewMiningClient(conn)
+	ff := rpchelper.New(ctx, nil, nil, mining, func() {})
+	api := NewEthAPI(NewBaseApi(ff, stateCache, snapshotsync.NewBlockReader(), false), db, nil, nil, nil, 5000000)
+
+	address := common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+	id, err := api.NewFilter(ctx, filters.FilterCriteria{Addresses: []common.Address{address}})
+	require.NoError(t, err)
+
+	// Logs of an unwound block come again with Removed set
+	blockHash := common.HexToHash("0x01")
+	ff.OnNewLogs(&remote.SubscribeLogsReply{
+		Address:     gointerfaces.ConvertAddressToH160(address),
+		BlockHash:   gointerfaces.ConvertHashToH256(blockHash),
+		BlockNumber: 5,
+		Removed:     true,
+	})
+
+	var changes []interface{}
+	require.Eventually(t, func() bool {
+		changes, err = api.GetFilterChanges(ctx, id)
+		require.NoError(t, err)
+		return len(changes) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, changes, 1)
+	lg, ok := changes[0].(*types.Log)
+	require.True(t, ok)
+	require.True(t, lg.Removed)
+	require.Equal(t, address, lg.Address)
+	require.Equal(t, blockHash, lg.BlockHash)
+	require.Equal(t, uint64(5), lg.BlockNumber)
+
+	// Polling drains the filter
+	changes, err = api.GetFilterChanges(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, changes, 0)
+}
@@ -32,8 +32,19 @@ import (
 )
 
 func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, chainConfig *params.ChainConfig, block *types.Block, senders []common.Address) (types.Receipts, error) {
-	if cached := rawdb.ReadReceipts(tx, block, senders); cached != nil {
-		return cached, nil
+	// the receipts are read from the db or, once retired, from the receipts snapshots
+	stored, err := api._blockReader.RawReceipts(ctx, tx, block.NumberU64())
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		if len(senders) > 0 {
+			block.SendersToTxs(senders)
+		}
+		if err = stored.DeriveFields(block.Hash(), block.NumberU64(), block.Transactions(), senders); err == nil {
+			return stored, nil
+		}
+		log.Error("Failed to derive block receipts fields", "hash", block.Hash(), "number", block.NumberU64(), "err", err)
 	}
 
 	getHeader := func(hash common.Hash, number uint64) *types.Header {
@@ -73,7 +84,6 @@ func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, chainConfig *para
 
 // GetLogs implements eth_getLogs. Returns an array of logs matching a given filter object.
 func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([]*types.Log, error) {
-	var begin, end uint64
 	logs := []*types.Log{}
 
 	tx, beginErr := api.db.BeginRo(ctx)
@@ -82,47 +92,90 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([
 	}
 	defer tx.Rollback()
 
+	begin, end, err := logsRange(tx, crit)
+	if err != nil {
+		return nil, err
+	}
+	if api.LogsLimits.MaxBlockRange > 0 && end-begin >= api.LogsLimits.MaxBlockRange {
+		return nil, &logsLimitError{
+			msg:       fmt.Sprintf("block range is too wide, at most %d blocks can be queried", api.LogsLimits.MaxBlockRange),
+			fromBlock: begin,
+			toBlock:   begin + api.LogsLimits.MaxBlockRange - 1,
+		}
+	}
+
+	err = api.walkLogs(ctx, tx, crit, begin, end, func(blockNumber uint64, blockLogs []*types.Log) (bool, error) {
+		if api.LogsLimits.MaxResults > 0 && uint64(len(blockLogs)) > api.LogsLimits.MaxResults {
+			// No narrower range helps, the data points at the block so that the client can filter it down
+			return false, &logsLimitError{
+				msg:       fmt.Sprintf("block %d alone has %d matching logs, more than the limit of %d results", blockNumber, len(blockLogs), api.LogsLimits.MaxResults),
+				fromBlock: blockNumber,
+				toBlock:   blockNumber,
+			}
+		}
+		if api.LogsLimits.MaxResults > 0 && uint64(len(logs)+len(blockLogs)) > api.LogsLimits.MaxResults {
+			// The block fits on its own, so there are logs of earlier blocks and those are within the limit
+			return false, &logsLimitError{
+				msg:       fmt.Sprintf("query returned more than %d results", api.LogsLimits.MaxResults),
+				fromBlock: begin,
+				toBlock:   blockNumber - 1,
+			}
+		}
+		logs = append(logs, blockLogs...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// logsRange resolves the block range of a filter, by its block hash or its from and to blocks
+func logsRange(tx kv.Tx, crit filters.FilterCriteria) (begin, end uint64, err error) {
 	if crit.BlockHash != nil {
 		number := rawdb.ReadHeaderNumber(tx, *crit.BlockHash)
 		if number == nil {
-			return nil, fmt.Errorf("block not found: %x", *crit.BlockHash)
-		}
-		begin = *number
-		end = *number
-	} else {
-		// Convert the RPC block numbers into internal representations
-		latest, err := rpchelper.GetLatestBlockNumber(tx)
-		if err != nil {
-			return nil, err
+			return 0, 0, fmt.Errorf("block not found: %x", *crit.BlockHash)
 		}
+		return *number, *number, nil
+	}
+	// Convert the RPC block numbers into internal representations
+	latest, err := rpchelper.GetLatestBlockNumber(tx)
+	if err != nil {
+		return 0, 0, err
+	}
 
-		begin = latest
-		if crit.FromBlock != nil {
-			if crit.FromBlock.Sign() >= 0 {
-				begin = crit.FromBlock.Uint64()
-			} else if !crit.FromBlock.IsInt64() || crit.FromBlock.Int64() != int64(rpc.LatestBlockNumber) {
-				return nil, fmt.Errorf("negative value for FromBlock: %v", crit.FromBlock)
-			}
+	begin = latest
+	if crit.FromBlock != nil {
+		if crit.FromBlock.Sign() >= 0 {
+			begin = crit.FromBlock.Uint64()
+		} else if !crit.FromBlock.IsInt64() || crit.FromBlock.Int64() != int64(rpc.LatestBlockNumber) {
+			return 0, 0, fmt.Errorf("negative value for FromBlock: %v", crit.FromBlock)
 		}
-		end = latest
-		if crit.ToBlock != nil {
-			if crit.ToBlock.Sign() >= 0 {
-				end = crit.ToBlock.Uint64()
-			} else if !crit.ToBlock.IsInt64() || crit.ToBlock.Int64() != int64(rpc.LatestBlockNumber) {
-				return nil, fmt.Errorf("negative value for ToBlock: %v", crit.ToBlock)
-			}
+	}
+	end = latest
+	if crit.ToBlock != nil {
+		if crit.ToBlock.Sign() >= 0 {
+			end = crit.ToBlock.Uint64()
+		} else if !crit.ToBlock.IsInt64() || crit.ToBlock.Int64() != int64(rpc.LatestBlockNumber) {
+			return 0, 0, fmt.Errorf("negative value for ToBlock: %v", crit.ToBlock)
 		}
 	}
 	if end < begin {
-		return nil, fmt.Errorf("end (%d) < begin (%d)", end, begin)
+		return 0, 0, fmt.Errorf("end (%d) < begin (%d)", end, begin)
 	}
+	return begin, end, nil
+}
 
+// walkLogs calls onBlock with the logs matching crit of every block in [begin, end] that has some, in block order.
+// The walk stops when onBlock returns false or an error.
+func (api *BaseAPI) walkLogs(ctx context.Context, tx kv.Tx, crit filters.FilterCriteria, begin, end uint64, onBlock func(blockNumber uint64, logs []*types.Log) (bool, error)) error {
 	blockNumbers := roaring.New()
 	blockNumbers.AddRange(begin, end+1) // [min,max)
 
 	topicsBitmap, err := getTopicsBitmap(tx, crit.Topics, uint32(begin), uint32(end))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if topicsBitmap != nil {
 		blockNumbers.And(topicsBitmap)
@@ -132,7 +185,7 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([
 	for _, addr := range crit.Addresses {
 		m, err := bitmapdb.Get(tx, kv.LogAddressIndex, addr[:], uint32(begin), uint32(end))
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if addrBitmap == nil {
 			addrBitmap = m
@@ -146,42 +199,56 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([
 	}
 
 	if blockNumbers.GetCardinality() == 0 {
-		return logs, nil
+		return nil
 	}
 
 	iter := blockNumbers.Iterator()
 	for iter.HasNext() {
 		if err = ctx.Err(); err != nil {
-			return nil, err
+			return err
 		}
 
 		blockNumber := uint64(iter.Next())
 		var logIndex uint
 		var txIndex uint
 		var blockLogs []*types.Log
-		err := tx.ForPrefix(kv.Log, dbutils.EncodeBlockNumber(blockNumber), func(k, v []byte) error {
-			var logs types.Logs
-			if err := cbor.Unmarshal(&logs, bytes.NewReader(v)); err != nil {
-				return fmt.Errorf("receipt unmarshal failed:  %w", err)
-			}
+		addTxLogs := func(txn uint, logs types.Logs) {
 			for _, log := range logs {
 				log.Index = logIndex
 				logIndex++
 			}
 			filtered := filterLogs(logs, crit.Addresses, crit.Topics)
 			if len(filtered) == 0 {
-				return nil
+				return
 			}
-			txIndex = uint(binary.BigEndian.Uint32(k[8:]))
+			txIndex = txn
 			for _, log := range filtered {
 				log.TxIndex = txIndex
 			}
 			blockLogs = append(blockLogs, filtered...)
-
+		}
+		var inDB bool
+		err := tx.ForPrefix(kv.Log, dbutils.EncodeBlockNumber(blockNumber), func(k, v []byte) error {
+			inDB = true
+			var logs types.Logs
+			if err := cbor.Unmarshal(&logs, bytes.NewReader(v)); err != nil {
+				return fmt.Errorf("receipt unmarshal failed:  %w", err)
+			}
+			addTxLogs(uint(binary.BigEndian.Uint32(k[8:])), logs)
 			return nil
 		})
 		if err != nil {
-			return logs, err
+			return err
+		}
+		if !inDB {
+			// the logs of the blocks retired into the receipts snapshots are not in the db anymore
+			receipts, err := api._blockReader.RawReceipts(ctx, tx, blockNumber)
+			if err != nil {
+				return err
+			}
+			for i, receipt := range receipts {
+				addTxLogs(uint(i), receipt.Logs)
+			}
 		}
 		if len(blockLogs) == 0 {
 			continue
@@ -189,33 +256,50 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([
 
 		blockHash, err := rawdb.ReadCanonicalHash(tx, blockNumber)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		body, err := api._blockReader.BodyWithTransactions(ctx, tx, blockHash, blockNumber)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if body == nil {
-			return nil, fmt.Errorf("block not found %d", blockNumber)
+			return fmt.Errorf("block not found %d", blockNumber)
 		}
 		for _, log := range blockLogs {
 			log.BlockNumber = blockNumber
 			log.BlockHash = blockHash
 			log.TxHash = body.Transactions[log.TxIndex].Hash()
 		}
-		logs = append(logs, blockLogs...)
 
 		borLogs := rawdb.ReadBorReceiptLogs(tx, blockHash, blockNumber, txIndex+1, logIndex)
 		if borLogs != nil {
 			borLogs = filterLogs(borLogs, crit.Addresses, crit.Topics)
 			if len(borLogs) > 0 {
-				logs = append(logs, borLogs...)
+				blockLogs = append(blockLogs, borLogs...)
 			}
 		}
+
+		more, err := onBlock(blockNumber, blockLogs)
+		if err != nil || !more {
+			return err
+		}
 	}
+	return nil
+}
 
-	return logs, nil
+// logsLimitError is returned when a logs query is over one of the LogsLimits, its data is a narrower range to retry with
+type logsLimitError struct {
+	msg       string
+	fromBlock uint64
+	toBlock   uint64
+}
+
+func (e *logsLimitError) Error() string  { return e.msg }
+func (e *logsLimitError) ErrorCode() int { return -32005 }
+
+func (e *logsLimitError) ErrorData() interface{} {
+	return map[string]interface{}{"fromBlock": hexutil.Uint64(e.fromBlock), "toBlock": hexutil.Uint64(e.toBlock)}
 }
 
 // The Topic list restricts matches to particular event topics. Each event has a list
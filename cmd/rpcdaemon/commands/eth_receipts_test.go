@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/filters"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/services"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/stretchr/testify/require"
+)
+
+// chainWithLogs has a contract emitting a log on every call deployed in block 1, and 3 calls of it in each of the blocks 2-6
+func chainWithLogs(t *testing.T) (kv.RwDB, common.Address) {
+	var (
+		signer      = types.LatestSignerForChainID(nil)
+		bankKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		bankAddress = crypto.PubkeyToAddress(bankKey.PublicKey)
+		// PUSH1 0 PUSH1 0 LOG0 STOP, behind the code copying it
+		contract = hexutil.MustDecode("0x6006600c60003960066000f360006000a000")
+		gspec    = &core.Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc:  core.GenesisAlloc{bankAddress: {Balance: big.NewInt(1e18)}},
+		}
+	)
+	m := stages.MockWithGenesis(t, gspec, bankKey, false)
+	contractAddr := crypto.CreateAddress(bankAddress, 0)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 6, func(i int, block *core.BlockGen) {
+		if i == 0 {
+			txn, err := types.SignTx(types.NewContractCreation(block.TxNonce(bankAddress), new(uint256.Int), 1e6, new(uint256.Int), contract), *signer, bankKey)
+			require.NoError(t, err)
+			block.AddTx(txn)
+			return
+		}
+		for j := 0; j < 3; j++ {
+			txn, err := types.SignTx(types.NewTransaction(block.TxNonce(bankAddress), contractAddr, new(uint256.Int), 50000, new(uint256.Int), nil), *signer, bankKey)
+			require.NoError(t, err)
+			block.AddTx(txn)
+		}
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+	return m.DB, contractAddr
+}
+
+func TestGetLogsLimits(t *testing.T) {
+	db, contractAddr := chainWithLogs(t)
+	api := NewEthAPI(NewBaseApi(nil, kvcache.New(kvcache.DefaultCoherentConfig), snapshotsync.NewBlockReader(), false), db, nil, nil, nil, 5000000)
+	ctx := context.Background()
+	crit := filters.FilterCriteria{FromBlock: big.NewInt(0), Addresses: []common.Address{contractAddr}}
+
+	logs, err := api.GetLogs(ctx, crit)
+	require.NoError(t, err)
+	require.Len(t, logs, 15)
+	require.Equal(t, uint64(2), logs[0].BlockNumber)
+	require.Equal(t, uint(2), logs[14].Index)
+
+	api.LogsLimits = LogsLimits{MaxBlockRange: 3}
+	_, err = api.GetLogs(ctx, crit)
+	requireLogsLimitError(t, err, 0, 2)
+	logs, err = api.GetLogs(ctx, filters.FilterCriteria{FromBlock: big.NewInt(2), ToBlock: big.NewInt(4), Addresses: []common.Address{contractAddr}})
+	require.NoError(t, err)
+	require.Len(t, logs, 9)
+
+	// Blocks 2 and 3 fit into the limit, block 4 does not
+	api.LogsLimits = LogsLimits{MaxResults: 7}
+	_, err = api.GetLogs(ctx, crit)
+	requireLogsLimitError(t, err, 0, 3)
+
+	// Block 2 alone is over the limit, no narrower range helps
+	api.LogsLimits = LogsLimits{MaxResults: 2}
+	_, err = api.GetLogs(ctx, crit)
+	requireLogsLimitError(t, err, 2, 2)
+	require.Contains(t, err.Error(), "block 2 alone")
+}
+
+func requireLogsLimitError(t *testing.T, err error, fromBlock, toBlock uint64) {
+	t.Helper()
+	var limitErr *logsLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, -32005, limitErr.ErrorCode())
+	require.Equal(t, map[string]interface{}{"fromBlock": hexutil.Uint64(fromBlock), "toBlock": hexutil.Uint64(toBlock)}, limitErr.ErrorData())
+}
+
+func TestErigonGetLogsPages(t *testing.T) {
+	db, contractAddr := chainWithLogs(t)
+	api := NewErigonAPI(NewBaseApi(nil, kvcache.New(kvcache.DefaultCoherentConfig), snapshotsync.NewBlockReader(), false), db, nil)
+	ctx := context.Background()
+	crit := filters.FilterCriteria{FromBlock: big.NewInt(0), Addresses: []common.Address{contractAddr}}
+
+	readAll := func(limit uint64) (logs []*types.Log, pages int) {
+		var cursor *LogsCursor
+		for {
+			var buf bytes.Buffer
+			stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
+			require.NoError(t, api.GetLogs(ctx, crit, cursor, (*hexutil.Uint64)(&limit), stream))
+			require.NoError(t, stream.Flush())
+			var page struct {
+				Logs []*types.Log `json:"logs"`
+				Next *LogsCursor  `json:"next"`
+			}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &page))
+			require.LessOrEqual(t, uint64(len(page.Logs)), limit)
+			logs = append(logs, page.Logs...)
+			pages++
+			if page.Next == nil {
+				return logs, pages
+			}
+			cursor = page.Next
+		}
+	}
+
+	logs, pages := readAll(4)
+	require.Equal(t, 4, pages)
+	require.Len(t, logs, 15)
+	for i, l := range logs {
+		require.Equal(t, uint64(2+i/3), l.BlockNumber)
+		require.Equal(t, uint(i%3), l.Index)
+	}
+
+	// Pages stop at the end of the allowed block range, the first one covers the blocks 0-1 without logs
+	api.LogsLimits = LogsLimits{MaxBlockRange: 2}
+	logs, pages = readAll(100)
+	require.Equal(t, 4, pages)
+	require.Len(t, logs, 15)
+}
+
+// retiredReceiptsReader serves the receipts which are gone from the db, as the receipts snapshots do
+type retiredReceiptsReader struct {
+	services.FullBlockReader
+	receipts map[uint64]types.Receipts
+}
+
+func (r *retiredReceiptsReader) RawReceipts(ctx context.Context, tx kv.Getter, blockNum uint64) (types.Receipts, error) {
+	return r.receipts[blockNum], nil
+}
+
+func TestReceiptsAfterTruncate(t *testing.T) {
+	var (
+		signer      = types.LatestSignerForChainID(nil)
+		bankKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		bankAddress = crypto.PubkeyToAddress(bankKey.PublicKey)
+		config      = *params.AllEthashProtocolChanges
+	)
+	config.LondonBlock = big.NewInt(0)
+	gspec := &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{bankAddress: {Balance: big.NewInt(1e18)}}}
+	m := stages.MockWithGenesis(t, gspec, bankKey, false)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, block *core.BlockGen) {
+		for j := 0; j < 2; j++ {
+			txn, err := types.SignTx(types.NewTransaction(block.TxNonce(bankAddress), common.Address{1}, uint256.NewInt(1), params.TxGas, uint256.NewInt(2*params.GWei), nil), *signer, bankKey)
+			require.NoError(t, err)
+			block.AddTx(txn)
+		}
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+	ctx := context.Background()
+
+	reader := &retiredReceiptsReader{FullBlockReader: snapshotsync.NewBlockReader(), receipts: map[uint64]types.Receipts{}}
+	require.NoError(t, m.DB.View(ctx, func(tx kv.Tx) error {
+		for _, block := range chain.Blocks {
+			reader.receipts[block.NumberU64()] = rawdb.ReadRawReceipts(tx, block.NumberU64())
+		}
+		return nil
+	}))
+	base := NewBaseApi(nil, kvcache.New(kvcache.DefaultCoherentConfig), reader, false)
+	api := NewErigonAPI(base, m.DB, nil)
+	before, err := api.WatchTheBurn(ctx, 2)
+	require.NoError(t, err)
+	require.NotZero(t, before.Tips.ToInt().Sign())
+
+	require.NoError(t, m.DB.Update(ctx, func(tx kv.RwTx) error {
+		return rawdb.TruncateReceipts(tx, 0)
+	}))
+	after, err := api.WatchTheBurn(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+
+	tx, err := m.DB.BeginRo(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.Nil(t, rawdb.ReadRawReceipts(tx, 2))
+	receipts, err := NewGasPriceOracleBackend(tx, m.ChainConfig, base).GetReceipts(ctx, chain.Blocks[1].Hash())
+	require.NoError(t, err)
+	require.Len(t, receipts, 2)
+	require.Equal(t, chain.Blocks[1].Transactions()[1].Hash(), receipts[1].TxHash)
+	require.Equal(t, params.TxGas, receipts[1].GasUsed)
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/signer/core/apitypes"
+)
+
+// Sign implements eth_sign. Calculates an Ethereum specific signature with: sign(keccak256('\\x19Ethereum Signed Message:\\n' + len(message) + message))).
+// The account must be unlocked in the local keystore.
+func (api *APIImpl) Sign(ctx context.Context, address common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if api.keystore == nil {
+		return nil, errNoKeystore
+	}
+	signature, err := api.keystore.SignHash(address, signHash(data))
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	return signature, nil
+}
+
+// SignTypedData_v4 implements eth_signTypedData_v4. Calculates the signature of EIP-712 typed structured data.
+// The account must be unlocked in the local keystore.
+func (api *APIImpl) SignTypedData_v4(ctx context.Context, address common.Address, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	if api.keystore == nil {
+		return nil, errNoKeystore
+	}
+	if err := typedData.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid typed data: %w", err)
+	}
+	sighash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := api.keystore.SignHash(address, sighash)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	return signature, nil
+}
+
+// signHash is a helper function that calculates a hash for the given message that can be
+// safely used to calculate a signature from.
+//
+// The hash is calculated as
+//
+//	keccak256("\x19Ethereum Signed Message:\n"${message length}${message}).
+//
+// This gives context to the signed message and prevents signing of transactions.
+func signHash(data []byte) []byte {
+	msg := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(data), data)
+	return crypto.Keccak256([]byte(msg))
+}
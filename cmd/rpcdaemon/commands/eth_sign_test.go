@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon/accounts/keystore"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/signer/core/apitypes"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSigningAPI(t *testing.T) (*APIImpl, *PersonalAPIImpl) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), false), db, nil, nil, nil, 5000000)
+	api.keystore = keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	personal := NewPersonalAPI(api)
+	personal.AllowInsecureUnlock = true
+	return api, personal
+}
+
+func TestUnlockAccountForbidden(t *testing.T) {
+	ctx := context.Background()
+	api, personal := newTestSigningAPI(t)
+	personal.AllowInsecureUnlock = false
+
+	addr, err := personal.NewAccount(ctx, "secret")
+	require.NoError(t, err)
+	ok, err := personal.UnlockAccount(ctx, addr, "secret", nil)
+	require.ErrorIs(t, err, errInsecureUnlock)
+	require.False(t, ok)
+	if _, err = api.Sign(ctx, addr, hexutil.Bytes("hello")); !errors.Is(err, keystore.ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	// password taking methods are still served
+	_, err = personal.Sign(ctx, hexutil.Bytes("hello"), addr, "secret")
+	require.NoError(t, err)
+}
+
+func TestEthSign(t *testing.T) {
+	ctx := context.Background()
+	api, personal := newTestSigningAPI(t)
+
+	accounts, err := api.Accounts(ctx)
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+
+	addr, err := personal.NewAccount(ctx, "secret")
+	require.NoError(t, err)
+	accounts, err = api.Accounts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []common.Address{addr}, accounts)
+
+	msg := hexutil.Bytes("hello")
+	if _, err = api.Sign(ctx, addr, msg); !errors.Is(err, keystore.ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	_, err = personal.UnlockAccount(ctx, addr, "wrong", nil)
+	require.ErrorIs(t, err, keystore.ErrDecrypt)
+	ok, err := personal.UnlockAccount(ctx, addr, "secret", nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	sig, err := api.Sign(ctx, addr, msg)
+	require.NoError(t, err)
+	recovered, err := personal.EcRecover(ctx, msg, sig)
+	require.NoError(t, err)
+	require.Equal(t, addr, recovered)
+
+	// personal_sign does not need the account to be unlocked and produces the same signature
+	_, err = personal.LockAccount(ctx, addr)
+	require.NoError(t, err)
+	sig2, err := personal.Sign(ctx, msg, addr, "secret")
+	require.NoError(t, err)
+	require.Equal(t, sig, sig2)
+}
+
+func TestEthSignTypedData(t *testing.T) {
+	ctx := context.Background()
+	api, personal := newTestSigningAPI(t)
+	addr, err := personal.NewAccount(ctx, "secret")
+	require.NoError(t, err)
+	_, err = personal.UnlockAccount(ctx, addr, "secret", nil)
+	require.NoError(t, err)
+
+	var typedData apitypes.TypedData
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"types": {
+			"EIP712Domain": [{"name": "name", "type": "string"}, {"name": "chainId", "type": "uint256"}],
+			"Greeting": [{"name": "text", "type": "string"}, {"name": "count", "type": "uint8"}]
+		},
+		"primaryType": "Greeting",
+		"domain": {"name": "Test", "chainId": "0x539"},
+		"message": {"text": "hi", "count": 3}
+	}`), &typedData))
+
+	sig, err := api.SignTypedData_v4(ctx, addr, typedData)
+	require.NoError(t, err)
+	sighash, _, err := apitypes.TypedDataAndHash(typedData)
+	require.NoError(t, err)
+	sig[crypto.RecoveryIDOffset] -= 27
+	pub, err := crypto.SigToPub(sighash, sig)
+	require.NoError(t, err)
+	require.Equal(t, addr, crypto.PubkeyToAddress(*pub))
+
+	typedData.PrimaryType = "Unknown"
+	_, err = api.SignTypedData_v4(ctx, addr, typedData)
+	require.Error(t, err)
+}
+
+func TestEthSignTransaction(t *testing.T) {
+	ctx := context.Background()
+	api, personal := newTestSigningAPI(t)
+	addr, err := personal.NewAccount(ctx, "secret")
+	require.NoError(t, err)
+
+	to := common.Address{1}
+	nonce, gas := hexutil.Uint64(5), hexutil.Uint64(params.TxGas)
+	args := SendTxArgs{
+		From:     addr,
+		To:       &to,
+		Nonce:    &nonce,
+		Gas:      &gas,
+		GasPrice: (*hexutil.Big)(big.NewInt(params.GWei)),
+		Value:    (*hexutil.Big)(big.NewInt(1234)),
+	}
+	_, err = api.SignTransaction(ctx, args)
+	require.ErrorIs(t, err, keystore.ErrLocked)
+
+	_, err = personal.UnlockAccount(ctx, addr, "secret", nil)
+	require.NoError(t, err)
+	res, err := api.SignTransaction(ctx, args)
+	require.NoError(t, err)
+	require.Equal(t, addr, res.Tx.From)
+	require.Equal(t, nonce, res.Tx.Nonce)
+	require.Equal(t, big.NewInt(1234), res.Tx.Value.ToInt())
+
+	txn, err := types.DecodeTransaction(rlp.NewStream(bytes.NewReader(res.Raw), uint64(len(res.Raw))))
+	require.NoError(t, err)
+	require.True(t, txn.Protected())
+	require.Equal(t, res.Tx.Hash, txn.Hash())
+
+	wrongChain := args
+	wrongChain.ChainID = (*hexutil.Big)(big.NewInt(12345))
+	_, err = api.SignTransaction(ctx, wrongChain)
+	require.Error(t, err)
+
+	conflicting := args
+	conflicting.GasPrice = nil
+	conflicting.MaxFeePerGas = (*hexutil.Big)(big.NewInt(params.GWei))
+	conflicting.Data, conflicting.Input = &hexutil.Bytes{1}, &hexutil.Bytes{2}
+	_, err = api.SignTransaction(ctx, conflicting)
+	require.Error(t, err)
+}
@@ -0,0 +1,591 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/consensus/misc"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+	"github.com/ledgerwatch/log/v3"
+)
+
+const (
+	maxSimulatedBlocks = 256
+	simulatedBlockTime = 12 // seconds between the simulated blocks, unless the timestamp is overridden
+	simulateTimeout    = 5 * time.Minute
+)
+
+var (
+	// transferLogAddress is the address of the synthetic logs of ETH transfers, they look like ERC-20 Transfer events
+	transferLogAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+	transferTopic      = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// SimulationOptions are the parameters of eth_simulateV1
+type SimulationOptions struct {
+	BlockStateCalls        []SimulatedBlockSpec `json:"blockStateCalls"`
+	TraceTransfers         bool                 `json:"traceTransfers"`         // add a synthetic log for every ETH transfer
+	Validation             bool                 `json:"validation"`             // check nonces, balances and fees like for real transactions
+	ReturnFullTransactions bool                 `json:"returnFullTransactions"` // return transaction objects instead of hashes
+	ReturnStateRoot        bool                 `json:"returnStateRoot"`        // calculate the state root of every simulated block
+}
+
+// SimulatedBlockSpec is one block of eth_simulateV1, its overrides are applied before the calls
+type SimulatedBlockSpec struct {
+	BlockOverrides *BlockOverrides        `json:"blockOverrides"`
+	StateOverrides *ethapi.StateOverrides `json:"stateOverrides"`
+	Calls          []ethapi.CallArgs      `json:"calls"`
+}
+
+type SimulatedCallResult struct {
+	ReturnData hexutil.Bytes       `json:"returnData"`
+	Logs       []*types.Log        `json:"logs"`
+	GasUsed    hexutil.Uint64      `json:"gasUsed"`
+	Status     hexutil.Uint64      `json:"status"`
+	Error      *SimulatedCallError `json:"error,omitempty"`
+}
+
+type SimulatedCallError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+// SimulateV1 implements eth_simulateV1. Executes the calls in a chain of blocks built on top of the given one and returns the
+// blocks with the results of their calls. Every block can override its header fields and the state it starts with.
+func (api *APIImpl) SimulateV1(ctx context.Context, opts SimulationOptions, blockNrOrHash *rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, errors.New("empty blockStateCalls")
+	}
+	if len(opts.BlockStateCalls) > maxSimulatedBlocks {
+		return nil, fmt.Errorf("too many blocks, at most %d can be simulated", maxSimulatedBlocks)
+	}
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	blockNum, _, _, err := rpchelper.GetCanonicalBlockNumber(bNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := api._blockReader.HeaderByNumber(ctx, tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("block %d not found", blockNum)
+	}
+	var trieBlock uint64
+	if opts.ReturnStateRoot {
+		if trieBlock, err = api.trieBlockFor(tx, blockNum); err != nil {
+			return nil, err
+		}
+	}
+	stateReader, err := rpchelper.CreateStateReader(ctx, tx, bNrOrHash, api.filters, api.stateCache)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(start time.Time) { log.Trace("Executing EVM simulateV1 finished", "runtime", time.Since(start)) }(time.Now())
+	ctx, cancel := context.WithTimeout(ctx, simulateTimeout)
+	defer cancel()
+
+	s := &simulator{
+		api:         api,
+		tx:          tx,
+		chainConfig: chainConfig,
+		opts:        opts,
+		ibs:         state.New(stateReader),
+		writer:      newSimulatedStateWriter(),
+		hashes:      map[uint64]common.Hash{},
+		baseBlock:   blockNum,
+		trieBlock:   trieBlock,
+	}
+	s.contractHasTEVM = func(contractHash common.Hash) (bool, error) { return false, nil }
+	if api.TevmEnabled {
+		s.contractHasTEVM = ethdb.GetHasTEVM(tx)
+	}
+
+	results := make([]map[string]interface{}, 0, len(opts.BlockStateCalls))
+	for i := range opts.BlockStateCalls {
+		block, fields, err := s.simulateBlock(ctx, parent, &opts.BlockStateCalls[i])
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		results = append(results, fields)
+		parent = block.Header()
+	}
+	return results, nil
+}
+
+// simulator keeps the state of eth_simulateV1 between the simulated blocks
+type simulator struct {
+	api             *APIImpl
+	tx              kv.Tx
+	chainConfig     *params.ChainConfig
+	contractHasTEVM func(contractHash common.Hash) (bool, error)
+	opts            SimulationOptions
+
+	ibs       *state.IntraBlockState
+	writer    *simulatedStateWriter  // collects the state changes of all simulated blocks for the state root
+	hashes    map[uint64]common.Hash // of the simulated blocks, and the overrides of BlockOverrides.BlockHash
+	baseBlock uint64                 // block the simulation starts from
+	trieBlock uint64                 // progress of the IntermediateHashes stage, if ReturnStateRoot
+}
+
+func (s *simulator) getHash(n uint64) common.Hash {
+	if hash, ok := s.hashes[n]; ok {
+		return hash
+	}
+	if n > s.baseBlock {
+		return common.Hash{}
+	}
+	hash, err := rawdb.ReadCanonicalHash(s.tx, n)
+	if err != nil {
+		log.Debug("Can't get block hash by number", "number", n, "only-canonical", true)
+	}
+	return hash
+}
+
+// simulatedHeader is the header of the block following parent, without the fields which depend on the calls
+func (s *simulator) simulatedHeader(parent *types.Header, overrides *BlockOverrides) (*types.Header, error) {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   parent.Coinbase,
+		Difficulty: new(big.Int).Set(parent.Difficulty),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + simulatedBlockTime,
+		MixDigest:  parent.MixDigest,
+	}
+	if overrides != nil {
+		if overrides.BlockNumber != nil {
+			if uint64(*overrides.BlockNumber) <= parent.Number.Uint64() {
+				return nil, fmt.Errorf("block number %d is not after the previous block %d", uint64(*overrides.BlockNumber), parent.Number.Uint64())
+			}
+			header.Number.SetUint64(uint64(*overrides.BlockNumber))
+		}
+		if overrides.Coinbase != nil {
+			header.Coinbase = *overrides.Coinbase
+		}
+		if overrides.Timestamp != nil {
+			if uint64(*overrides.Timestamp) <= parent.Time {
+				return nil, fmt.Errorf("timestamp %d is not after the previous block timestamp %d", uint64(*overrides.Timestamp), parent.Time)
+			}
+			header.Time = uint64(*overrides.Timestamp)
+		}
+		if overrides.GasLimit != nil {
+			header.GasLimit = uint64(*overrides.GasLimit)
+		}
+		if overrides.Difficulty != nil {
+			header.Difficulty = big.NewInt(int64(*overrides.Difficulty))
+		}
+		if overrides.BlockHash != nil {
+			for blockNum, hash := range *overrides.BlockHash {
+				s.hashes[blockNum] = hash
+			}
+		}
+	}
+	if s.chainConfig.IsLondon(header.Number.Uint64()) {
+		header.Eip1559 = true
+		header.BaseFee = misc.CalcBaseFee(s.chainConfig, parent)
+		if overrides != nil && overrides.BaseFee != nil {
+			header.BaseFee = overrides.BaseFee.ToBig()
+		}
+	}
+	return header, nil
+}
+
+func (s *simulator) simulateBlock(ctx context.Context, parent *types.Header, spec *SimulatedBlockSpec) (*types.Block, map[string]interface{}, error) {
+	header, err := s.simulatedHeader(parent, spec.BlockOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+	blockNum := header.Number.Uint64()
+	rules := s.chainConfig.Rules(blockNum, header.Time)
+	blockCtx := core.NewEVMBlockContext(header, s.getHash, nil /* engine */, &header.Coinbase, s.contractHasTEVM)
+
+	if spec.StateOverrides != nil {
+		if err = s.overrideState(*spec.StateOverrides, rules); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var tracer *transferTracer
+	vmConfig := vm.Config{NoBaseFee: !s.opts.Validation}
+	if s.opts.TraceTransfers {
+		tracer = &transferTracer{ibs: s.ibs}
+		vmConfig.Debug, vmConfig.Tracer = true, tracer
+	}
+	evm := vm.NewEVM(blockCtx, vm.TxContext{}, s.ibs, s.chainConfig, vmConfig)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			evm.Cancel()
+		case <-done:
+		}
+	}()
+
+	gp := new(core.GasPool).AddGas(header.GasLimit)
+	txs := make(types.Transactions, 0, len(spec.Calls))
+	receipts := make(types.Receipts, 0, len(spec.Calls))
+	calls := make([]*SimulatedCallResult, 0, len(spec.Calls))
+	for i := range spec.Calls {
+		args := spec.Calls[i]
+		if args.Gas == nil {
+			remaining := hexutil.Uint64(gp.Gas())
+			args.Gas = &remaining
+		}
+		msg, err := args.ToMessage(s.api.GasCap, blockCtx.BaseFee)
+		if err != nil {
+			return nil, nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		nonce := s.ibs.GetNonce(msg.From())
+		if args.Nonce != nil {
+			nonce = uint64(*args.Nonce)
+		}
+		msg = types.NewMessage(msg.From(), msg.To(), nonce, msg.Value(), msg.Gas(), msg.GasPrice(), msg.FeeCap(), msg.Tip(), msg.Data(), msg.AccessList(), s.opts.Validation)
+		txn := simulatedTransaction(&args, msg, s.chainConfig.ChainID)
+		txHash := txn.Hash()
+
+		s.ibs.Prepare(txHash, common.Hash{}, i)
+		if tracer != nil {
+			tracer.reset(txHash)
+		}
+		evm.Reset(core.NewEVMTxContext(msg), s.ibs)
+		// Without validation the sender doesn't need the funds to pay for the gas
+		result, err := core.ApplyMessage(evm, msg, gp, true /* refunds */, !s.opts.Validation /* gasBailout */)
+		if err != nil {
+			return nil, nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		if evm.Cancelled() {
+			return nil, nil, fmt.Errorf("execution aborted (timeout = %v)", simulateTimeout)
+		}
+		if err = s.ibs.FinalizeTx(rules, s.writer); err != nil {
+			return nil, nil, err
+		}
+
+		receipt := &types.Receipt{
+			Type:             txn.Type(),
+			TxHash:           txHash,
+			GasUsed:          result.UsedGas,
+			Logs:             s.ibs.GetLogs(txHash),
+			BlockNumber:      header.Number,
+			TransactionIndex: uint(i),
+			Status:           types.ReceiptStatusSuccessful,
+		}
+		if len(receipts) > 0 {
+			receipt.CumulativeGasUsed = receipts[len(receipts)-1].CumulativeGasUsed
+		}
+		receipt.CumulativeGasUsed += result.UsedGas
+		if msg.To() == nil {
+			receipt.ContractAddress = crypto.CreateAddress(msg.From(), nonce)
+		}
+		call := &SimulatedCallResult{ReturnData: result.Return(), GasUsed: hexutil.Uint64(result.UsedGas), Status: hexutil.Uint64(types.ReceiptStatusSuccessful)}
+		if result.Err != nil {
+			receipt.Status = types.ReceiptStatusFailed
+			call.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			call.Error = &SimulatedCallError{Code: -32015, Message: result.Err.Error()}
+			if len(result.Revert()) > 0 {
+				revertErr := ethapi.NewRevertError(result)
+				call.Error = &SimulatedCallError{Code: revertErr.ErrorCode(), Message: revertErr.Error(), Data: revertErr.ErrorData().(string)}
+			}
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		call.Logs = receipt.Logs
+		if tracer != nil {
+			call.Logs = tracer.withTransfers(receipt.Logs)
+		}
+		txs = append(txs, txn)
+		receipts = append(receipts, receipt)
+		calls = append(calls, call)
+	}
+	header.GasUsed = header.GasLimit - gp.Gas()
+
+	if s.opts.ReturnStateRoot {
+		if header.Root, err = s.stateRoot(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	var block *types.Block
+	if s.chainConfig.IsShanghai(header.Time) {
+		block = types.NewBlockWithWithdrawals(header, txs, nil, receipts, []*types.Withdrawal{})
+	} else {
+		block = types.NewBlock(header, txs, nil, receipts)
+	}
+	blockHash := block.Hash()
+	s.hashes[blockNum] = blockHash
+
+	// Logs are numbered within the block, the ones of the calls including the synthetic transfer logs. They are copied,
+	// the logs of the state are shared between the receipts and the calls.
+	var callLogIndex, receiptLogIndex uint
+	withPosition := func(logs []*types.Log, txIndex int, logIndex *uint) []*types.Log {
+		positioned := make([]*types.Log, len(logs))
+		for i, l := range logs {
+			cpy := *l
+			cpy.BlockHash, cpy.BlockNumber, cpy.TxIndex, cpy.Index = blockHash, blockNum, uint(txIndex), *logIndex
+			*logIndex++
+			positioned[i] = &cpy
+		}
+		return positioned
+	}
+	for i, call := range calls {
+		call.Logs = withPosition(call.Logs, i, &callLogIndex)
+		receipts[i].Logs = withPosition(receipts[i].Logs, i, &receiptLogIndex)
+		receipts[i].BlockHash = blockHash
+	}
+
+	fields, err := ethapi.RPCMarshalBlock(block, true, s.opts.ReturnFullTransactions)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !s.opts.ReturnStateRoot {
+		delete(fields, "stateRoot")
+	}
+	marshalledReceipts := make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		marshalledReceipts[i] = marshalReceipt(receipt, txs[i], s.chainConfig, block, receipt.TxHash, true)
+	}
+	fields["calls"] = calls
+	fields["receipts"] = marshalledReceipts
+	return block, fields, nil
+}
+
+// overrideState applies the state overrides of a block and hands them to the writer, even if the block has no calls.
+// Unlike eth_call, a full `state` override can't use the fake storage of IntraBlockState, which never reaches the
+// writer: the account is recreated with its nonce and code instead, so its storage in db is dropped and the new
+// slots are written like a `stateDiff`.
+func (s *simulator) overrideState(overrides ethapi.StateOverrides, rules *params.Rules) error {
+	diffs := make(ethapi.StateOverrides, len(overrides))
+	for addr, account := range overrides {
+		if account.State != nil {
+			if account.StateDiff != nil {
+				return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+			}
+			nonce, code := s.ibs.GetNonce(addr), s.ibs.GetCode(addr)
+			s.ibs.CreateAccount(addr, true)
+			s.ibs.SetNonce(addr, nonce)
+			if len(code) > 0 {
+				s.ibs.SetCode(addr, code)
+			}
+			account.State, account.StateDiff = nil, account.State
+		}
+		diffs[addr] = account
+	}
+	if err := diffs.Override(s.ibs); err != nil {
+		return err
+	}
+	return s.ibs.FinalizeTx(rules, s.writer)
+}
+
+// stateRoot calculates the root of the state at the end of the simulated blocks so far, on top of the hashed state in db
+func (s *simulator) stateRoot(ctx context.Context) (common.Hash, error) {
+	rl := trie.NewRetainList(0)
+	overlay, err := ethapi.NewStateOverlay(s.tx, s.baseBlock, s.trieBlock, rl)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err = overlay.Override(s.writer.accounts, s.writer.storageOfIncarnations(), rl); err != nil {
+		return common.Hash{}, err
+	}
+	loader := trie.NewFlatDBTrieLoader("eth_simulateV1")
+	if err = loader.Reset(rl, nil, nil, false); err != nil {
+		return common.Hash{}, err
+	}
+	loader.SetStreamReceiver(ethapi.NewStateOverlayReceiver(s.tx, overlay, loader.DefaultReceiver()))
+	return loader.CalcTrieRoot(s.tx, []byte{}, ctx.Done())
+}
+
+// simulatedTransaction is the unsigned transaction the call would be sent as, the sender is attached to it
+func simulatedTransaction(args *ethapi.CallArgs, msg types.Message, chainID *big.Int) types.Transaction {
+	chainId, _ := uint256.FromBig(chainID)
+	commonTx := types.CommonTx{
+		Nonce: msg.Nonce(),
+		Gas:   msg.Gas(),
+		To:    msg.To(),
+		Value: msg.Value(),
+		Data:  msg.Data(),
+	}
+	var txn types.Transaction
+	switch {
+	case args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil:
+		commonTx.ChainID = chainId
+		txn = &types.DynamicFeeTransaction{CommonTx: commonTx, Tip: msg.Tip(), FeeCap: msg.FeeCap(), AccessList: msg.AccessList()}
+	case args.AccessList != nil:
+		txn = &types.AccessListTx{LegacyTx: types.LegacyTx{CommonTx: commonTx, GasPrice: msg.GasPrice()}, ChainID: chainId, AccessList: msg.AccessList()}
+	default:
+		txn = &types.LegacyTx{CommonTx: commonTx, GasPrice: msg.GasPrice()}
+	}
+	txn.SetSender(msg.From())
+	return txn
+}
+
+// simulatedStateWriter keeps the latest values of the accounts and storage slots written by the simulated blocks
+type simulatedStateWriter struct {
+	accounts map[common.Address]*accounts.Account // nil - deleted
+	storage  map[simulatedSlot]uint256.Int
+}
+
+type simulatedSlot struct {
+	address     common.Address
+	incarnation uint64
+	key         common.Hash
+}
+
+func newSimulatedStateWriter() *simulatedStateWriter {
+	return &simulatedStateWriter{accounts: map[common.Address]*accounts.Account{}, storage: map[simulatedSlot]uint256.Int{}}
+}
+
+func (w *simulatedStateWriter) UpdateAccountData(address common.Address, original, account *accounts.Account) error {
+	w.accounts[address] = account.SelfCopy()
+	return nil
+}
+
+func (w *simulatedStateWriter) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	return nil
+}
+
+func (w *simulatedStateWriter) DeleteAccount(address common.Address, original *accounts.Account) error {
+	w.accounts[address] = nil
+	return nil
+}
+
+func (w *simulatedStateWriter) WriteAccountStorage(address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	w.storage[simulatedSlot{address: address, incarnation: incarnation, key: *key}] = *value
+	return nil
+}
+
+func (w *simulatedStateWriter) CreateContract(address common.Address) error {
+	return nil
+}
+
+// storageOfIncarnations - the written slots of the current incarnations of the accounts
+func (w *simulatedStateWriter) storageOfIncarnations() map[common.Address]map[common.Hash]uint256.Int {
+	storage := map[common.Address]map[common.Hash]uint256.Int{}
+	for slot, value := range w.storage {
+		acc := w.accounts[slot.address]
+		if acc == nil || acc.Incarnation != slot.incarnation {
+			continue
+		}
+		if storage[slot.address] == nil {
+			storage[slot.address] = map[common.Hash]uint256.Int{}
+		}
+		storage[slot.address][slot.key] = value
+	}
+	return storage
+}
+
+// transferTracer records the ETH transfers of a call as logs, together with the position among the logs of the call
+// they happen at. The transfers of reverted frames are dropped, like their logs.
+type transferTracer struct {
+	ibs       *state.IntraBlockState
+	txHash    common.Hash
+	transfers []transferLog
+	frames    []int // number of transfers when the frame started
+}
+
+type transferLog struct {
+	log      *types.Log
+	position int // number of the real logs emitted before the transfer
+}
+
+func (t *transferTracer) reset(txHash common.Hash) {
+	t.txHash, t.transfers, t.frames = txHash, nil, nil
+}
+
+func (t *transferTracer) addTransfer(from, to common.Address, value *big.Int) {
+	if value == nil || value.Sign() <= 0 {
+		return
+	}
+	t.transfers = append(t.transfers, transferLog{
+		log: &types.Log{
+			Address: transferLogAddress,
+			Topics:  []common.Hash{transferTopic, from.Hash(), to.Hash()},
+			Data:    common.LeftPadBytes(value.Bytes(), 32),
+			TxHash:  t.txHash,
+		},
+		position: len(t.ibs.GetLogs(t.txHash)),
+	})
+}
+
+// withTransfers merges the transfers into the logs of the call
+func (t *transferTracer) withTransfers(logs []*types.Log) []*types.Log {
+	if len(t.transfers) == 0 {
+		return logs
+	}
+	merged := make([]*types.Log, 0, len(logs)+len(t.transfers))
+	transfers := t.transfers
+	for i, l := range logs {
+		for len(transfers) > 0 && transfers[0].position <= i {
+			merged = append(merged, transfers[0].log)
+			transfers = transfers[1:]
+		}
+		merged = append(merged, l)
+	}
+	for _, transfer := range transfers {
+		merged = append(merged, transfer.log)
+	}
+	return merged
+}
+
+func (t *transferTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	t.frames = append(t.frames, len(t.transfers))
+	if callType == vm.CALLT || callType == vm.CREATET || callType == vm.CREATE2T {
+		t.addTransfer(from, to, value)
+	}
+}
+func (t *transferTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+func (t *transferTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+func (t *transferTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+	if len(t.frames) == 0 {
+		return
+	}
+	start := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	if err != nil {
+		t.transfers = t.transfers[:start]
+	}
+}
+func (t *transferTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+	t.addTransfer(from, to, value)
+}
+func (t *transferTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+func (t *transferTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateV1(t *testing.T) {
+	db, bankAddress, contractAddress := chainWithDeployedContract(t)
+	api := NewEthAPI(NewBaseApi(nil, kvcache.New(kvcache.DefaultCoherentConfig), snapshotsync.NewBlockReader(), false), db, nil, nil, nil, 5000000)
+	ctx := context.Background()
+
+	store := hexutil.Bytes(hexutil.MustDecode("0x6057361d000000000000000000000000000000000000000000000000000000000000002a"))
+	retrieve := hexutil.Bytes(hexutil.MustDecode("0x2e64cec1"))
+	receiver := common.HexToAddress("0x0d3ab14bbad3d99f4203bd7a11acb94882050e7e")
+	value := (*hexutil.Big)(big.NewInt(1000))
+	timestamp := hexutil.Uint64(1e10)
+
+	blocks, err := api.SimulateV1(ctx, SimulationOptions{
+		BlockStateCalls: []SimulatedBlockSpec{
+			{Calls: []ethapi.CallArgs{
+				{From: &bankAddress, To: &contractAddress, Data: &store},
+				{From: &bankAddress, To: &receiver, Value: value},
+			}},
+			{
+				BlockOverrides: &BlockOverrides{Timestamp: &timestamp},
+				Calls:          []ethapi.CallArgs{{From: &bankAddress, To: &contractAddress, Data: &retrieve}},
+			},
+		},
+		TraceTransfers: true,
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+
+	first, second := blocks[0], blocks[1]
+	require.Equal(t, (*hexutil.Big)(big.NewInt(3)), first["number"])
+	require.Equal(t, (*hexutil.Big)(big.NewInt(4)), second["number"])
+	require.Equal(t, first["hash"], second["parentHash"])
+	require.Equal(t, hexutil.Uint64(timestamp), second["timestamp"])
+	require.NotContains(t, first, "stateRoot")
+
+	calls := first["calls"].([]*SimulatedCallResult)
+	require.Len(t, calls, 2)
+	require.Nil(t, calls[0].Error)
+	// The transfer is reported as a synthetic log, it is not in the receipt
+	require.Len(t, calls[1].Logs, 1)
+	transfer := calls[1].Logs[0]
+	require.Equal(t, transferLogAddress, transfer.Address)
+	require.Equal(t, []common.Hash{transferTopic, bankAddress.Hash(), receiver.Hash()}, transfer.Topics)
+	require.Equal(t, uint64(3), transfer.BlockNumber)
+	require.Empty(t, first["receipts"].([]map[string]interface{})[1]["logs"])
+
+	// The state written by the first block is seen by the second one
+	calls = second["calls"].([]*SimulatedCallResult)
+	require.Len(t, calls, 1)
+	require.Equal(t, hexutil.Bytes(common.LeftPadBytes([]byte{42}, 32)), calls[0].ReturnData)
+
+	// Blocks must move forward in time
+	_, err = api.SimulateV1(ctx, SimulationOptions{
+		BlockStateCalls: []SimulatedBlockSpec{
+			{BlockOverrides: &BlockOverrides{Timestamp: &timestamp}},
+			{BlockOverrides: &BlockOverrides{Timestamp: &timestamp}},
+		},
+	}, nil)
+	require.Error(t, err)
+}
+
+func TestSimulateV1StateRoot(t *testing.T) {
+	db, bankAddress, contractAddress := chainWithDeployedContract(t)
+	api := NewEthAPI(NewBaseApi(nil, kvcache.New(kvcache.DefaultCoherentConfig), snapshotsync.NewBlockReader(), false), db, nil, nil, nil, 5000000)
+	ctx := context.Background()
+
+	tx, err := db.BeginRo(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	head := rawdb.ReadCurrentHeader(tx)
+
+	receiver := common.HexToAddress("0x0d3ab14bbad3d99f4203bd7a11acb94882050e7e")
+	value := (*hexutil.Big)(big.NewInt(1000))
+	store := hexutil.Bytes(hexutil.MustDecode("0x6057361d000000000000000000000000000000000000000000000000000000000000002a"))
+	balance := (*hexutil.Big)(big.NewInt(1000))
+	slot, slotValue := common.HexToHash("0x05"), *uint256.NewInt(7)
+	blocks, err := api.SimulateV1(ctx, SimulationOptions{
+		BlockStateCalls: []SimulatedBlockSpec{
+			{},
+			{StateOverrides: &ethapi.StateOverrides{receiver: {Balance: &balance}}},
+			{Calls: []ethapi.CallArgs{
+				{From: &bankAddress, To: &receiver, Value: value},
+				{From: &bankAddress, To: &contractAddress, Data: &store},
+			}},
+			{StateOverrides: &ethapi.StateOverrides{contractAddress: {State: &map[common.Hash]uint256.Int{slot: slotValue}}}},
+		},
+		ReturnStateRoot: true,
+	}, nil)
+	require.NoError(t, err)
+	// Nothing changes in an empty block, no rewards are paid in the simulation
+	require.Equal(t, head.Root, blocks[0]["stateRoot"])
+
+	// Without validation the calls pay no gas, only the nonce of the sender changes
+	received := expectedStateRoot(t, db, map[common.Address]func(*accounts.Account){
+		receiver: func(acc *accounts.Account) { acc.Balance.SetUint64(1000) },
+	}, nil)
+	require.Equal(t, received, blocks[1]["stateRoot"])
+	transferred := expectedStateRoot(t, db, map[common.Address]func(*accounts.Account){
+		receiver: func(acc *accounts.Account) { acc.Balance.SetUint64(2000) },
+		bankAddress: func(acc *accounts.Account) {
+			acc.Nonce += 2
+			acc.Balance.Sub(&acc.Balance, uint256.NewInt(1000))
+		},
+	}, map[common.Address]map[common.Hash]uint256.Int{contractAddress: {{}: *uint256.NewInt(42)}})
+	require.Equal(t, transferred, blocks[2]["stateRoot"])
+	// The state override replaces the whole storage of the contract
+	replaced := expectedStateRoot(t, db, map[common.Address]func(*accounts.Account){
+		receiver: func(acc *accounts.Account) { acc.Balance.SetUint64(2000) },
+		bankAddress: func(acc *accounts.Account) {
+			acc.Nonce += 2
+			acc.Balance.Sub(&acc.Balance, uint256.NewInt(1000))
+		},
+	}, map[common.Address]map[common.Hash]uint256.Int{contractAddress: {slot: slotValue}})
+	require.Equal(t, replaced, blocks[3]["stateRoot"])
+
+	// Simulating from an older block needs the trie to be rolled back, outside of the window it is refused
+	api.MaxGetProofRewindBlockCount = 1
+	from := rpc.BlockNumberOrHashWithNumber(0)
+	_, err = api.SimulateV1(ctx, SimulationOptions{BlockStateCalls: []SimulatedBlockSpec{{}}, ReturnStateRoot: true}, &from)
+	require.Error(t, err)
+	_, err = api.SimulateV1(ctx, SimulationOptions{BlockStateCalls: []SimulatedBlockSpec{{}}}, &from)
+	require.NoError(t, err)
+}
+
+// expectedStateRoot calculates the state root of the head of db from scratch, after changing the accounts and
+// replacing the storage of the given accounts
+func expectedStateRoot(t *testing.T, db kv.RwDB, changes map[common.Address]func(*accounts.Account), storage map[common.Address]map[common.Hash]uint256.Int) common.Hash {
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	for addr, change := range changes {
+		addrHash, err := common.HashData(addr[:])
+		require.NoError(t, err)
+		var acc accounts.Account
+		enc, err := tx.GetOne(kv.HashedAccounts, addrHash[:])
+		require.NoError(t, err)
+		if len(enc) > 0 {
+			require.NoError(t, acc.DecodeForStorage(enc))
+		} else {
+			acc = accounts.NewAccount()
+		}
+		change(&acc)
+		enc = make([]byte, acc.EncodingLengthForStorage())
+		acc.EncodeForStorage(enc)
+		require.NoError(t, tx.Put(kv.HashedAccounts, addrHash[:], enc))
+	}
+	for addr, slots := range storage {
+		addrHash, err := common.HashData(addr[:])
+		require.NoError(t, err)
+		var acc accounts.Account
+		enc, err := tx.GetOne(kv.HashedAccounts, addrHash[:])
+		require.NoError(t, err)
+		require.NoError(t, acc.DecodeForStorage(enc))
+		var keys [][]byte
+		require.NoError(t, tx.ForPrefix(kv.HashedStorage, dbutils.GenerateStoragePrefix(addrHash[:], acc.Incarnation), func(k, v []byte) error {
+			keys = append(keys, common.CopyBytes(k))
+			return nil
+		}))
+		for _, k := range keys {
+			require.NoError(t, tx.Delete(kv.HashedStorage, k))
+		}
+		for key, value := range slots {
+			locHash, err := common.HashData(key[:])
+			require.NoError(t, err)
+			require.NoError(t, tx.Put(kv.HashedStorage, dbutils.GenerateCompositeStorageKey(addrHash, acc.Incarnation, locHash), value.Bytes()))
+		}
+	}
+	// Without the intermediate hashes the root is calculated from the hashed state alone
+	require.NoError(t, tx.ClearBucket(kv.TrieOfAccounts))
+	require.NoError(t, tx.ClearBucket(kv.TrieOfStorage))
+	root, err := trie.CalcRoot("test", tx)
+	require.NoError(t, err)
+	return root
+}
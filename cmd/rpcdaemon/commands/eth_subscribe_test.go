@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/ledgerwatch/erigon-lib/direct"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
@@ -12,6 +13,7 @@ import (
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	stagedsyncstages "github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/ethdb/privateapi"
 	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
@@ -61,3 +63,60 @@ func TestEthSubscribe(t *testing.T) {
 		require.Equal(i, header.Number.Uint64())
 	}
 }
+
+func TestEthSubscribeSyncing(t *testing.T) {
+	m, require := stages.Mock(t), require.New(t)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 7, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	}, false /* intermediateHashes */)
+	require.NoError(err)
+
+	b, err := rlp.EncodeToBytes(&eth.BlockHeadersPacket66{
+		RequestId:          1,
+		BlockHeadersPacket: chain.Headers,
+	})
+	require.NoError(err)
+
+	m.ReceiveWg.Add(1)
+	for _, err = range m.Send(&sentry.InboundMessage{Id: sentry.MessageId_BLOCK_HEADERS_66, Data: b, PeerId: m.PeerId}) {
+		require.NoError(err)
+	}
+	m.ReceiveWg.Wait() // Wait for all messages to be processed before we proceeed
+
+	ctx := context.Background()
+	backendServer := privateapi.NewEthBackendServer(ctx, nil, m.DB, m.Notifications.Events, snapshotsync.NewBlockReader(), nil, nil, nil, false)
+	backendClient := direct.NewEthBackendClientDirect(backendServer)
+	backend := rpcservices.NewRemoteBackend(backendClient, m.DB, snapshotsync.NewBlockReader())
+	// The server sends a NEW_SNAPSHOT event right after it has subscribed to all events
+	subscribed := make(chan struct{}, 1)
+	ff := rpchelper.New(ctx, backend, nil, nil, func() {
+		select {
+		case subscribed <- struct{}{}:
+		default:
+		}
+	})
+
+	progressCh := make(chan *stagedsyncstages.SyncProgress, 8)
+	id := ff.SubscribeSyncing(progressCh)
+	defer ff.UnsubscribeSyncing(id)
+	<-subscribed
+
+	highestSeenHeader := chain.TopBlock.NumberU64()
+	if _, err := stages.StageLoopStep(m.Ctx, m.DB, m.Sync, highestSeenHeader, m.Notifications, true /* initialCycle */, m.UpdateHead, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Updates may be dropped for slow consumers, but never the latest one
+	var progress *stagedsyncstages.SyncProgress
+	for progress == nil || progress.Syncing {
+		select {
+		case progress = <-progressCh:
+		case <-time.After(10 * time.Second):
+			t.Fatal("timeout waiting for the end of the sync")
+		}
+	}
+	require.Equal(highestSeenHeader, uint64(progress.CurrentBlock))
+	require.Equal(highestSeenHeader, uint64(progress.HighestBlock))
+	require.Len(progress.Stages, len(stagedsyncstages.AllStages))
+	require.NotEmpty(progress.Timings)
+}
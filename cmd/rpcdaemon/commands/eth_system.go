@@ -37,39 +37,19 @@ func (api *APIImpl) Syncing(ctx context.Context) (interface{}, error) {
 		return nil, err
 	}
 	defer tx.Rollback()
-	highestBlock, err := stages.GetStageProgress(tx, stages.Headers)
+	progress, err := stages.ReadSyncProgress(tx)
 	if err != nil {
 		return false, err
 	}
-
-	currentBlock, err := stages.GetStageProgress(tx, stages.Finish)
-	if err != nil {
-		return false, err
-	}
-
-	if currentBlock > 0 && currentBlock >= highestBlock { // Return not syncing if the synchronisation already completed
+	if !progress.Syncing { // Return not syncing if the synchronisation already completed
 		return false, nil
 	}
 
 	// Otherwise gather the block sync stats
-	type S struct {
-		StageName   string         `json:"stage_name"`
-		BlockNumber hexutil.Uint64 `json:"block_number"`
-	}
-	stagesMap := make([]S, len(stages.AllStages))
-	for i, stage := range stages.AllStages {
-		progress, err := stages.GetStageProgress(tx, stage)
-		if err != nil {
-			return nil, err
-		}
-		stagesMap[i].StageName = string(stage)
-		stagesMap[i].BlockNumber = hexutil.Uint64(progress)
-	}
-
 	return map[string]interface{}{
-		"currentBlock": hexutil.Uint64(currentBlock),
-		"highestBlock": hexutil.Uint64(highestBlock),
-		"stages":       stagesMap,
+		"currentBlock": progress.CurrentBlock,
+		"highestBlock": progress.HighestBlock,
+		"stages":       progress.Stages,
 	}, nil
 }
 
@@ -215,7 +195,14 @@ func (b *GasPriceOracleBackend) ChainConfig() *params.ChainConfig {
 	return b.cc
 }
 func (b *GasPriceOracleBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
-	return rawdb.ReadReceiptsByHash(b.tx, hash)
+	block, err := b.baseApi.blockByHashWithSenders(b.tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+	return b.baseApi.getReceipts(ctx, b.tx, b.cc, block, block.Body().SendersFromTxs())
 }
 func (b *GasPriceOracleBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts) {
 	return nil, nil
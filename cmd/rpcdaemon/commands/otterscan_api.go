@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/transactions"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// otsApiLevel is reported by ots_getApiLevel, it has to be incremented every time the ots_ namespace changes
+const otsApiLevel = 8
+
+// OtterscanAPI the interface for the ots_ RPC commands, used by the Otterscan block explorer
+type OtterscanAPI interface {
+	GetApiLevel() uint8
+
+	// Search related (see ./otterscan_search.go)
+	SearchTransactionsBefore(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error)
+	SearchTransactionsAfter(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error)
+	GetTransactionBySenderAndNonce(ctx context.Context, addr common.Address, nonce uint64) (*common.Hash, error)
+	GetContractCreator(ctx context.Context, addr common.Address) (*ContractCreatorData, error)
+
+	// Tracing related (see ./otterscan_trace.go)
+	TraceTransaction(ctx context.Context, hash common.Hash) ([]*TraceEntry, error)
+	GetInternalOperations(ctx context.Context, hash common.Hash) ([]*InternalOperation, error)
+	GetTransactionError(ctx context.Context, hash common.Hash) (hexutil.Bytes, error)
+
+	// Blocks and accounts related (see ./otterscan_block_details.go)
+	HasCode(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (bool, error)
+	GetBlockDetails(ctx context.Context, number rpc.BlockNumber) (map[string]interface{}, error)
+	GetBlockDetailsByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error)
+	GetBlockTransactions(ctx context.Context, number rpc.BlockNumber, pageNumber uint8, pageSize uint8) (map[string]interface{}, error)
+}
+
+// OtterscanAPIImpl is implementation of the OtterscanAPI interface
+type OtterscanAPIImpl struct {
+	*BaseAPI
+	db          kv.RoDB
+	maxPageSize uint64
+}
+
+// NewOtterscanAPI returns OtterscanAPIImpl instance
+func NewOtterscanAPI(base *BaseAPI, db kv.RoDB, maxPageSize uint64) *OtterscanAPIImpl {
+	return &OtterscanAPIImpl{
+		BaseAPI:     base,
+		db:          db,
+		maxPageSize: maxPageSize,
+	}
+}
+
+// GetApiLevel implements ots_getApiLevel. Returns the version of the ots_ namespace, Otterscan refuses to work with older nodes.
+func (api *OtterscanAPIImpl) GetApiLevel() uint8 {
+	return otsApiLevel
+}
+
+// TransactionsWithReceipts is a page of the transactions of an address, always sorted from the newest to the oldest one
+type TransactionsWithReceipts struct {
+	Txs       []*RPCTransaction        `json:"txs"`
+	Receipts  []map[string]interface{} `json:"receipts"`
+	FirstPage bool                     `json:"firstPage"`
+	LastPage  bool                     `json:"lastPage"`
+}
+
+// ContractCreatorData is the transaction which deployed a contract and the address that deployed it
+type ContractCreatorData struct {
+	Tx      common.Hash    `json:"hash"`
+	Creator common.Address `json:"creator"`
+}
+
+func (api *OtterscanAPIImpl) getHeaderFunc(ctx context.Context, tx kv.Tx) func(hash common.Hash, number uint64) *types.Header {
+	return func(hash common.Hash, number uint64) *types.Header {
+		h, e := api._blockReader.Header(ctx, tx, hash, number)
+		if e != nil {
+			log.Error("getHeader error", "number", number, "hash", hash, "err", e)
+		}
+		return h
+	}
+}
+
+func (api *OtterscanAPIImpl) contractHasTEVM(tx kv.Tx) func(contractHash common.Hash) (bool, error) {
+	if api.TevmEnabled {
+		return ethdb.GetHasTEVM(tx)
+	}
+	return func(contractHash common.Hash) (bool, error) { return false, nil }
+}
+
+// runTracer re-executes the transaction on top of the state it was originally executed on and hands it to the tracer,
+// which may be nil. Returns nil if the transaction is unknown.
+func (api *OtterscanAPIImpl) runTracer(ctx context.Context, tx kv.Tx, hash common.Hash, tracer vm.Tracer) (*core.ExecutionResult, error) {
+	blockNum, ok, err := api.txnLookup(ctx, tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+	txnIndex := -1
+	for i, txn := range block.Transactions() {
+		if txn.Hash() == hash {
+			txnIndex = i
+			break
+		}
+	}
+	if txnIndex == -1 {
+		return nil, fmt.Errorf("transaction %#x not found", hash)
+	}
+
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	msg, blockCtx, txCtx, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, api.getHeaderFunc(ctx, tx), api.contractHasTEVM(tx), ethash.NewFaker(), tx, block.Hash(), uint64(txnIndex))
+	if err != nil {
+		return nil, err
+	}
+	vmConfig := vm.Config{}
+	if tracer != nil {
+		vmConfig.Debug, vmConfig.Tracer = true, tracer
+	}
+	vmenv := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vmConfig)
+	result, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()), true /* refunds */, false /* gasBailout */)
+	if err != nil {
+		return nil, fmt.Errorf("tracing failed: %w", err)
+	}
+	return result, nil
+}
+
+// traceBlock re-executes all transactions of the block, newTracer is asked for the tracer of every transaction and may return nil
+func (api *OtterscanAPIImpl) traceBlock(ctx context.Context, tx kv.Tx, chainConfig *params.ChainConfig, block *types.Block, newTracer func(idx int, txn types.Transaction) vm.Tracer) error {
+	reader := state.NewPlainState(tx, block.NumberU64())
+	ibs := state.New(reader)
+
+	header := block.Header()
+	blockCtx := core.NewEVMBlockContext(header, core.GetHashFn(header, api.getHeaderFunc(ctx, tx)), ethash.NewFaker(), nil, api.contractHasTEVM(tx))
+	signer := types.MakeSigner(chainConfig, block.NumberU64())
+	rules := chainConfig.Rules(block.NumberU64(), block.Time())
+	for idx, txn := range block.Transactions() {
+		select {
+		default:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		ibs.Prepare(txn.Hash(), block.Hash(), idx)
+		msg, err := txn.AsMessage(*signer, block.BaseFee(), rules)
+		if err != nil {
+			return fmt.Errorf("convert transaction %x into message: %w", txn.Hash(), err)
+		}
+
+		vmConfig := vm.Config{}
+		if tracer := newTracer(idx, txn); tracer != nil {
+			vmConfig.Debug, vmConfig.Tracer = true, tracer
+		}
+		vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), ibs, chainConfig, vmConfig)
+		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()), true /* refunds */, false /* gasBailout */); err != nil {
+			return fmt.Errorf("transaction %x failed: %w", txn.Hash(), err)
+		}
+		if err := ibs.FinalizeTx(rules, reader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalOtsReceipt is marshalReceipt extended with the block timestamp, Otterscan shows it next to every transaction
+func marshalOtsReceipt(receipt *types.Receipt, txn types.Transaction, chainConfig *params.ChainConfig, block *types.Block) map[string]interface{} {
+	fields := marshalReceipt(receipt, txn, chainConfig, block, txn.Hash(), true)
+	fields["timestamp"] = hexutil.Uint64(block.Time())
+	return fields
+}
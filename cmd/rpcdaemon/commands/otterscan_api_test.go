@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/ledgerwatch/erigon/turbo/stages"
+)
+
+func TestOtterscanSearchTransactions(t *testing.T) {
+	m := stages.Mock(t)
+	defer m.DB.Close()
+	rcv, other := common.Address{2}, common.Address{3}
+	var hashes []common.Hash
+	// The mock account pays rcv in the even blocks and other in the odd ones
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 10, func(i int, block *core.BlockGen) {
+		to := other
+		if (i+1)%2 == 0 {
+			to = rcv
+		}
+		signer := types.LatestSigner(m.ChainConfig)
+		txn, err := types.SignTx(types.NewTransaction(block.TxNonce(m.Address), to, uint256.NewInt(1), 21000, new(uint256.Int), nil), *signer, m.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.AddTx(txn)
+		hashes = append(hashes, txn.Hash())
+	}, false /* intermediateHashes */)
+	require.NoError(t, err, "generate chain")
+	require.NoError(t, m.InsertChain(chain), "inserting chain")
+
+	api := NewOtterscanAPI(NewBaseApi(nil, kvcache.New(kvcache.DefaultCoherentConfig), snapshotsync.NewBlockReader(), false), m.DB, 25)
+	ctx := context.Background()
+	blockNumbers := func(page *TransactionsWithReceipts) []uint64 {
+		var numbers []uint64
+		for _, txn := range page.Txs {
+			numbers = append(numbers, txn.BlockNumber.ToInt().Uint64())
+		}
+		return numbers
+	}
+
+	t.Run("before", func(t *testing.T) {
+		page, err := api.SearchTransactionsBefore(ctx, rcv, 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{10, 8}, blockNumbers(page))
+		assert.True(t, page.FirstPage)
+		assert.False(t, page.LastPage)
+		assert.Equal(t, len(page.Txs), len(page.Receipts))
+
+		page, err = api.SearchTransactionsBefore(ctx, rcv, 8, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{6, 4, 2}, blockNumbers(page))
+		assert.False(t, page.FirstPage)
+		assert.True(t, page.LastPage)
+	})
+	t.Run("after", func(t *testing.T) {
+		page, err := api.SearchTransactionsAfter(ctx, rcv, 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{4, 2}, blockNumbers(page))
+		assert.False(t, page.FirstPage)
+		assert.True(t, page.LastPage)
+
+		page, err = api.SearchTransactionsAfter(ctx, rcv, 4, 25)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{10, 8, 6}, blockNumbers(page))
+		assert.True(t, page.FirstPage)
+	})
+	t.Run("sender", func(t *testing.T) {
+		page, err := api.SearchTransactionsBefore(ctx, m.Address, 0, 25)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, blockNumbers(page))
+		assert.True(t, page.LastPage)
+	})
+	t.Run("page size", func(t *testing.T) {
+		_, err := api.SearchTransactionsBefore(ctx, rcv, 0, 26)
+		require.Error(t, err)
+	})
+	t.Run("sender and nonce", func(t *testing.T) {
+		hash, err := api.GetTransactionBySenderAndNonce(ctx, m.Address, 4)
+		require.NoError(t, err)
+		require.NotNil(t, hash)
+		assert.Equal(t, hashes[4], *hash)
+
+		hash, err = api.GetTransactionBySenderAndNonce(ctx, m.Address, 10)
+		require.NoError(t, err)
+		assert.Nil(t, hash)
+	})
+	t.Run("trace", func(t *testing.T) {
+		entries, err := api.TraceTransaction(ctx, hashes[1])
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "CALL", entries[0].Type)
+		assert.Equal(t, m.Address, entries[0].From)
+		assert.Equal(t, rcv, entries[0].To)
+
+		ops, err := api.GetInternalOperations(ctx, hashes[1])
+		require.NoError(t, err)
+		assert.Empty(t, ops)
+
+		revert, err := api.GetTransactionError(ctx, hashes[1])
+		require.NoError(t, err)
+		assert.Empty(t, revert)
+	})
+	t.Run("accounts", func(t *testing.T) {
+		hasCode, err := api.HasCode(ctx, rcv, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+		require.NoError(t, err)
+		assert.False(t, hasCode)
+
+		creator, err := api.GetContractCreator(ctx, rcv)
+		require.NoError(t, err)
+		assert.Nil(t, creator)
+	})
+	t.Run("block details", func(t *testing.T) {
+		details, err := api.GetBlockDetails(ctx, 2)
+		require.NoError(t, err)
+		block := details["block"].(map[string]interface{})
+		assert.Equal(t, 1, block["transactionCount"])
+		assert.NotContains(t, block, "transactions")
+
+		byHash, err := api.GetBlockDetailsByHash(ctx, chain.Blocks[1].Hash())
+		require.NoError(t, err)
+		assert.Equal(t, details, byHash)
+	})
+	t.Run("block transactions", func(t *testing.T) {
+		page, err := api.GetBlockTransactions(ctx, 2, 0, 25)
+		require.NoError(t, err)
+		txs := page["fullblock"].(map[string]interface{})["transactions"].([]*RPCTransaction)
+		require.Len(t, txs, 1)
+		assert.Equal(t, hashes[1], txs[0].Hash)
+		assert.Len(t, page["receipts"], 1)
+
+		page, err = api.GetBlockTransactions(ctx, 2, 1, 25)
+		require.NoError(t, err)
+		assert.Empty(t, page["fullblock"].(map[string]interface{})["transactions"])
+	})
+}
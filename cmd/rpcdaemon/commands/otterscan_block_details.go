@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+)
+
+// HasCode implements ots_hasCode. Returns whether there is a contract at the address at the given block.
+func (api *OtterscanAPIImpl) HasCode(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (bool, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return false, fmt.Errorf("hasCode cannot open tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	reader, err := rpchelper.CreateStateReader(ctx, tx, blockNrOrHash, api.filters, api.stateCache)
+	if err != nil {
+		return false, err
+	}
+	acc, err := reader.ReadAccountData(address)
+	if err != nil || acc == nil {
+		return false, err
+	}
+	return !acc.IsEmptyCodeHash(), nil
+}
+
+// GetBlockDetails implements ots_getBlockDetails. Returns the block without its transactions, plus the
+// number of transactions, the issuance and the fees paid in the block.
+func (api *OtterscanAPIImpl) GetBlockDetails(ctx context.Context, number rpc.BlockNumber) (map[string]interface{}, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	b, err := api.blockByRPCNumber(number, tx)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, nil
+	}
+	return api.getBlockDetails(ctx, tx, b)
+}
+
+// GetBlockDetailsByHash implements ots_getBlockDetailsByHash, same as ots_getBlockDetails but for the block with the given hash.
+func (api *OtterscanAPIImpl) GetBlockDetailsByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	b, err := api.blockByHashWithSenders(tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, nil
+	}
+	return api.getBlockDetails(ctx, tx, b)
+}
+
+func (api *OtterscanAPIImpl) getBlockDetails(ctx context.Context, tx kv.Tx, b *types.Block) (map[string]interface{}, error) {
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := ethapi.RPCMarshalBlock(b, true, false)
+	if err != nil {
+		return nil, err
+	}
+	td, err := rawdb.ReadTd(tx, b.Hash(), b.NumberU64())
+	if err != nil {
+		return nil, err
+	}
+	if td != nil {
+		block["totalDifficulty"] = (*hexutil.Big)(td)
+	}
+	// Otterscan fetches the transactions page by page and has no use for the bloom
+	delete(block, "transactions")
+	delete(block, "logsBloom")
+	block["transactionCount"] = len(b.Transactions())
+
+	issuance := map[string]interface{}{}
+	if chainConfig.Ethash != nil {
+		minerReward, uncleRewards := ethash.AccumulateRewards(chainConfig, b.Header(), b.Uncles())
+		total := minerReward
+		var uncleReward uint256.Int
+		for i := range uncleRewards {
+			uncleReward.Add(&uncleReward, &uncleRewards[i])
+		}
+		total.Add(&total, &uncleReward)
+		issuance["blockReward"] = (*hexutil.Big)(minerReward.ToBig())
+		issuance["uncleReward"] = (*hexutil.Big)(uncleReward.ToBig())
+		issuance["issuance"] = (*hexutil.Big)(total.ToBig())
+	}
+
+	receipts, err := api.getReceipts(ctx, tx, chainConfig, b, b.Body().SendersFromTxs())
+	if err != nil {
+		return nil, err
+	}
+	var baseFee *uint256.Int
+	if b.BaseFee() != nil {
+		baseFee, _ = uint256.FromBig(b.BaseFee())
+	}
+	totalFees := new(big.Int)
+	for i, txn := range b.Transactions() {
+		gasPrice := txn.GetPrice().ToBig()
+		if baseFee != nil {
+			gasPrice = new(big.Int).Add(b.BaseFee(), txn.GetEffectiveGasTip(baseFee).ToBig())
+		}
+		totalFees.Add(totalFees, gasPrice.Mul(gasPrice, new(big.Int).SetUint64(receipts[i].GasUsed)))
+	}
+
+	return map[string]interface{}{
+		"block":     block,
+		"issuance":  issuance,
+		"totalFees": (*hexutil.Big)(totalFees),
+	}, nil
+}
+
+// GetBlockTransactions implements ots_getBlockTransactions. Returns a page of the transactions of the block together
+// with their receipts. Pages are counted from the end of the block: page 0 holds the last pageSize transactions.
+// The input of the transactions is cut to the 4 bytes of the method selector, the logs of the receipts are omitted.
+func (api *OtterscanAPIImpl) GetBlockTransactions(ctx context.Context, number rpc.BlockNumber, pageNumber uint8, pageSize uint8) (map[string]interface{}, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	b, err := api.blockByRPCNumber(number, tx)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, nil
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := api.getReceipts(ctx, tx, chainConfig, b, b.Body().SendersFromTxs())
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := ethapi.RPCMarshalBlock(b, false, false)
+	if err != nil {
+		return nil, err
+	}
+	td, err := rawdb.ReadTd(tx, b.Hash(), b.NumberU64())
+	if err != nil {
+		return nil, err
+	}
+	if td != nil {
+		block["totalDifficulty"] = (*hexutil.Big)(td)
+	}
+	delete(block, "logsBloom")
+	block["transactionCount"] = len(b.Transactions())
+
+	pageEnd := len(b.Transactions()) - int(pageNumber)*int(pageSize)
+	if pageEnd < 0 {
+		pageEnd = 0
+	}
+	pageStart := pageEnd - int(pageSize)
+	if pageStart < 0 {
+		pageStart = 0
+	}
+	txs := make([]*RPCTransaction, 0, pageEnd-pageStart)
+	txReceipts := make([]map[string]interface{}, 0, pageEnd-pageStart)
+	for idx := pageStart; idx < pageEnd; idx++ {
+		txn := b.Transactions()[idx]
+		rpcTx := newRPCTransaction(txn, b.Hash(), b.NumberU64(), uint64(idx), b.BaseFee())
+		if len(rpcTx.Input) > 4 {
+			rpcTx.Input = rpcTx.Input[:4]
+		}
+		txs = append(txs, rpcTx)
+		receipt := marshalReceipt(receipts[idx], txn, chainConfig, b, txn.Hash(), true)
+		receipt["logs"] = nil
+		receipt["logsBloom"] = nil
+		txReceipts = append(txReceipts, receipt)
+	}
+	block["transactions"] = txs
+
+	return map[string]interface{}{
+		"fullblock": block,
+		"receipts":  txReceipts,
+	}, nil
+}
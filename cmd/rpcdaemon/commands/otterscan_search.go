@@ -0,0 +1,446 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+)
+
+// SearchTransactionsBefore implements ots_searchTransactionsBefore. Returns the transactions of the address in the blocks before blockNum,
+// starting from the newest one. blockNum 0 means from the head of the chain.
+// The page is at least pageSize transactions long unless there are no more of them, and never splits a block.
+func (api *OtterscanAPIImpl) SearchTransactionsBefore(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error) {
+	start := blockNum - 1
+	if blockNum == 0 {
+		start = ^uint64(0)
+	}
+	result, hasMore, err := api.searchTransactions(ctx, addr, start, pageSize, true /* reverse */)
+	if err != nil {
+		return nil, err
+	}
+	result.FirstPage = blockNum == 0
+	result.LastPage = !hasMore
+	return result, nil
+}
+
+// SearchTransactionsAfter implements ots_searchTransactionsAfter. Returns the transactions of the address in the blocks after blockNum,
+// starting from the oldest one. blockNum 0 means from the genesis. Like in ots_searchTransactionsBefore, the page is sorted from
+// the newest to the oldest transaction.
+func (api *OtterscanAPIImpl) SearchTransactionsAfter(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error) {
+	result, hasMore, err := api.searchTransactions(ctx, addr, blockNum+1, pageSize, false /* reverse */)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(result.Txs)-1; i < j; i, j = i+1, j-1 {
+		result.Txs[i], result.Txs[j] = result.Txs[j], result.Txs[i]
+		result.Receipts[i], result.Receipts[j] = result.Receipts[j], result.Receipts[i]
+	}
+	result.FirstPage = !hasMore
+	result.LastPage = blockNum == 0
+	return result, nil
+}
+
+func (api *OtterscanAPIImpl) searchTransactions(ctx context.Context, addr common.Address, start uint64, pageSize uint16, reverse bool) (*TransactionsWithReceipts, bool, error) {
+	if pageSize == 0 || uint64(pageSize) > api.maxPageSize {
+		return nil, false, fmt.Errorf("page size must be between 1 and %d", api.maxPageSize)
+	}
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	fromIt, err := newCallIndexIterator(tx, kv.CallFromIndex, addr, start, reverse)
+	if err != nil {
+		return nil, false, err
+	}
+	defer fromIt.close()
+	toIt, err := newCallIndexIterator(tx, kv.CallToIndex, addr, start, reverse)
+	if err != nil {
+		return nil, false, err
+	}
+	defer toIt.close()
+	it := newUnionIterator(fromIt, toIt, reverse)
+
+	result := &TransactionsWithReceipts{Txs: []*RPCTransaction{}, Receipts: []map[string]interface{}{}}
+	for len(result.Txs) < int(pageSize) {
+		blockNum, ok, err := it.next()
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			return result, false, nil
+		}
+		block, err := api.blockByNumberWithSenders(tx, blockNum)
+		if err != nil {
+			return nil, false, err
+		}
+		if block == nil {
+			continue
+		}
+
+		// The index only tells that the address was touched somewhere in the block, find out by which transactions
+		var touched []int
+		tracer := &touchTracer{addr: addr}
+		if err := api.traceBlock(ctx, tx, chainConfig, block, func(idx int, txn types.Transaction) vm.Tracer {
+			if tracer.found {
+				touched = append(touched, idx-1)
+			}
+			tracer.found = false
+			return tracer
+		}); err != nil {
+			return nil, false, err
+		}
+		if tracer.found {
+			touched = append(touched, len(block.Transactions())-1)
+		}
+		if len(touched) == 0 {
+			continue
+		}
+
+		receipts, err := api.getReceipts(ctx, tx, chainConfig, block, block.Body().SendersFromTxs())
+		if err != nil {
+			return nil, false, err
+		}
+		if reverse {
+			sort.Sort(sort.Reverse(sort.IntSlice(touched)))
+		}
+		for _, idx := range touched {
+			txn := block.Transactions()[idx]
+			result.Txs = append(result.Txs, newRPCTransaction(txn, block.Hash(), blockNum, uint64(idx), block.BaseFee()))
+			result.Receipts = append(result.Receipts, marshalOtsReceipt(receipts[idx], txn, chainConfig, block))
+		}
+	}
+	_, hasMore, err := it.next()
+	if err != nil {
+		return nil, false, err
+	}
+	return result, hasMore, nil
+}
+
+// GetTransactionBySenderAndNonce implements ots_getTransactionBySenderAndNonce. Returns the hash of the transaction
+// sent by the address with the given nonce, or nil if the address has not used the nonce yet.
+func (api *OtterscanAPIImpl) GetTransactionBySenderAndNonce(ctx context.Context, addr common.Address, nonce uint64) (*common.Hash, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The nonce is used by the first block after which the account nonce exceeds it
+	blockNum, found, err := searchFirstBlock(tx, addr, func(acc *accounts.Account) bool { return acc != nil && acc.Nonce > nonce })
+	if err != nil || !found {
+		return nil, err
+	}
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("could not find block %d", blockNum)
+	}
+	senders := block.Body().SendersFromTxs()
+	for i, txn := range block.Transactions() {
+		if senders[i] == addr && txn.GetNonce() == nonce {
+			hash := txn.Hash()
+			return &hash, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find the transaction of %x with nonce %d in block %d", addr, nonce, blockNum)
+}
+
+// GetContractCreator implements ots_getContractCreator. Returns the transaction that deployed the contract at the address
+// and the account that deployed it, nil if there is no contract at the address.
+func (api *OtterscanAPIImpl) GetContractCreator(ctx context.Context, addr common.Address) (*ContractCreatorData, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	latest, err := state.NewPlainStateReader(tx).ReadAccountData(addr)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil || latest.IsEmptyCodeHash() {
+		return nil, nil
+	}
+	// Contracts are created with a fresh incarnation, so the first block after which the account has the
+	// current incarnation is the one that deployed the current code
+	blockNum, found, err := searchFirstBlock(tx, addr, func(acc *accounts.Account) bool {
+		return acc != nil && acc.Incarnation >= latest.Incarnation && !acc.IsEmptyCodeHash()
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("could not find block %d", blockNum)
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer := &createTracer{addr: addr}
+	if err := api.traceBlock(ctx, tx, chainConfig, block, func(idx int, txn types.Transaction) vm.Tracer {
+		if tracer.found {
+			// No need to trace the rest of the block
+			return nil
+		}
+		tracer.txIndex = idx
+		return tracer
+	}); err != nil {
+		return nil, err
+	}
+	if !tracer.found {
+		// Contracts of the genesis allocation have no creator
+		return nil, nil
+	}
+	return &ContractCreatorData{Tx: block.Transactions()[tracer.txIndex].Hash(), Creator: tracer.creator}, nil
+}
+
+// searchFirstBlock bisects the history of the account for the first block after which its state satisfies the condition.
+// The condition must stay satisfied once it is, the state passed to it is nil if the account does not exist.
+func searchFirstBlock(tx kv.Tx, addr common.Address, cond func(acc *accounts.Account) bool) (uint64, bool, error) {
+	latest, err := rpchelper.GetLatestBlockNumber(tx)
+	if err != nil {
+		return 0, false, err
+	}
+	reader := state.NewPlainState(tx, latest+1)
+	check := func(blockNum uint64) (bool, error) {
+		reader.SetBlockNr(blockNum + 1)
+		acc, err := reader.ReadAccountData(addr)
+		if err != nil {
+			return false, err
+		}
+		return cond(acc), nil
+	}
+	ok, err := check(latest)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	lo, hi := uint64(0), latest
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		ok, err := check(mid)
+		if err != nil {
+			return 0, false, err
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, true, nil
+}
+
+// callIndexIterator walks the blocks of an address in the chunked bitmaps of CallFromIndex or CallToIndex, in either direction.
+// Chunks are keyed by the address followed by the biggest block of the chunk, the last chunk of an address by ^uint64(0).
+type callIndexIterator struct {
+	c       kv.Cursor
+	addr    []byte
+	start   uint64
+	reverse bool
+	started bool
+	done    bool
+	blocks  []uint64 // not yet visited blocks of the current chunk, in the order of the walk
+}
+
+func newCallIndexIterator(tx kv.Tx, bucket string, addr common.Address, start uint64, reverse bool) (*callIndexIterator, error) {
+	c, err := tx.Cursor(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return &callIndexIterator{c: c, addr: addr.Bytes(), start: start, reverse: reverse}, nil
+}
+
+func (it *callIndexIterator) close() {
+	it.c.Close()
+}
+
+func (it *callIndexIterator) next() (uint64, bool, error) {
+	for len(it.blocks) == 0 {
+		if it.done {
+			return 0, false, nil
+		}
+		if err := it.nextChunk(); err != nil {
+			return 0, false, err
+		}
+	}
+	blockNum := it.blocks[0]
+	it.blocks = it.blocks[1:]
+	return blockNum, true, nil
+}
+
+func (it *callIndexIterator) nextChunk() error {
+	var k, v []byte
+	var err error
+	switch {
+	case !it.started:
+		it.started = true
+		seek := make([]byte, len(it.addr)+8)
+		copy(seek, it.addr)
+		binary.BigEndian.PutUint64(seek[len(it.addr):], it.start)
+		k, v, err = it.c.Seek(seek)
+	case it.reverse:
+		k, v, err = it.c.Prev()
+	default:
+		k, v, err = it.c.Next()
+	}
+	if err != nil {
+		return err
+	}
+	if k == nil || !bytes.HasPrefix(k, it.addr) {
+		it.done = true
+		return nil
+	}
+	bm := roaring64.New()
+	if _, err := bm.ReadFrom(bytes.NewReader(v)); err != nil {
+		return err
+	}
+	blocks := bm.ToArray()
+	if it.reverse {
+		n := sort.Search(len(blocks), func(i int) bool { return blocks[i] > it.start })
+		blocks = blocks[:n]
+		for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+			blocks[i], blocks[j] = blocks[j], blocks[i]
+		}
+	} else {
+		n := sort.Search(len(blocks), func(i int) bool { return blocks[i] >= it.start })
+		blocks = blocks[n:]
+	}
+	it.blocks = blocks
+	return nil
+}
+
+// unionIterator merges the blocks of two callIndexIterators walking in the same direction, dropping duplicates
+type unionIterator struct {
+	a, b       *callIndexIterator
+	reverse    bool
+	started    bool
+	aNum, bNum uint64
+	aOk, bOk   bool
+}
+
+func newUnionIterator(a, b *callIndexIterator, reverse bool) *unionIterator {
+	return &unionIterator{a: a, b: b, reverse: reverse}
+}
+
+func (it *unionIterator) next() (uint64, bool, error) {
+	var err error
+	if !it.started {
+		it.started = true
+		if it.aNum, it.aOk, err = it.a.next(); err != nil {
+			return 0, false, err
+		}
+		if it.bNum, it.bOk, err = it.b.next(); err != nil {
+			return 0, false, err
+		}
+	}
+	if !it.aOk && !it.bOk {
+		return 0, false, nil
+	}
+	var blockNum uint64
+	switch {
+	case !it.bOk:
+		blockNum = it.aNum
+	case !it.aOk:
+		blockNum = it.bNum
+	case it.reverse == (it.aNum > it.bNum):
+		blockNum = it.aNum
+	default:
+		blockNum = it.bNum
+	}
+	if it.aOk && it.aNum == blockNum {
+		if it.aNum, it.aOk, err = it.a.next(); err != nil {
+			return 0, false, err
+		}
+	}
+	if it.bOk && it.bNum == blockNum {
+		if it.bNum, it.bOk, err = it.b.next(); err != nil {
+			return 0, false, err
+		}
+	}
+	return blockNum, true, nil
+}
+
+// touchTracer finds out whether an address is the sender, the recipient or the beneficiary of any call,
+// contract creation or selfdestruct of a transaction
+type touchTracer struct {
+	addr  common.Address
+	found bool
+}
+
+func (t *touchTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if from == t.addr || to == t.addr {
+		t.found = true
+	}
+}
+func (t *touchTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+func (t *touchTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+func (t *touchTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+}
+func (t *touchTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+	if from == t.addr || to == t.addr {
+		t.found = true
+	}
+}
+func (t *touchTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+func (t *touchTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
+
+// createTracer finds the transaction of a block that deployed the contract at an address, and who deployed it
+type createTracer struct {
+	addr    common.Address
+	found   bool
+	txIndex int
+	creator common.Address
+}
+
+func (t *createTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if create && to == t.addr && !t.found {
+		t.found, t.creator = true, from
+	}
+}
+func (t *createTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+func (t *createTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+func (t *createTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+}
+func (t *createTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+}
+func (t *createTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+func (t *createTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
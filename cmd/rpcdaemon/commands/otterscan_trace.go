@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/vm"
+)
+
+// TraceEntry is a call, contract creation or selfdestruct of a transaction, in the order of execution
+type TraceEntry struct {
+	Type  string         `json:"type"`
+	Depth int            `json:"depth"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Input hexutil.Bytes  `json:"input"`
+}
+
+// OperationType is the kind of an InternalOperation
+type OperationType int
+
+const (
+	OpTransfer     OperationType = 0
+	OpSelfDestruct OperationType = 1
+	OpCreate       OperationType = 2
+	OpCreate2      OperationType = 3
+)
+
+// InternalOperation is a movement of ETH or a contract creation done by a contract rather than by the transaction itself
+type InternalOperation struct {
+	Type  OperationType  `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+}
+
+// TraceTransaction implements ots_traceTransaction. Returns the call tree of the transaction as a flat list with depths.
+func (api *OtterscanAPIImpl) TraceTransaction(ctx context.Context, hash common.Hash) ([]*TraceEntry, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tracer := &transactionTracer{results: []*TraceEntry{}}
+	result, err := api.runTracer(ctx, tx, hash, tracer)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return tracer.results, nil
+}
+
+// GetInternalOperations implements ots_getInternalOperations. Returns the ETH transfers, selfdestructs and contract creations
+// done by the contracts called by the transaction.
+func (api *OtterscanAPIImpl) GetInternalOperations(ctx context.Context, hash common.Hash) ([]*InternalOperation, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tracer := &operationsTracer{results: []*InternalOperation{}}
+	result, err := api.runTracer(ctx, tx, hash, tracer)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return tracer.results, nil
+}
+
+// GetTransactionError implements ots_getTransactionError. Returns the revert output of the transaction, which is empty
+// if the transaction succeeded or failed without reverting.
+func (api *OtterscanAPIImpl) GetTransactionError(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := api.runTracer(ctx, tx, hash, nil)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return result.Revert(), nil
+}
+
+// transactionTracer collects the TraceEntries of ots_traceTransaction
+type transactionTracer struct {
+	results []*TraceEntry
+	depth   int // depth of the currently executing frame
+}
+
+func (t *transactionTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	t.depth = depth
+	entry := &TraceEntry{Depth: depth, From: from, To: to, Input: common.CopyBytes(input)}
+	switch callType {
+	case vm.CALLT:
+		entry.Type = "CALL"
+	case vm.CALLCODET:
+		entry.Type = "CALLCODE"
+	case vm.DELEGATECALLT:
+		entry.Type = "DELEGATECALL"
+	case vm.STATICCALLT:
+		entry.Type = "STATICCALL"
+	case vm.CREATET:
+		entry.Type = "CREATE"
+	case vm.CREATE2T:
+		entry.Type = "CREATE2"
+	}
+	// DELEGATECALL and STATICCALL carry no value, the EVM reports negative placeholders for them
+	if value != nil && value.Sign() >= 0 {
+		entry.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+	t.results = append(t.results, entry)
+}
+func (t *transactionTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+func (t *transactionTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+func (t *transactionTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+	t.depth = depth - 1
+}
+func (t *transactionTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+	t.results = append(t.results, &TraceEntry{Type: "SELFDESTRUCT", Depth: t.depth + 1, From: from, To: to, Value: (*hexutil.Big)(new(big.Int).Set(value))})
+}
+func (t *transactionTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+func (t *transactionTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
+
+// operationsTracer collects the InternalOperations of ots_getInternalOperations
+type operationsTracer struct {
+	results []*InternalOperation
+}
+
+func (t *operationsTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if depth == 0 {
+		// Whatever the transaction does by itself is not internal
+		return
+	}
+	var op OperationType
+	switch {
+	case callType == vm.CREATET:
+		op = OpCreate
+	case callType == vm.CREATE2T:
+		op = OpCreate2
+	case callType == vm.CALLT && value != nil && value.Sign() > 0:
+		op = OpTransfer
+	default:
+		return
+	}
+	t.results = append(t.results, &InternalOperation{Type: op, From: from, To: to, Value: (*hexutil.Big)(new(big.Int).Set(value))})
+}
+func (t *operationsTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+func (t *operationsTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+func (t *operationsTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+}
+func (t *operationsTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+	t.results = append(t.results, &InternalOperation{Type: OpSelfDestruct, From: from, To: to, Value: (*hexutil.Big)(new(big.Int).Set(value))})
+}
+func (t *operationsTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+func (t *operationsTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
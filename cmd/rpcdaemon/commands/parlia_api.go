@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/parlia"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+// ParliaAPI Parlia specific routines
+type ParliaAPI interface {
+	GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*parlia.Snapshot, error)
+	GetSnapshotAtHash(ctx context.Context, hash common.Hash) (*parlia.Snapshot, error)
+	GetValidators(ctx context.Context, number *rpc.BlockNumber) ([]common.Address, error)
+	GetValidatorsAtHash(ctx context.Context, hash common.Hash) ([]common.Address, error)
+}
+
+// ParliaImpl is implementation of the ParliaAPI interface
+type ParliaImpl struct {
+	*BaseAPI
+	db       kv.RoDB // the chain db
+	parliaDb kv.RoDB // the consensus db
+}
+
+// NewParliaAPI returns ParliaImpl instance
+func NewParliaAPI(base *BaseAPI, db kv.RoDB, parliaDb kv.RoDB) *ParliaImpl {
+	return &ParliaImpl{
+		BaseAPI:  base,
+		db:       db,
+		parliaDb: parliaDb,
+	}
+}
+
+var errNotParlia = errors.New("chain doesn't use parlia consensus")
+
+// GetSnapshot retrieves the state snapshot at a given block.
+func (api *ParliaImpl) GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*parlia.Snapshot, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Retrieve the requested block number (or current if none requested)
+	blockNumber := rpc.LatestBlockNumber
+	if number != nil {
+		blockNumber = *number
+	}
+	header, err := api.headerByRPCNumber(blockNumber, tx)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.snapshot(ctx, tx, header)
+}
+
+// GetSnapshotAtHash retrieves the state snapshot at a given block.
+func (api *ParliaImpl) GetSnapshotAtHash(ctx context.Context, hash common.Hash) (*parlia.Snapshot, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	header, err := api._blockReader.HeaderByHash(ctx, tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.snapshot(ctx, tx, header)
+}
+
+// GetValidators retrieves the list of validators at the specified block.
+func (api *ParliaImpl) GetValidators(ctx context.Context, number *rpc.BlockNumber) ([]common.Address, error) {
+	snap, err := api.GetSnapshot(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return snap.GetValidators(), nil
+}
+
+// GetValidatorsAtHash retrieves the list of validators at the specified block.
+func (api *ParliaImpl) GetValidatorsAtHash(ctx context.Context, hash common.Hash) ([]common.Address, error) {
+	snap, err := api.GetSnapshotAtHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return snap.GetValidators(), nil
+}
+
+func (api *ParliaImpl) snapshot(ctx context.Context, tx kv.Tx, header *types.Header) (*parlia.Snapshot, error) {
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	if chainConfig.Parlia == nil || api.parliaDb == nil {
+		return nil, errNotParlia
+	}
+	parliaTx, err := api.parliaDb.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer parliaTx.Rollback()
+
+	chain := consensusChainReader{ctx: ctx, config: chainConfig, tx: tx, blockReader: api._blockReader}
+	return parlia.ReadSnapshot(chainConfig.Parlia, chainConfig.ChainID, parliaTx, chain, header.Number.Uint64(), header.Hash())
+}
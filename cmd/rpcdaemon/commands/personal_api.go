@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon/accounts/keystore"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+var errNoKeystore = errors.New("no keystore configured, see --keystore")
+var errInsecureUnlock = errors.New("account unlock with HTTP access is forbidden, see --allow-insecure-unlock")
+
+// defaultUnlockDuration - how long personal_unlockAccount keeps the account unlocked when no duration is given
+const defaultUnlockDuration = 300 * time.Second
+
+// PersonalAPI is the interface of the personal_ namespace: management of the accounts in the local keystore
+type PersonalAPI interface {
+	ListAccounts(ctx context.Context) ([]common.Address, error)
+	NewAccount(ctx context.Context, password string) (common.Address, error)
+	ImportRawKey(ctx context.Context, privkey string, password string) (common.Address, error)
+	UnlockAccount(ctx context.Context, address common.Address, password string, duration *uint64) (bool, error)
+	LockAccount(ctx context.Context, address common.Address) (bool, error)
+	Sign(ctx context.Context, data hexutil.Bytes, address common.Address, password string) (hexutil.Bytes, error)
+	EcRecover(ctx context.Context, data, sig hexutil.Bytes) (common.Address, error)
+	SendTransaction(ctx context.Context, args SendTxArgs, password string) (common.Hash, error)
+}
+
+// PersonalAPIImpl is implementation of the PersonalAPI interface
+type PersonalAPIImpl struct {
+	eth      *APIImpl
+	keystore *keystore.KeyStore
+
+	AllowInsecureUnlock bool // personal_unlockAccount is served, though every request comes over HTTP or WebSocket
+}
+
+// NewPersonalAPI returns PersonalAPIImpl instance, sharing the keystore of eth_ methods
+func NewPersonalAPI(eth *APIImpl) *PersonalAPIImpl {
+	return &PersonalAPIImpl{eth: eth, keystore: eth.keystore}
+}
+
+// ListAccounts implements personal_listAccounts. Returns the addresses of all accounts in the keystore.
+func (api *PersonalAPIImpl) ListAccounts(ctx context.Context) ([]common.Address, error) {
+	return api.eth.Accounts(ctx)
+}
+
+// NewAccount implements personal_newAccount. Generates a new key and stores it in the keystore, encrypted with the password.
+func (api *PersonalAPIImpl) NewAccount(_ context.Context, password string) (common.Address, error) {
+	if api.keystore == nil {
+		return common.Address{}, errNoKeystore
+	}
+	return api.keystore.NewAccount(password)
+}
+
+// ImportRawKey implements personal_importRawKey. Stores the given hex encoded private key in the keystore, encrypted with the password.
+func (api *PersonalAPIImpl) ImportRawKey(_ context.Context, privkey string, password string) (common.Address, error) {
+	if api.keystore == nil {
+		return common.Address{}, errNoKeystore
+	}
+	key, err := crypto.HexToECDSA(privkey)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return api.keystore.ImportECDSA(key, password)
+}
+
+// UnlockAccount implements personal_unlockAccount. Keeps the decrypted key in memory for the given duration in seconds
+// (default 300), 0 - until the rpcdaemon is stopped or the account is locked explicitly.
+// Any client reaching the HTTP endpoint can sign with an unlocked account, so it's refused unless AllowInsecureUnlock is set.
+func (api *PersonalAPIImpl) UnlockAccount(_ context.Context, address common.Address, password string, duration *uint64) (bool, error) {
+	if api.keystore == nil {
+		return false, errNoKeystore
+	}
+	if !api.AllowInsecureUnlock {
+		return false, errInsecureUnlock
+	}
+	const maxSeconds = uint64(time.Duration(1<<63-1) / time.Second)
+	d := defaultUnlockDuration
+	if duration != nil {
+		if *duration > maxSeconds {
+			return false, fmt.Errorf("unlock duration too large, max %d seconds", maxSeconds)
+		}
+		d = time.Duration(*duration) * time.Second
+	}
+	if err := api.keystore.TimedUnlock(address, password, d); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LockAccount implements personal_lockAccount. Removes the decrypted key from memory.
+func (api *PersonalAPIImpl) LockAccount(_ context.Context, address common.Address) (bool, error) {
+	if api.keystore == nil {
+		return false, errNoKeystore
+	}
+	if err := api.keystore.Lock(address); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Sign implements personal_sign. Same signature as eth_sign, but the key is decrypted with the given password
+// instead of requiring an unlocked account.
+func (api *PersonalAPIImpl) Sign(_ context.Context, data hexutil.Bytes, address common.Address, password string) (hexutil.Bytes, error) {
+	if api.keystore == nil {
+		return nil, errNoKeystore
+	}
+	signature, err := api.keystore.SignHashWithPassphrase(address, password, signHash(data))
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	return signature, nil
+}
+
+// EcRecover implements personal_ecRecover. Returns the address of the account which produced the signature
+// with personal_sign or eth_sign.
+func (api *PersonalAPIImpl) EcRecover(_ context.Context, data, sig hexutil.Bytes) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes long", crypto.SignatureLength)
+	}
+	if sig[crypto.RecoveryIDOffset] != 27 && sig[crypto.RecoveryIDOffset] != 28 {
+		return common.Address{}, errors.New("invalid Ethereum signature (V is not 27 or 28)")
+	}
+	sig = common.CopyBytes(sig)
+	sig[crypto.RecoveryIDOffset] -= 27 // Transform yellow paper V from 27/28 to 0/1
+
+	rpk, err := crypto.SigToPub(signHash(data), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*rpk), nil
+}
+
+// SendTransaction implements personal_sendTransaction. Same as eth_sendTransaction, but the key is decrypted
+// with the given password instead of requiring an unlocked account.
+func (api *PersonalAPIImpl) SendTransaction(ctx context.Context, args SendTxArgs, password string) (common.Hash, error) {
+	txn, err := api.eth.signTransaction(ctx, args, &password)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var buf bytes.Buffer
+	if err := txn.MarshalBinary(&buf); err != nil {
+		return common.Hash{}, err
+	}
+	return api.eth.SendRawTransaction(ctx, buf.Bytes())
+}
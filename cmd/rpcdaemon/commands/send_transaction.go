@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/holiman/uint256"
 	txPoolProto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpool"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/hexutil"
@@ -14,8 +15,11 @@ import (
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/gasprice"
+	"github.com/ledgerwatch/erigon/internal/ethapi"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/log/v3"
 )
 
@@ -82,9 +86,228 @@ func (api *APIImpl) SendRawTransaction(ctx context.Context, encodedTx hexutil.By
 	return txn.Hash(), nil
 }
 
+// SendTxArgs represents the arguments of eth_sendTransaction and eth_signTransaction.
+// Missing nonce, gas and fee fields are filled in by the node.
+type SendTxArgs struct {
+	From                 common.Address    `json:"from"`
+	To                   *common.Address   `json:"to"`
+	Gas                  *hexutil.Uint64   `json:"gas"`
+	GasPrice             *hexutil.Big      `json:"gasPrice"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas"`
+	Value                *hexutil.Big      `json:"value"`
+	Nonce                *hexutil.Uint64   `json:"nonce"`
+	Data                 *hexutil.Bytes    `json:"data"`
+	Input                *hexutil.Bytes    `json:"input"`
+	AccessList           *types.AccessList `json:"accessList,omitempty"`
+	ChainID              *hexutil.Big      `json:"chainId,omitempty"`
+}
+
+// SignTransactionResult represents a signed transaction: its binary encoding, as accepted by eth_sendRawTransaction, and its fields
+type SignTransactionResult struct {
+	Raw hexutil.Bytes   `json:"raw"`
+	Tx  *RPCTransaction `json:"tx"`
+}
+
 // SendTransaction implements eth_sendTransaction. Creates new message call transaction or a contract creation if the data field contains code.
-func (api *APIImpl) SendTransaction(_ context.Context, txObject interface{}) (common.Hash, error) {
-	return common.Hash{0}, fmt.Errorf(NotImplemented, "eth_sendTransaction")
+// The transaction is signed with the key of an unlocked account from the local keystore.
+func (api *APIImpl) SendTransaction(ctx context.Context, args SendTxArgs) (common.Hash, error) {
+	txn, err := api.signTransaction(ctx, args, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var buf bytes.Buffer
+	if err := txn.MarshalBinary(&buf); err != nil {
+		return common.Hash{}, err
+	}
+	return api.SendRawTransaction(ctx, buf.Bytes())
+}
+
+// SignTransaction implements eth_signTransaction. Signs the transaction with the key of an unlocked account
+// from the local keystore, without submitting it to the transaction pool.
+func (api *APIImpl) SignTransaction(ctx context.Context, args SendTxArgs) (*SignTransactionResult, error) {
+	txn, err := api.signTransaction(ctx, args, nil)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := txn.MarshalBinary(&buf); err != nil {
+		return nil, err
+	}
+	return &SignTransactionResult{Raw: buf.Bytes(), Tx: newRPCTransaction(txn, common.Hash{}, 0, 0, nil)}, nil
+}
+
+// signTransaction fills in the missing fields of the transaction and signs it: with the unlocked key if passphrase is nil,
+// otherwise with the key decrypted by the passphrase
+func (api *APIImpl) signTransaction(ctx context.Context, args SendTxArgs, passphrase *string) (types.Transaction, error) {
+	if api.keystore == nil {
+		return nil, errNoKeystore
+	}
+	cc, err := api.setTxDefaults(ctx, &args)
+	if err != nil {
+		return nil, err
+	}
+	txn, err := args.toTransaction()
+	if err != nil {
+		return nil, err
+	}
+	if passphrase != nil {
+		return api.keystore.SignTxWithPassphrase(args.From, *passphrase, txn, cc.ChainID)
+	}
+	return api.keystore.SignTx(args.From, txn, cc.ChainID)
+}
+
+// setTxDefaults fills in nonce (from the txpool), gas (estimated) and fees (suggested by the gas price oracle)
+// when they are not provided by the caller
+func (api *APIImpl) setTxDefaults(ctx context.Context, args *SendTxArgs) (*params.ChainConfig, error) {
+	if args.Data != nil && args.Input != nil && !bytes.Equal(*args.Data, *args.Input) {
+		return nil, errors.New(`both "data" and "input" are set and not equal. Please use "input" to pass transaction call data`)
+	}
+	if args.Input == nil {
+		args.Input = args.Data
+	}
+	if args.To == nil && (args.Input == nil || len(*args.Input) == 0) {
+		return nil, errors.New(`contract creation without any data provided`)
+	}
+	if args.Value == nil {
+		args.Value = new(hexutil.Big)
+	}
+	cc, err := api.setFeeDefaults(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if args.ChainID != nil && args.ChainID.ToInt().Cmp(cc.ChainID) != 0 {
+		return nil, fmt.Errorf("chainId does not match node's (have=%v, want=%v)", args.ChainID.ToInt(), cc.ChainID)
+	}
+	args.ChainID = (*hexutil.Big)(cc.ChainID)
+	if args.Nonce == nil {
+		nonce, err := api.GetTransactionCount(ctx, args.From, rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber))
+		if err != nil {
+			return nil, err
+		}
+		args.Nonce = nonce
+	}
+	if args.Gas == nil {
+		estimated, err := api.EstimateGas(ctx, &ethapi.CallArgs{
+			From:                 &args.From,
+			To:                   args.To,
+			GasPrice:             args.GasPrice,
+			MaxPriorityFeePerGas: args.MaxPriorityFeePerGas,
+			MaxFeePerGas:         args.MaxFeePerGas,
+			Value:                args.Value,
+			Nonce:                args.Nonce,
+			Data:                 args.Input,
+			AccessList:           args.AccessList,
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		args.Gas = &estimated
+	}
+	return cc, nil
+}
+
+// setFeeDefaults picks the transaction type from the fee fields and fills the missing ones:
+// dynamic fee transaction once London is active, unless gasPrice is given
+func (api *APIImpl) setFeeDefaults(ctx context.Context, args *SendTxArgs) (*params.ChainConfig, error) {
+	if args.GasPrice != nil && (args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil) {
+		return nil, errors.New("both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) specified")
+	}
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	cc, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	if args.GasPrice != nil {
+		return cc, nil
+	}
+	head := rawdb.ReadCurrentHeader(tx)
+	if head == nil {
+		return nil, errors.New("current header not found")
+	}
+	oracle := gasprice.NewOracle(NewGasPriceOracleBackend(tx, cc, api.BaseAPI), ethconfig.Defaults.GPO)
+	if head.BaseFee == nil {
+		if args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil {
+			return nil, errors.New("maxFeePerGas and maxPriorityFeePerGas are not valid before London is active")
+		}
+		price, err := oracle.SuggestTipCap(ctx)
+		if err != nil {
+			return nil, err
+		}
+		args.GasPrice = (*hexutil.Big)(price)
+		return cc, nil
+	}
+	if args.MaxPriorityFeePerGas == nil {
+		tip, err := oracle.SuggestTipCap(ctx)
+		if err != nil {
+			return nil, err
+		}
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tip)
+	}
+	if args.MaxFeePerGas == nil {
+		// Doubling the base fee keeps the transaction includable through several blocks of base fee growth
+		feeCap := new(big.Int).Add(args.MaxPriorityFeePerGas.ToInt(), new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+		args.MaxFeePerGas = (*hexutil.Big)(feeCap)
+	}
+	if args.MaxFeePerGas.ToInt().Cmp(args.MaxPriorityFeePerGas.ToInt()) < 0 {
+		return nil, fmt.Errorf("maxFeePerGas (%v) < maxPriorityFeePerGas (%v)", args.MaxFeePerGas, args.MaxPriorityFeePerGas)
+	}
+	return cc, nil
+}
+
+// toTransaction builds an unsigned transaction from the arguments, all fields must be already set by setTxDefaults
+func (args *SendTxArgs) toTransaction() (types.Transaction, error) {
+	var input []byte
+	if args.Input != nil {
+		input = *args.Input
+	}
+	value, overflow := uint256.FromBig(args.Value.ToInt())
+	if overflow {
+		return nil, errors.New("value overflows uint256")
+	}
+	chainID, overflow := uint256.FromBig(args.ChainID.ToInt())
+	if overflow {
+		return nil, errors.New("chainId overflows uint256")
+	}
+	commonTx := types.CommonTx{
+		To:    args.To,
+		Nonce: uint64(*args.Nonce),
+		Gas:   uint64(*args.Gas),
+		Value: value,
+		Data:  input,
+	}
+	if args.MaxFeePerGas != nil {
+		tip, overflow := uint256.FromBig(args.MaxPriorityFeePerGas.ToInt())
+		if overflow {
+			return nil, errors.New("maxPriorityFeePerGas overflows uint256")
+		}
+		feeCap, overflow := uint256.FromBig(args.MaxFeePerGas.ToInt())
+		if overflow {
+			return nil, errors.New("maxFeePerGas overflows uint256")
+		}
+		accessList := types.AccessList{}
+		if args.AccessList != nil {
+			accessList = *args.AccessList
+		}
+		commonTx.ChainID = chainID
+		return &types.DynamicFeeTransaction{CommonTx: commonTx, Tip: tip, FeeCap: feeCap, AccessList: accessList}, nil
+	}
+	gasPrice, overflow := uint256.FromBig(args.GasPrice.ToInt())
+	if overflow {
+		return nil, errors.New("gasPrice overflows uint256")
+	}
+	if args.AccessList != nil {
+		return &types.AccessListTx{
+			LegacyTx:   types.LegacyTx{CommonTx: commonTx, GasPrice: gasPrice},
+			ChainID:    chainID,
+			AccessList: *args.AccessList,
+		}, nil
+	}
+	return &types.LegacyTx{CommonTx: commonTx, GasPrice: gasPrice}, nil
 }
 
 // checkTxFee is an internal function used to check whether the fee of
@@ -7,10 +7,11 @@ import (
 	"fmt"
 	"math"
 	"math/big"
-	"strings"
+	"sort"
 	"time"
 
 	"github.com/holiman/uint256"
+	jsoniter "github.com/json-iterator/go"
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
@@ -22,6 +23,7 @@ import (
 	"github.com/ledgerwatch/erigon/core/types/accounts"
 	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/shards"
@@ -64,7 +66,7 @@ type TraceCallResult struct {
 	Output          hexutil.Bytes                        `json:"output"`
 	StateDiff       map[common.Address]*StateDiffAccount `json:"stateDiff"`
 	Trace           []*ParityTrace                       `json:"trace"`
-	VmTrace         *VmTrace                             `json:"vmTrace"`
+	VmTrace         json.RawMessage                      `json:"vmTrace"`
 	TransactionHash *common.Hash                         `json:"transactionHash,omitempty"`
 }
 
@@ -96,22 +98,7 @@ type StateDiffStorage struct {
 	To   common.Hash `json:"to"`
 }
 
-// VmTrace is the part of `trace_call` response that is under "vmTrace" tag
-type VmTrace struct {
-	Code hexutil.Bytes `json:"code"`
-	Ops  []*VmTraceOp  `json:"ops"`
-}
-
-// VmTraceOp is one element of the vmTrace ops trace
-type VmTraceOp struct {
-	Cost int        `json:"cost"`
-	Ex   *VmTraceEx `json:"ex"`
-	Pc   int        `json:"pc"`
-	Sub  *VmTrace   `json:"sub"`
-	Op   string     `json:"op,omitempty"`
-	Idx  string     `json:"idx,omitempty"`
-}
-
+// VmTraceEx is the part of the vmTrace operation that is under "ex" tag
 type VmTraceEx struct {
 	Mem   *VmTraceMem   `json:"mem"`
 	Push  []string      `json:"push"`
@@ -222,52 +209,18 @@ func (args *TraceCallParam) ToMessage(globalGasCap uint64, baseFee *uint256.Int)
 
 // OpenEthereum-style tracer
 type OeTracer struct {
-	r            *TraceCallResult
-	traceAddr    []int
-	traceStack   []*ParityTrace
-	precompile   bool // Whether the last CaptureStart was called with `precompile = true`
-	compat       bool // Bug for bug compatibility mode
-	lastVmOp     *VmTraceOp
-	lastOp       vm.OpCode
-	lastMemOff   uint64
-	lastMemLen   uint64
-	memOffStack  []uint64
-	memLenStack  []uint64
-	lastOffStack *VmTraceOp
-	vmOpStack    []*VmTraceOp // Stack of vmTrace operations as call depth increases
-	idx          []string     // Prefix for the "idx" inside operations, for easier navigation
+	r          *TraceCallResult
+	traceAddr  []int
+	traceStack []*ParityTrace
+	precompile bool           // Whether the last CaptureStart was called with `precompile = true`
+	compat     bool           // Bug for bug compatibility mode
+	vmTrace    *vmTraceWriter // Writer of the "vmTrace", nil if it is not requested
 }
 
 func (ot *OeTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, calltype vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
 	//fmt.Printf("CaptureStart depth %d, from %x, to %x, create %t, input %x, gas %d, value %d, precompile %t\n", depth, from, to, create, input, gas, value, precompile)
-	if ot.r.VmTrace != nil {
-		var vmTrace *VmTrace
-		if depth > 0 {
-			var vmT *VmTrace
-			if len(ot.vmOpStack) > 0 {
-				vmT = ot.vmOpStack[len(ot.vmOpStack)-1].Sub
-			} else {
-				vmT = ot.r.VmTrace
-			}
-			if !ot.compat {
-				ot.idx = append(ot.idx, fmt.Sprintf("%d-", len(vmT.Ops)-1))
-			}
-		}
-		if ot.lastVmOp != nil {
-			vmTrace = &VmTrace{Ops: []*VmTraceOp{}}
-			ot.lastVmOp.Sub = vmTrace
-			ot.vmOpStack = append(ot.vmOpStack, ot.lastVmOp)
-		} else {
-			vmTrace = ot.r.VmTrace
-		}
-		if create {
-			vmTrace.Code = common.CopyBytes(input)
-			if ot.lastVmOp != nil {
-				ot.lastVmOp.Cost += int(gas)
-			}
-		} else {
-			vmTrace.Code = code
-		}
+	if ot.vmTrace != nil {
+		ot.vmTrace.captureStart(depth, create, input, gas, code)
 	}
 	if precompile && depth > 0 && value.Sign() <= 0 {
 		ot.precompile = true
@@ -337,20 +290,8 @@ func (ot *OeTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to
 }
 
 func (ot *OeTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, t time.Duration, err error) {
-	if ot.r.VmTrace != nil {
-		if len(ot.vmOpStack) > 0 {
-			ot.lastOffStack = ot.vmOpStack[len(ot.vmOpStack)-1]
-			ot.vmOpStack = ot.vmOpStack[:len(ot.vmOpStack)-1]
-		}
-		if !ot.compat && depth > 0 {
-			ot.idx = ot.idx[:len(ot.idx)-1]
-		}
-		if depth > 0 {
-			ot.lastMemOff = ot.memOffStack[len(ot.memOffStack)-1]
-			ot.memOffStack = ot.memOffStack[:len(ot.memOffStack)-1]
-			ot.lastMemLen = ot.memLenStack[len(ot.memLenStack)-1]
-			ot.memLenStack = ot.memLenStack[:len(ot.memLenStack)-1]
-		}
+	if ot.vmTrace != nil {
+		ot.vmTrace.captureEnd(depth)
 	}
 	if ot.precompile {
 		ot.precompile = false
@@ -422,111 +363,8 @@ func (ot *OeTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64
 }
 
 func (ot *OeTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, opDepth int, err error) {
-	memory := scope.Memory
-	st := scope.Stack
-
-	if ot.r.VmTrace != nil {
-		var vmTrace *VmTrace
-		if len(ot.vmOpStack) > 0 {
-			vmTrace = ot.vmOpStack[len(ot.vmOpStack)-1].Sub
-		} else {
-			vmTrace = ot.r.VmTrace
-		}
-		if ot.lastVmOp != nil && ot.lastVmOp.Ex != nil {
-			// Set the "push" of the last operation
-			var showStack int
-			switch {
-			case ot.lastOp >= vm.PUSH1 && ot.lastOp <= vm.PUSH32:
-				showStack = 1
-			case ot.lastOp >= vm.SWAP1 && ot.lastOp <= vm.SWAP16:
-				showStack = int(ot.lastOp-vm.SWAP1) + 2
-			case ot.lastOp >= vm.DUP1 && ot.lastOp <= vm.DUP16:
-				showStack = int(ot.lastOp-vm.DUP1) + 2
-			}
-			switch ot.lastOp {
-			case vm.CALLDATALOAD, vm.SLOAD, vm.MLOAD, vm.CALLDATASIZE, vm.LT, vm.GT, vm.DIV, vm.SDIV, vm.SAR, vm.AND, vm.EQ, vm.CALLVALUE, vm.ISZERO,
-				vm.ADD, vm.EXP, vm.CALLER, vm.SHA3, vm.SUB, vm.ADDRESS, vm.GAS, vm.MUL, vm.RETURNDATASIZE, vm.NOT, vm.SHR, vm.SHL,
-				vm.EXTCODESIZE, vm.SLT, vm.OR, vm.NUMBER, vm.PC, vm.TIMESTAMP, vm.BALANCE, vm.SELFBALANCE, vm.MULMOD, vm.ADDMOD, vm.BASEFEE,
-				vm.BLOCKHASH, vm.BYTE, vm.XOR, vm.ORIGIN, vm.CODESIZE, vm.MOD, vm.SIGNEXTEND, vm.GASLIMIT, vm.DIFFICULTY, vm.SGT, vm.GASPRICE,
-				vm.MSIZE, vm.EXTCODEHASH, vm.SMOD, vm.CHAINID, vm.COINBASE:
-				showStack = 1
-			}
-			for i := showStack - 1; i >= 0; i-- {
-				ot.lastVmOp.Ex.Push = append(ot.lastVmOp.Ex.Push, st.Back(i).String())
-			}
-			// Set the "mem" of the last operation
-			var setMem bool
-			switch ot.lastOp {
-			case vm.MSTORE, vm.MSTORE8, vm.MLOAD, vm.RETURNDATACOPY, vm.CALLDATACOPY, vm.CODECOPY:
-				setMem = true
-			}
-			if setMem && ot.lastMemLen > 0 {
-				cpy := memory.GetCopy(ot.lastMemOff, ot.lastMemLen)
-				if len(cpy) == 0 {
-					cpy = make([]byte, ot.lastMemLen)
-				}
-				ot.lastVmOp.Ex.Mem = &VmTraceMem{Data: fmt.Sprintf("0x%0x", cpy), Off: int(ot.lastMemOff)}
-			}
-		}
-		if ot.lastOffStack != nil {
-			ot.lastOffStack.Ex.Used = int(gas)
-			ot.lastOffStack.Ex.Push = []string{st.Back(0).String()}
-			if ot.lastMemLen > 0 && memory != nil {
-				cpy := memory.GetCopy(ot.lastMemOff, ot.lastMemLen)
-				if len(cpy) == 0 {
-					cpy = make([]byte, ot.lastMemLen)
-				}
-				ot.lastOffStack.Ex.Mem = &VmTraceMem{Data: fmt.Sprintf("0x%0x", cpy), Off: int(ot.lastMemOff)}
-			}
-			ot.lastOffStack = nil
-		}
-		if ot.lastOp == vm.STOP && op == vm.STOP && len(ot.vmOpStack) == 0 {
-			// Looks like OE is "optimising away" the second STOP
-			return
-		}
-		ot.lastVmOp = &VmTraceOp{Ex: &VmTraceEx{}}
-		vmTrace.Ops = append(vmTrace.Ops, ot.lastVmOp)
-		if !ot.compat {
-			var sb strings.Builder
-			for _, idx := range ot.idx {
-				sb.WriteString(idx)
-			}
-			ot.lastVmOp.Idx = fmt.Sprintf("%s%d", sb.String(), len(vmTrace.Ops)-1)
-		}
-		ot.lastOp = op
-		ot.lastVmOp.Cost = int(cost)
-		ot.lastVmOp.Pc = int(pc)
-		ot.lastVmOp.Ex.Push = []string{}
-		ot.lastVmOp.Ex.Used = int(gas) - int(cost)
-		if !ot.compat {
-			ot.lastVmOp.Op = op.String()
-		}
-		switch op {
-		case vm.MSTORE, vm.MLOAD:
-			ot.lastMemOff = st.Back(0).Uint64()
-			ot.lastMemLen = 32
-		case vm.MSTORE8:
-			ot.lastMemOff = st.Back(0).Uint64()
-			ot.lastMemLen = 1
-		case vm.RETURNDATACOPY, vm.CALLDATACOPY, vm.CODECOPY:
-			ot.lastMemOff = st.Back(0).Uint64()
-			ot.lastMemLen = st.Back(2).Uint64()
-		case vm.STATICCALL, vm.DELEGATECALL:
-			ot.memOffStack = append(ot.memOffStack, st.Back(4).Uint64())
-			ot.memLenStack = append(ot.memLenStack, st.Back(5).Uint64())
-		case vm.CALL, vm.CALLCODE:
-			ot.memOffStack = append(ot.memOffStack, st.Back(5).Uint64())
-			ot.memLenStack = append(ot.memLenStack, st.Back(6).Uint64())
-		case vm.CREATE, vm.CREATE2:
-			// Effectively disable memory output
-			ot.memOffStack = append(ot.memOffStack, 0)
-			ot.memLenStack = append(ot.memLenStack, 0)
-		case vm.SSTORE:
-			ot.lastVmOp.Ex.Store = &VmTraceStore{Key: st.Back(0).String(), Val: st.Back(1).String()}
-		}
-		if ot.lastVmOp.Ex.Used < 0 {
-			ot.lastVmOp.Ex = nil
-		}
+	if ot.vmTrace != nil {
+		ot.vmTrace.captureState(pc, op, gas, cost, scope)
 	}
 }
 
@@ -608,92 +446,130 @@ func (sd *StateDiff) CreateContract(address common.Address) error {
 
 // CompareStates uses the addresses accumulated in the sdMap and compares balances, nonces, and codes of the accounts, and fills the rest of the sdMap
 func (sd *StateDiff) CompareStates(initialIbs, ibs *state.IntraBlockState) {
-	var toRemove []common.Address
 	for addr, accountDiff := range sd.sdMap {
-		initialExist := initialIbs.Exist(addr)
-		exist := ibs.Exist(addr)
-		if initialExist {
-			if exist {
-				var allEqual = len(accountDiff.Storage) == 0
-				fromBalance := initialIbs.GetBalance(addr).ToBig()
-				toBalance := ibs.GetBalance(addr).ToBig()
-				if fromBalance.Cmp(toBalance) == 0 {
-					accountDiff.Balance = "="
-				} else {
-					m := make(map[string]*StateDiffBalance)
-					m["*"] = &StateDiffBalance{From: (*hexutil.Big)(fromBalance), To: (*hexutil.Big)(toBalance)}
-					accountDiff.Balance = m
-					allEqual = false
-				}
-				fromCode := initialIbs.GetCode(addr)
-				toCode := ibs.GetCode(addr)
-				if bytes.Equal(fromCode, toCode) {
-					accountDiff.Code = "="
-				} else {
-					m := make(map[string]*StateDiffCode)
-					m["*"] = &StateDiffCode{From: fromCode, To: toCode}
-					accountDiff.Code = m
-					allEqual = false
-				}
-				fromNonce := initialIbs.GetNonce(addr)
-				toNonce := ibs.GetNonce(addr)
-				if fromNonce == toNonce {
-					accountDiff.Nonce = "="
-				} else {
-					m := make(map[string]*StateDiffNonce)
-					m["*"] = &StateDiffNonce{From: hexutil.Uint64(fromNonce), To: hexutil.Uint64(toNonce)}
-					accountDiff.Nonce = m
-					allEqual = false
-				}
-				if allEqual {
-					toRemove = append(toRemove, addr)
-				}
+		if !compareAccount(initialIbs, ibs, addr, accountDiff) {
+			delete(sd.sdMap, addr)
+		}
+	}
+}
+
+// WriteTo does the same as CompareStates, but writes the accounts into the stream (in the order of json.Marshal)
+// one by one instead of keeping them all in the sdMap
+func (sd *StateDiff) WriteTo(initialIbs, ibs *state.IntraBlockState, stream *jsoniter.Stream) error {
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	addrs := make([]common.Address, 0, len(sd.sdMap))
+	for addr := range sd.sdMap {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	stream.WriteObjectStart()
+	first := true
+	for _, addr := range addrs {
+		accountDiff := sd.sdMap[addr]
+		delete(sd.sdMap, addr)
+		if !compareAccount(initialIbs, ibs, addr, accountDiff) {
+			continue
+		}
+		b, err := json.Marshal(accountDiff)
+		if err != nil {
+			stream.WriteObjectEnd()
+			return err
+		}
+		if first {
+			first = false
+		} else {
+			stream.WriteMore()
+		}
+		stream.WriteObjectField(hexutil.Encode(addr[:]))
+		stream.Write(b)
+	}
+	stream.WriteObjectEnd()
+	return stream.Error
+}
+
+// compareAccount fills the balance, nonce and code of the accountDiff, returns false if the account did not change
+func compareAccount(initialIbs, ibs *state.IntraBlockState, addr common.Address, accountDiff *StateDiffAccount) bool {
+	initialExist := initialIbs.Exist(addr)
+	exist := ibs.Exist(addr)
+	if initialExist {
+		if exist {
+			var allEqual = len(accountDiff.Storage) == 0
+			fromBalance := initialIbs.GetBalance(addr).ToBig()
+			toBalance := ibs.GetBalance(addr).ToBig()
+			if fromBalance.Cmp(toBalance) == 0 {
+				accountDiff.Balance = "="
 			} else {
-				{
-					m := make(map[string]*hexutil.Big)
-					m["-"] = (*hexutil.Big)(initialIbs.GetBalance(addr).ToBig())
-					accountDiff.Balance = m
-				}
-				{
-					m := make(map[string]hexutil.Bytes)
-					m["-"] = initialIbs.GetCode(addr)
-					accountDiff.Code = m
-				}
-				{
-					m := make(map[string]hexutil.Uint64)
-					m["-"] = hexutil.Uint64(initialIbs.GetNonce(addr))
-					accountDiff.Nonce = m
-				}
+				m := make(map[string]*StateDiffBalance)
+				m["*"] = &StateDiffBalance{From: (*hexutil.Big)(fromBalance), To: (*hexutil.Big)(toBalance)}
+				accountDiff.Balance = m
+				allEqual = false
+			}
+			fromCode := initialIbs.GetCode(addr)
+			toCode := ibs.GetCode(addr)
+			if bytes.Equal(fromCode, toCode) {
+				accountDiff.Code = "="
+			} else {
+				m := make(map[string]*StateDiffCode)
+				m["*"] = &StateDiffCode{From: fromCode, To: toCode}
+				accountDiff.Code = m
+				allEqual = false
+			}
+			fromNonce := initialIbs.GetNonce(addr)
+			toNonce := ibs.GetNonce(addr)
+			if fromNonce == toNonce {
+				accountDiff.Nonce = "="
+			} else {
+				m := make(map[string]*StateDiffNonce)
+				m["*"] = &StateDiffNonce{From: hexutil.Uint64(fromNonce), To: hexutil.Uint64(toNonce)}
+				accountDiff.Nonce = m
+				allEqual = false
+			}
+			if allEqual {
+				return false
 			}
-		} else if exist {
+		} else {
 			{
 				m := make(map[string]*hexutil.Big)
-				m["+"] = (*hexutil.Big)(ibs.GetBalance(addr).ToBig())
+				m["-"] = (*hexutil.Big)(initialIbs.GetBalance(addr).ToBig())
 				accountDiff.Balance = m
 			}
 			{
 				m := make(map[string]hexutil.Bytes)
-				m["+"] = ibs.GetCode(addr)
+				m["-"] = initialIbs.GetCode(addr)
 				accountDiff.Code = m
 			}
 			{
 				m := make(map[string]hexutil.Uint64)
-				m["+"] = hexutil.Uint64(ibs.GetNonce(addr))
+				m["-"] = hexutil.Uint64(initialIbs.GetNonce(addr))
 				accountDiff.Nonce = m
 			}
-			// Transform storage
-			for _, sm := range accountDiff.Storage {
-				str := sm["*"].(*StateDiffStorage)
-				delete(sm, "*")
-				sm["+"] = &str.To
-			}
-		} else {
-			toRemove = append(toRemove, addr)
 		}
+	} else if exist {
+		{
+			m := make(map[string]*hexutil.Big)
+			m["+"] = (*hexutil.Big)(ibs.GetBalance(addr).ToBig())
+			accountDiff.Balance = m
+		}
+		{
+			m := make(map[string]hexutil.Bytes)
+			m["+"] = ibs.GetCode(addr)
+			accountDiff.Code = m
+		}
+		{
+			m := make(map[string]hexutil.Uint64)
+			m["+"] = hexutil.Uint64(ibs.GetNonce(addr))
+			accountDiff.Nonce = m
+		}
+		// Transform storage
+		for _, sm := range accountDiff.Storage {
+			str := sm["*"].(*StateDiffStorage)
+			delete(sm, "*")
+			sm["+"] = &str.To
+		}
+	} else {
+		return false
 	}
-	for _, addr := range toRemove {
-		delete(sd.sdMap, addr)
-	}
+	return true
 }
 
 func (api *TraceAPIImpl) ReplayTransaction(ctx context.Context, txHash common.Hash, traceTypes []string) (*TraceCallResult, error) {
@@ -737,7 +613,7 @@ func (api *TraceAPIImpl) ReplayTransaction(ctx context.Context, txHash common.Ha
 	}
 
 	// Returns an array of trace arrays, one trace array for each transaction
-	traces, err := api.callManyTransactions(ctx, tx, block.Transactions(), traceTypes, block.ParentHash(), rpc.BlockNumber(parentNr), block.Header(), int(txnIndex), types.MakeSigner(chainConfig, blockNum), chainConfig.Rules(blockNum))
+	traces, err := api.callManyTransactions(ctx, tx, block.Transactions(), traceTypes, block.ParentHash(), rpc.BlockNumber(parentNr), block.Header(), int(txnIndex), types.MakeSigner(chainConfig, blockNum), chainConfig.Rules(blockNum, block.Time()), nil /* stream */)
 	if err != nil {
 		return nil, err
 	}
@@ -778,20 +654,25 @@ func (api *TraceAPIImpl) ReplayTransaction(ctx context.Context, txHash common.Ha
 
 }
 
-func (api *TraceAPIImpl) ReplayBlockTransactions(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, traceTypes []string) ([]*TraceCallResult, error) {
+// ReplayBlockTransactions implements trace_replayBlockTransactions. The result of every transaction is written into the
+// stream as soon as it is replayed, so that vmTrace and stateDiff of big blocks are not kept in memory
+func (api *TraceAPIImpl) ReplayBlockTransactions(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, traceTypes []string, stream *jsoniter.Stream) error {
 	tx, err := api.kv.BeginRo(ctx)
 	if err != nil {
-		return nil, err
+		stream.WriteNil()
+		return err
 	}
 	defer tx.Rollback()
 	chainConfig, err := api.chainConfig(tx)
 	if err != nil {
-		return nil, err
+		stream.WriteNil()
+		return err
 	}
 
 	blockNumber, _, _, err := rpchelper.GetBlockNumber(blockNrOrHash, tx, api.filters)
 	if err != nil {
-		return nil, err
+		stream.WriteNil()
+		return err
 	}
 
 	parentNr := blockNumber
@@ -801,52 +682,31 @@ func (api *TraceAPIImpl) ReplayBlockTransactions(ctx context.Context, blockNrOrH
 	// Extract transactions from block
 	block, bErr := api.blockByNumberWithSenders(tx, blockNumber)
 	if bErr != nil {
-		return nil, bErr
+		stream.WriteNil()
+		return bErr
 	}
 	if block == nil {
-		return nil, fmt.Errorf("could not find block  %d", blockNumber)
+		stream.WriteNil()
+		return fmt.Errorf("could not find block  %d", blockNumber)
 	}
-	var traceTypeTrace, traceTypeStateDiff, traceTypeVmTrace bool
 	for _, traceType := range traceTypes {
 		switch traceType {
-		case TraceTypeTrace:
-			traceTypeTrace = true
-		case TraceTypeStateDiff:
-			traceTypeStateDiff = true
-		case TraceTypeVmTrace:
-			traceTypeVmTrace = true
+		case TraceTypeTrace, TraceTypeStateDiff, TraceTypeVmTrace:
 		default:
-			return nil, fmt.Errorf("unrecognized trace type: %s", traceType)
+			stream.WriteNil()
+			return fmt.Errorf("unrecognized trace type: %s", traceType)
 		}
 	}
 
-	// Returns an array of trace arrays, one trace array for each transaction
-	traces, err := api.callManyTransactions(ctx, tx, block.Transactions(), traceTypes, block.ParentHash(), rpc.BlockNumber(parentNr), block.Header(), -1 /* all tx indices */, types.MakeSigner(chainConfig, blockNumber), chainConfig.Rules(blockNumber))
-	if err != nil {
-		return nil, err
+	stream.WriteArrayStart()
+	// Writes an array of trace results, one for each transaction
+	if _, err = api.callManyTransactions(ctx, tx, block.Transactions(), traceTypes, block.ParentHash(), rpc.BlockNumber(parentNr), block.Header(), -1 /* all tx indices */, types.MakeSigner(chainConfig, blockNumber), chainConfig.Rules(blockNumber, block.Time()), stream); err != nil {
+		// results of the transactions replayed so far are already written, the error follows them
+		stream.WriteArrayEnd()
+		return err
 	}
-
-	result := make([]*TraceCallResult, len(traces))
-	for i, trace := range traces {
-		tr := &TraceCallResult{}
-		tr.Output = trace.Output
-		if traceTypeTrace {
-			tr.Trace = trace.Trace
-		} else {
-			tr.Trace = []*ParityTrace{}
-		}
-		if traceTypeStateDiff {
-			tr.StateDiff = trace.StateDiff
-		}
-		if traceTypeVmTrace {
-			tr.VmTrace = trace.VmTrace
-		}
-		result[i] = tr
-		txhash := block.Transactions()[i].Hash()
-		tr.TransactionHash = &txhash
-	}
-
-	return result, nil
+	stream.WriteArrayEnd()
+	return stream.Flush()
 }
 
 // Call implements trace_call.
@@ -919,15 +779,15 @@ func (api *TraceAPIImpl) Call(ctx context.Context, args TraceCallParam, traceTyp
 			return nil, fmt.Errorf("unrecognized trace type: %s", traceType)
 		}
 	}
-	if traceTypeVmTrace {
-		traceResult.VmTrace = &VmTrace{Ops: []*VmTraceOp{}}
-	}
 	var ot OeTracer
 	ot.compat = api.compatibility
 	if traceTypeTrace || traceTypeVmTrace {
 		ot.r = traceResult
 		ot.traceAddr = []int{}
 	}
+	if traceTypeVmTrace {
+		ot.vmTrace = newVmTraceWriter(vmTraceBuffer(), ot.compat, nil)
+	}
 
 	// Get a new instance of the EVM.
 	var baseFee *uint256.Int
@@ -968,6 +828,11 @@ func (api *TraceAPIImpl) Call(ctx context.Context, args TraceCallParam, traceTyp
 		return nil, err
 	}
 	traceResult.Output = common.CopyBytes(execResult.ReturnData)
+	if ot.vmTrace != nil {
+		if traceResult.VmTrace, err = rawVmTrace(ot.vmTrace); err != nil {
+			return nil, err
+		}
+	}
 	if traceTypeStateDiff {
 		sdMap := make(map[common.Address]*StateDiffAccount)
 		traceResult.StateDiff = sdMap
@@ -1069,11 +934,13 @@ func (api *TraceAPIImpl) CallMany(ctx context.Context, calls json.RawMessage, pa
 			return nil, fmt.Errorf("convert callParam to msg: %w", err)
 		}
 	}
-	return api.doCallMany(ctx, dbtx, msgs, callParams, parentNrOrHash, nil, true /* gasBailout */, -1 /* all tx indices */)
+	return api.doCallMany(ctx, dbtx, msgs, callParams, parentNrOrHash, nil, true /* gasBailout */, -1 /* all tx indices */, nil /* stream */)
 }
 
+// doCallMany executes the messages one after another and traces them. If the stream is given, the results are written
+// into it as the elements of a JSON array (without the array brackets) while the messages are executed, and nil is returned
 func (api *TraceAPIImpl) doCallMany(ctx context.Context, dbtx kv.Tx, msgs []types.Message, callParams []TraceCallParam, parentNrOrHash *rpc.BlockNumberOrHash, header *types.Header,
-	gasBailout bool, txIndexNeeded int) ([]*TraceCallResult, error) {
+	gasBailout bool, txIndexNeeded int, stream *jsoniter.Stream) ([]*TraceCallResult, error) {
 	chainConfig, err := api.chainConfig(dbtx)
 	if err != nil {
 		return nil, err
@@ -1137,6 +1004,14 @@ func (api *TraceAPIImpl) doCallMany(ctx context.Context, dbtx kv.Tx, msgs []type
 		contractHasTEVM = ethdb.GetHasTEVM(dbtx)
 	}
 
+	// The result of a transaction is written into the stream while it is replayed. If replaying fails, closeResult
+	// closes the result written so far, so that the stream stays valid JSON and the error can follow the results
+	closeResult := func(vmTrace *vmTraceWriter) {
+		if vmTrace != nil {
+			_ = vmTrace.finish()
+		}
+		stream.WriteObjectEnd()
+	}
 	for txIndex, msg := range msgs {
 		if err := libcommon.Stopped(ctx.Done()); err != nil {
 			return nil, err
@@ -1156,17 +1031,31 @@ func (api *TraceAPIImpl) doCallMany(ctx context.Context, dbtx kv.Tx, msgs []type
 				return nil, fmt.Errorf("unrecognized trace type: %s", traceType)
 			}
 		}
+		if stream != nil {
+			if txIndex > 0 {
+				stream.WriteMore()
+			}
+			stream.WriteObjectStart()
+			// vmTrace goes first, it is written while the message is executed
+			stream.WriteObjectField("vmTrace")
+			if !traceTypeVmTrace {
+				stream.WriteNil()
+			}
+		}
 		vmConfig := vm.Config{}
+		var ot OeTracer
 		if (traceTypeTrace && (txIndexNeeded == -1 || txIndex == txIndexNeeded)) || traceTypeVmTrace {
-			var ot OeTracer
 			ot.compat = api.compatibility
 			ot.r = traceResult
-			ot.idx = []string{fmt.Sprintf("%d-", txIndex)}
 			if traceTypeTrace && (txIndexNeeded == -1 || txIndex == txIndexNeeded) {
 				ot.traceAddr = []int{}
 			}
 			if traceTypeVmTrace {
-				traceResult.VmTrace = &VmTrace{Ops: []*VmTraceOp{}}
+				vmStream := stream
+				if vmStream == nil {
+					vmStream = vmTraceBuffer()
+				}
+				ot.vmTrace = newVmTraceWriter(vmStream, ot.compat, []string{fmt.Sprintf("%d-", txIndex)})
 			}
 			vmConfig.Debug = true
 			vmConfig.Tracer = &ot
@@ -1198,39 +1087,148 @@ func (api *TraceAPIImpl) doCallMany(ctx context.Context, dbtx kv.Tx, msgs []type
 		}
 		execResult, err = core.ApplyMessage(evm, msg, gp, true /* refunds */, gasBailout /* gasBailout */)
 		if err != nil {
+			if stream != nil {
+				closeResult(ot.vmTrace)
+			}
 			return nil, fmt.Errorf("first run for txIndex %d error: %w", txIndex, err)
 		}
 		traceResult.Output = common.CopyBytes(execResult.ReturnData)
+		if ot.vmTrace != nil {
+			if stream != nil {
+				if err = ot.vmTrace.finish(); err != nil {
+					return nil, err
+				}
+			} else if traceResult.VmTrace, err = rawVmTrace(ot.vmTrace); err != nil {
+				return nil, err
+			}
+		}
+		if stream != nil {
+			stream.WriteMore()
+			stream.WriteObjectField("output")
+			stream.WriteString(hexutil.Encode(traceResult.Output))
+		}
 		if traceTypeStateDiff {
 			initialIbs := state.New(cloneReader)
 			sdMap := make(map[common.Address]*StateDiffAccount)
-			traceResult.StateDiff = sdMap
 			sd := &StateDiff{sdMap: sdMap}
 			if err = ibs.FinalizeTx(evm.ChainRules(), sd); err != nil {
+				if stream != nil {
+					closeResult(nil)
+				}
 				return nil, err
 			}
-			sd.CompareStates(initialIbs, ibs)
+			if stream != nil {
+				stream.WriteMore()
+				stream.WriteObjectField("stateDiff")
+				if err = sd.WriteTo(initialIbs, ibs, stream); err != nil {
+					closeResult(nil)
+					return nil, err
+				}
+			} else {
+				sd.CompareStates(initialIbs, ibs)
+				traceResult.StateDiff = sdMap
+			}
 			if err = ibs.CommitBlock(evm.ChainRules(), cachedWriter); err != nil {
+				if stream != nil {
+					closeResult(nil)
+				}
 				return nil, err
 			}
 		} else {
+			if stream != nil {
+				stream.WriteMore()
+				stream.WriteObjectField("stateDiff")
+				stream.WriteNil()
+			}
 			if err = ibs.FinalizeTx(evm.ChainRules(), noop); err != nil {
+				if stream != nil {
+					closeResult(nil)
+				}
 				return nil, err
 			}
 			if err = ibs.CommitBlock(evm.ChainRules(), cachedWriter); err != nil {
+				if stream != nil {
+					closeResult(nil)
+				}
 				return nil, err
 			}
 		}
 		if !traceTypeTrace {
 			traceResult.Trace = []*ParityTrace{}
 		}
+		if stream != nil {
+			var json = jsoniter.ConfigCompatibleWithStandardLibrary
+			b, err := json.Marshal(traceResult.Trace)
+			if err != nil {
+				closeResult(nil)
+				return nil, err
+			}
+			stream.WriteMore()
+			stream.WriteObjectField("trace")
+			stream.Write(b)
+			if args.txHash != nil {
+				stream.WriteMore()
+				stream.WriteObjectField("transactionHash")
+				stream.WriteString(args.txHash.Hex())
+			}
+			stream.WriteObjectEnd()
+			if err = stream.Flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		results = append(results, traceResult)
 	}
+	if stream != nil {
+		return nil, nil
+	}
 	return results, nil
 }
 
 // RawTransaction implements trace_rawTransaction.
-func (api *TraceAPIImpl) RawTransaction(ctx context.Context, txHash common.Hash, traceTypes []string) ([]interface{}, error) {
-	var stub []interface{}
-	return stub, fmt.Errorf(NotImplemented, "trace_rawTransaction")
+func (api *TraceAPIImpl) RawTransaction(ctx context.Context, encodedTx hexutil.Bytes, traceTypes []string) (*TraceCallResult, error) {
+	txn, err := types.DecodeTransaction(rlp.NewStream(bytes.NewReader(encodedTx), uint64(len(encodedTx))))
+	if err != nil {
+		return nil, err
+	}
+
+	dbtx, err := api.kv.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer dbtx.Rollback()
+
+	chainConfig, err := api.chainConfig(dbtx)
+	if err != nil {
+		return nil, err
+	}
+	// The transaction is executed on top of the latest state and in the block context of the latest block (as trace_call
+	// on "latest" does), only signature and fields are validated by the rules of the next block it could be included in
+	var num = rpc.LatestBlockNumber
+	parentNrOrHash := &rpc.BlockNumberOrHash{BlockNumber: &num}
+	blockNumber, hash, _, err := rpchelper.GetBlockNumber(*parentNrOrHash, dbtx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	parentHeader, err := api._blockReader.Header(ctx, dbtx, hash, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if parentHeader == nil {
+		return nil, fmt.Errorf("parent header %d(%x) not found", blockNumber, hash)
+	}
+
+	signer := types.MakeSigner(chainConfig, blockNumber+1)
+	msg, err := txn.AsMessage(*signer, parentHeader.BaseFee, chainConfig.Rules(blockNumber+1, parentHeader.Time))
+	if err != nil {
+		return nil, fmt.Errorf("convert tx into msg: %w", err)
+	}
+
+	txHash := txn.Hash()
+	callParams := []TraceCallParam{{txHash: &txHash, traceTypes: traceTypes}}
+	traces, err := api.doCallMany(ctx, dbtx, []types.Message{msg}, callParams, parentNrOrHash, nil, false /* gasBailout */, -1 /* all tx indices */, nil /* stream */)
+	if err != nil {
+		return nil, err
+	}
+	return traces[0], nil
 }
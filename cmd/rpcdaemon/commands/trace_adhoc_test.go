@@ -1,10 +1,13 @@
 package commands
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"testing"
 
+	"github.com/holiman/uint256"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli/httpcfg"
@@ -12,6 +15,9 @@ import (
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 	"github.com/stretchr/testify/require"
@@ -96,13 +102,181 @@ func TestReplayBlockTransactions(t *testing.T) {
 
 	// Call GetTransactionReceipt for transaction which is not in the database
 	n := rpc.BlockNumber(6)
-	results, err := api.ReplayBlockTransactions(context.Background(), rpc.BlockNumberOrHash{BlockNumber: &n}, []string{"stateDiff"})
+	stream := jsoniter.ConfigDefault.BorrowStream(nil)
+	defer jsoniter.ConfigDefault.ReturnStream(stream)
+	err := api.ReplayBlockTransactions(context.Background(), rpc.BlockNumberOrHash{BlockNumber: &n}, []string{"stateDiff"}, stream)
 	if err != nil {
 		t.Errorf("calling ReplayBlockTransactions: %v", err)
 	}
-	require.NotNil(t, results)
+	var results []struct {
+		StateDiff map[common.Address]struct {
+			Balance map[string]*hexutil.Big `json:"balance"`
+		} `json:"stateDiff"`
+	}
+	require.NoError(t, json.Unmarshal(stream.Buffer(), &results))
+	require.NotEmpty(t, results)
 	require.NotNil(t, results[0].StateDiff)
 	addrDiff := results[0].StateDiff[common.HexToAddress("0x0000000000000001000000000000000000000000")]
-	v := addrDiff.Balance.(map[string]*hexutil.Big)["+"].ToInt().Uint64()
+	v := addrDiff.Balance["+"].ToInt().Uint64()
 	require.Equal(t, uint64(1_000_000_000_000_000), v)
 }
+
+func TestReplayBlockTransactionsVmTrace(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewTraceAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), false), db, &httpcfg.HttpCfg{})
+
+	for _, n := range []rpc.BlockNumber{1, 3, 6, 10} {
+		n := n
+		stream := jsoniter.ConfigDefault.BorrowStream(nil)
+		err := api.ReplayBlockTransactions(context.Background(), rpc.BlockNumberOrHash{BlockNumber: &n}, []string{"trace", "vmTrace", "stateDiff"}, stream)
+		require.NoError(t, err)
+		var results []*TraceCallResult
+		require.NoError(t, json.Unmarshal(stream.Buffer(), &results), "block %d", n)
+		jsoniter.ConfigDefault.ReturnStream(stream)
+		require.NotEmpty(t, results)
+		// Streamed results have to be the same as the ones built in memory
+		for _, result := range results {
+			require.NotNil(t, result.TransactionHash)
+			expected, err := api.ReplayTransaction(context.Background(), *result.TransactionHash, []string{"trace", "vmTrace", "stateDiff"})
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected.VmTrace), string(result.VmTrace))
+			require.Equal(t, len(expected.Trace), len(result.Trace))
+			require.Equal(t, len(expected.StateDiff), len(result.StateDiff))
+		}
+	}
+}
+
+// cancelOnWrite cancels the context once the first result is written into it
+type cancelOnWrite struct {
+	bytes.Buffer
+	cancel context.CancelFunc
+}
+
+func (w *cancelOnWrite) Write(p []byte) (int, error) {
+	w.cancel()
+	return w.Buffer.Write(p)
+}
+
+func TestReplayBlockTransactionsFailure(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewTraceAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), false), db, &httpcfg.HttpCfg{})
+
+	// block with more than one transaction, the replay is stopped after the first one
+	var n rpc.BlockNumber
+	require.NoError(t, db.View(context.Background(), func(tx kv.Tx) error {
+		for i := uint64(1); ; i++ {
+			block, err := rawdb.ReadBlockByNumber(tx, i)
+			require.NoError(t, err)
+			require.NotNil(t, block, "no block with several transactions")
+			if block.Transactions().Len() > 1 {
+				n = rpc.BlockNumber(i)
+				return nil
+			}
+		}
+	}))
+
+	for _, traceTypes := range [][]string{{"trace"}, {"trace", "vmTrace", "stateDiff"}} {
+		ctx, cancel := context.WithCancel(context.Background())
+		out := &cancelOnWrite{cancel: cancel}
+		stream := jsoniter.NewStream(jsoniter.ConfigDefault, out, 4096)
+		err := api.ReplayBlockTransactions(ctx, rpc.BlockNumberOrHash{BlockNumber: &n}, traceTypes, stream)
+		require.Error(t, err)
+		require.NoError(t, stream.Flush())
+
+		// the response stays valid JSON, with the results of the transactions replayed before the failure
+		var results []*TraceCallResult
+		require.NoError(t, json.Unmarshal(out.Bytes(), &results), "%v: %s", traceTypes, out.Bytes())
+		require.Len(t, results, 1, "%v", traceTypes)
+		require.NotNil(t, results[0].TransactionHash)
+	}
+}
+
+func TestReplayTransactionsFailureInTransaction(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewTraceAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), false), db, &httpcfg.HttpCfg{})
+	ctx := context.Background()
+	tx, err := db.BeginRo(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// the second transaction is sent from an account without funds, it fails once its result is started
+	var (
+		rich     = common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+		poor     = common.HexToAddress("0x000000000000000000000000000000000000dead")
+		to       = common.HexToAddress("0x0d3ab14bbad3d99f4203bd7a11acb94882050e7e")
+		gas      = hexutil.Uint64(0x15f90)
+		gasPrice = (*hexutil.Big)(common.Big1)
+	)
+	traceTypes := []string{TraceTypeTrace, TraceTypeStateDiff, TraceTypeVmTrace}
+	callParams := []TraceCallParam{
+		{From: &rich, To: &to, Gas: &gas, GasPrice: gasPrice, traceTypes: traceTypes},
+		{From: &poor, To: &to, Gas: &gas, GasPrice: gasPrice, traceTypes: traceTypes},
+	}
+	msgs := make([]types.Message, len(callParams))
+	for i := range callParams {
+		msgs[i], err = callParams[i].ToMessage(api.gasCap, nil)
+		require.NoError(t, err)
+	}
+
+	var out bytes.Buffer
+	stream := jsoniter.NewStream(jsoniter.ConfigDefault, &out, 4096)
+	var latest = rpc.LatestBlockNumber
+	stream.WriteArrayStart()
+	_, err = api.doCallMany(ctx, tx, msgs, callParams, &rpc.BlockNumberOrHash{BlockNumber: &latest}, nil, false /* gasBailout */, -1 /* all tx indices */, stream)
+	require.Error(t, err)
+	stream.WriteArrayEnd()
+	require.NoError(t, stream.Flush())
+
+	// the result of the failed transaction is closed after the part of it written before the failure
+	var results []*TraceCallResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &results), "%s", out.Bytes())
+	require.Len(t, results, 2)
+	require.NotEmpty(t, results[0].StateDiff)
+	require.NotNil(t, results[1].VmTrace)
+	require.Nil(t, results[1].StateDiff)
+}
+
+func TestRawTransaction(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewTraceAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), false), db, &httpcfg.HttpCfg{})
+
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x0d3ab14bbad3d99f4203bd7a11acb94882050e7e")
+	var nonce uint64
+	if err := db.View(context.Background(), func(tx kv.Tx) error {
+		acc, err := state.NewPlainStateReader(tx).ReadAccountData(from)
+		if err != nil {
+			return err
+		}
+		nonce = acc.Nonce
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	txn, err := types.SignTx(types.NewTransaction(nonce, to, uint256.NewInt(1), 21000, uint256.NewInt(20_000_000_000), nil), *types.LatestSignerForChainID(nil), key)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, txn.MarshalBinary(&buf))
+
+	results, err := api.RawTransaction(context.Background(), buf.Bytes(), []string{"trace", "stateDiff", "vmTrace"})
+	require.NoError(t, err)
+	require.NotNil(t, results)
+	require.Len(t, results.Trace, 1)
+	require.Equal(t, from, results.Trace[0].Action.(*CallTraceAction).From)
+	require.NotNil(t, results.VmTrace)
+	require.NotNil(t, results.StateDiff[from])
+	require.NotNil(t, results.StateDiff[to])
+
+	// Transactions with a wrong nonce are rejected
+	txn, err = types.SignTx(types.NewTransaction(nonce+1, to, uint256.NewInt(1), 21000, uint256.NewInt(20_000_000_000), nil), *types.LatestSignerForChainID(nil), key)
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, txn.MarshalBinary(&buf))
+	_, err = api.RawTransaction(context.Background(), buf.Bytes(), []string{"trace"})
+	require.Error(t, err)
+}
@@ -0,0 +1,360 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/vm"
+)
+
+// vmTraceFlushSize - the stream is flushed after an operation once its buffer is bigger than this
+const vmTraceFlushSize = 64 * 1024
+
+// vmTraceWriter builds the "vmTrace" of OeTracer and writes it into the stream while the transaction is executed.
+// Parts of an operation ("push", "mem", "used") are only known when the next operation starts, or when the sub-call
+// it made returns, so the writer holds back the last operation of every call frame, memory does not grow with
+// the number of operations. Operations which made a sub-call are written as {"cost","pc","op","idx","sub","ex"}
+// because their "ex" is only known after the sub-call, other ones are written in the order of OpenEthereum
+type vmTraceWriter struct {
+	stream       *jsoniter.Stream
+	compat       bool           // Bug for bug compatibility mode
+	frames       []vmTraceFrame // Call frames which "ops" are being written, innermost last
+	ended        int            // Number of innermost frames which returned, but are not closed in the stream yet
+	started      bool
+	finished     bool
+	lastVmOp     *vmTraceOp
+	lastOp       vm.OpCode
+	lastMemOff   uint64
+	lastMemLen   uint64
+	memOffStack  []uint64
+	memLenStack  []uint64
+	lastOffStack *vmTraceOp
+	idx          []string // Prefix for the "idx" inside operations, for easier navigation
+}
+
+type vmTraceFrame struct {
+	ops  int        // Number of operations in the frame
+	call *vmTraceOp // Operation of the parent frame which made the call, nil for the top frame
+}
+
+type vmTraceOp struct {
+	cost    int
+	pc      int
+	op      string
+	idx     string
+	ex      *VmTraceEx
+	n       int  // Position in the frame
+	written bool // Everything but "ex" is written (the operation made a sub-call), or the whole operation is written
+}
+
+func newVmTraceWriter(stream *jsoniter.Stream, compat bool, idx []string) *vmTraceWriter {
+	return &vmTraceWriter{stream: stream, compat: compat, idx: idx}
+}
+
+func (w *vmTraceWriter) captureStart(depth int, create bool, input []byte, gas uint64, code []byte) {
+	// Calls are only made by operations, so the frames which returned are already closed by captureState here
+	if depth > 0 && len(w.frames) > 0 && !w.compat {
+		w.idx = append(w.idx, fmt.Sprintf("%d-", w.frames[len(w.frames)-1].ops-1))
+	}
+	if create {
+		code = input
+		if w.lastVmOp != nil {
+			w.lastVmOp.cost += int(gas)
+		}
+	}
+	if w.lastVmOp != nil && !w.lastVmOp.written {
+		w.writeOpHead(w.lastVmOp)
+		w.stream.WriteObjectField("sub")
+		w.frames = append(w.frames, vmTraceFrame{call: w.lastVmOp})
+	} else {
+		w.frames = append(w.frames, vmTraceFrame{})
+	}
+	w.started = true
+	w.stream.WriteObjectStart()
+	w.stream.WriteObjectField("code")
+	w.stream.WriteString(hexutil.Encode(code))
+	w.stream.WriteMore()
+	w.stream.WriteObjectField("ops")
+	w.stream.WriteArrayStart()
+}
+
+func (w *vmTraceWriter) captureEnd(depth int) {
+	if call := w.frames[len(w.frames)-1-w.ended].call; call != nil {
+		w.lastOffStack = call
+	}
+	w.ended++
+	if !w.compat && depth > 0 {
+		w.idx = w.idx[:len(w.idx)-1]
+	}
+	if depth > 0 {
+		w.lastMemOff = w.memOffStack[len(w.memOffStack)-1]
+		w.memOffStack = w.memOffStack[:len(w.memOffStack)-1]
+		w.lastMemLen = w.memLenStack[len(w.memLenStack)-1]
+		w.memLenStack = w.memLenStack[:len(w.memLenStack)-1]
+	}
+}
+
+func (w *vmTraceWriter) captureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext) {
+	memory := scope.Memory
+	st := scope.Stack
+
+	if w.lastVmOp != nil && w.lastVmOp.ex != nil {
+		// Set the "push" of the last operation
+		var showStack int
+		switch {
+		case w.lastOp >= vm.PUSH1 && w.lastOp <= vm.PUSH32:
+			showStack = 1
+		case w.lastOp >= vm.SWAP1 && w.lastOp <= vm.SWAP16:
+			showStack = int(w.lastOp-vm.SWAP1) + 2
+		case w.lastOp >= vm.DUP1 && w.lastOp <= vm.DUP16:
+			showStack = int(w.lastOp-vm.DUP1) + 2
+		}
+		switch w.lastOp {
+		case vm.CALLDATALOAD, vm.SLOAD, vm.MLOAD, vm.CALLDATASIZE, vm.LT, vm.GT, vm.DIV, vm.SDIV, vm.SAR, vm.AND, vm.EQ, vm.CALLVALUE, vm.ISZERO,
+			vm.ADD, vm.EXP, vm.CALLER, vm.SHA3, vm.SUB, vm.ADDRESS, vm.GAS, vm.MUL, vm.RETURNDATASIZE, vm.NOT, vm.SHR, vm.SHL,
+			vm.EXTCODESIZE, vm.SLT, vm.OR, vm.NUMBER, vm.PC, vm.TIMESTAMP, vm.BALANCE, vm.SELFBALANCE, vm.MULMOD, vm.ADDMOD, vm.BASEFEE,
+			vm.BLOCKHASH, vm.BYTE, vm.XOR, vm.ORIGIN, vm.CODESIZE, vm.MOD, vm.SIGNEXTEND, vm.GASLIMIT, vm.DIFFICULTY, vm.SGT, vm.GASPRICE,
+			vm.MSIZE, vm.EXTCODEHASH, vm.SMOD, vm.CHAINID, vm.COINBASE:
+			showStack = 1
+		}
+		for i := showStack - 1; i >= 0; i-- {
+			w.lastVmOp.ex.Push = append(w.lastVmOp.ex.Push, st.Back(i).String())
+		}
+		// Set the "mem" of the last operation
+		var setMem bool
+		switch w.lastOp {
+		case vm.MSTORE, vm.MSTORE8, vm.MLOAD, vm.RETURNDATACOPY, vm.CALLDATACOPY, vm.CODECOPY:
+			setMem = true
+		}
+		if setMem && w.lastMemLen > 0 {
+			cpy := memory.GetCopy(w.lastMemOff, w.lastMemLen)
+			if len(cpy) == 0 {
+				cpy = make([]byte, w.lastMemLen)
+			}
+			w.lastVmOp.ex.Mem = &VmTraceMem{Data: fmt.Sprintf("0x%0x", cpy), Off: int(w.lastMemOff)}
+		}
+	}
+	if w.lastOffStack != nil {
+		if ex := w.lastOffStack.ex; ex != nil {
+			ex.Used = int(gas)
+			ex.Push = []string{st.Back(0).String()}
+			if w.lastMemLen > 0 && memory != nil {
+				cpy := memory.GetCopy(w.lastMemOff, w.lastMemLen)
+				if len(cpy) == 0 {
+					cpy = make([]byte, w.lastMemLen)
+				}
+				ex.Mem = &VmTraceMem{Data: fmt.Sprintf("0x%0x", cpy), Off: int(w.lastMemOff)}
+			}
+		}
+		w.lastOffStack = nil
+	}
+	// The last operation is complete now, as well as the operations which made the calls that returned
+	w.closeEnded()
+	if len(w.stream.Buffer()) > vmTraceFlushSize {
+		_ = w.stream.Flush()
+	}
+
+	if w.lastOp == vm.STOP && op == vm.STOP && len(w.frames) == 1 {
+		// Looks like OE is "optimising away" the second STOP
+		return
+	}
+	frame := &w.frames[len(w.frames)-1]
+	w.lastVmOp = &vmTraceOp{ex: &VmTraceEx{}, n: frame.ops}
+	frame.ops++
+	if !w.compat {
+		var sb strings.Builder
+		for _, idx := range w.idx {
+			sb.WriteString(idx)
+		}
+		w.lastVmOp.idx = fmt.Sprintf("%s%d", sb.String(), w.lastVmOp.n)
+	}
+	w.lastOp = op
+	w.lastVmOp.cost = int(cost)
+	w.lastVmOp.pc = int(pc)
+	w.lastVmOp.ex.Push = []string{}
+	w.lastVmOp.ex.Used = int(gas) - int(cost)
+	if !w.compat {
+		w.lastVmOp.op = op.String()
+	}
+	switch op {
+	case vm.MSTORE, vm.MLOAD:
+		w.lastMemOff = st.Back(0).Uint64()
+		w.lastMemLen = 32
+	case vm.MSTORE8:
+		w.lastMemOff = st.Back(0).Uint64()
+		w.lastMemLen = 1
+	case vm.RETURNDATACOPY, vm.CALLDATACOPY, vm.CODECOPY:
+		w.lastMemOff = st.Back(0).Uint64()
+		w.lastMemLen = st.Back(2).Uint64()
+	case vm.STATICCALL, vm.DELEGATECALL:
+		w.memOffStack = append(w.memOffStack, st.Back(4).Uint64())
+		w.memLenStack = append(w.memLenStack, st.Back(5).Uint64())
+	case vm.CALL, vm.CALLCODE:
+		w.memOffStack = append(w.memOffStack, st.Back(5).Uint64())
+		w.memLenStack = append(w.memLenStack, st.Back(6).Uint64())
+	case vm.CREATE, vm.CREATE2:
+		// Effectively disable memory output
+		w.memOffStack = append(w.memOffStack, 0)
+		w.memLenStack = append(w.memLenStack, 0)
+	case vm.SSTORE:
+		w.lastVmOp.ex.Store = &VmTraceStore{Key: st.Back(0).String(), Val: st.Back(1).String()}
+	}
+	if w.lastVmOp.ex.Used < 0 {
+		w.lastVmOp.ex = nil
+	}
+}
+
+// finish writes what is held back, must be called once the transaction is executed
+func (w *vmTraceWriter) finish() error {
+	if w.finished {
+		return w.stream.Error
+	}
+	w.finished = true
+	if !w.started {
+		// The transaction was not traced at all
+		w.stream.WriteObjectStart()
+		w.stream.WriteObjectField("code")
+		w.stream.WriteString(hexutil.Encode(nil))
+		w.stream.WriteMore()
+		w.stream.WriteObjectField("ops")
+		w.stream.WriteEmptyArray()
+		w.stream.WriteObjectEnd()
+		return w.stream.Error
+	}
+	w.ended = len(w.frames)
+	w.closeEnded()
+	return w.stream.Error
+}
+
+// closeEnded writes the last operation and closes the frames which returned, together with the operations
+// which made the calls
+func (w *vmTraceWriter) closeEnded() {
+	if w.lastVmOp != nil && !w.lastVmOp.written {
+		w.writeOp(w.lastVmOp)
+	}
+	for ; w.ended > 0; w.ended-- {
+		call := w.frames[len(w.frames)-1].call
+		w.frames = w.frames[:len(w.frames)-1]
+		w.stream.WriteArrayEnd()
+		w.stream.WriteObjectEnd()
+		if call != nil {
+			w.stream.WriteMore()
+			w.stream.WriteObjectField("ex")
+			w.writeEx(call.ex)
+			w.stream.WriteObjectEnd()
+		}
+	}
+}
+
+func (w *vmTraceWriter) writeOp(op *vmTraceOp) {
+	if op.n > 0 {
+		w.stream.WriteMore()
+	}
+	w.stream.WriteObjectStart()
+	w.stream.WriteObjectField("cost")
+	w.stream.WriteInt(op.cost)
+	w.stream.WriteMore()
+	w.stream.WriteObjectField("ex")
+	w.writeEx(op.ex)
+	w.stream.WriteMore()
+	w.stream.WriteObjectField("pc")
+	w.stream.WriteInt(op.pc)
+	w.stream.WriteMore()
+	w.stream.WriteObjectField("sub")
+	w.stream.WriteNil()
+	w.writeOpName(op)
+	w.stream.WriteObjectEnd()
+	op.written = true
+}
+
+// writeOpHead writes the operation which made a sub-call, up to the "sub"
+func (w *vmTraceWriter) writeOpHead(op *vmTraceOp) {
+	if op.n > 0 {
+		w.stream.WriteMore()
+	}
+	w.stream.WriteObjectStart()
+	w.stream.WriteObjectField("cost")
+	w.stream.WriteInt(op.cost)
+	w.stream.WriteMore()
+	w.stream.WriteObjectField("pc")
+	w.stream.WriteInt(op.pc)
+	w.writeOpName(op)
+	w.stream.WriteMore()
+	op.written = true
+}
+
+func (w *vmTraceWriter) writeOpName(op *vmTraceOp) {
+	if op.op != "" {
+		w.stream.WriteMore()
+		w.stream.WriteObjectField("op")
+		w.stream.WriteString(op.op)
+	}
+	if op.idx != "" {
+		w.stream.WriteMore()
+		w.stream.WriteObjectField("idx")
+		w.stream.WriteString(op.idx)
+	}
+}
+
+func (w *vmTraceWriter) writeEx(ex *VmTraceEx) {
+	if ex == nil {
+		w.stream.WriteNil()
+		return
+	}
+	w.stream.WriteObjectStart()
+	w.stream.WriteObjectField("mem")
+	if ex.Mem == nil {
+		w.stream.WriteNil()
+	} else {
+		w.stream.WriteObjectStart()
+		w.stream.WriteObjectField("data")
+		w.stream.WriteString(ex.Mem.Data)
+		w.stream.WriteMore()
+		w.stream.WriteObjectField("off")
+		w.stream.WriteInt(ex.Mem.Off)
+		w.stream.WriteObjectEnd()
+	}
+	w.stream.WriteMore()
+	w.stream.WriteObjectField("push")
+	w.stream.WriteArrayStart()
+	for i, push := range ex.Push {
+		if i > 0 {
+			w.stream.WriteMore()
+		}
+		w.stream.WriteString(push)
+	}
+	w.stream.WriteArrayEnd()
+	w.stream.WriteMore()
+	w.stream.WriteObjectField("store")
+	if ex.Store == nil {
+		w.stream.WriteNil()
+	} else {
+		w.stream.WriteObjectStart()
+		w.stream.WriteObjectField("key")
+		w.stream.WriteString(ex.Store.Key)
+		w.stream.WriteMore()
+		w.stream.WriteObjectField("val")
+		w.stream.WriteString(ex.Store.Val)
+		w.stream.WriteObjectEnd()
+	}
+	w.stream.WriteMore()
+	w.stream.WriteObjectField("used")
+	w.stream.WriteInt(ex.Used)
+	w.stream.WriteObjectEnd()
+}
+
+// vmTraceBuffer - stream of the methods which return the whole result, the vmTrace is taken from its buffer
+func vmTraceBuffer() *jsoniter.Stream {
+	return jsoniter.NewStream(jsoniter.ConfigDefault, nil, 4096)
+}
+
+// rawVmTrace - the vmTrace written into the buffer of the stream
+func rawVmTrace(w *vmTraceWriter) ([]byte, error) {
+	if err := w.finish(); err != nil {
+		return nil, err
+	}
+	return common.CopyBytes(w.stream.Buffer()), nil
+}
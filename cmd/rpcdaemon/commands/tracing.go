@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/holiman/uint256"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/common/math"
@@ -20,6 +22,7 @@ import (
 	"github.com/ledgerwatch/erigon/eth/tracers"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/transactions"
@@ -76,6 +79,10 @@ func (api *PrivateDebugAPIImpl) traceBlock(ctx context.Context, blockNrOrHash rp
 		return h
 	}
 
+	if api.TraceWorkers > 1 && len(block.Transactions()) > 1 {
+		return api.traceBlockParallel(ctx, tx, block, chainConfig, contractHasTEVM, config, stream)
+	}
+
 	_, blockCtx, _, ibs, reader, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, contractHasTEVM, ethash.NewFaker(), tx, block.Hash(), 0)
 	if err != nil {
 		stream.WriteNil()
@@ -83,13 +90,14 @@ func (api *PrivateDebugAPIImpl) traceBlock(ctx context.Context, blockNrOrHash rp
 	}
 
 	signer := types.MakeSigner(chainConfig, block.NumberU64())
-	rules := chainConfig.Rules(block.NumberU64())
+	rules := chainConfig.Rules(block.NumberU64(), block.Time())
 	stream.WriteArrayStart()
 	for idx, tx := range block.Transactions() {
 		select {
 		default:
 		case <-ctx.Done():
 			stream.WriteNil()
+			stream.WriteArrayEnd()
 			return ctx.Err()
 		}
 		ibs.Prepare(tx.Hash(), block.Hash(), idx)
@@ -100,7 +108,12 @@ func (api *PrivateDebugAPIImpl) traceBlock(ctx context.Context, blockNrOrHash rp
 			GasPrice: msg.GasPrice().ToBig(),
 		}
 
-		transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream)
+		if err := transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream); err != nil {
+			// The traces written so far stay, the following transactions would be traced on a partly executed state
+			stream.WriteArrayEnd()
+			stream.Flush()
+			return err
+		}
 		_ = ibs.FinalizeTx(rules, reader)
 		if idx != len(block.Transactions())-1 {
 			stream.WriteMore()
@@ -112,6 +125,148 @@ func (api *PrivateDebugAPIImpl) traceBlock(ctx context.Context, blockNrOrHash rp
 	return nil
 }
 
+// traceBlockParallel traces the transactions of the block on TraceWorkers goroutines. The block is replayed once without
+// tracing to collect the change sets of its transactions, then every transaction is traced on its own pre-state: the
+// state at the beginning of the block with the change sets of the transactions before it. The traces are written in the
+// order of the transactions, at most TraceWorkers of them are in flight or waiting to be written.
+func (api *PrivateDebugAPIImpl) traceBlockParallel(ctx context.Context, tx kv.Tx, block *types.Block, chainConfig *params.ChainConfig, contractHasTEVM func(common.Hash) (bool, error), config *tracers.TraceConfig, stream *jsoniter.Stream) error {
+	txs := block.Transactions()
+	signer := types.MakeSigner(chainConfig, block.NumberU64())
+	rules := chainConfig.Rules(block.NumberU64(), block.Time())
+	blockContext := func(tx kv.Tx) vm.BlockContext {
+		getHeader := func(hash common.Hash, number uint64) *types.Header {
+			h, e := api._blockReader.Header(ctx, tx, hash, number)
+			if e != nil {
+				log.Error("getHeader error", "number", number, "hash", hash, "err", e)
+			}
+			return h
+		}
+		header := block.Header()
+		return core.NewEVMBlockContext(header, core.GetHashFn(header, getHeader), ethash.NewFaker(), nil, contractHasTEVM)
+	}
+
+	changes := transactions.NewTxChangeSets()
+	msgs := make([]types.Message, len(txs))
+	ibs := state.New(state.NewPlainState(tx, block.NumberU64()))
+	vmenv := vm.NewEVM(blockContext(tx), vm.TxContext{}, ibs, chainConfig, vm.Config{})
+	for idx, txn := range txs {
+		if err := ctx.Err(); err != nil {
+			stream.WriteNil()
+			return err
+		}
+		ibs.Prepare(txn.Hash(), block.Hash(), idx)
+		msgs[idx], _ = txn.AsMessage(*signer, block.BaseFee(), rules)
+		vmenv.Reset(core.NewEVMTxContext(msgs[idx]), ibs)
+		if _, err := core.ApplyMessage(vmenv, msgs[idx], new(core.GasPool).AddGas(msgs[idx].Gas()), true /* refunds */, false /* gasBailout */); err != nil {
+			stream.WriteNil()
+			return fmt.Errorf("transaction %x failed: %w", txn.Hash(), err)
+		}
+		if err := ibs.FinalizeTx(rules, changes.Writer(idx)); err != nil {
+			stream.WriteNil()
+			return err
+		}
+	}
+
+	type tracedTx struct {
+		trace []byte
+		err   error
+	}
+	results := make([]chan tracedTx, len(txs))
+	for idx := range results {
+		results[idx] = make(chan tracedTx, 1)
+	}
+	workers := api.TraceWorkers
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	inFlight := make(chan struct{}, workers)
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for idx := range txs {
+			select {
+			case inFlight <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- idx:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// A db transaction can't be shared between goroutines
+			wtx, err := api.db.BeginRo(ctx)
+			if err != nil {
+				for idx := range jobs {
+					results[idx] <- tracedTx{err: err}
+				}
+				return
+			}
+			defer wtx.Rollback()
+			blockCtx := blockContext(wtx)
+			base := state.NewPlainState(wtx, block.NumberU64())
+			for idx := range jobs {
+				txn := txs[idx]
+				ibs := state.New(changes.Reader(base, idx))
+				ibs.Prepare(txn.Hash(), block.Hash(), idx)
+				txCtx := vm.TxContext{
+					TxHash:   txn.Hash(),
+					Origin:   msgs[idx].From(),
+					GasPrice: msgs[idx].GasPrice().ToBig(),
+				}
+				txStream := jsoniter.NewStream(jsoniter.ConfigDefault, nil, 4096)
+				err := transactions.TraceTx(ctx, msgs[idx], blockCtx, txCtx, ibs, config, chainConfig, txStream)
+				results[idx] <- tracedTx{trace: txStream.Buffer(), err: err}
+			}
+		}()
+	}
+
+	stream.WriteArrayStart()
+	for idx := range txs {
+		var result tracedTx
+		select {
+		case result = <-results[idx]:
+		case <-ctx.Done():
+			// The traces written so far stay, the array is closed after a null in place of this one
+			if idx > 0 {
+				stream.WriteMore()
+			}
+			stream.WriteNil()
+			stream.WriteArrayEnd()
+			return ctx.Err()
+		}
+		<-inFlight
+		if idx > 0 {
+			stream.WriteMore()
+		}
+		if len(result.trace) > 0 {
+			stream.Write(result.trace)
+		} else {
+			stream.WriteNil()
+		}
+		if result.err != nil {
+			stream.WriteArrayEnd()
+			return result.err
+		}
+		stream.Flush()
+	}
+	stream.WriteArrayEnd()
+	stream.Flush()
+	return nil
+}
+
 // TraceTransaction implements debug_traceTransaction. Returns Geth style transaction traces.
 func (api *PrivateDebugAPIImpl) TraceTransaction(ctx context.Context, hash common.Hash, config *tracers.TraceConfig, stream *jsoniter.Stream) error {
 	tx, err := api.db.BeginRo(ctx)
@@ -335,7 +490,7 @@ func (api *PrivateDebugAPIImpl) TraceCallMany(ctx context.Context, bundles []Bun
 
 	// Get a new instance of the EVM
 	signer := types.MakeSigner(chainConfig, blockNum)
-	rules := chainConfig.Rules(blockNum)
+	rules := chainConfig.Rules(blockNum, block.Time())
 
 	contractHasTEVM := func(contractHash common.Hash) (bool, error) { return false, nil }
 
@@ -422,7 +577,8 @@ func (api *PrivateDebugAPIImpl) TraceCallMany(ctx context.Context, bundles []Bun
 			err = transactions.TraceTx(ctx, msg, blockCtx, txCtx, evm.IntraBlockState(), config, chainConfig, stream)
 
 			if err != nil {
-				stream.WriteNil()
+				stream.WriteArrayEnd()
+				stream.WriteArrayEnd()
 				return err
 			}
 
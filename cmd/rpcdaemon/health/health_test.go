@@ -11,8 +11,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/txpool"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
 	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/rpc"
+	"google.golang.org/grpc"
 )
 
 type netApiStub struct {
@@ -560,3 +565,162 @@ func TestProcessHealthcheckIfNeeded_RequestBody(t *testing.T) {
 		}
 	}
 }
+
+type txPoolStub struct {
+	reply *txpool.StatusReply
+	error error
+}
+
+func (p *txPoolStub) Status(_ context.Context, _ *txpool.StatusRequest, _ ...grpc.CallOption) (*txpool.StatusReply, error) {
+	return p.reply, p.error
+}
+
+type snapshotsStub struct {
+	indicesReady bool
+}
+
+func (s *snapshotsStub) IndicesReady() bool      { return s.indicesReady }
+func (s *snapshotsStub) BlocksAvailable() uint64 { return 90 }
+
+func TestProcessProbeIfNeeded(t *testing.T) {
+	db := memdb.NewTestDB(t)
+	if err := db.Update(context.Background(), func(tx kv.RwTx) error {
+		if err := stages.SaveStageProgress(tx, stages.Headers, 100); err != nil {
+			return err
+		}
+		return stages.SaveStageProgress(tx, stages.Finish, 95)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		url                string
+		backend            *Backend
+		expectedStatusCode int
+		expectedStatus     string
+		expectedChecks     map[string]string
+	}{
+		// 0 - live, the lag doesn't matter
+		{
+			url:                "/health/live",
+			backend:            &Backend{DB: db, TxPool: &txPoolStub{error: errors.New("no txpool")}},
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     "HEALTHY",
+			expectedChecks: map[string]string{
+				checkDB:        "HEALTHY",
+				synced:         "ERROR: not synced: 5 blocks behind the highest header (maximum 4)",
+				checkSnapshots: "DISABLED",
+				checkTxPool:    "ERROR: no txpool",
+			},
+		},
+		// 1 - not ready, too far behind
+		{
+			url:                "/health/ready",
+			backend:            &Backend{DB: db},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus:     "UNHEALTHY",
+			expectedChecks: map[string]string{
+				checkDB: "HEALTHY",
+				synced:  "ERROR: not synced",
+			},
+		},
+		// 2 - ready with a larger lag allowed
+		{
+			url:                "/health/ready?max_blocks_behind=5",
+			backend:            &Backend{DB: db, TxPool: &txPoolStub{reply: &txpool.StatusReply{PendingCount: 3}}, Snapshots: &snapshotsStub{indicesReady: true}},
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     "HEALTHY",
+			expectedChecks: map[string]string{
+				checkDB:        "HEALTHY",
+				synced:         "HEALTHY",
+				checkSnapshots: "HEALTHY",
+				checkTxPool:    "HEALTHY",
+			},
+		},
+		// 3 - not ready before the snapshots are indexed
+		{
+			url:                "/health/ready?max_blocks_behind=5",
+			backend:            &Backend{DB: db, Snapshots: &snapshotsStub{}},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus:     "UNHEALTHY",
+			expectedChecks: map[string]string{
+				synced:         "HEALTHY",
+				checkSnapshots: "ERROR: snapshots are not indexed yet",
+			},
+		},
+		// 4 - not live without a database
+		{
+			url:                "/health/live",
+			backend:            nil,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus:     "UNHEALTHY",
+			expectedChecks: map[string]string{
+				checkDB: "ERROR: no database",
+			},
+		},
+	}
+
+	for idx, c := range cases {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest(http.MethodGet, c.url, nil)
+		if err != nil {
+			t.Errorf("%v: creating request: %v", idx, err)
+		}
+
+		if !ProcessProbeIfNeeded(w, r, c.backend, 4) {
+			t.Errorf("%v: expected the probe to be processed", idx)
+		}
+
+		result := w.Result()
+		if result.StatusCode != c.expectedStatusCode {
+			t.Errorf("%v: expected status code: %v, but got: %v", idx, c.expectedStatusCode, result.StatusCode)
+		}
+
+		var body probeReport
+		if err := json.NewDecoder(result.Body).Decode(&body); err != nil {
+			t.Errorf("%v: unmarshalling the response body: %s", idx, err)
+		}
+		result.Body.Close()
+
+		if body.Status != c.expectedStatus {
+			t.Errorf("%v: expected status: %s, but got: %s", idx, c.expectedStatus, body.Status)
+		}
+		for k, v := range c.expectedChecks {
+			val, found := body.Checks[k]
+			if !found {
+				t.Errorf("%v: expected the check: %s to be in the response body but it wasn't there", idx, k)
+			}
+			if !strings.Contains(val, v) {
+				t.Errorf("%v: expected the check: %s to contain: %s, but it contained: %s", idx, k, v, val)
+			}
+		}
+		if c.backend != nil && body.Sync == nil {
+			t.Errorf("%v: expected the sync progress to be reported", idx)
+		}
+		if body.Sync != nil && body.Sync.Lag != 5 {
+			t.Errorf("%v: expected a lag of 5 blocks, but got: %d", idx, body.Sync.Lag)
+		}
+	}
+
+	// A node which has not synced anything yet has no lag, it is still not ready
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodGet, "/health/ready", nil)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+	if !ProcessProbeIfNeeded(w, r, &Backend{DB: memdb.NewTestDB(t)}, 4) {
+		t.Fatal("expected the probe to be processed")
+	}
+	result := w.Result()
+	defer result.Body.Close()
+	if result.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status code: %v, but got: %v", http.StatusServiceUnavailable, result.StatusCode)
+	}
+	var body probeReport
+	if err := json.NewDecoder(result.Body).Decode(&body); err != nil {
+		t.Fatalf("unmarshalling the response body: %s", err)
+	}
+	if !strings.Contains(body.Checks[synced], "not synced") {
+		t.Errorf("expected the check: %s to contain: not synced, but it contained: %s", synced, body.Checks[synced])
+	}
+}
@@ -3,8 +3,10 @@ package health
 import (
 	"context"
 
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/txpool"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/rpc"
+	"google.golang.org/grpc"
 )
 
 type NetAPI interface {
@@ -15,3 +17,12 @@ type EthAPI interface {
 	GetBlockByNumber(_ context.Context, number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error)
 	Syncing(ctx context.Context) (interface{}, error)
 }
+
+type TxPoolClient interface {
+	Status(ctx context.Context, in *txpool.StatusRequest, opts ...grpc.CallOption) (*txpool.StatusReply, error)
+}
+
+type Snapshots interface {
+	IndicesReady() bool
+	BlocksAvailable() uint64
+}
@@ -0,0 +1,215 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/txpool"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+)
+
+const (
+	livenessPath         = "/health/live"
+	readinessPath        = "/health/ready"
+	maxBlocksBehindParam = "max_blocks_behind"
+	healthy              = "HEALTHY"
+	unhealthy            = "UNHEALTHY"
+	checkDB              = "db"
+	checkSnapshots       = "snapshots_indexed"
+	checkTxPool          = "txpool"
+)
+
+var (
+	errNoDB                = errors.New("no database")
+	errSnapshotsNotIndexed = errors.New("snapshots are not indexed yet")
+)
+
+// Backend is what the liveness and readiness probes look at, the parts left nil are reported as disabled
+type Backend struct {
+	DB        kv.RoDB
+	TxPool    TxPoolClient
+	Snapshots Snapshots
+}
+
+func NewBackend(db kv.RoDB, txPool TxPoolClient, snapshots *snapshotsync.RoSnapshots) *Backend {
+	backend := &Backend{DB: db, TxPool: txPool}
+	if snapshots != nil {
+		backend.Snapshots = snapshots
+	}
+	return backend
+}
+
+type probeReport struct {
+	Status    string                  `json:"status"`
+	Checks    map[string]string       `json:"checks"`
+	Sync      *syncReport             `json:"sync,omitempty"`
+	TxPool    map[string]hexutil.Uint `json:"txpool,omitempty"`
+	Snapshots *snapshotsReport        `json:"snapshots,omitempty"`
+}
+
+type syncReport struct {
+	CurrentBlock hexutil.Uint64 `json:"currentBlock"`
+	HighestBlock hexutil.Uint64 `json:"highestBlock"`
+	Lag          uint64         `json:"lag"`
+	Stages       []stageLag     `json:"stages"`
+}
+
+type stageLag struct {
+	StageName   string         `json:"stage_name"`
+	BlockNumber hexutil.Uint64 `json:"block_number"`
+	Lag         uint64         `json:"lag"`
+}
+
+type snapshotsReport struct {
+	IndicesReady    bool           `json:"indicesReady"`
+	BlocksAvailable hexutil.Uint64 `json:"blocksAvailable"`
+}
+
+// ProcessProbeIfNeeded serves the liveness and readiness probes. The node is live while its database can be read, and
+// ready once it is also synced to within maxBlocksBehind blocks of the highest header and its snapshots are indexed.
+// Both answer with the same JSON diagnostics, the state of the txpool connection is reported without affecting them.
+func ProcessProbeIfNeeded(w http.ResponseWriter, r *http.Request, backend *Backend, maxBlocksBehind uint64) bool {
+	var readiness bool
+	switch {
+	case strings.EqualFold(r.URL.Path, livenessPath):
+	case strings.EqualFold(r.URL.Path, readinessPath):
+		readiness = true
+	default:
+		return false
+	}
+
+	if param := r.URL.Query().Get(maxBlocksBehindParam); param != "" {
+		blocks, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			if err := writeResponse(w, map[string]string{"healthcheck_query": errorStringOrOK(err)}, http.StatusBadRequest); err != nil {
+				log.Root().Warn("unable to process healthcheck request", "err", err)
+			}
+			return true
+		}
+		maxBlocksBehind = blocks
+	}
+	if backend == nil {
+		backend = &Backend{}
+	}
+
+	report, errs := probe(r.Context(), backend, maxBlocksBehind)
+	failed := shouldChangeStatusCode(errs[checkDB])
+	if readiness {
+		failed = failed || shouldChangeStatusCode(errs[synced]) || shouldChangeStatusCode(errs[checkSnapshots])
+	}
+
+	statusCode := http.StatusOK
+	report.Status = healthy
+	if failed {
+		statusCode = http.StatusServiceUnavailable
+		report.Status = unhealthy
+	}
+	report.Checks = make(map[string]string, len(errs))
+	for check, err := range errs {
+		report.Checks[check] = errorStringOrOK(err)
+	}
+
+	if err := writeResponse(w, report, statusCode); err != nil {
+		log.Root().Warn("unable to process healthcheck request", "err", err)
+	}
+	return true
+}
+
+// probe runs all the checks of the backend, they are reported by both probes
+func probe(ctx context.Context, backend *Backend, maxBlocksBehind uint64) (*probeReport, map[string]error) {
+	report := &probeReport{}
+	errs := map[string]error{
+		checkDB:        errNoDB,
+		synced:         errNoDB,
+		checkSnapshots: errCheckDisabled,
+		checkTxPool:    errCheckDisabled,
+	}
+
+	if backend.DB != nil {
+		progress, err := readSyncProgress(ctx, backend.DB)
+		errs[checkDB], errs[synced] = err, err
+		if err == nil {
+			report.Sync = newSyncReport(progress)
+			errs[synced] = checkBlocksBehind(report.Sync.Lag, maxBlocksBehind)
+			// Before the first sync cycle is done there is no lag to measure, the highest header is not known yet
+			if progress.CurrentBlock == 0 {
+				errs[synced] = fmt.Errorf("%w: no block has gone through all the stages yet", errNotSynced)
+			}
+		}
+	}
+
+	if backend.Snapshots != nil {
+		report.Snapshots = &snapshotsReport{
+			IndicesReady:    backend.Snapshots.IndicesReady(),
+			BlocksAvailable: hexutil.Uint64(backend.Snapshots.BlocksAvailable()),
+		}
+		errs[checkSnapshots] = nil
+		if !report.Snapshots.IndicesReady {
+			errs[checkSnapshots] = errSnapshotsNotIndexed
+		}
+	}
+
+	if backend.TxPool != nil {
+		reply, err := backend.TxPool.Status(ctx, &txpool.StatusRequest{})
+		errs[checkTxPool] = err
+		if err == nil {
+			report.TxPool = map[string]hexutil.Uint{
+				"pending": hexutil.Uint(reply.PendingCount),
+				"baseFee": hexutil.Uint(reply.BaseFeeCount),
+				"queued":  hexutil.Uint(reply.QueuedCount),
+			}
+		}
+	}
+
+	return report, errs
+}
+
+func readSyncProgress(ctx context.Context, db kv.RoDB) (*stages.SyncProgress, error) {
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return stages.ReadSyncProgress(tx)
+}
+
+func newSyncReport(progress *stages.SyncProgress) *syncReport {
+	highest := uint64(progress.HighestBlock)
+	report := &syncReport{
+		CurrentBlock: progress.CurrentBlock,
+		HighestBlock: progress.HighestBlock,
+		Lag:          blocksBehind(uint64(progress.CurrentBlock), highest),
+		Stages:       make([]stageLag, len(progress.Stages)),
+	}
+	for i, stage := range progress.Stages {
+		report.Stages[i] = stageLag{
+			StageName:   stage.StageName,
+			BlockNumber: stage.BlockNumber,
+			Lag:         blocksBehind(uint64(stage.BlockNumber), highest),
+		}
+	}
+	return report
+}
+
+func blocksBehind(block, highest uint64) uint64 {
+	if block >= highest {
+		return 0
+	}
+	return highest - block
+}
+
+func checkBlocksBehind(lag, maxBlocksBehind uint64) error {
+	if lag > maxBlocksBehind {
+		return fmt.Errorf("%w: %d blocks behind the highest header (maximum %d)", errNotSynced, lag, maxBlocksBehind)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/commands"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/health"
 	"github.com/ledgerwatch/log/v3"
 	"github.com/spf13/cobra"
 )
@@ -16,18 +17,18 @@ func main() {
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		logger := log.New()
-		db, borDb, backend, txPool, mining, starknet, stateCache, blockReader, ff, err := cli.RemoteServices(ctx, *cfg, logger, rootCancel)
+		db, consensusDb, backend, txPool, mining, starknet, stateCache, blockReader, snapshots, ff, err := cli.RemoteServices(ctx, *cfg, logger, rootCancel)
 		if err != nil {
 			log.Error("Could not connect to DB", "err", err)
 			return nil
 		}
 		defer db.Close()
-		if borDb != nil {
-			defer borDb.Close()
+		if consensusDb != nil {
+			defer consensusDb.Close()
 		}
 
-		apiList := commands.APIList(db, borDb, backend, txPool, mining, starknet, ff, stateCache, blockReader, *cfg)
-		if err := cli.StartRpcServer(ctx, *cfg, apiList); err != nil {
+		apiList := commands.APIList(db, consensusDb, backend, txPool, mining, starknet, ff, stateCache, blockReader, *cfg)
+		if err := cli.StartRpcServer(ctx, *cfg, apiList, health.NewBackend(db, txPool, snapshots)); err != nil {
 			log.Error(err.Error())
 			return nil
 		}
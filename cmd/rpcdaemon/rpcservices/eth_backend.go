@@ -17,6 +17,7 @@ import (
 	"github.com/ledgerwatch/erigon/ethdb/privateapi"
 	"github.com/ledgerwatch/erigon/p2p"
 	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/engineapi"
 	"github.com/ledgerwatch/erigon/turbo/services"
 	"github.com/ledgerwatch/log/v3"
 	"google.golang.org/grpc"
@@ -26,12 +27,17 @@ import (
 
 type RemoteBackend struct {
 	remoteEthBackend remote.ETHBACKENDClient
+	engineV2         privateapi.EngineV2Server
+	clique           remote.CLIQUEClient
 	log              log.Logger
 	version          gointerfaces.Version
 	db               kv.RoDB
 	blockReader      services.FullBlockReader
 }
 
+var errEngineV2NotAvailable = errors.New("engine API V2 is only available when rpcdaemon is embedded into Erigon")
+var errCliqueNotAvailable = errors.New("clique signer voting is not available")
+
 func NewRemoteBackend(client remote.ETHBACKENDClient, db kv.RoDB, blockReader services.FullBlockReader) *RemoteBackend {
 	return &RemoteBackend{
 		remoteEthBackend: client,
@@ -42,6 +48,18 @@ func NewRemoteBackend(client remote.ETHBACKENDClient, db kv.RoDB, blockReader se
 	}
 }
 
+// WithEngineV2 makes the Engine API V2 methods available, they bypass gRPC and go directly to the given server
+func (back *RemoteBackend) WithEngineV2(server privateapi.EngineV2Server) *RemoteBackend {
+	back.engineV2 = server
+	return back
+}
+
+// WithClique makes clique_propose, clique_discard and clique_proposals available
+func (back *RemoteBackend) WithClique(client remote.CLIQUEClient) *RemoteBackend {
+	back.clique = client
+	return back
+}
+
 func (back *RemoteBackend) EnsureVersionCompatibility() bool {
 	versionReply, err := back.remoteEthBackend.Version(context.Background(), &emptypb.Empty{}, grpc.WaitForReady(true))
 	if err != nil {
@@ -195,6 +213,9 @@ func (back *RemoteBackend) CanonicalHash(ctx context.Context, tx kv.Getter, bloc
 func (back *RemoteBackend) TxnByIdxInBlock(ctx context.Context, tx kv.Getter, blockNum uint64, i int) (types.Transaction, error) {
 	return back.blockReader.TxnByIdxInBlock(ctx, tx, blockNum, i)
 }
+func (back *RemoteBackend) RawReceipts(ctx context.Context, tx kv.Getter, blockNum uint64) (types.Receipts, error) {
+	return back.blockReader.RawReceipts(ctx, tx, blockNum)
+}
 
 func (back *RemoteBackend) EngineNewPayloadV1(ctx context.Context, payload *types2.ExecutionPayload) (res *remote.EnginePayloadStatus, err error) {
 	return back.remoteEthBackend.EngineNewPayloadV1(ctx, payload)
@@ -210,6 +231,34 @@ func (back *RemoteBackend) EngineGetPayloadV1(ctx context.Context, payloadId uin
 	})
 }
 
+// EngineV2Available reports whether the Engine API V2 methods can be served, see WithEngineV2
+func (back *RemoteBackend) EngineV2Available() bool {
+	return back.engineV2 != nil
+}
+
+func (back *RemoteBackend) EngineNewPayloadV2(ctx context.Context, payload *types2.ExecutionPayload, withdrawals []*types.Withdrawal) (*remote.EnginePayloadStatus, error) {
+	if back.engineV2 == nil {
+		return nil, errEngineV2NotAvailable
+	}
+	return back.engineV2.EngineNewPayloadV2(ctx, payload, withdrawals)
+}
+
+func (back *RemoteBackend) EngineForkchoiceUpdatedV2(ctx context.Context, request *remote.EngineForkChoiceUpdatedRequest, withdrawals []*types.Withdrawal) (*remote.EngineForkChoiceUpdatedReply, error) {
+	if back.engineV2 == nil {
+		return nil, errEngineV2NotAvailable
+	}
+	return back.engineV2.EngineForkChoiceUpdatedV2(ctx, request, withdrawals)
+}
+
+func (back *RemoteBackend) EngineGetPayloadV2(ctx context.Context, payloadId uint64) (*engineapi.ExecutionPayloadV2, error) {
+	if back.engineV2 == nil {
+		return nil, errEngineV2NotAvailable
+	}
+	return back.engineV2.EngineGetPayloadV2(ctx, &remote.EngineGetPayloadRequest{
+		PayloadId: payloadId,
+	})
+}
+
 func (back *RemoteBackend) NodeInfo(ctx context.Context, limit uint32) ([]p2p.NodeInfo, error) {
 	nodes, err := back.remoteEthBackend.NodeInfo(ctx, &remote.NodesInfoRequest{Limit: limit})
 	if err != nil {
@@ -289,3 +338,44 @@ func (back *RemoteBackend) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
 
 	return peers, nil
 }
+
+func (back *RemoteBackend) CliquePropose(ctx context.Context, address common.Address, auth bool) error {
+	if back.clique == nil {
+		return errCliqueNotAvailable
+	}
+	_, err := back.clique.Propose(ctx, &remote.CliqueProposeRequest{Address: gointerfaces.ConvertAddressToH160(address), Authorize: auth})
+	return cliqueError(err)
+}
+
+func (back *RemoteBackend) CliqueDiscard(ctx context.Context, address common.Address) error {
+	if back.clique == nil {
+		return errCliqueNotAvailable
+	}
+	_, err := back.clique.Discard(ctx, &remote.CliqueDiscardRequest{Address: gointerfaces.ConvertAddressToH160(address)})
+	return cliqueError(err)
+}
+
+func (back *RemoteBackend) CliqueProposals(ctx context.Context) (map[common.Address]bool, error) {
+	if back.clique == nil {
+		return nil, errCliqueNotAvailable
+	}
+	res, err := back.clique.Proposals(ctx, &remote.CliqueProposalsRequest{})
+	if err != nil {
+		return nil, cliqueError(err)
+	}
+	proposals := make(map[common.Address]bool, len(res.Proposals))
+	for _, p := range res.Proposals {
+		proposals[gointerfaces.ConvertH160toAddress(p.Address)] = p.Authorize
+	}
+	return proposals, nil
+}
+
+func cliqueError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s, ok := status.FromError(err); ok {
+		return errors.New(s.Message())
+	}
+	return err
+}
@@ -179,7 +179,7 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([
 			lastBlockNum = blockNum
 			lastBlockHash = lastHeader.Hash()
 			lastSigner = types.MakeSigner(chainConfig, blockNum)
-			lastRules = chainConfig.Rules(blockNum)
+			lastRules = chainConfig.Rules(blockNum, lastHeader.Time)
 		}
 		var startTxNum uint64
 		if blockNum > 0 {
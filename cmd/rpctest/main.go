@@ -242,6 +242,16 @@ func main() {
 	}
 	with(benchTraceReplayTransactionCmd, withGethUrl, withErigonUrl, withNeedCompare, withBlockNum, withRecord, withErrorFile)
 
+	var benchTraceReplayBlockTransactionsCmd = &cobra.Command{
+		Use:   "benchTraceReplayBlockTransactions",
+		Short: "",
+		Long:  ``,
+		Run: func(cmd *cobra.Command, args []string) {
+			rpctest.BenchTraceReplayBlockTransactions(erigonURL, gethURL, needCompare, blockFrom, blockTo, recordFile, errorFile)
+		},
+	}
+	with(benchTraceReplayBlockTransactionsCmd, withGethUrl, withErigonUrl, withNeedCompare, withBlockNum, withRecord, withErrorFile)
+
 	var benchEthBlockByNumberCmd = &cobra.Command{
 		Use:   "benchBlockByNumber",
 		Short: "",
@@ -303,6 +313,7 @@ func main() {
 		benchTxReceiptCmd,
 		compareAccountRange,
 		benchTraceReplayTransactionCmd,
+		benchTraceReplayBlockTransactionsCmd,
 		benchEthBlockByNumberCmd,
 		replayCmd,
 	)
@@ -0,0 +1,71 @@
+package rpctest
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// BenchTraceReplayBlockTransactions replays the blocks with all trace types ("trace", "vmTrace" and "stateDiff"),
+// prints how long it took for every block and in total. Can compare the responses with OpenEthereum.
+// parameters:
+// needCompare - if false - doesn't call OpenEthereum and doesn't compare responses
+func BenchTraceReplayBlockTransactions(erigonURL, oeURL string, needCompare bool, blockFrom uint64, blockTo uint64, recordFile string, errorFile string) {
+	setRoutes(erigonURL, oeURL)
+	var client = &http.Client{
+		Timeout: time.Second * 600,
+	}
+	var rec *bufio.Writer
+	if recordFile != "" {
+		f, err := os.Create(recordFile)
+		if err != nil {
+			fmt.Printf("Cannot create file %s for recording: %v\n", recordFile, err)
+			return
+		}
+		defer f.Close()
+		rec = bufio.NewWriter(f)
+		defer rec.Flush()
+	}
+	var errs *bufio.Writer
+	if errorFile != "" {
+		ferr, err := os.Create(errorFile)
+		if err != nil {
+			fmt.Printf("Cannot create file %s for error output: %v\n", errorFile, err)
+			return
+		}
+		defer ferr.Close()
+		errs = bufio.NewWriter(ferr)
+		defer errs.Flush()
+	}
+
+	reqGen := &RequestGenerator{
+		client: client,
+	}
+	// Receives the responses of Erigon, to measure the time
+	resultsCh := make(chan CallResult, 1)
+	var total time.Duration
+	var blocks, bytes int
+	for bn := blockFrom; bn <= blockTo; bn++ {
+		reqGen.reqID++
+		request := reqGen.traceReplayBlockTransactions(bn)
+		errCtx := fmt.Sprintf("block %d", bn)
+		if err := requestAndCompare(request, "trace_replayBlockTransactions", errCtx, reqGen, needCompare, rec, errs, resultsCh); err != nil {
+			fmt.Println(err)
+			return
+		}
+		res := <-resultsCh
+		if errVal := res.Result.Get("error"); errVal != nil {
+			fmt.Printf("error invoking trace_replayBlockTransactions (Erigon) for block %d: %d %s\n", bn, errVal.GetInt("code"), errVal.GetStringBytes("message"))
+			return
+		}
+		total += res.Took
+		blocks++
+		bytes += len(res.Response)
+		fmt.Printf("Block %d: %d txs, %d bytes, %s\n", bn, len(res.Result.GetArray("result")), len(res.Response), res.Took)
+	}
+	if blocks > 0 {
+		fmt.Printf("Replayed %d blocks, %d bytes, in %s, %s per block\n", blocks, bytes, total, total/time.Duration(blocks))
+	}
+}
@@ -224,6 +224,11 @@ func (g *RequestGenerator) traceReplayTransaction(hash string) string {
 	return fmt.Sprintf(template, hash, g.reqID)
 }
 
+func (g *RequestGenerator) traceReplayBlockTransactions(bn uint64) string {
+	const template = `{"jsonrpc":"2.0","method":"trace_replayBlockTransactions","params":["0x%x", ["trace", "vmTrace", "stateDiff"]],"id":%d}`
+	return fmt.Sprintf(template, bn, g.reqID)
+}
+
 func (g *RequestGenerator) ethCall(from common.Address, to *common.Address, gas *hexutil.Big, gasPrice *hexutil.Big, value *hexutil.Big, data hexutil.Bytes, bn uint64) string {
 	var sb strings.Builder
 	fmt.Fprintf(&sb, `{ "jsonrpc": "2.0", "method": "eth_call", "params": [{"from":"0x%x"`, from)
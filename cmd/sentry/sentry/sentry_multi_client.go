@@ -37,6 +37,7 @@ import (
 	"github.com/ledgerwatch/erigon/turbo/services"
 	"github.com/ledgerwatch/erigon/turbo/stages/bodydownload"
 	"github.com/ledgerwatch/erigon/turbo/stages/headerdownload"
+	"github.com/ledgerwatch/erigon/turbo/stages/receiptdownload"
 )
 
 type sentryMessageStream grpc.ClientStream
@@ -100,6 +101,7 @@ func (cs *MultiClient) RecvMessageLoop(
 		eth.ToProto[eth.ETH66][eth.BlockBodiesMsg],
 		eth.ToProto[eth.ETH66][eth.NewBlockHashesMsg],
 		eth.ToProto[eth.ETH66][eth.NewBlockMsg],
+		eth.ToProto[eth.ETH66][eth.ReceiptsMsg],
 	}
 	streamFactory := func(streamCtx context.Context, sentry direct.SentryClient) (sentryMessageStream, error) {
 		return sentry.Messages(streamCtx, &proto_sentry.MessagesRequest{Ids: ids}, grpc.WaitForReady(true))
@@ -244,6 +246,7 @@ type MultiClient struct {
 	lock        sync.RWMutex
 	Hd          *headerdownload.HeaderDownload
 	Bd          *bodydownload.BodyDownload
+	Rd          *receiptdownload.ReceiptDownload
 	nodeName    string
 	sentries    []direct.SentryClient
 	headHeight  uint64
@@ -255,7 +258,7 @@ type MultiClient struct {
 	networkId   uint64
 	db          kv.RwDB
 	Engine      consensus.Engine
-	blockReader services.HeaderAndCanonicalReader
+	blockReader services.FullBlockReader
 	logPeerInfo bool
 }
 
@@ -268,7 +271,7 @@ func NewMultiClient(
 	networkID uint64,
 	sentries []direct.SentryClient,
 	syncCfg ethconfig.Sync,
-	blockReader services.HeaderAndCanonicalReader,
+	blockReader services.FullBlockReader,
 	logPeerInfo bool,
 ) (*MultiClient, error) {
 	hd := headerdownload.NewHeaderDownload(
@@ -287,6 +290,7 @@ func NewMultiClient(
 		nodeName:    nodeName,
 		Hd:          hd,
 		Bd:          bd,
+		Rd:          receiptdownload.NewReceiptDownload(db, blockReader),
 		sentries:    sentries,
 		db:          db,
 		Engine:      engine,
@@ -501,12 +505,17 @@ func (cs *MultiClient) blockBodies66(inreq *proto_sentry.InboundMessage, _ direc
 	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
 		return fmt.Errorf("decode BlockBodiesPacket66: %w, data: %x", err, inreq.Data)
 	}
-	txs, uncles := request.BlockRawBodiesPacket.Unpack()
-	cs.Bd.DeliverBodies(&txs, &uncles, uint64(len(inreq.Data)), ConvertH512ToPeerID(inreq.PeerId))
+	txs, uncles, withdrawals := request.BlockRawBodiesPacket.Unpack()
+	cs.Bd.DeliverBodies(&txs, &uncles, &withdrawals, uint64(len(inreq.Data)), ConvertH512ToPeerID(inreq.PeerId))
 	return nil
 }
 
-func (cs *MultiClient) receipts66(_ context.Context, _ *proto_sentry.InboundMessage, _ direct.SentryClient) error {
+func (cs *MultiClient) receipts66(_ context.Context, inreq *proto_sentry.InboundMessage, _ direct.SentryClient) error {
+	var request eth.ReceiptsPacket66
+	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
+		return fmt.Errorf("decode ReceiptsPacket66: %w, data: %x", err, inreq.Data)
+	}
+	cs.Rd.DeliverReceipts(request.RequestId, request.ReceiptsPacket, ConvertH512ToPeerID(inreq.PeerId))
 	return nil
 }
 
@@ -598,7 +607,7 @@ func (cs *MultiClient) getReceipts66(ctx context.Context, inreq *proto_sentry.In
 		return err
 	}
 	defer tx.Rollback()
-	receipts, err := eth.AnswerGetReceiptsQuery(tx, query.GetReceiptsPacket)
+	receipts, err := eth.AnswerGetReceiptsQuery(tx, query.GetReceiptsPacket, cs.blockReader)
 	if err != nil {
 		return err
 	}
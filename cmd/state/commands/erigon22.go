@@ -546,10 +546,10 @@ func Erigon22(genesis *core.Genesis, logger log.Logger) error {
 loop:
 	for blockNum = block; blockNum < maxBlockNum; blockNum++ {
 		atomic.StoreUint64(&inputBlockNum, blockNum)
-		rules := chainConfig.Rules(blockNum)
 		if header, err = blockReader.HeaderByNumber(ctx, nil, blockNum); err != nil {
 			return err
 		}
+		rules := chainConfig.Rules(blockNum, header.Time)
 		blockHash := header.Hash()
 		b, _, err := blockReader.BlockWithSenders(ctx, nil, blockHash, blockNum)
 		if err != nil {
@@ -117,7 +117,7 @@ func (rw *ReconWorker) runTxTask(txTask state.TxTask) {
 	rw.stateReader.ResetError()
 	rw.stateWriter.SetTxNum(txTask.TxNum)
 	noop := state.NewNoopWriter()
-	rules := rw.chainConfig.Rules(txTask.BlockNum)
+	rules := rw.chainConfig.Rules(txTask.BlockNum, txTask.Header.Time)
 	ibs := state.New(rw.stateReader)
 	daoForkTx := rw.chainConfig.DAOForkSupport && rw.chainConfig.DAOForkBlock != nil && rw.chainConfig.DAOForkBlock.Uint64() == txTask.BlockNum && txTask.TxIndex == -1
 	var err error
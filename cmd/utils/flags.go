@@ -384,16 +384,55 @@ var (
 		Name:  "rpc.accessList",
 		Usage: "Specify granular (method-by-method) API allowlist",
 	}
+	RpcRateLimitFlag = cli.StringFlag{
+		Name:  "rpc.ratelimit",
+		Usage: "JSON file with the per-client request rates, method costs, batch length and response size limits of the http and websocket APIs",
+	}
+	RpcRateLimitJWTSecretFlag = cli.StringFlag{
+		Name:  "rpc.ratelimit.jwtsecret",
+		Usage: "Path to the hex encoded secret of the JWTs which identify the clients of the perSubject rate limit",
+	}
 
 	RpcGasCapFlag = cli.UintFlag{
 		Name:  "rpc.gascap",
 		Usage: "Sets a cap on gas that can be used in eth_call/estimateGas",
 		Value: 50000000,
 	}
+	RpcMaxGetProofRewindBlockCountFlag = cli.Uint64Flag{
+		Name:  "rpc.maxgetproofrewindblockcount.limit",
+		Usage: "Sets the maximum number of blocks eth_getProof can go back in history from the head",
+		Value: 1_000,
+	}
+	RpcMaxGetLogsBlockRangeFlag = cli.Uint64Flag{
+		Name:  "rpc.getlogs.maxblockrange",
+		Usage: "Sets the maximum number of blocks an eth_getLogs query can span, wider queries get an error with a narrower range (0 = no limit)",
+	}
+	RpcMaxGetLogsResultsFlag = cli.Uint64Flag{
+		Name:  "rpc.getlogs.maxresults",
+		Usage: "Sets the maximum number of logs an eth_getLogs query can return and the page size of erigon_getLogs (0 = no limit)",
+	}
+	RpcTraceWorkersFlag = cli.IntFlag{
+		Name:  "rpc.trace.workers",
+		Usage: "Trace the transactions of a block in debug_traceBlockByNumber/Hash in parallel on that many goroutines (1 = one after another)",
+		Value: 1,
+	}
+	RpcHealthMaxBlocksBehindFlag = cli.Uint64Flag{
+		Name:  "rpc.health.maxblocksbehind",
+		Usage: "The node is reported ready by /health/ready when its last synced block is at most that many blocks behind the highest known header",
+		Value: 4,
+	}
 	RpcTraceCompatFlag = cli.BoolFlag{
 		Name:  "trace.compat",
 		Usage: "Bug for bug compatibility with OE for trace_ routines",
 	}
+	KeyStoreDirFlag = cli.StringFlag{
+		Name:  "keystore",
+		Usage: "Directory for the encrypted account keys used by eth_sendTransaction, eth_sign and personal_ methods (default = inside the datadir)",
+	}
+	LightKDFFlag = cli.BoolFlag{
+		Name:  "lightkdf",
+		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
+	}
 
 	StarknetGrpcAddressFlag = cli.StringFlag{
 		Name:  "starknet.grpc.address",
@@ -420,6 +459,12 @@ var (
 		Value: 200,
 	}
 
+	OtsSearchMaxPageSizeFlag = cli.Uint64Flag{
+		Name:  "ots.search.max.pagesize",
+		Usage: "Sets a limit on the page size of ots_searchTransactionsBefore/After",
+		Value: 25,
+	}
+
 	HTTPPathPrefixFlag = cli.StringFlag{
 		Name:  "http.rpcprefix",
 		Usage: "HTTP path path prefix on which JSON-RPC is served. Use '/' to serve on all paths.",
@@ -656,6 +701,10 @@ var (
 		Name:  ethconfig.FlagSnapStop,
 		Usage: "Workaround to stop producing new snapshots, if you meet some snapshots-related critical bug",
 	}
+	SnapReceiptsFlag = cli.BoolFlag{
+		Name:  ethconfig.FlagSnapReceipts,
+		Usage: "Also retire the receipts of the ancient blocks into snapshots, they are read from there and shared by the downloader",
+	}
 	TorrentVerbosityFlag = cli.IntFlag{
 		Name:  "torrent.verbosity",
 		Value: 2,
@@ -676,6 +725,11 @@ var (
 		Value: 3,
 		Usage: "amount of files to download in parallel. If network has enough seeders 1-3 slot enough, if network has lack of seeders increase to 5-7 (too big value will slow down everything).",
 	}
+	WebSeedsFlag = cli.StringFlag{
+		Name:  "webseeds",
+		Usage: "Comma separated base URLs of HTTP(S) mirrors of the snapshots dir, files and their .torrent files are fetched from them instead of BitTorrent",
+		Value: "",
+	}
 	NoDownloaderFlag = cli.BoolFlag{
 		Name:  "no-downloader",
 		Usage: "to disable downloader component",
@@ -1429,6 +1483,7 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 	cfg.MemoryOverlay = ctx.GlobalBool(MemoryOverlayFlag.Name)
 	cfg.Snapshot.KeepBlocks = ctx.GlobalBool(SnapKeepBlocksFlag.Name)
 	cfg.Snapshot.Produce = !ctx.GlobalBool(SnapStopFlag.Name)
+	cfg.Snapshot.Receipts = ctx.GlobalBool(SnapReceiptsFlag.Name)
 	cfg.Snapshot.NoDownloader = ctx.GlobalBool(NoDownloaderFlag.Name)
 	cfg.Snapshot.Verify = ctx.GlobalBool(DownloaderVerifyFlag.Name)
 	cfg.Snapshot.DownloaderAddr = strings.TrimSpace(ctx.GlobalString(DownloaderAddrFlag.Name))
@@ -1447,7 +1502,7 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 		if err != nil {
 			panic(err)
 		}
-		cfg.Downloader, err = downloadercfg.New(cfg.Dirs.Snap, lvl, dbg, nodeConfig.P2P.NAT, downloadRate, uploadRate, ctx.GlobalInt(TorrentPortFlag.Name), ctx.GlobalInt(TorrentConnsPerFileFlag.Name), ctx.GlobalInt(TorrentDownloadSlotsFlag.Name))
+		cfg.Downloader, err = downloadercfg.New(cfg.Dirs.Snap, lvl, dbg, nodeConfig.P2P.NAT, downloadRate, uploadRate, ctx.GlobalInt(TorrentPortFlag.Name), ctx.GlobalInt(TorrentConnsPerFileFlag.Name), ctx.GlobalInt(TorrentDownloadSlotsFlag.Name), SplitAndTrim(ctx.GlobalString(WebSeedsFlag.Name)))
 		if err != nil {
 			panic(err)
 		}
@@ -245,6 +245,11 @@ func (c *Clique) Type() params.ConsensusType {
 	return params.CliqueConsensus
 }
 
+// DB returns the database the snapshot checkpoints are stored in
+func (c *Clique) DB() kv.RwDB {
+	return c.db
+}
+
 // Author implements consensus.Engine, returning the Ethereum address recovered
 // from the signature in the header's extra-data section.
 func (c *Clique) Author(header *types.Header) (common.Address, error) {
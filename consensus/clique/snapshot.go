@@ -30,6 +30,7 @@ import (
 
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/log/v3"
@@ -137,6 +138,65 @@ func lastSnapshot(db kv.RwDB) (uint64, error) {
 	return lastNum, nil
 }
 
+// ReadSnapshot reconstructs the snapshot at the given block for readers of the clique db which don't run
+// the engine, e.g. a standalone rpcdaemon. Unlike Clique.Snapshot it neither uses the in-memory caches nor
+// stores new checkpoints: it walks back to the closest snapshot persisted by the node (or to a trusted
+// checkpoint) and applies the collected headers on top of it.
+func ReadSnapshot(config *params.CliqueConfig, tx kv.Tx, chain consensus.ChainHeaderReader, number uint64, hash common.Hash) (*Snapshot, error) {
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = epochLength
+	}
+
+	var (
+		headers []*types.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		blob, err := tx.GetOne(kv.CliqueSeparate, SnapshotFullKey(number, hash))
+		if err != nil {
+			return nil, err
+		}
+		if len(blob) > 0 {
+			snap = new(Snapshot)
+			if err := json.Unmarshal(blob, snap); err != nil {
+				return nil, err
+			}
+			snap.config = &conf
+			break
+		}
+		header := chain.GetHeader(hash, number)
+		if header == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+		// Same rule as in Clique.Snapshot: the genesis and checkpoints deeper than the reorg limit are trusted
+		if number == 0 || (number%conf.Epoch == 0 && len(headers) > params.FullImmutabilityThreshold) {
+			snap = newSnapshot(&conf, number, hash, checkpointSigners(header))
+			break
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	sigcache, err := lru.NewARC(len(headers) + 1)
+	if err != nil {
+		return nil, err
+	}
+	return snap.apply(sigcache, headers...)
+}
+
+// checkpointSigners extracts the list of authorized signers from the extra-data of a checkpoint header
+func checkpointSigners(checkpoint *types.Header) []common.Address {
+	signers := make([]common.Address, (len(checkpoint.Extra)-ExtraVanity-ExtraSeal)/common.AddressLength)
+	for i := 0; i < len(signers); i++ {
+		copy(signers[i][:], checkpoint.Extra[ExtraVanity+i*common.AddressLength:])
+	}
+	return signers
+}
+
 // store inserts the snapshot into the database.
 func (s *Snapshot) store(db kv.RwDB) error {
 	blob, err := json.Marshal(s)
@@ -18,7 +18,9 @@ package clique_test
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
+	"reflect"
 	"sort"
 	"testing"
 
@@ -533,6 +535,18 @@ func TestClique(t *testing.T) {
 					t.Errorf("test %d, signer %d: signer mismatch: have %x, want %x", i, j, result[j], signers[j])
 				}
 			}
+			// The read-only reconstruction served by rpcdaemon must agree with the engine
+			roTx, err := cliqueDB.BeginRo(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			roSnap, err := clique.ReadSnapshot(config.Clique, roTx, stagedsync.ChainReader{Cfg: config, Db: olddb.NewObjectDatabase(m.DB)}, head.NumberU64(), head.Hash())
+			roTx.Rollback()
+			if err != nil {
+				t.Errorf("test %d: failed to read voting snapshot: %v", i, err)
+			} else if !reflect.DeepEqual(roSnap.GetSigners(), result) {
+				t.Errorf("test %d: read-only snapshot signers mismatch: have %x, want %x", i, roSnap.GetSigners(), result)
+			}
 			engine.Close()
 		})
 	}
@@ -0,0 +1,33 @@
+package misc
+
+import (
+	"fmt"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// VerifyEip4895Header verifies that the header carries the withdrawals root
+// (EIP-4895) if and only if Shanghai is active at its time.
+func VerifyEip4895Header(config *params.ChainConfig, header *types.Header) error {
+	shanghai := config.IsShanghai(header.Time)
+	if shanghai && header.WithdrawalsHash == nil {
+		return fmt.Errorf("header is missing withdrawalsHash")
+	}
+	if !shanghai && header.WithdrawalsHash != nil {
+		return fmt.Errorf("invalid withdrawalsHash before fork: have %x, expected 'nil'", *header.WithdrawalsHash)
+	}
+	return nil
+}
+
+// ApplyWithdrawals credits the beacon chain withdrawals of a block to their
+// target addresses (EIP-4895). Withdrawal amounts are denominated in Gwei.
+func ApplyWithdrawals(statedb *state.IntraBlockState, withdrawals []*types.Withdrawal) {
+	for _, w := range withdrawals {
+		amount := new(uint256.Int).Mul(uint256.NewInt(w.Amount), uint256.NewInt(params.GWei))
+		statedb.AddBalance(w.Address, amount)
+	}
+}
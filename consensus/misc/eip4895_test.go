@@ -0,0 +1,32 @@
+package misc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+func TestVerifyEip4895Header(t *testing.T) {
+	config := copyConfig(params.TestChainConfig)
+	config.ShanghaiTime = big.NewInt(1000)
+
+	root := types.EmptyRootHash
+	for i, test := range []struct {
+		time            uint64
+		withdrawalsHash *common.Hash
+		ok              bool
+	}{
+		{time: 999, withdrawalsHash: nil, ok: true},
+		{time: 999, withdrawalsHash: &root, ok: false},
+		{time: 1000, withdrawalsHash: nil, ok: false},
+		{time: 1000, withdrawalsHash: &root, ok: true},
+	} {
+		err := VerifyEip4895Header(config, &types.Header{Time: test.time, WithdrawalsHash: test.withdrawalsHash})
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("test %d: have error %v, want ok %v", i, err, test.ok)
+		}
+	}
+}
@@ -291,6 +291,11 @@ func (p *Parlia) Type() params.ConsensusType {
 	return params.ParliaConsensus
 }
 
+// DB returns the database the snapshot checkpoints are stored in
+func (p *Parlia) DB() kv.RwDB {
+	return p.db
+}
+
 // Author retrieves the Ethereum address of the account that minted the given
 // block, which may be different from the header's coinbase if a consensus
 // engine is based on signatures.
@@ -1190,7 +1195,7 @@ func (p *Parlia) applyTransaction(from common.Address, to common.Address, value
 	receipt := types.NewReceipt(false, *usedGas)
 	receipt.TxHash = expectedTx.Hash()
 	receipt.GasUsed = gasUsed
-	if err := ibs.FinalizeTx(p.chainConfig.Rules(header.Number.Uint64()), state.NewNoopWriter()); err != nil {
+	if err := ibs.FinalizeTx(p.chainConfig.Rules(header.Number.Uint64(), header.Time), state.NewNoopWriter()); err != nil {
 		return nil, nil, nil, err
 	}
 	// Set the receipt logs and create a bloom for filtering
@@ -114,6 +114,61 @@ func loadSnapshot(config *params.ParliaConfig, sigCache *lru.ARCCache, db kv.RwD
 	return snap, nil
 }
 
+// ReadSnapshot reconstructs the snapshot at the given block for readers of the parlia db which don't run
+// the engine, e.g. a standalone rpcdaemon. Unlike Parlia.snapshot it neither uses the in-memory caches nor
+// stores new checkpoints: it walks back to the closest snapshot persisted by the node (or to a trusted
+// checkpoint) and applies the collected headers on top of it.
+func ReadSnapshot(config *params.ParliaConfig, chainId *big.Int, tx kv.Tx, chain consensus.ChainHeaderReader, number uint64, hash common.Hash) (*Snapshot, error) {
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = defaultEpochLength
+	}
+	sigCache, err := lru.NewARC(inMemorySignatures)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		headers []*types.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		blob, err := tx.GetOne(kv.ParliaSnapshot, SnapshotFullKey(number, hash))
+		if err != nil {
+			return nil, err
+		}
+		if len(blob) > 0 {
+			snap = new(Snapshot)
+			if err := json.Unmarshal(blob, snap); err != nil {
+				return nil, err
+			}
+			snap.config = &conf
+			snap.sigCache = sigCache
+			break
+		}
+		header := chain.GetHeader(hash, number)
+		if header == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+		// The genesis is trusted, as well as the epoch checkpoints deeper than the reorg limit
+		if number == 0 || (number%conf.Epoch == 0 && len(headers) > params.FullImmutabilityThreshold) {
+			validators, err := ParseValidators(header.Extra[extraVanity : len(header.Extra)-extraSeal])
+			if err != nil {
+				return nil, err
+			}
+			snap = newSnapshot(&conf, sigCache, number, hash, validators)
+			break
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	return snap.apply(headers, chain, nil, chainId)
+}
+
 // store inserts the snapshot into the database.
 func (s *Snapshot) store(db kv.RwDB) error {
 	blob, err := json.Marshal(s)
@@ -246,6 +301,11 @@ func (s *Snapshot) apply(headers []*types.Header, chain consensus.ChainHeaderRea
 	return snap, nil
 }
 
+// GetValidators retrieves the list of validators in ascending order.
+func (s *Snapshot) GetValidators() []common.Address {
+	return s.validators()
+}
+
 // validators retrieves the list of validators in ascending order.
 func (s *Snapshot) validators() []common.Address {
 	validators := make([]common.Address, 0, len(s.Validators))
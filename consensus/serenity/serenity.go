@@ -191,6 +191,10 @@ func (s *Serenity) verifyHeader(chain consensus.ChainHeaderReader, header, paren
 		return errInvalidUncleHash
 	}
 
+	if err := misc.VerifyEip4895Header(chain.Config(), header); err != nil {
+		return err
+	}
+
 	return misc.VerifyEip1559Header(chain.Config(), parent, header)
 }
 
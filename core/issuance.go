@@ -0,0 +1,49 @@
+package core
+
+import (
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/consensus/serenity"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// BlockIssuance is the ether a block creates and destroys outside of the transfers between accounts
+type BlockIssuance struct {
+	BlockReward uint256.Int // of the miner, PoW only
+	UncleReward uint256.Int // of the miners of the uncles, PoW only
+	Withdrawals uint256.Int // credited from the beacon chain, EIP-4895
+	Burnt       uint256.Int // base fee, EIP-1559
+}
+
+// Issued is the ether created by the block
+func (i *BlockIssuance) Issued() *uint256.Int {
+	issued := new(uint256.Int).Add(&i.BlockReward, &i.UncleReward)
+	return issued.Add(issued, &i.Withdrawals)
+}
+
+// CalcBlockIssuance works out the issuance of a block from its header, uncles and withdrawals.
+//
+// Only ethash pays block rewards, and only before the merge - the proof-of-stake rewards are paid on the beacon chain
+// and reach the execution layer as withdrawals. Clique, AuRa, Bor and Parlia create no ether, their validators are
+// paid the transaction fees (by system transactions on Bor and Parlia). On Bor the base fee is not destroyed but sent
+// to the burnt contract, which bridges it back to L1 to be burnt there, so it is accounted as burnt as well.
+func CalcBlockIssuance(config *params.ChainConfig, header *types.Header, uncles []*types.Header, withdrawals []*types.Withdrawal) BlockIssuance {
+	var issuance BlockIssuance
+	if config.Consensus == params.EtHashConsensus && header.Difficulty.Cmp(serenity.SerenityDifficulty) != 0 {
+		blockReward, uncleRewards := ethash.AccumulateRewards(config, header, uncles)
+		issuance.BlockReward = blockReward
+		for i := range uncleRewards {
+			issuance.UncleReward.Add(&issuance.UncleReward, &uncleRewards[i])
+		}
+	}
+	for _, w := range withdrawals {
+		amount := new(uint256.Int).SetUint64(w.Amount)
+		issuance.Withdrawals.Add(&issuance.Withdrawals, amount.Mul(amount, uint256.NewInt(params.GWei)))
+	}
+	if header.BaseFee != nil {
+		issuance.Burnt.SetFromBig(header.BaseFee)
+		issuance.Burnt.Mul(&issuance.Burnt, new(uint256.Int).SetUint64(header.GasUsed))
+	}
+	return issuance
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalcBlockIssuance(t *testing.T) {
+	config := params.MainnetChainConfig
+	londonBlock := config.LondonBlock.Uint64()
+
+	// Proof-of-work block with an uncle, after London
+	header := &types.Header{Number: new(big.Int).SetUint64(londonBlock), Difficulty: big.NewInt(1), BaseFee: big.NewInt(10), GasUsed: 100}
+	uncle := &types.Header{Number: new(big.Int).SetUint64(londonBlock - 1)}
+	issuance := CalcBlockIssuance(config, header, []*types.Header{uncle}, nil)
+	blockReward, uncleRewards := ethash.AccumulateRewards(config, header, []*types.Header{uncle})
+	require.Equal(t, blockReward, issuance.BlockReward)
+	require.Equal(t, uncleRewards[0], issuance.UncleReward)
+	require.Equal(t, uint256.NewInt(1000), &issuance.Burnt)
+	require.Equal(t, new(uint256.Int).Add(&blockReward, &uncleRewards[0]), issuance.Issued())
+
+	// Proof-of-stake block pays no rewards, only the withdrawals are issued
+	header = &types.Header{Number: new(big.Int).SetUint64(londonBlock + 1), Difficulty: big.NewInt(0), BaseFee: big.NewInt(10), GasUsed: 100}
+	withdrawals := []*types.Withdrawal{{Amount: 1}, {Amount: 2}}
+	issuance = CalcBlockIssuance(config, header, nil, withdrawals)
+	require.True(t, issuance.BlockReward.IsZero())
+	require.Equal(t, uint256.NewInt(3*params.GWei), &issuance.Withdrawals)
+	require.Equal(t, uint256.NewInt(3*params.GWei), issuance.Issued())
+	require.Equal(t, uint256.NewInt(1000), &issuance.Burnt)
+
+	// Clique creates no ether
+	header = &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(2)}
+	issuance = CalcBlockIssuance(params.RinkebyChainConfig, header, nil, nil)
+	require.True(t, issuance.Issued().IsZero())
+	require.True(t, issuance.Burnt.IsZero())
+}
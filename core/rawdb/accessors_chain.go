@@ -628,6 +628,7 @@ func ReadBody(db kv.Getter, hash common.Hash, number uint64) (*types.Body, uint6
 	}
 	body := new(types.Body)
 	body.Uncles = bodyForStorage.Uncles
+	body.Withdrawals = bodyForStorage.Withdrawals
 
 	if bodyForStorage.TxAmount < 2 {
 		panic(fmt.Sprintf("block body hash too few txs amount: %d, %d", number, bodyForStorage.TxAmount))
@@ -664,9 +665,10 @@ func WriteRawBody(db kv.RwTx, hash common.Hash, number uint64, body *types.RawBo
 		return err
 	}
 	data := types.BodyForStorage{
-		BaseTxId: baseTxId,
-		TxAmount: uint32(len(body.Transactions)) + 2,
-		Uncles:   body.Uncles,
+		BaseTxId:    baseTxId,
+		TxAmount:    uint32(len(body.Transactions)) + 2,
+		Uncles:      body.Uncles,
+		Withdrawals: body.Withdrawals,
 	}
 	if err = WriteBodyForStorage(db, hash, number, &data); err != nil {
 		return fmt.Errorf("WriteBodyForStorage: %w", err)
@@ -685,9 +687,10 @@ func WriteBody(db kv.RwTx, hash common.Hash, number uint64, body *types.Body) er
 		return err
 	}
 	data := types.BodyForStorage{
-		BaseTxId: baseTxId,
-		TxAmount: uint32(len(body.Transactions)) + 2,
-		Uncles:   body.Uncles,
+		BaseTxId:    baseTxId,
+		TxAmount:    uint32(len(body.Transactions)) + 2,
+		Uncles:      body.Uncles,
+		Withdrawals: body.Withdrawals,
 	}
 	if err := WriteBodyForStorage(db, hash, number, &data); err != nil {
 		return fmt.Errorf("failed to write body: %w", err)
@@ -922,7 +925,7 @@ func HasReceipts(db kv.Has, hash common.Hash, number uint64) bool {
 // ReadRawReceipts retrieves all the transaction receipts belonging to a block.
 // The receipt metadata fields are not guaranteed to be populated, so they
 // should not be used. Use ReadReceipts instead if the metadata is needed.
-func ReadRawReceipts(db kv.Tx, blockNum uint64) types.Receipts {
+func ReadRawReceipts(db kv.Getter, blockNum uint64) types.Receipts {
 	// Retrieve the flattened receipt slice
 	data, err := db.GetOne(kv.Receipts, dbutils.EncodeBlockNumber(blockNum))
 	if err != nil {
@@ -1116,7 +1119,7 @@ func ReadBlock(tx kv.Getter, hash common.Hash, number uint64) *types.Block {
 	if body == nil {
 		return nil
 	}
-	return types.NewBlockFromStorage(hash, header, body.Transactions, body.Uncles)
+	return types.NewBlockFromStorage(hash, header, body.Transactions, body.Uncles).WithWithdrawals(body.Withdrawals)
 }
 
 func NonCanonicalBlockWithSenders(tx kv.Getter, hash common.Hash, number uint64) (*types.Block, []common.Address, error) {
@@ -1128,7 +1131,7 @@ func NonCanonicalBlockWithSenders(tx kv.Getter, hash common.Hash, number uint64)
 	if body == nil {
 		return nil, nil, fmt.Errorf("body not found for block %d, %x", number, hash)
 	}
-	block := types.NewBlockFromStorage(hash, header, body.Transactions, body.Uncles)
+	block := types.NewBlockFromStorage(hash, header, body.Transactions, body.Uncles).WithWithdrawals(body.Withdrawals)
 	senders, err := ReadSenders(tx, hash, number)
 	if err != nil {
 		return nil, nil, err
@@ -1634,6 +1637,126 @@ func WriteSnapshots(tx kv.RwTx, list []string) error {
 	return tx.Put(kv.DatabaseInfo, SapshotsKey, []byte(strings.Join(list, ",")))
 }
 
+var ReceiptsBackfillKey = []byte("receiptsBackfill")
+
+// BlockRange is the blocks [From, To)
+type BlockRange struct {
+	From, To uint64
+}
+
+// ReadReceiptsBackfill returns the ranges of blocks which receipts were downloaded from the peers, sorted
+// and not overlapping. Empty if receipts were never backfilled
+func ReadReceiptsBackfill(tx kv.Getter) ([]BlockRange, error) {
+	v, err := tx.GetOne(kv.DatabaseInfo, ReceiptsBackfillKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(v)%16 != 0 {
+		return nil, fmt.Errorf("invalid receipts backfill ranges, len %d", len(v))
+	}
+	ranges := make([]BlockRange, 0, len(v)/16)
+	for ; len(v) > 0; v = v[16:] {
+		ranges = append(ranges, BlockRange{From: binary.BigEndian.Uint64(v), To: binary.BigEndian.Uint64(v[8:])})
+	}
+	return ranges, nil
+}
+
+// WriteReceiptsBackfill adds the blocks [from, to) which receipts are downloaded from the peers to the recorded
+// ranges, overlapping and adjacent ranges are merged
+func WriteReceiptsBackfill(tx kv.RwTx, from, to uint64) error {
+	ranges, err := ReadReceiptsBackfill(tx)
+	if err != nil {
+		return err
+	}
+	ranges = append(ranges, BlockRange{From: from, To: to})
+	slices.SortFunc(ranges, func(a, b BlockRange) bool { return a.From < b.From })
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.From > last.To {
+			merged = append(merged, r)
+			continue
+		}
+		if r.To > last.To {
+			last.To = r.To
+		}
+	}
+
+	v := make([]byte, 16*len(merged))
+	for i, r := range merged {
+		binary.BigEndian.PutUint64(v[16*i:], r.From)
+		binary.BigEndian.PutUint64(v[16*i+8:], r.To)
+	}
+	return tx.Put(kv.DatabaseInfo, ReceiptsBackfillKey, v)
+}
+
+// BadBlocksPrefix is the prefix of the DatabaseInfo keys of the blocks rejected by the staged sync,
+// the key continues with the sequence number of the record and the block hash
+var BadBlocksPrefix = []byte("badBlock")
+
+// maxBadBlocks is how many bad blocks are kept, the earliest recorded blocks are evicted first
+const maxBadBlocks = 16
+
+// BadBlock is a block rejected by the staged sync, together with the reason of the rejection
+type BadBlock struct {
+	Block  *types.Block
+	Reason string
+}
+
+// WriteBadBlock records a block rejected by the staged sync. Only the latest maxBadBlocks recorded blocks are kept,
+// recording the same block again replaces its previous record.
+func WriteBadBlock(tx kv.RwTx, block *types.Block, reason string) error {
+	v, err := rlp.EncodeToBytes(&BadBlock{Block: block, Reason: reason})
+	if err != nil {
+		return fmt.Errorf("failed to RLP encode bad block: %w", err)
+	}
+	hash := block.Hash()
+
+	var keys, evict [][]byte
+	var seq uint64
+	if err = tx.ForPrefix(kv.DatabaseInfo, BadBlocksPrefix, func(k, _ []byte) error {
+		seq = binary.BigEndian.Uint64(k[len(BadBlocksPrefix):]) + 1
+		if bytes.Equal(k[len(BadBlocksPrefix)+8:], hash.Bytes()) {
+			evict = append(evict, common.CopyBytes(k))
+		} else {
+			keys = append(keys, common.CopyBytes(k))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for i := 0; i+maxBadBlocks <= len(keys); i++ {
+		evict = append(evict, keys[i])
+	}
+	for _, k := range evict {
+		if err = tx.Delete(kv.DatabaseInfo, k); err != nil {
+			return err
+		}
+	}
+
+	k := make([]byte, len(BadBlocksPrefix)+8+common.HashLength)
+	copy(k, BadBlocksPrefix)
+	binary.BigEndian.PutUint64(k[len(BadBlocksPrefix):], seq)
+	copy(k[len(BadBlocksPrefix)+8:], hash.Bytes())
+	return tx.Put(kv.DatabaseInfo, k, v)
+}
+
+// ReadBadBlocks returns the recorded bad blocks, in the order they were recorded
+func ReadBadBlocks(tx kv.Tx) ([]*BadBlock, error) {
+	var badBlocks []*BadBlock
+	if err := tx.ForPrefix(kv.DatabaseInfo, BadBlocksPrefix, func(k, v []byte) error {
+		badBlock := new(BadBlock)
+		if err := rlp.DecodeBytes(v, badBlock); err != nil {
+			return fmt.Errorf("invalid bad block RLP %x: %w", k, err)
+		}
+		badBlocks = append(badBlocks, badBlock)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return badBlocks, nil
+}
+
 // EnforceSnapshotsInvariant if DB has record - then file exists, if file exists - DB has record.
 func EnforceSnapshotsInvariant(tx kv.RwTx, snListInFolder []string) (filtered []string, err error) {
 	snList, err := ReadSnapshots(tx)
@@ -1699,6 +1822,66 @@ func PruneTable(tx kv.RwTx, table string, pruneTo uint64, ctx context.Context, l
 	return nil
 }
 
+// PruneReceipts deletes receipts and logs of the blocks before pruneTo, except the blocks which receipts were
+// backfilled from the peers (see WriteReceiptsBackfill)
+func PruneReceipts(tx kv.RwTx, pruneTo uint64, ctx context.Context) error {
+	backfilled, err := ReadReceiptsBackfill(tx)
+	if err != nil {
+		return err
+	}
+	for _, table := range []string{kv.Receipts, kv.Log} {
+		// prune the gaps between the backfilled ranges
+		var from uint64
+		for _, r := range backfilled {
+			if r.From >= pruneTo {
+				break
+			}
+			if from < r.From {
+				if err = pruneTableRange(tx, table, from, r.From, ctx); err != nil {
+					return err
+				}
+			}
+			if r.To > from {
+				from = r.To
+			}
+		}
+		if from < pruneTo {
+			if err = pruneTableRange(tx, table, from, pruneTo, ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneTableRange is same as PruneTable, but deletes only the blocks [from, to)
+func pruneTableRange(tx kv.RwTx, table string, from, to uint64, ctx context.Context) error {
+	c, err := tx.RwCursor(table)
+	if err != nil {
+		return fmt.Errorf("failed to create cursor for pruning %w", err)
+	}
+	defer c.Close()
+
+	for k, _, err := c.Seek(dbutils.EncodeBlockNumber(from)); k != nil; k, _, err = c.Next() {
+		if err != nil {
+			return err
+		}
+		blockNum := binary.BigEndian.Uint64(k)
+		if blockNum >= to {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return common2.ErrStopped
+		default:
+		}
+		if err = c.DeleteCurrent(); err != nil {
+			return fmt.Errorf("failed to remove for block %d: %w", blockNum, err)
+		}
+	}
+	return nil
+}
+
 func PruneTableDupSort(tx kv.RwTx, table string, logPrefix string, pruneTo uint64, logEvery *time.Ticker, ctx context.Context) error {
 	c, err := tx.RwCursorDupSort(table)
 	if err != nil {
@@ -24,8 +24,10 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/common/u256"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/crypto"
@@ -315,6 +317,43 @@ func TestCanonicalMappingStorage(t *testing.T) {
 	}
 }
 
+// Tests that bad blocks are stored with their reasons and only the latest ones are kept.
+func TestBadBlockStorage(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+
+	for i := 1; i <= maxBadBlocks+2; i++ {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i)), Extra: []byte("bad block")})
+		if err := WriteBadBlock(tx, block, fmt.Sprintf("reason %d", i)); err != nil {
+			t.Fatalf("WriteBadBlock failed: %v", err)
+		}
+	}
+	badBlocks, err := ReadBadBlocks(tx)
+	require.NoError(t, err)
+	require.Len(t, badBlocks, maxBadBlocks)
+	// The two oldest blocks are evicted
+	require.Equal(t, uint64(3), badBlocks[0].Block.NumberU64())
+	require.Equal(t, "reason 3", badBlocks[0].Reason)
+	require.Equal(t, uint64(maxBadBlocks+2), badBlocks[maxBadBlocks-1].Block.NumberU64())
+
+	// A lower block recorded later is kept, the earliest recorded one is evicted
+	low := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: []byte("late bad block")})
+	require.NoError(t, WriteBadBlock(tx, low, "late"))
+	badBlocks, err = ReadBadBlocks(tx)
+	require.NoError(t, err)
+	require.Len(t, badBlocks, maxBadBlocks)
+	require.Equal(t, uint64(4), badBlocks[0].Block.NumberU64())
+	require.Equal(t, low.Hash(), badBlocks[maxBadBlocks-1].Block.Hash())
+
+	// Recording the same block again replaces its record
+	require.NoError(t, WriteBadBlock(tx, badBlocks[0].Block, "again"))
+	badBlocks, err = ReadBadBlocks(tx)
+	require.NoError(t, err)
+	require.Len(t, badBlocks, maxBadBlocks)
+	require.Equal(t, uint64(5), badBlocks[0].Block.NumberU64())
+	require.Equal(t, uint64(4), badBlocks[maxBadBlocks-1].Block.NumberU64())
+	require.Equal(t, "again", badBlocks[maxBadBlocks-1].Reason)
+}
+
 // Tests that head headers and head blocks can be assigned, individually.
 func TestHeadStorage(t *testing.T) {
 	_, db := memdb.NewTestTx(t)
@@ -434,6 +473,39 @@ func TestBlockReceiptStorage(t *testing.T) {
 	}
 }
 
+func TestReceiptsBackfill(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	require := require.New(t)
+
+	for i := uint64(0); i < 100; i++ {
+		require.NoError(WriteReceipts(tx, i, types.Receipts{{CumulativeGasUsed: i, Logs: []*types.Log{{Address: common.Address{1}}}}}))
+	}
+	require.NoError(WriteReceiptsBackfill(tx, 30, 40))
+	require.NoError(WriteReceiptsBackfill(tx, 10, 20))
+	require.NoError(WriteReceiptsBackfill(tx, 60, 70))
+	require.NoError(WriteReceiptsBackfill(tx, 15, 25))  // overlapping
+	require.NoError(WriteReceiptsBackfill(tx, 70, 75))  // adjacent
+	require.NoError(WriteReceiptsBackfill(tx, 62, 64))  // contained
+	require.NoError(WriteReceiptsBackfill(tx, 90, 200)) // beyond pruning
+	backfilled, err := ReadReceiptsBackfill(tx)
+	require.NoError(err)
+	require.Equal([]BlockRange{{10, 25}, {30, 40}, {60, 75}, {90, 200}}, backfilled)
+
+	require.NoError(PruneReceipts(tx, 95, context.Background()))
+	for i := uint64(0); i < 100; i++ {
+		kept := (i >= 10 && i < 25) || (i >= 30 && i < 40) || (i >= 60 && i < 75) || i >= 90
+		has, err := tx.Has(kv.Receipts, dbutils.EncodeBlockNumber(i))
+		require.NoError(err)
+		require.Equal(kept, has, "receipts of block %d", i)
+		hasLogs := false
+		require.NoError(tx.ForPrefix(kv.Log, dbutils.EncodeBlockNumber(i), func(k, v []byte) error {
+			hasLogs = true
+			return nil
+		}))
+		require.Equal(kept, hasLogs, "logs of block %d", i)
+	}
+}
+
 func checkReceiptsRLP(have, want types.Receipts) error {
 	if len(have) != len(want) {
 		return fmt.Errorf("receipts sizes mismatch: have %d, want %d", len(have), len(want))
@@ -2,9 +2,11 @@ package rawdbreset
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 
 	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
@@ -228,6 +230,32 @@ func ResetTxLookup(tx kv.RwTx) error {
 	return nil
 }
 
+// ResetIssuance removes the issuance and burn totals of all blocks but the genesis one, the Issuance stage computes
+// them again from there
+func ResetIssuance(tx kv.RwTx) error {
+	var keys [][]byte
+	if err := tx.ForEach(kv.Issuance, nil, func(k, _ []byte) error {
+		if binary.BigEndian.Uint64(k[len(k)-8:]) > 0 {
+			keys = append(keys, common.CopyBytes(k))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := tx.Delete(kv.Issuance, k); err != nil {
+			return err
+		}
+	}
+	if err := stages.SaveStageProgress(tx, stages.Issuance, 0); err != nil {
+		return err
+	}
+	if err := stages.SaveStagePruneProgress(tx, stages.Issuance, 0); err != nil {
+		return err
+	}
+	return nil
+}
+
 func ResetFinish(tx kv.RwTx) error {
 	if err := stages.SaveStageProgress(tx, stages.Finish, 0); err != nil {
 		return err
@@ -89,6 +89,8 @@ type IntraBlockState struct {
 	trace          bool
 	accessList     *accessList
 	balanceInc     map[common.Address]*BalanceIncrease // Map of balance increases (without first reading the account)
+
+	transientStorage transientStorage // Transient storage of EIP-1153, cleared by Prepare
 }
 
 // Create a new state from a given trie
@@ -101,6 +103,7 @@ func New(stateReader StateReader) *IntraBlockState {
 		logs:              map[common.Hash][]*types.Log{},
 		journal:           newJournal(),
 		accessList:        newAccessList(),
+		transientStorage:  newTransientStorage(),
 		balanceInc:        map[common.Address]*BalanceIncrease{},
 	}
 }
@@ -136,6 +139,7 @@ func (sdb *IntraBlockState) Reset() {
 	sdb.logSize = 0
 	sdb.clearJournalAndRefund()
 	sdb.accessList = newAccessList()
+	sdb.transientStorage = newTransientStorage()
 	sdb.balanceInc = make(map[common.Address]*BalanceIncrease)
 }
 
@@ -505,6 +509,45 @@ func (sdb *IntraBlockState) Suicide(addr common.Address) bool {
 	return true
 }
 
+// Selfdestruct6780 implements the SELFDESTRUCT of EIP-6780: the account is only removed if it was created
+// in the same transaction
+func (sdb *IntraBlockState) Selfdestruct6780(addr common.Address) {
+	stateObject := sdb.getStateObject(addr)
+	if stateObject == nil {
+		return
+	}
+	if stateObject.newlyCreated {
+		sdb.Suicide(addr)
+	}
+}
+
+// SetTransientState sets transient storage for a given account. It
+// adds the change to the journal so that it can be rolled back
+// to its previous value if there is a revert.
+func (sdb *IntraBlockState) SetTransientState(addr common.Address, key common.Hash, value uint256.Int) {
+	prev := sdb.GetTransientState(addr, key)
+	if prev == value {
+		return
+	}
+	sdb.journal.append(transientStorageChange{
+		account:  &addr,
+		key:      key,
+		prevalue: prev,
+	})
+	sdb.setTransientState(addr, key, value)
+}
+
+// setTransientState is a lower level setter for transient storage. It
+// is called during a revert to prevent modifications to the journal.
+func (sdb *IntraBlockState) setTransientState(addr common.Address, key common.Hash, value uint256.Int) {
+	sdb.transientStorage.Set(addr, key, value)
+}
+
+// GetTransientState gets transient storage for a given account.
+func (sdb *IntraBlockState) GetTransientState(addr common.Address, key common.Hash) uint256.Int {
+	return sdb.transientStorage.Get(addr, key)
+}
+
 func (sdb *IntraBlockState) getStateObject(addr common.Address) (stateObject *stateObject) {
 	// Prefer 'live' objects.
 	if obj := sdb.stateObjects[addr]; obj != nil {
@@ -624,6 +667,7 @@ func (sdb *IntraBlockState) CreateAccount(addr common.Address, contractCreation
 
 	if contractCreation {
 		newObj.created = true
+		newObj.newlyCreated = true
 		newObj.data.Incarnation = prevInc + 1
 	} else {
 		newObj.suicided = false
@@ -735,6 +779,7 @@ func (sdb *IntraBlockState) FinalizeTx(chainRules *params.Rules, stateWriter Sta
 		if err := updateAccount(chainRules.IsSpuriousDragon, stateWriter, addr, so, true); err != nil {
 			return err
 		}
+		so.newlyCreated = false
 
 		sdb.stateObjectsDirty[addr] = struct{}{}
 	}
@@ -745,7 +790,7 @@ func (sdb *IntraBlockState) FinalizeTx(chainRules *params.Rules, stateWriter Sta
 
 func (sdb *IntraBlockState) SoftFinalise() {
 	for addr := range sdb.journal.dirties {
-		_, exist := sdb.stateObjects[addr]
+		so, exist := sdb.stateObjects[addr]
 		if !exist {
 			// ripeMD is 'touched' at block 1714175, in tx 0x1237f737031e40bcde4a8b7e717b2d15e3ecadfe49bb1bbc71ee9deb09c6fcf2
 			// That tx goes out of gas, and although the notion of 'touched' does not exist there, the
@@ -755,6 +800,7 @@ func (sdb *IntraBlockState) SoftFinalise() {
 			// Thus, we can safely ignore it here
 			continue
 		}
+		so.newlyCreated = false
 		sdb.stateObjectsDirty[addr] = struct{}{}
 	}
 	// Invalidate journal because reverting across transactions is not allowed.
@@ -813,6 +859,7 @@ func (sdb *IntraBlockState) Prepare(thash, bhash common.Hash, ti int) {
 	sdb.bhash = bhash
 	sdb.txIndex = ti
 	sdb.accessList = newAccessList()
+	sdb.transientStorage = newTransientStorage()
 }
 
 // no not lock
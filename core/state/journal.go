@@ -143,6 +143,12 @@ type (
 		prevhash common.Hash
 	}
 
+	transientStorageChange struct {
+		account  *common.Address
+		key      common.Hash
+		prevalue uint256.Int
+	}
+
 	// Changes to other state values.
 	refundChange struct {
 		prev uint64
@@ -262,6 +268,14 @@ func (ch fakeStorageChange) dirtied() *common.Address {
 	return ch.account
 }
 
+func (ch transientStorageChange) revert(s *IntraBlockState) {
+	s.setTransientState(*ch.account, ch.key, ch.prevalue)
+}
+
+func (ch transientStorageChange) dirtied() *common.Address {
+	return nil
+}
+
 func (ch refundChange) revert(s *IntraBlockState) {
 	s.refund = ch.prev
 }
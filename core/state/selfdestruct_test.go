@@ -0,0 +1,70 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+func TestSelfdestruct6780(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	rules := params.TestChainConfig.Rules(1, 0)
+
+	for _, tt := range []struct {
+		name     string
+		finalize func(state *IntraBlockState) error
+		suicided bool
+	}{
+		{
+			name:     "same transaction",
+			finalize: func(state *IntraBlockState) error { return nil },
+			suicided: true,
+		},
+		{
+			name: "after FinalizeTx",
+			finalize: func(state *IntraBlockState) error {
+				return state.FinalizeTx(rules, NewNoopWriter())
+			},
+		},
+		{
+			name: "after SoftFinalise",
+			finalize: func(state *IntraBlockState) error {
+				state.SoftFinalise()
+				return nil
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			state := New(NewPlainState(tx, 1))
+			addr := common.HexToAddress("aa")
+
+			state.CreateAccount(addr, true)
+			state.SetCode(addr, []byte{0x00})
+			state.AddBalance(addr, uint256.NewInt(1))
+			if err := tt.finalize(state); err != nil {
+				t.Fatal(err)
+			}
+
+			state.Selfdestruct6780(addr)
+			if got := state.HasSuicided(addr); got != tt.suicided {
+				t.Fatalf("suicided mismatch: have %v, want %v", got, tt.suicided)
+			}
+			if !tt.suicided && len(state.GetCode(addr)) == 0 {
+				t.Fatal("code of pre-existing contract removed")
+			}
+		})
+	}
+
+	// Accounts which are not created by a transaction of this block are never destroyed
+	state := New(NewPlainState(tx, 1))
+	addr := common.HexToAddress("bb")
+	state.SetCode(addr, []byte{0x00})
+	state.Selfdestruct6780(addr)
+	if state.HasSuicided(addr) {
+		t.Fatal("pre-existing account destroyed")
+	}
+}
@@ -88,6 +88,8 @@ type stateObject struct {
 	suicided  bool
 	deleted   bool // true if account was deleted during the lifetime of this object
 	created   bool // true if this object represents a newly created contract
+	// true if the contract was created in the current transaction, SELFDESTRUCT only removes such contracts (EIP-6780)
+	newlyCreated bool
 }
 
 // empty returns whether the account is considered empty.
@@ -0,0 +1,39 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+
+	"github.com/ledgerwatch/erigon/common"
+)
+
+func TestTransientStorage(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	state := New(NewPlainState(tx, 1))
+
+	addr := common.HexToAddress("aa")
+	key := common.HexToHash("01")
+	one, two := *uint256.NewInt(1), *uint256.NewInt(2)
+
+	state.SetTransientState(addr, key, one)
+	snapshot := state.Snapshot()
+	state.SetTransientState(addr, key, two)
+	if got := state.GetTransientState(addr, key); got != two {
+		t.Fatalf("transient storage mismatch: have %v, want %v", &got, &two)
+	}
+	state.RevertToSnapshot(snapshot)
+	if got := state.GetTransientState(addr, key); got != one {
+		t.Fatalf("transient storage mismatch after revert: have %v, want %v", &got, &one)
+	}
+	if got := state.GetTransientState(common.HexToAddress("bb"), key); !got.IsZero() {
+		t.Fatalf("expected empty transient storage for unknown account, got %v", &got)
+	}
+
+	// Transient storage does not survive the transaction
+	state.Prepare(common.Hash{}, common.Hash{}, 1)
+	if got := state.GetTransientState(addr, key); !got.IsZero() {
+		t.Fatalf("expected transient storage to be cleared, got %v", &got)
+	}
+}
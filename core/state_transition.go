@@ -125,7 +125,7 @@ func (result *ExecutionResult) Revert() []byte {
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation bool, isHomestead, isEIP2028 bool) (uint64, error) {
+func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation bool, isHomestead, isEIP2028, isEIP3860 bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if isContractCreation && isHomestead {
@@ -170,6 +170,19 @@ func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation b
 		if overflow != 0 {
 			return 0, ErrGasUintOverflow
 		}
+
+		// EIP-3860: the init code of a creation transaction is charged per word
+		if isContractCreation && isEIP3860 {
+			lenWords := (uint64(len(data)) + 31) / 32
+			overflow, product = bits.Mul64(lenWords, params.InitCodeWordGas)
+			if overflow != 0 {
+				return 0, ErrGasUintOverflow
+			}
+			gas, overflow = bits.Add64(gas, product, 0)
+			if overflow != 0 {
+				return 0, ErrGasUintOverflow
+			}
+		}
 	}
 	if accessList != nil {
 		overflow, product = bits.Mul64(uint64(len(accessList)), params.TxAccessListAddressGas)
@@ -375,10 +388,11 @@ func (st *StateTransition) TransitionDb(refunds bool, gasBailout bool) (*Executi
 	homestead := st.evm.ChainRules().IsHomestead
 	istanbul := st.evm.ChainRules().IsIstanbul
 	london := st.evm.ChainRules().IsLondon
+	shanghai := st.evm.ChainRules().IsShanghai
 	contractCreation := msg.To() == nil
 
 	// Check clauses 4-5, subtract intrinsic gas if everything is correct
-	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), contractCreation, homestead, istanbul)
+	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), contractCreation, homestead, istanbul, shanghai)
 	if err != nil {
 		return nil, err
 	}
@@ -387,6 +401,11 @@ func (st *StateTransition) TransitionDb(refunds bool, gasBailout bool) (*Executi
 	}
 	st.gas -= gas
 
+	// Check whether the init code size has been exceeded (EIP-3860)
+	if shanghai && contractCreation && len(st.data) > params.MaxInitCodeSize {
+		return nil, fmt.Errorf("%w: code size %v limit %v", ErrMaxInitCodeSizeExceeded, len(st.data), params.MaxInitCodeSize)
+	}
+
 	var bailout bool
 	// Gas bailout (for trace_call) should only be applied if there is not sufficient balance to perform value transfer
 	if gasBailout {
@@ -399,6 +418,10 @@ func (st *StateTransition) TransitionDb(refunds bool, gasBailout bool) (*Executi
 	if st.evm.ChainRules().IsBerlin {
 		st.state.PrepareAccessList(msg.From(), msg.To(), vm.ActivePrecompiles(st.evm.ChainRules()), msg.AccessList())
 	}
+	// EIP-3651: the coinbase is warm from the start of the transaction
+	if shanghai {
+		st.state.AddAddressToAccessList(st.evm.Context().Coinbase)
+	}
 
 	var (
 		ret   []byte
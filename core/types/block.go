@@ -80,25 +80,26 @@ func (n *BlockNonce) UnmarshalText(input []byte) error {
 // Header represents a block header in the Ethereum blockchain.
 // DESCRIBED: docs/programmers_guide/guide.md#organising-ethereum-state-into-a-merkle-tree
 type Header struct {
-	ParentHash  common.Hash    `json:"parentHash"       gencodec:"required"`
-	UncleHash   common.Hash    `json:"sha3Uncles"       gencodec:"required"`
-	Coinbase    common.Address `json:"miner"            gencodec:"required"`
-	Root        common.Hash    `json:"stateRoot"        gencodec:"required"`
-	TxHash      common.Hash    `json:"transactionsRoot" gencodec:"required"`
-	ReceiptHash common.Hash    `json:"receiptsRoot"     gencodec:"required"`
-	Bloom       Bloom          `json:"logsBloom"        gencodec:"required"`
-	Difficulty  *big.Int       `json:"difficulty"       gencodec:"required"`
-	Number      *big.Int       `json:"number"           gencodec:"required"`
-	GasLimit    uint64         `json:"gasLimit"         gencodec:"required"`
-	GasUsed     uint64         `json:"gasUsed"          gencodec:"required"`
-	Time        uint64         `json:"timestamp"        gencodec:"required"`
-	Extra       []byte         `json:"extraData"        gencodec:"required"`
-	MixDigest   common.Hash    `json:"mixHash"`
-	Nonce       BlockNonce     `json:"nonce"`
-	BaseFee     *big.Int       `json:"baseFeePerGas"`
-	Eip1559     bool           // to avoid relying on BaseFee != nil for that
-	Seal        []rlp.RawValue // AuRa POA network field
-	WithSeal    bool           // to avoid relying on Seal != nil for that
+	ParentHash      common.Hash    `json:"parentHash"       gencodec:"required"`
+	UncleHash       common.Hash    `json:"sha3Uncles"       gencodec:"required"`
+	Coinbase        common.Address `json:"miner"            gencodec:"required"`
+	Root            common.Hash    `json:"stateRoot"        gencodec:"required"`
+	TxHash          common.Hash    `json:"transactionsRoot" gencodec:"required"`
+	ReceiptHash     common.Hash    `json:"receiptsRoot"     gencodec:"required"`
+	Bloom           Bloom          `json:"logsBloom"        gencodec:"required"`
+	Difficulty      *big.Int       `json:"difficulty"       gencodec:"required"`
+	Number          *big.Int       `json:"number"           gencodec:"required"`
+	GasLimit        uint64         `json:"gasLimit"         gencodec:"required"`
+	GasUsed         uint64         `json:"gasUsed"          gencodec:"required"`
+	Time            uint64         `json:"timestamp"        gencodec:"required"`
+	Extra           []byte         `json:"extraData"        gencodec:"required"`
+	MixDigest       common.Hash    `json:"mixHash"`
+	Nonce           BlockNonce     `json:"nonce"`
+	BaseFee         *big.Int       `json:"baseFeePerGas"`
+	WithdrawalsHash *common.Hash   `json:"withdrawalsRoot"` // EIP-4895, nil for pre-Shanghai headers
+	Eip1559         bool           // to avoid relying on BaseFee != nil for that
+	Seal            []rlp.RawValue // AuRa POA network field
+	WithSeal        bool           // to avoid relying on Seal != nil for that
 }
 
 func (h Header) EncodingSize() int {
@@ -174,6 +175,9 @@ func (h Header) EncodingSize() int {
 		}
 		encodingSize += baseFeeLen
 	}
+	if h.WithdrawalsHash != nil {
+		encodingSize += 33
+	}
 
 	return encodingSize
 }
@@ -256,6 +260,9 @@ func (h Header) EncodeRLP(w io.Writer) error {
 		}
 		encodingSize += baseFeeLen
 	}
+	if h.WithdrawalsHash != nil {
+		encodingSize += 33
+	}
 
 	var b [33]byte
 	// Prefix
@@ -424,6 +431,16 @@ func (h Header) EncodeRLP(w io.Writer) error {
 		}
 	}
 
+	if h.WithdrawalsHash != nil {
+		b[0] = 128 + 32
+		if _, err := w.Write(b[:1]); err != nil {
+			return err
+		}
+		if _, err := w.Write(h.WithdrawalsHash.Bytes()); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -543,6 +560,21 @@ func (h *Header) DecodeRLP(s *rlp.Stream) error {
 		}
 		h.Eip1559 = true
 		h.BaseFee = new(big.Int).SetBytes(b)
+		if b, err = s.Bytes(); err != nil {
+			if errors.Is(err, rlp.EOL) {
+				h.WithdrawalsHash = nil
+				if err := s.ListEnd(); err != nil {
+					return fmt.Errorf("close header struct (no WithdrawalsHash): %w", err)
+				}
+				return nil
+			}
+			return fmt.Errorf("read WithdrawalsHash: %w", err)
+		}
+		if len(b) != 32 {
+			return fmt.Errorf("wrong size for WithdrawalsHash: %d", len(b))
+		}
+		h.WithdrawalsHash = new(common.Hash)
+		h.WithdrawalsHash.SetBytes(b)
 	}
 	if err := s.ListEnd(); err != nil {
 		return fmt.Errorf("close header struct: %w", err)
@@ -623,10 +655,11 @@ func (h *Header) copySeal() []rlp.RawValue {
 }
 
 // Body is a simple (mutable, non-safe) data container for storing and moving
-// a block's data contents (transactions and uncles) together.
+// a block's data contents (transactions, uncles and withdrawals) together.
 type Body struct {
 	Transactions []Transaction
 	Uncles       []*Header
+	Withdrawals  []*Withdrawal
 }
 
 // RawBody is semi-parsed variant of Body, where transactions are still unparsed RLP strings
@@ -635,12 +668,14 @@ type Body struct {
 type RawBody struct {
 	Transactions [][]byte
 	Uncles       []*Header
+	Withdrawals  []*Withdrawal
 }
 
 type BodyForStorage struct {
-	BaseTxId uint64
-	TxAmount uint32
-	Uncles   []*Header
+	BaseTxId    uint64
+	TxAmount    uint32
+	Uncles      []*Header
+	Withdrawals []*Withdrawal `rlp:"optional"`
 }
 
 // Block represents an entire block in the Ethereum blockchain.
@@ -648,6 +683,7 @@ type Block struct {
 	header       *Header
 	uncles       []*Header
 	transactions Transactions
+	withdrawals  []*Withdrawal
 
 	// caches
 	hash atomic.Value
@@ -681,11 +717,11 @@ func (b *Body) SendersFromTxs() []common.Address {
 }
 
 func (rb RawBody) EncodingSize() int {
-	payloadSize, _, _ := rb.payloadSize()
+	payloadSize, _, _, _ := rb.payloadSize()
 	return payloadSize
 }
 
-func (rb RawBody) payloadSize() (payloadSize int, txsLen, unclesLen int) {
+func (rb RawBody) payloadSize() (payloadSize int, txsLen, unclesLen, withdrawalsLen int) {
 	// size of Transactions
 	payloadSize++
 	for _, tx := range rb.Transactions {
@@ -714,11 +750,20 @@ func (rb RawBody) payloadSize() (payloadSize int, txsLen, unclesLen int) {
 		payloadSize += (bits.Len(uint(unclesLen)) + 7) / 8
 	}
 	payloadSize += unclesLen
-	return payloadSize, txsLen, unclesLen
+	// size of Withdrawals
+	if rb.Withdrawals != nil {
+		payloadSize++
+		withdrawalsLen = withdrawalsPayloadSize(rb.Withdrawals)
+		if withdrawalsLen >= 56 {
+			payloadSize += (bits.Len(uint(withdrawalsLen)) + 7) / 8
+		}
+		payloadSize += withdrawalsLen
+	}
+	return payloadSize, txsLen, unclesLen, withdrawalsLen
 }
 
 func (rb RawBody) EncodeRLP(w io.Writer) error {
-	payloadSize, txsLen, unclesLen := rb.payloadSize()
+	payloadSize, txsLen, unclesLen, withdrawalsLen := rb.payloadSize()
 	var b [33]byte
 	// prefix
 	if err := EncodeStructSizePrefix(payloadSize, w, b[:]); err != nil {
@@ -742,6 +787,12 @@ func (rb RawBody) EncodeRLP(w io.Writer) error {
 			return err
 		}
 	}
+	// encode Withdrawals
+	if rb.Withdrawals != nil {
+		if err := encodeWithdrawals(rb.Withdrawals, withdrawalsLen, w, b[:]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -783,15 +834,19 @@ func (rb *RawBody) DecodeRLP(s *rlp.Stream) error {
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
+	// decode Withdrawals
+	if rb.Withdrawals, err = decodeWithdrawals(s); err != nil {
+		return err
+	}
 	return s.ListEnd()
 }
 
 func (bb Body) EncodingSize() int {
-	payloadSize, _, _ := bb.payloadSize()
+	payloadSize, _, _, _ := bb.payloadSize()
 	return payloadSize
 }
 
-func (bb Body) payloadSize() (payloadSize int, txsLen, unclesLen int) {
+func (bb Body) payloadSize() (payloadSize int, txsLen, unclesLen, withdrawalsLen int) {
 	// size of Transactions
 	payloadSize++
 	for _, tx := range bb.Transactions {
@@ -828,11 +883,20 @@ func (bb Body) payloadSize() (payloadSize int, txsLen, unclesLen int) {
 		payloadSize += (bits.Len(uint(unclesLen)) + 7) / 8
 	}
 	payloadSize += unclesLen
-	return payloadSize, txsLen, unclesLen
+	// size of Withdrawals
+	if bb.Withdrawals != nil {
+		payloadSize++
+		withdrawalsLen = withdrawalsPayloadSize(bb.Withdrawals)
+		if withdrawalsLen >= 56 {
+			payloadSize += (bits.Len(uint(withdrawalsLen)) + 7) / 8
+		}
+		payloadSize += withdrawalsLen
+	}
+	return payloadSize, txsLen, unclesLen, withdrawalsLen
 }
 
 func (bb Body) EncodeRLP(w io.Writer) error {
-	payloadSize, txsLen, unclesLen := bb.payloadSize()
+	payloadSize, txsLen, unclesLen, withdrawalsLen := bb.payloadSize()
 	var b [33]byte
 	// prefix
 	if err := EncodeStructSizePrefix(payloadSize, w, b[:]); err != nil {
@@ -867,6 +931,12 @@ func (bb Body) EncodeRLP(w io.Writer) error {
 			return err
 		}
 	}
+	// encode Withdrawals
+	if bb.Withdrawals != nil {
+		if err := encodeWithdrawals(bb.Withdrawals, withdrawalsLen, w, b[:]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -908,9 +978,19 @@ func (bb *Body) DecodeRLP(s *rlp.Stream) error {
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
+	// decode Withdrawals
+	if bb.Withdrawals, err = decodeWithdrawals(s); err != nil {
+		return err
+	}
 	return s.ListEnd()
 }
 
+// BlockWithReceipts is a block together with the receipts produced by its execution.
+type BlockWithReceipts struct {
+	Block    *Block
+	Receipts Receipts
+}
+
 // NewBlock creates a new block. The input data is copied,
 // changes to header and to the field values will not affect the
 // block.
@@ -951,6 +1031,24 @@ func NewBlock(header *Header, txs []Transaction, uncles []*Header, receipts []*R
 	return b
 }
 
+// NewBlockWithWithdrawals creates a new post-Shanghai block. Like NewBlock it
+// derives the header's WithdrawalsHash from the given withdrawals.
+func NewBlockWithWithdrawals(header *Header, txs []Transaction, uncles []*Header, receipts []*Receipt, withdrawals []*Withdrawal) *Block {
+	b := NewBlock(header, txs, uncles, receipts)
+	if withdrawals == nil {
+		b.header.WithdrawalsHash = nil
+		return b
+	}
+	h := DeriveSha(Withdrawals(withdrawals))
+	b.header.WithdrawalsHash = &h
+	b.withdrawals = make([]*Withdrawal, len(withdrawals))
+	for i, w := range withdrawals {
+		cpy := *w
+		b.withdrawals[i] = &cpy
+	}
+	return b
+}
+
 // NewBlockFromStorage like NewBlock but used to create Block object when read it from DB
 // in this case no reason to copy parts, or re-calculate headers fields - they are all stored in DB
 func NewBlockFromStorage(hash common.Hash, header *Header, txs []Transaction, uncles []*Header) *Block {
@@ -980,6 +1078,10 @@ func CopyHeader(h *Header) *Header {
 		cpy.BaseFee = new(big.Int)
 		cpy.BaseFee.Set(h.BaseFee)
 	}
+	if h.WithdrawalsHash != nil {
+		cpy.WithdrawalsHash = new(common.Hash)
+		cpy.WithdrawalsHash.SetBytes(h.WithdrawalsHash.Bytes())
+	}
 	if len(h.Extra) > 0 {
 		cpy.Extra = make([]byte, len(h.Extra))
 		copy(cpy.Extra, h.Extra)
@@ -1033,6 +1135,10 @@ func (bb *Block) DecodeRLP(s *rlp.Stream) error {
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
+	// decode Withdrawals
+	if bb.withdrawals, err = decodeWithdrawals(s); err != nil {
+		return err
+	}
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
@@ -1040,7 +1146,7 @@ func (bb *Block) DecodeRLP(s *rlp.Stream) error {
 	return nil
 }
 
-func (bb Block) payloadSize() (payloadSize int, txsLen, unclesLen int) {
+func (bb Block) payloadSize() (payloadSize int, txsLen, unclesLen, withdrawalsLen int) {
 	// size of Header
 	payloadSize++
 	headerLen := bb.header.EncodingSize()
@@ -1086,17 +1192,26 @@ func (bb Block) payloadSize() (payloadSize int, txsLen, unclesLen int) {
 		payloadSize += (bits.Len(uint(unclesLen)) + 7) / 8
 	}
 	payloadSize += unclesLen
-	return payloadSize, txsLen, unclesLen
+	// size of Withdrawals
+	if bb.withdrawals != nil {
+		payloadSize++
+		withdrawalsLen = withdrawalsPayloadSize(bb.withdrawals)
+		if withdrawalsLen >= 56 {
+			payloadSize += (bits.Len(uint(withdrawalsLen)) + 7) / 8
+		}
+		payloadSize += withdrawalsLen
+	}
+	return payloadSize, txsLen, unclesLen, withdrawalsLen
 }
 
 func (bb Block) EncodingSize() int {
-	payloadSize, _, _ := bb.payloadSize()
+	payloadSize, _, _, _ := bb.payloadSize()
 	return payloadSize
 }
 
 // EncodeRLP serializes b into the Ethereum RLP block format.
 func (bb Block) EncodeRLP(w io.Writer) error {
-	payloadSize, txsLen, unclesLen := bb.payloadSize()
+	payloadSize, txsLen, unclesLen, withdrawalsLen := bb.payloadSize()
 	var b [33]byte
 	// prefix
 	if err := EncodeStructSizePrefix(payloadSize, w, b[:]); err != nil {
@@ -1139,11 +1254,18 @@ func (bb Block) EncodeRLP(w io.Writer) error {
 			return err
 		}
 	}
+	// encode Withdrawals
+	if bb.withdrawals != nil {
+		if err := encodeWithdrawals(bb.withdrawals, withdrawalsLen, w, b[:]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (b *Block) Uncles() []*Header          { return b.uncles }
 func (b *Block) Transactions() Transactions { return b.transactions }
+func (b *Block) Withdrawals() Withdrawals   { return b.withdrawals }
 
 func (b *Block) Transaction(hash common.Hash) Transaction {
 	for _, transaction := range b.transactions {
@@ -1185,7 +1307,7 @@ func (b *Block) Header() *Header { return CopyHeader(b.header) }
 
 // Body returns the non-header content of the block.
 func (b *Block) Body() *Body {
-	bd := &Body{Transactions: b.transactions, Uncles: b.uncles}
+	bd := &Body{Transactions: b.transactions, Uncles: b.uncles, Withdrawals: b.withdrawals}
 	bd.SendersFromTxs()
 	return bd
 }
@@ -1201,7 +1323,7 @@ func (b *Block) SendersToTxs(senders []common.Address) {
 // RawBody creates a RawBody based on the block. It is not very efficient, so
 // will probably be removed in favour of RawBlock. Also it panics
 func (b *Block) RawBody() *RawBody {
-	br := &RawBody{Transactions: make([][]byte, len(b.transactions)), Uncles: b.uncles}
+	br := &RawBody{Transactions: make([][]byte, len(b.transactions)), Uncles: b.uncles, Withdrawals: b.withdrawals}
 	for i, tx := range b.transactions {
 		var err error
 		br.Transactions[i], err = rlp.EncodeToBytes(tx)
@@ -1251,6 +1373,15 @@ func (b *Block) Copy() *Block {
 		uncles = append(uncles, CopyHeader(uncle))
 	}
 
+	var withdrawals []*Withdrawal
+	if b.withdrawals != nil {
+		withdrawals = make([]*Withdrawal, 0, len(b.withdrawals))
+		for _, w := range b.withdrawals {
+			cpy := *w
+			withdrawals = append(withdrawals, &cpy)
+		}
+	}
+
 	transactionsData, err := MarshalTransactionsBinary(b.transactions)
 	if err != nil {
 		panic(fmt.Errorf("MarshalTransactionsBinary failed: %w", err))
@@ -1280,6 +1411,7 @@ func (b *Block) Copy() *Block {
 		header:       CopyHeader(b.header),
 		uncles:       uncles,
 		transactions: transactions,
+		withdrawals:  withdrawals,
 		hash:         hashValue,
 		size:         sizeValue,
 		ReceivedAt:   b.ReceivedAt,
@@ -1296,6 +1428,7 @@ func (b *Block) WithSeal(header *Header) *Block {
 		header:       &cpy,
 		transactions: b.transactions,
 		uncles:       b.uncles,
+		withdrawals:  b.withdrawals,
 	}
 }
 
@@ -1313,6 +1446,15 @@ func (b *Block) WithBody(transactions []Transaction, uncles []*Header) *Block {
 	return block
 }
 
+// WithWithdrawals sets the withdrawal contents of a block, does not return a new block.
+func (b *Block) WithWithdrawals(withdrawals []*Withdrawal) *Block {
+	if withdrawals != nil {
+		b.withdrawals = make([]*Withdrawal, len(withdrawals))
+		copy(b.withdrawals, withdrawals)
+	}
+	return b
+}
+
 // Hash returns the keccak256 hash of b's header.
 // The hash is computed on the first call and cached thereafter.
 func (b *Block) Hash() common.Hash {
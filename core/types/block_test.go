@@ -205,6 +205,67 @@ func TestEIP2718BlockEncoding(t *testing.T) {
 	}
 }
 
+func TestWithdrawalsEncoding(t *testing.T) {
+	header := &Header{
+		Difficulty: common.Big0,
+		Number:     big.NewInt(1),
+		GasLimit:   30_000_000,
+		Time:       1681338455,
+		BaseFee:    big.NewInt(7),
+	}
+	withdrawals := []*Withdrawal{
+		{Index: 0, Validator: 1, Address: common.HexToAddress("0x00000000000000000000000000000000000000aa"), Amount: 1},
+		{Index: 1, Validator: 300, Address: common.HexToAddress("0x00000000000000000000000000000000000000bb"), Amount: 32_000_000_000},
+	}
+	block := NewBlockWithWithdrawals(header, nil, nil, nil, withdrawals)
+	if block.Header().WithdrawalsHash == nil {
+		t.Fatal("withdrawals hash is not set")
+	}
+
+	enc, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		t.Fatal("encode error: ", err)
+	}
+	if len(enc) != int(block.Size()) {
+		t.Errorf("encoding size mismatch: got %d, want %d", len(enc), block.Size())
+	}
+	var decoded Block
+	if err := rlp.DecodeBytes(enc, &decoded); err != nil {
+		t.Fatal("decode error: ", err)
+	}
+	if decoded.Hash() != block.Hash() {
+		t.Errorf("hash mismatch: got %x, want %x", decoded.Hash(), block.Hash())
+	}
+	if !reflect.DeepEqual(decoded.Withdrawals(), block.Withdrawals()) {
+		t.Errorf("withdrawals mismatch: got %v, want %v", decoded.Withdrawals(), block.Withdrawals())
+	}
+
+	var body Body
+	bodyEnc, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		t.Fatal("encode body error: ", err)
+	}
+	if err := rlp.DecodeBytes(bodyEnc, &body); err != nil {
+		t.Fatal("decode body error: ", err)
+	}
+	if !reflect.DeepEqual(body.Withdrawals, []*Withdrawal(block.Withdrawals())) {
+		t.Errorf("body withdrawals mismatch: got %v, want %v", body.Withdrawals, block.Withdrawals())
+	}
+
+	// pre-Shanghai bodies have no withdrawals list at all
+	bodyEnc, err = rlp.EncodeToBytes(&Body{})
+	if err != nil {
+		t.Fatal("encode body error: ", err)
+	}
+	body = Body{}
+	if err := rlp.DecodeBytes(bodyEnc, &body); err != nil {
+		t.Fatal("decode body error: ", err)
+	}
+	if body.Withdrawals != nil {
+		t.Errorf("expected nil withdrawals, got %v", body.Withdrawals)
+	}
+}
+
 func TestUncleHash(t *testing.T) {
 	uncles := make([]*Header, 0)
 	h := CalcUncleHash(uncles)
@@ -0,0 +1,193 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+//go:generate gencodec -type Withdrawal -field-override withdrawalMarshaling -out gen_withdrawal_json.go
+
+// Withdrawal represents a validator withdrawal from the consensus layer (EIP-4895).
+type Withdrawal struct {
+	Index     uint64         `json:"index"`          // monotonically increasing identifier issued by consensus layer
+	Validator uint64         `json:"validatorIndex"` // index of validator associated with withdrawal
+	Address   common.Address `json:"address"`        // target address for withdrawn ether
+	Amount    uint64         `json:"amount"`         // value of withdrawal in Gwei
+}
+
+// field type overrides for gencodec
+type withdrawalMarshaling struct {
+	Index     hexutil.Uint64
+	Validator hexutil.Uint64
+	Amount    hexutil.Uint64
+}
+
+func (obj *Withdrawal) EncodingSize() int {
+	encodingSize := 21 /* Address */
+	encodingSize++
+	encodingSize += uint64LenExcludingHead(obj.Index)
+	encodingSize++
+	encodingSize += uint64LenExcludingHead(obj.Validator)
+	encodingSize++
+	encodingSize += uint64LenExcludingHead(obj.Amount)
+	return encodingSize
+}
+
+func (obj *Withdrawal) EncodeRLP(w io.Writer) error {
+	encodingSize := obj.EncodingSize()
+
+	var b [33]byte
+	if err := EncodeStructSizePrefix(encodingSize, w, b[:]); err != nil {
+		return err
+	}
+	if err := encodeUint64(obj.Index, w, b[:]); err != nil {
+		return err
+	}
+	if err := encodeUint64(obj.Validator, w, b[:]); err != nil {
+		return err
+	}
+	b[0] = 128 + 20
+	if _, err := w.Write(b[:1]); err != nil {
+		return err
+	}
+	if _, err := w.Write(obj.Address.Bytes()); err != nil {
+		return err
+	}
+	return encodeUint64(obj.Amount, w, b[:])
+}
+
+func (obj *Withdrawal) DecodeRLP(s *rlp.Stream) error {
+	_, err := s.List()
+	if err != nil {
+		return err
+	}
+	if obj.Index, err = s.Uint(); err != nil {
+		return fmt.Errorf("read Index: %w", err)
+	}
+	if obj.Validator, err = s.Uint(); err != nil {
+		return fmt.Errorf("read Validator: %w", err)
+	}
+	var b []byte
+	if b, err = s.Bytes(); err != nil {
+		return fmt.Errorf("read Address: %w", err)
+	}
+	if len(b) != 20 {
+		return fmt.Errorf("wrong size for Address: %d", len(b))
+	}
+	copy(obj.Address[:], b)
+	if obj.Amount, err = s.Uint(); err != nil {
+		return fmt.Errorf("read Amount: %w", err)
+	}
+	return s.ListEnd()
+}
+
+// Withdrawals implements DerivableList for withdrawals.
+type Withdrawals []*Withdrawal
+
+// Len returns the length of s.
+func (s Withdrawals) Len() int { return len(s) }
+
+// EncodeIndex encodes the i'th withdrawal to w.
+func (s Withdrawals) EncodeIndex(i int, w *bytes.Buffer) {
+	if err := s[i].EncodeRLP(w); err != nil {
+		panic(err)
+	}
+}
+
+// withdrawalsPayloadSize returns the size of the RLP list payload of the given withdrawals.
+func withdrawalsPayloadSize(withdrawals []*Withdrawal) int {
+	var withdrawalsLen int
+	for _, withdrawal := range withdrawals {
+		withdrawalsLen++
+		withdrawalLen := withdrawal.EncodingSize()
+		if withdrawalLen >= 56 {
+			withdrawalsLen += (bits.Len(uint(withdrawalLen)) + 7) / 8
+		}
+		withdrawalsLen += withdrawalLen
+	}
+	return withdrawalsLen
+}
+
+func encodeWithdrawals(withdrawals []*Withdrawal, withdrawalsLen int, w io.Writer, b []byte) error {
+	if err := EncodeStructSizePrefix(withdrawalsLen, w, b); err != nil {
+		return err
+	}
+	for _, withdrawal := range withdrawals {
+		if err := withdrawal.EncodeRLP(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeWithdrawals decodes the optional trailing list of withdrawals. A missing
+// list (pre-Shanghai encoding) results in nil withdrawals.
+func decodeWithdrawals(s *rlp.Stream) ([]*Withdrawal, error) {
+	if _, err := s.List(); err != nil {
+		if errors.Is(err, rlp.EOL) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read Withdrawals: %w", err)
+	}
+	withdrawals := []*Withdrawal{}
+	var err error
+	for err == nil {
+		var withdrawal Withdrawal
+		if err = withdrawal.DecodeRLP(s); err != nil {
+			break
+		}
+		withdrawals = append(withdrawals, &withdrawal)
+	}
+	if !errors.Is(err, rlp.EOL) {
+		return nil, err
+	}
+	// end of Withdrawals
+	if err = s.ListEnd(); err != nil {
+		return nil, err
+	}
+	return withdrawals, nil
+}
+
+func uint64LenExcludingHead(i uint64) int {
+	if i < 128 {
+		return 0
+	}
+	return (bits.Len64(i) + 7) / 8
+}
+
+func encodeUint64(i uint64, w io.Writer, b []byte) error {
+	if i > 0 && i < 128 {
+		b[0] = byte(i)
+		_, err := w.Write(b[:1])
+		return err
+	}
+	l := uint64LenExcludingHead(i)
+	binary.BigEndian.PutUint64(b[1:], i)
+	b[8-l] = 128 + byte(l)
+	_, err := w.Write(b[8-l : 9])
+	return err
+}
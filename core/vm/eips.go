@@ -22,10 +22,16 @@ import (
 
 	"github.com/holiman/uint256"
 
+	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/params"
 )
 
 var activators = map[int]func(*JumpTable){
+	6780: enable6780,
+	5656: enable5656,
+	1153: enable1153,
+	3860: enable3860,
+	3855: enable3855,
 	3529: enable3529,
 	3198: enable3198,
 	2929: enable2929,
@@ -171,3 +177,117 @@ func opBaseFee(pc *uint64, interpreter *EVMInterpreter, callContext *ScopeContex
 	callContext.Stack.Push(baseFee)
 	return nil, nil
 }
+
+// enable3855 applies EIP-3855 (PUSH0 opcode)
+// - Adds an opcode that pushes the constant value 0 onto the stack.
+func enable3855(jt *JumpTable) {
+	// New opcode
+	jt[PUSH0] = &operation{
+		execute:     opPush0,
+		constantGas: GasQuickStep,
+		minStack:    minStack(0, 1),
+		maxStack:    maxStack(0, 1),
+	}
+}
+
+// opPush0 implements the PUSH0 opcode
+func opPush0(pc *uint64, interpreter *EVMInterpreter, callContext *ScopeContext) ([]byte, error) {
+	callContext.Stack.Push(new(uint256.Int))
+	return nil, nil
+}
+
+// enable3860 applies EIP-3860 (Limit and meter initcode)
+// - Charges InitCodeWordGas for every word of the initcode passed to CREATE and CREATE2.
+// https://eips.ethereum.org/EIPS/eip-3860
+func enable3860(jt *JumpTable) {
+	jt[CREATE].dynamicGas = gasCreateEip3860
+	jt[CREATE2].dynamicGas = gasCreate2Eip3860
+}
+
+// enable1153 applies EIP-1153 (Transient Storage)
+// - Adds TLOAD that reads from transient storage
+// - Adds TSTORE that writes to transient storage
+// https://eips.ethereum.org/EIPS/eip-1153
+func enable1153(jt *JumpTable) {
+	jt[TLOAD] = &operation{
+		execute:     opTload,
+		constantGas: params.WarmStorageReadCostEIP2929,
+		minStack:    minStack(1, 1),
+		maxStack:    maxStack(1, 1),
+		numPop:      1,
+		numPush:     1,
+	}
+	jt[TSTORE] = &operation{
+		execute:     opTstore,
+		constantGas: params.WarmStorageReadCostEIP2929,
+		minStack:    minStack(2, 0),
+		maxStack:    maxStack(2, 0),
+		numPop:      2,
+		writes:      true,
+	}
+}
+
+// opTload implements TLOAD opcode
+func opTload(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	loc := scope.Stack.Peek()
+	val := interpreter.evm.IntraBlockState().GetTransientState(scope.Contract.Address(), loc.Bytes32())
+	loc.Set(&val)
+	return nil, nil
+}
+
+// opTstore implements TSTORE opcode
+func opTstore(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	loc := scope.Stack.Pop()
+	val := scope.Stack.Pop()
+	interpreter.evm.IntraBlockState().SetTransientState(scope.Contract.Address(), loc.Bytes32(), val)
+	return nil, nil
+}
+
+// enable5656 applies EIP-5656 (MCOPY opcode)
+// - Adds an opcode that copies memory areas, the areas may overlap
+// https://eips.ethereum.org/EIPS/eip-5656
+func enable5656(jt *JumpTable) {
+	jt[MCOPY] = &operation{
+		execute:     opMcopy,
+		constantGas: GasFastestStep,
+		dynamicGas:  gasMcopy,
+		minStack:    minStack(3, 0),
+		maxStack:    maxStack(3, 0),
+		numPop:      3,
+		memorySize:  memoryMcopy,
+	}
+}
+
+// opMcopy implements MCOPY opcode
+func opMcopy(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	dst := scope.Stack.Pop()
+	src := scope.Stack.Pop()
+	length := scope.Stack.Pop()
+	// These values are checked for validity during the dynamic gas phase
+	scope.Memory.Copy(dst.Uint64(), src.Uint64(), length.Uint64())
+	return nil, nil
+}
+
+// enable6780 applies EIP-6780 (SELFDESTRUCT only in same transaction)
+// - SELFDESTRUCT only removes the account if it was created in the same transaction,
+// otherwise it just sends all the balance to the beneficiary
+// https://eips.ethereum.org/EIPS/eip-6780
+func enable6780(jt *JumpTable) {
+	jt[SELFDESTRUCT].execute = opSelfdestruct6780
+}
+
+// opSelfdestruct6780 implements SELFDESTRUCT opcode of EIP-6780
+func opSelfdestruct6780(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	beneficiary := scope.Stack.Pop()
+	callerAddr := scope.Contract.Address()
+	beneficiaryAddr := common.Address(beneficiary.Bytes20())
+	ibs := interpreter.evm.IntraBlockState()
+	balance := *ibs.GetBalance(callerAddr)
+	if interpreter.evm.Config().Debug {
+		interpreter.evm.Config().Tracer.CaptureSelfDestruct(callerAddr, beneficiaryAddr, balance.ToBig())
+	}
+	ibs.SubBalance(callerAddr, &balance)
+	ibs.AddBalance(beneficiaryAddr, &balance)
+	ibs.Selfdestruct6780(callerAddr)
+	return nil, nil
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/holiman/uint256"
@@ -545,6 +546,60 @@ func TestOpMstore(t *testing.T) {
 	}
 }
 
+func TestOpMCopy(t *testing.T) {
+	// Test cases from https://eips.ethereum.org/EIPS/eip-5656#test-cases
+	for i, tc := range []struct {
+		dst, src, len uint64
+		pre           string
+		want          string
+	}{
+		{ // MCOPY 0 32 32 - copy 32 bytes from offset 32 to offset 0.
+			dst: 0, src: 32, len: 32,
+			pre:  "0000000000000000000000000000000000000000000000000000000000000000 000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			want: "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f 000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+		},
+		{ // MCOPY 0 0 32 - copy 32 bytes from offset 0 to offset 0.
+			dst: 0, src: 0, len: 32,
+			pre:  "0101010101010101010101010101010101010101010101010101010101010101",
+			want: "0101010101010101010101010101010101010101010101010101010101010101",
+		},
+		{ // MCOPY 0 1 8 - copy 8 bytes from offset 1 to offset 0 (overlapping).
+			dst: 0, src: 1, len: 8,
+			pre:  "000102030405060708 000000000000000000000000000000000000000000000000",
+			want: "010203040506070808 000000000000000000000000000000000000000000000000",
+		},
+		{ // MCOPY 1 0 8 - copy 8 bytes from offset 0 to offset 1 (overlapping).
+			dst: 1, src: 0, len: 8,
+			pre:  "000102030405060708 000000000000000000000000000000000000000000000000",
+			want: "000001020304050607 000000000000000000000000000000000000000000000000",
+		},
+		{ // MCOPY with zero length does nothing.
+			dst: 32, src: 0, len: 0,
+			pre:  "0101010101010101010101010101010101010101010101010101010101010101",
+			want: "0101010101010101010101010101010101010101010101010101010101010101",
+		},
+	} {
+		var (
+			env = NewEVM(BlockContext{
+				ContractHasTEVM: func(common.Hash) (bool, error) { return false, nil },
+			}, TxContext{}, nil, params.TestChainConfig, Config{})
+			stack          = stack.New()
+			mem            = NewMemory()
+			evmInterpreter = NewEVMInterpreter(env, env.Config())
+			pc             = uint64(0)
+		)
+		env.interpreter = evmInterpreter
+		data := common.FromHex(strings.ReplaceAll(tc.pre, " ", ""))
+		mem.Resize(uint64(len(data)))
+		mem.Set(0, uint64(len(data)), data)
+		stack.PushN(*uint256.NewInt(tc.len), *uint256.NewInt(tc.src), *uint256.NewInt(tc.dst))
+		opMcopy(&pc, evmInterpreter, &ScopeContext{mem, stack, nil})
+		if have, want := common.Bytes2Hex(mem.Data()), strings.ReplaceAll(tc.want, " ", ""); have != want {
+			t.Errorf("case %d: have %v, want %v", i, have, want)
+		}
+	}
+}
+
 func BenchmarkOpMstore(bench *testing.B) {
 	var (
 		env = NewEVM(BlockContext{
@@ -53,6 +53,10 @@ type IntraBlockState interface {
 
 	Suicide(common.Address) bool
 	HasSuicided(common.Address) bool
+	Selfdestruct6780(common.Address)
+
+	GetTransientState(addr common.Address, key common.Hash) uint256.Int
+	SetTransientState(addr common.Address, key common.Hash, value uint256.Int)
 
 	// Exist reports whether the given account exists in state.
 	// Notably this should also return true for suicided accounts.
@@ -23,6 +23,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
 	"github.com/ledgerwatch/erigon/accounts/abi"
 	"github.com/ledgerwatch/erigon/common"
@@ -593,3 +594,102 @@ func TestEip2929Cases(t *testing.T) {
 			"account (cheap)", code)
 	}
 }
+
+func cancunConfig() *params.ChainConfig {
+	cfg := new(Config)
+	setDefaults(cfg)
+	cfg.ChainConfig.ShanghaiTime = new(big.Int)
+	cfg.ChainConfig.CancunTime = new(big.Int)
+	return cfg.ChainConfig
+}
+
+// TestTransientStorageStaticCall checks that TSTORE is a state modifying
+// operation, which fails within STATICCALL but succeeds within CALL.
+func TestTransientStorageStaticCall(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	statedb := state.New(state.NewDbStateReader(tx))
+	caller, callee := common.HexToAddress("0x0a"), common.HexToAddress("0x0b")
+	statedb.SetCode(callee, []byte{
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.TSTORE),
+		byte(vm.STOP),
+	})
+	statedb.SetCode(caller, []byte{
+		// sstore(0, staticcall(gas, 0x0b, 0, 0, 0, 0))
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0x0b, byte(vm.GAS), byte(vm.STATICCALL),
+		byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		// sstore(1, call(gas, 0x0b, 0, 0, 0, 0, 0))
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0x0b, byte(vm.GAS), byte(vm.CALL),
+		byte(vm.PUSH1), 1, byte(vm.SSTORE),
+		byte(vm.STOP),
+	})
+
+	if _, _, err := Call(caller, nil, &Config{ChainConfig: cancunConfig(), State: statedb, kv: tx}); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+
+	var staticCall, call uint256.Int
+	key0, key1 := common.HexToHash("0x00"), common.HexToHash("0x01")
+	statedb.GetState(caller, &key0, &staticCall)
+	statedb.GetState(caller, &key1, &call)
+	if !staticCall.IsZero() {
+		t.Error("expected TSTORE within STATICCALL to fail")
+	}
+	if call.Uint64() != 1 {
+		t.Error("expected TSTORE within CALL to succeed")
+	}
+}
+
+// TestSelfdestruct6780 checks that SELFDESTRUCT only transfers the balance of a
+// pre-existing contract, and only destroys contracts created in the same transaction.
+func TestSelfdestruct6780(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	statedb := state.New(state.NewDbStateReader(tx))
+	beneficiary := common.HexToAddress("0xbb")
+	selfdestruct := []byte{byte(vm.PUSH1), 0xbb, byte(vm.SELFDESTRUCT)}
+
+	existing := common.HexToAddress("0x0a")
+	statedb.SetCode(existing, selfdestruct)
+	statedb.AddBalance(existing, uint256.NewInt(10))
+
+	// sstore(0, create(0, 29, 3)) with selfdestructing init code
+	factory := common.HexToAddress("0x0c")
+	statedb.SetCode(factory, []byte{
+		byte(vm.PUSH3), selfdestruct[0], selfdestruct[1], selfdestruct[2],
+		byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 3, byte(vm.PUSH1), 29, byte(vm.PUSH1), 0, byte(vm.CREATE),
+		byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.STOP),
+	})
+
+	cfg := &Config{ChainConfig: cancunConfig(), State: statedb, kv: tx}
+	if _, _, err := Call(existing, nil, cfg); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if statedb.HasSuicided(existing) {
+		t.Error("pre-existing contract destroyed")
+	}
+	if len(statedb.GetCode(existing)) == 0 {
+		t.Error("code of pre-existing contract removed")
+	}
+	if balance := statedb.GetBalance(existing); !balance.IsZero() {
+		t.Errorf("balance of pre-existing contract not transferred: have %d", balance)
+	}
+	if balance := statedb.GetBalance(beneficiary); balance.Uint64() != 10 {
+		t.Errorf("balance of beneficiary mismatch: have %d, want 10", balance)
+	}
+
+	if _, _, err := Call(factory, nil, cfg); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	var created uint256.Int
+	key := common.HexToHash("0x00")
+	statedb.GetState(factory, &key, &created)
+	if created.IsZero() {
+		t.Fatal("expected contract creation to succeed")
+	}
+	if !statedb.HasSuicided(common.Address(created.Bytes20())) {
+		t.Error("contract created in the same transaction not destroyed")
+	}
+}
@@ -23,6 +23,7 @@ type EthAPIBackend struct {
 	allowUnprotectedTxs bool
 	eth                 *Ethereum
 	gpo                 *gasprice.Oracle
+	blockReader         services.FullBlockReader
 }
 
 // ChainConfig returns the active chain configuration.
@@ -145,9 +146,12 @@ func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (type
 		return nil, nil
 	}
 
-	block := rawdb.ReadBlock(b.eth.chainDb, hash, *number)
+	block, senders, err := b.blockReader.BlockWithSenders(ctx, b.eth.chainDb, hash, *number)
+	if err != nil {
+		return nil, err
+	}
 
-	if cached := b.tryGetReceiptsFromDb(block); cached != nil {
+	if cached := b.tryGetReceiptsFromDb(ctx, block, senders); cached != nil {
 		return cached, nil
 	}
 
@@ -176,12 +180,16 @@ func (b *EthAPIBackend) getReceiptsByReApplyingTransactions(block *types.Block,
 	return receipts, nil
 }
 
-func (b *EthAPIBackend) tryGetReceiptsFromDb(block *types.Block) types.Receipts {
-	return rawdb.ReadReceipts(
-		b.eth.chainDb,
-		block.Hash(),
-		block.NumberU64(),
-	)
+// tryGetReceiptsFromDb reads the receipts from the db or, once retired, from the receipts snapshots
+func (b *EthAPIBackend) tryGetReceiptsFromDb(ctx context.Context, block *types.Block, senders []common.Address) types.Receipts {
+	receipts, err := b.blockReader.RawReceipts(ctx, b.eth.chainDb, block.NumberU64())
+	if err != nil || receipts == nil {
+		return nil
+	}
+	if err := receipts.DeriveFields(block.Hash(), block.NumberU64(), block.Transactions(), senders); err != nil {
+		return nil
+	}
+	return receipts
 }
 
 func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
@@ -48,6 +48,7 @@ import (
 	"github.com/ledgerwatch/erigon/cmd/downloader/downloadergrpc"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/commands"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/health"
 	"github.com/ledgerwatch/erigon/cmd/sentry/sentry"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/debug"
@@ -378,7 +379,7 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 	}
 
 	// proof-of-stake mining
-	assembleBlockPOS := func(param *core.BlockBuilderParameters, interrupt *int32) (*types.Block, error) {
+	assembleBlockPOS := func(param *core.BlockBuilderParameters, interrupt *int32) (*types.BlockWithReceipts, error) {
 		miningStatePos := stagedsync.NewProposingState(&config.Miner)
 		miningStatePos.MiningConfig.Etherbase = param.SuggestedFeeRecipient
 		proposingSync := stagedsync.New(
@@ -415,6 +416,16 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 		blockReader, chainConfig, assembleBlockPOS, backend.sentriesClient.Hd, config.Miner.EnabledPOS)
 	miningRPC = privateapi.NewMiningServer(ctx, backend, ethashApi)
 
+	var clq *clique.Clique
+	if c, ok := backend.engine.(*clique.Clique); ok {
+		clq = c
+	} else if cl, ok := backend.engine.(*serenity.Serenity); ok {
+		if c, ok := cl.InnerEngine().(*clique.Clique); ok {
+			clq = c
+		}
+	}
+	cliqueRPC := privateapi.NewCliqueServer(clq)
+
 	if stack.Config().PrivateApiAddr != "" {
 		var creds credentials.TransportCredentials
 		if stack.Config().TLSConnection {
@@ -428,6 +439,7 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 			ethBackendRPC,
 			backend.txPool2GrpcServer,
 			miningRPC,
+			cliqueRPC,
 			stack.Config().PrivateApiAddr,
 			stack.Config().PrivateApiRateLimit,
 			creds,
@@ -529,7 +541,7 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 
 		if badBlockHeader != nil {
 			unwindPoint := badBlockHeader.Number.Uint64() - 1
-			backend.stagedSync.UnwindTo(unwindPoint, config.BadBlockHash)
+			backend.stagedSync.UnwindTo(unwindPoint, config.BadBlockHash, errors.New("marked as bad by the --bad.block flag"))
 		}
 	}
 
@@ -552,18 +564,28 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 			ethBackendRPC,
 			backend.txPool2GrpcServer,
 			miningRPC,
+			cliqueRPC,
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		var borDb kv.RoDB
-		if casted, ok := backend.engine.(*bor.Bor); ok {
-			borDb = casted.DB
+		var consensusDb kv.RoDB
+		engine := backend.engine
+		if casted, ok := engine.(*serenity.Serenity); ok {
+			engine = casted.InnerEngine()
 		}
-		apiList := commands.APIList(chainKv, borDb, ethRpcClient, txPoolRpcClient, miningRpcClient, starkNetRpcClient, ff, stateCache, blockReader, httpRpcCfg)
+		switch casted := engine.(type) {
+		case *bor.Bor:
+			consensusDb = casted.DB
+		case *clique.Clique:
+			consensusDb = casted.DB()
+		case *parlia.Parlia:
+			consensusDb = casted.DB()
+		}
+		apiList := commands.APIList(chainKv, consensusDb, ethRpcClient, txPoolRpcClient, miningRpcClient, starkNetRpcClient, ff, stateCache, blockReader, httpRpcCfg)
 		go func() {
-			if err := cli.StartRpcServer(ctx, httpRpcCfg, apiList); err != nil {
+			if err := cli.StartRpcServer(ctx, httpRpcCfg, apiList, health.NewBackend(chainKv, txPoolRpcClient, allSnapshots)); err != nil {
 				log.Error(err.Error())
 				return
 			}
@@ -855,6 +877,14 @@ func (s *Ethereum) Start() error {
 
 	go stages2.StageLoop(s.sentryCtx, s.chainDB, s.stagedSync, s.sentriesClient.Hd, s.notifications, s.sentriesClient.UpdateHead, s.waitForStageLoopStop, s.config.Sync.LoopThrottle)
 
+	if s.config.Sync.ReceiptsBackfillTo > s.config.Sync.ReceiptsBackfillFrom {
+		go func() {
+			if err := s.sentriesClient.Rd.Backfill(s.sentryCtx, s.config.Sync.ReceiptsBackfillFrom, s.config.Sync.ReceiptsBackfillTo, s.sentriesClient.SendReceiptRequest, s.sentriesClient.Penalize); err != nil && !errors.Is(err, context.Canceled) {
+				log.Error("[Receipts backfill] Failed", "err", err)
+			}
+		}()
+	}
+
 	return nil
 }
 
@@ -123,6 +123,7 @@ func init() {
 type Snapshot struct {
 	Enabled        bool
 	KeepBlocks     bool // produce new snapshots of blocks but don't remove blocks from DB
+	Receipts       bool // also produce snapshots of receipts, and remove the retired receipts from DB
 	Produce        bool // produce new snapshots
 	NoDownloader   bool // possible to use snapshots without calling Downloader
 	Verify         bool // verify snapshots on startup
@@ -140,12 +141,16 @@ func (s Snapshot) String() string {
 	if !s.Produce {
 		out = append(out, "--"+FlagSnapStop+"=true")
 	}
+	if s.Receipts {
+		out = append(out, "--"+FlagSnapReceipts+"=true")
+	}
 	return strings.Join(out, " ")
 }
 
 var (
 	FlagSnapKeepBlocks = "snap.keepblocks"
 	FlagSnapStop       = "snap.stop"
+	FlagSnapReceipts   = "snap.receipts"
 )
 
 func NewSnapCfg(enabled, keepBlocks, produce bool) Snapshot {
@@ -241,6 +246,9 @@ type Sync struct {
 
 	BlockDownloaderWindow      int
 	BodyDownloadTimeoutSeconds int // TODO: change to duration
+
+	// ReceiptsBackfillFrom, ReceiptsBackfillTo - blocks [from, to) which missing receipts are downloaded from the peers
+	ReceiptsBackfillFrom, ReceiptsBackfillTo uint64
 }
 
 // Chains where snapshots are enabled by default
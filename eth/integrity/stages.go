@@ -0,0 +1,652 @@
+package integrity
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/changeset"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/eth/calltracer"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/services"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// Divergence - first block on which output of the stage doesn't match its input
+type Divergence struct {
+	Stage stages.SyncStage
+	Block uint64
+	Err   error
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("stage %s diverged at block %d: %s", d.Stage, d.Block, d.Err)
+}
+
+func diverged(block uint64, format string, args ...interface{}) *Divergence {
+	return &Divergence{Block: block, Err: fmt.Errorf(format, args...)}
+}
+
+type VerifyCfg struct {
+	ChainConfig *params.ChainConfig
+	BlockReader services.FullBlockReader
+	Engine      consensus.Engine // to re-execute blocks, nil - CallTraces are not checked
+	Prune       prune.Mode
+	Sample      uint64 // re-execute every Sample-th block, hash every Sample-th state entry. 0 - skip such checks
+}
+
+// stageInputs - stage which output is input of given stage, stage can't be ahead of it
+var stageInputs = map[stages.SyncStage]stages.SyncStage{
+	stages.BlockHashes:         stages.Headers,
+	stages.Bodies:              stages.Headers,
+	stages.Senders:             stages.Bodies,
+	stages.Execution:           stages.Senders,
+	stages.HashState:           stages.Execution,
+	stages.IntermediateHashes:  stages.HashState,
+	stages.AccountHistoryIndex: stages.Execution,
+	stages.StorageHistoryIndex: stages.Execution,
+	stages.LogIndex:            stages.Execution,
+	stages.CallTraces:          stages.Execution,
+	stages.TxLookup:            stages.Execution,
+	stages.Finish:              stages.Execution,
+	stages.Issuance:            stages.Bodies,
+}
+
+type stageCheck func(v *stageVerifier, from, to uint64) error
+
+var stageChecks = map[stages.SyncStage]stageCheck{
+	stages.Headers:             checkHeaders,
+	stages.BlockHashes:         checkBlockHashes,
+	stages.Bodies:              checkBodies,
+	stages.Senders:             checkSenders,
+	stages.Execution:           checkReceipts,
+	stages.HashState:           checkHashState,
+	stages.IntermediateHashes:  checkStateRoot,
+	stages.AccountHistoryIndex: checkAccountHistoryIndex,
+	stages.StorageHistoryIndex: checkStorageHistoryIndex,
+	stages.LogIndex:            checkLogIndex,
+	stages.CallTraces:          checkCallTraces,
+	stages.TxLookup:            checkTxLookup,
+	stages.Issuance:            checkIssuance,
+}
+
+// VerifyStages - cross-checks output of every stage against its input on blocks [from, to], returns first divergence
+// of each stage. Stages are checked up to their progress.
+func VerifyStages(ctx context.Context, tx kv.Tx, cfg VerifyCfg, from, to uint64) ([]*Divergence, error) {
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+
+	var res []*Divergence
+	for _, stage := range append(stages.AllStages, stages.Issuance) {
+		progress, err := stages.GetStageProgress(tx, stage)
+		if err != nil {
+			return nil, err
+		}
+		if input, ok := stageInputs[stage]; ok {
+			inputProgress, err := stages.GetStageProgress(tx, input)
+			if err != nil {
+				return nil, err
+			}
+			if progress > inputProgress {
+				res = append(res, &Divergence{Stage: stage, Block: inputProgress + 1, Err: fmt.Errorf("progress %d is ahead of %s progress %d", progress, input, inputProgress)})
+				continue
+			}
+		}
+		check, ok := stageChecks[stage]
+		if !ok || from > progress || from > to {
+			continue
+		}
+		log.Info("[verify] Stage", "name", stage, "from", from, "to", minU64(to, progress))
+		v := &stageVerifier{ctx: ctx, tx: tx, cfg: cfg, stage: stage, progress: progress, logEvery: logEvery}
+		err = check(v, from, minU64(to, progress))
+		var d *Divergence
+		if errors.As(err, &d) {
+			d.Stage = stage
+			res = append(res, d)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", stage, err)
+		}
+	}
+	return res, nil
+}
+
+type stageVerifier struct {
+	ctx      context.Context
+	tx       kv.Tx
+	cfg      VerifyCfg
+	stage    stages.SyncStage
+	progress uint64
+	logEvery *time.Ticker
+}
+
+func (v *stageVerifier) tick(blockNum uint64) error {
+	select {
+	case <-v.ctx.Done():
+		return v.ctx.Err()
+	case <-v.logEvery.C:
+		log.Info(fmt.Sprintf("[verify] %s", v.stage), "block", blockNum)
+	default:
+	}
+	return nil
+}
+
+// kept - first block of which pruned data is still in DB
+func (v *stageVerifier) kept(amount prune.BlockAmount, from uint64) uint64 {
+	if amount == nil || !amount.Enabled() {
+		return from
+	}
+	return maxU64(from, amount.PruneTo(v.progress)+1)
+}
+
+func (v *stageVerifier) header(blockNum uint64) (*types.Header, error) {
+	hash, err := v.cfg.BlockReader.CanonicalHash(v.ctx, v.tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if hash == (common.Hash{}) {
+		return nil, diverged(blockNum, "no canonical hash")
+	}
+	header, err := v.cfg.BlockReader.Header(v.ctx, v.tx, hash, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, diverged(blockNum, "no header %x", hash)
+	}
+	return header, nil
+}
+
+func (v *stageVerifier) body(header *types.Header) (*types.Body, error) {
+	blockNum := header.Number.Uint64()
+	body, err := v.cfg.BlockReader.BodyWithTransactions(v.ctx, v.tx, header.Hash(), blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, diverged(blockNum, "no body")
+	}
+	return body, nil
+}
+
+func checkHeaders(v *stageVerifier, from, to uint64) error {
+	var parentHash common.Hash
+	for blockNum := from; blockNum <= to; blockNum++ {
+		if err := v.tick(blockNum); err != nil {
+			return err
+		}
+		header, err := v.header(blockNum)
+		if err != nil {
+			return err
+		}
+		if header.Number.Uint64() != blockNum {
+			return diverged(blockNum, "header has number %d", header.Number.Uint64())
+		}
+		if blockNum > 0 {
+			if blockNum == from {
+				if parentHash, err = v.cfg.BlockReader.CanonicalHash(v.ctx, v.tx, blockNum-1); err != nil {
+					return err
+				}
+			}
+			if header.ParentHash != parentHash {
+				return diverged(blockNum, "parent hash %x, canonical %x", header.ParentHash, parentHash)
+			}
+		}
+		parentHash = header.Hash()
+	}
+	return nil
+}
+
+func checkBlockHashes(v *stageVerifier, from, to uint64) error {
+	for blockNum := from; blockNum <= to; blockNum++ {
+		if err := v.tick(blockNum); err != nil {
+			return err
+		}
+		hash, err := v.cfg.BlockReader.CanonicalHash(v.ctx, v.tx, blockNum)
+		if err != nil {
+			return err
+		}
+		number := rawdb.ReadHeaderNumber(v.tx, hash)
+		if number == nil || *number != blockNum {
+			return diverged(blockNum, "hash %x is mapped to block %v", hash, number)
+		}
+	}
+	return nil
+}
+
+func checkBodies(v *stageVerifier, from, to uint64) error {
+	for blockNum := from; blockNum <= to; blockNum++ {
+		if err := v.tick(blockNum); err != nil {
+			return err
+		}
+		header, err := v.header(blockNum)
+		if err != nil {
+			return err
+		}
+		body, err := v.body(header)
+		if err != nil {
+			return err
+		}
+		if txHash := types.DeriveSha(types.Transactions(body.Transactions)); txHash != header.TxHash {
+			return diverged(blockNum, "transactions root %x, header has %x", txHash, header.TxHash)
+		}
+		if uncleHash := types.CalcUncleHash(body.Uncles); uncleHash != header.UncleHash {
+			return diverged(blockNum, "uncles hash %x, header has %x", uncleHash, header.UncleHash)
+		}
+	}
+	return nil
+}
+
+func checkSenders(v *stageVerifier, from, to uint64) error {
+	for blockNum := from; blockNum <= to; blockNum++ {
+		if err := v.tick(blockNum); err != nil {
+			return err
+		}
+		hash, err := v.cfg.BlockReader.CanonicalHash(v.ctx, v.tx, blockNum)
+		if err != nil {
+			return err
+		}
+		block, senders, err := v.cfg.BlockReader.BlockWithSenders(v.ctx, v.tx, hash, blockNum)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			return diverged(blockNum, "no block %x", hash)
+		}
+		txs := block.Transactions()
+		if len(txs) != len(senders) {
+			return diverged(blockNum, "%d senders of %d transactions", len(senders), len(txs))
+		}
+		signer := types.MakeSigner(v.cfg.ChainConfig, blockNum)
+		for i, txn := range txs {
+			from, err := signer.Sender(txn)
+			if err != nil {
+				return diverged(blockNum, "recover sender of tx %d: %w", i, err)
+			}
+			if from != senders[i] {
+				return diverged(blockNum, "sender of tx %d is %x, recovered %x", i, senders[i], from)
+			}
+		}
+	}
+	return nil
+}
+
+func checkReceipts(v *stageVerifier, from, to uint64) error {
+	for blockNum := v.kept(v.cfg.Prune.Receipts, from); blockNum <= to; blockNum++ {
+		if err := v.tick(blockNum); err != nil {
+			return err
+		}
+		header, err := v.header(blockNum)
+		if err != nil {
+			return err
+		}
+		body, err := v.body(header)
+		if err != nil {
+			return err
+		}
+		receipts, err := v.cfg.BlockReader.RawReceipts(v.ctx, v.tx, blockNum)
+		if err != nil {
+			return err
+		}
+		if len(receipts) != len(body.Transactions) {
+			return diverged(blockNum, "%d receipts of %d transactions", len(receipts), len(body.Transactions))
+		}
+		if len(receipts) > 0 && receipts[len(receipts)-1].CumulativeGasUsed != header.GasUsed {
+			return diverged(blockNum, "receipts used %d gas, header has %d", receipts[len(receipts)-1].CumulativeGasUsed, header.GasUsed)
+		}
+	}
+	return nil
+}
+
+// checkHashState - every Sample-th entry of plain state has the same value in hashed state.
+// Hashed state is checked only when it's at the same block as plain state.
+func checkHashState(v *stageVerifier, from, to uint64) error {
+	execution, err := stages.GetStageProgress(v.tx, stages.Execution)
+	if err != nil {
+		return err
+	}
+	if v.cfg.Sample == 0 || to != v.progress || v.progress != execution {
+		return nil
+	}
+	var i uint64
+	return v.tx.ForEach(kv.PlainState, nil, func(k, val []byte) error {
+		i++
+		if i%v.cfg.Sample != 0 {
+			return nil
+		}
+		if err := v.tick(v.progress); err != nil {
+			return err
+		}
+		table, hashedKey, err := hashedStateKey(k)
+		if err != nil {
+			return err
+		}
+		hashedVal, err := v.tx.GetOne(table, hashedKey)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(val, hashedVal) {
+			return diverged(v.progress, "%s of plain state key %x is %x, expected %x", table, k, hashedVal, val)
+		}
+		return nil
+	})
+}
+
+// hashedStateKey - same as transformPlainStateKey of HashState stage
+func hashedStateKey(k []byte) (string, []byte, error) {
+	if len(k) == length.Addr {
+		h, err := common.HashData(k)
+		return kv.HashedAccounts, h[:], err
+	}
+	addrHash, err := common.HashData(k[:length.Addr])
+	if err != nil {
+		return "", nil, err
+	}
+	locHash, err := common.HashData(k[length.Addr+length.Incarnation:])
+	if err != nil {
+		return "", nil, err
+	}
+	inc := binary.BigEndian.Uint64(k[length.Addr:])
+	return kv.HashedStorage, dbutils.GenerateCompositeStorageKey(addrHash, inc, locHash), nil
+}
+
+// checkStateRoot - state root calculated from hashed state is equal to state root of the header
+func checkStateRoot(v *stageVerifier, from, to uint64) error {
+	if to != v.progress {
+		return nil
+	}
+	header, err := v.header(v.progress)
+	if err != nil {
+		return err
+	}
+	root, err := trie.CalcRoot("verify", v.tx)
+	if err != nil {
+		return err
+	}
+	if root != header.Root {
+		return diverged(v.progress, "state root %x, header has %x", root, header.Root)
+	}
+	return nil
+}
+
+func checkAccountHistoryIndex(v *stageVerifier, from, to uint64) error {
+	return checkHistoryIndex(v, kv.AccountChangeSet, kv.AccountsHistory, from, to)
+}
+
+func checkStorageHistoryIndex(v *stageVerifier, from, to uint64) error {
+	return checkHistoryIndex(v, kv.StorageChangeSet, kv.StorageHistory, from, to)
+}
+
+// checkHistoryIndex - every key of change set is in the index at the block of change set
+func checkHistoryIndex(v *stageVerifier, changeSetTable, indexTable string, from, to uint64) error {
+	return changeset.ForRange(v.tx, changeSetTable, v.kept(v.cfg.Prune.History, from), to+1, func(blockN uint64, k, _ []byte) error {
+		if err := v.tick(blockN); err != nil {
+			return err
+		}
+		bm, err := bitmapdb.Get64(v.tx, indexTable, dbutils.CompositeKeyWithoutIncarnation(k), blockN, blockN+1)
+		if err != nil {
+			return err
+		}
+		if !bm.Contains(blockN) {
+			return diverged(blockN, "%s has no key %x", indexTable, k)
+		}
+		return nil
+	})
+}
+
+// checkLogIndex - every address and topic of receipts logs is in the index at the block of receipt
+func checkLogIndex(v *stageVerifier, from, to uint64) error {
+	for blockNum := v.kept(v.cfg.Prune.Receipts, from); blockNum <= to; blockNum++ {
+		if err := v.tick(blockNum); err != nil {
+			return err
+		}
+		receipts, err := v.cfg.BlockReader.RawReceipts(v.ctx, v.tx, blockNum)
+		if err != nil {
+			return err
+		}
+		for _, receipt := range receipts {
+			for _, l := range receipt.Logs {
+				if err := checkBitmap(v.tx, kv.LogAddressIndex, l.Address[:], blockNum); err != nil {
+					return err
+				}
+				for _, topic := range l.Topics {
+					if err := checkBitmap(v.tx, kv.LogTopicIndex, topic[:], blockNum); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func checkBitmap(tx kv.Tx, table string, key []byte, blockNum uint64) error {
+	bm, err := bitmapdb.Get(tx, table, key, uint32(blockNum), uint32(blockNum+1))
+	if err != nil {
+		return err
+	}
+	if !bm.Contains(uint32(blockNum)) {
+		return diverged(blockNum, "%s has no key %x", table, key)
+	}
+	return nil
+}
+
+// checkCallTraces - every Sample-th block is re-executed on historical state, all addresses seen by call tracer must
+// be in the call traces index
+func checkCallTraces(v *stageVerifier, from, to uint64) error {
+	if v.cfg.Sample == 0 || v.cfg.Engine == nil {
+		return nil
+	}
+	// re-execution needs history of state before the block
+	from = maxU64(v.kept(v.cfg.Prune.CallTraces, from), v.kept(v.cfg.Prune.History, from))
+	if rem := from % v.cfg.Sample; rem != 0 {
+		from += v.cfg.Sample - rem
+	}
+	for blockNum := from; blockNum <= to; blockNum += v.cfg.Sample {
+		if err := v.tick(blockNum); err != nil {
+			return err
+		}
+		froms, tos, err := v.reExecute(blockNum)
+		if err != nil {
+			return err
+		}
+		for addr := range froms {
+			if err := checkBitmap64(v.tx, kv.CallFromIndex, addr[:], blockNum); err != nil {
+				return err
+			}
+		}
+		for addr := range tos {
+			if err := checkBitmap64(v.tx, kv.CallToIndex, addr[:], blockNum); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkBitmap64(tx kv.Tx, table string, key []byte, blockNum uint64) error {
+	bm, err := bitmapdb.Get64(tx, table, key, blockNum, blockNum+1)
+	if err != nil {
+		return err
+	}
+	if !bm.Contains(blockNum) {
+		return diverged(blockNum, "%s has no key %x", table, key)
+	}
+	return nil
+}
+
+func (v *stageVerifier) reExecute(blockNum uint64) (froms, tos map[common.Address]struct{}, err error) {
+	hash, err := v.cfg.BlockReader.CanonicalHash(v.ctx, v.tx, blockNum)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, senders, err := v.cfg.BlockReader.BlockWithSenders(v.ctx, v.tx, hash, blockNum)
+	if err != nil {
+		return nil, nil, err
+	}
+	if block == nil {
+		return nil, nil, diverged(blockNum, "no block %x", hash)
+	}
+	block.SendersToTxs(senders)
+
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		h, _ := v.cfg.BlockReader.Header(v.ctx, v.tx, hash, number)
+		return h
+	}
+	tracer := &addressTracer{CallTracer: calltracer.NewCallTracer(nil), froms: map[common.Address]struct{}{}, tos: map[common.Address]struct{}{}}
+	vmConfig := vm.Config{Debug: true, Tracer: tracer}
+	chainReader := stagedsync.ChainReader{Cfg: *v.cfg.ChainConfig, Db: v.tx}
+	execute := core.ExecuteBlockEphemerally
+	if _, isPoSa := v.cfg.Engine.(consensus.PoSA); isPoSa {
+		execute = core.ExecuteBlockEphemerallyForBSC
+	}
+	if _, err = execute(v.cfg.ChainConfig, &vmConfig, core.GetHashFn(block.Header(), getHeader), v.cfg.Engine, block,
+		state.NewPlainState(v.tx, blockNum), state.NewNoopWriter(), epochReader{tx: v.tx}, chainReader, nil, false, nil); err != nil {
+		return nil, nil, diverged(blockNum, "re-execution: %w", err)
+	}
+	// same as calltracer.CallTracer.WriteToDb
+	tracer.tos[block.Coinbase()] = struct{}{}
+	for _, uncle := range block.Uncles() {
+		tracer.tos[uncle.Coinbase] = struct{}{}
+	}
+	return tracer.froms, tracer.tos, nil
+}
+
+// addressTracer - collects addresses which are written to call traces by calltracer.CallTracer
+type addressTracer struct {
+	*calltracer.CallTracer
+	froms, tos map[common.Address]struct{}
+}
+
+func (t *addressTracer) CaptureStart(evm *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, calltype vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	t.froms[from] = struct{}{}
+	t.tos[to] = struct{}{}
+}
+
+func (t *addressTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+	t.froms[from] = struct{}{}
+	t.tos[to] = struct{}{}
+}
+
+// epochReader - doesn't persist epochs of re-executed blocks
+type epochReader struct {
+	tx kv.Tx
+}
+
+func (cr epochReader) GetEpoch(hash common.Hash, number uint64) ([]byte, error) {
+	return rawdb.ReadEpoch(cr.tx, number, hash)
+}
+func (cr epochReader) PutEpoch(hash common.Hash, number uint64, proof []byte) error { return nil }
+func (cr epochReader) GetPendingEpoch(hash common.Hash, number uint64) ([]byte, error) {
+	return rawdb.ReadPendingEpoch(cr.tx, number, hash)
+}
+func (cr epochReader) PutPendingEpoch(hash common.Hash, number uint64, proof []byte) error {
+	return nil
+}
+func (cr epochReader) FindBeforeOrEqualNumber(number uint64) (blockNum uint64, blockHash common.Hash, transitionProof []byte, err error) {
+	return rawdb.FindEpochBeforeOrEqualNumber(cr.tx, number)
+}
+
+// checkTxLookup - every transaction of canonical block is looked up to this block
+func checkTxLookup(v *stageVerifier, from, to uint64) error {
+	for blockNum := v.kept(v.cfg.Prune.TxIndex, from); blockNum <= to; blockNum++ {
+		if err := v.tick(blockNum); err != nil {
+			return err
+		}
+		header, err := v.header(blockNum)
+		if err != nil {
+			return err
+		}
+		body, err := v.body(header)
+		if err != nil {
+			return err
+		}
+		for i, txn := range body.Transactions {
+			lookup, ok, err := v.cfg.BlockReader.TxnLookup(v.ctx, v.tx, txn.Hash())
+			if err != nil {
+				return err
+			}
+			if !ok || lookup != blockNum {
+				return diverged(blockNum, "tx %d %x is looked up to block %d (found: %t)", i, txn.Hash(), lookup, ok)
+			}
+		}
+	}
+	return nil
+}
+
+// checkIssuance - difference of totals of adjacent blocks is issuance of the block
+func checkIssuance(v *stageVerifier, from, to uint64) error {
+	from = maxU64(from, 1) // genesis allocations are not issuance
+	prevIssued, err := rawdb.ReadTotalIssued(v.tx, from-1)
+	if err != nil {
+		return err
+	}
+	prevBurnt, err := rawdb.ReadTotalBurnt(v.tx, from-1)
+	if err != nil {
+		return err
+	}
+	for blockNum := from; blockNum <= to; blockNum++ {
+		if err := v.tick(blockNum); err != nil {
+			return err
+		}
+		header, err := v.header(blockNum)
+		if err != nil {
+			return err
+		}
+		body, err := v.body(header)
+		if err != nil {
+			return err
+		}
+		issuance := core.CalcBlockIssuance(v.cfg.ChainConfig, header, body.Uncles, body.Withdrawals)
+		issued, err := rawdb.ReadTotalIssued(v.tx, blockNum)
+		if err != nil {
+			return err
+		}
+		burnt, err := rawdb.ReadTotalBurnt(v.tx, blockNum)
+		if err != nil {
+			return err
+		}
+		if diff := new(big.Int).Sub(issued, prevIssued); diff.Cmp(issuance.Issued().ToBig()) != 0 {
+			return diverged(blockNum, "total issued grew by %d, block issued %d", diff, issuance.Issued().ToBig())
+		}
+		if diff := new(big.Int).Sub(burnt, prevBurnt); diff.Cmp(issuance.Burnt.ToBig()) != 0 {
+			return diverged(blockNum, "total burnt grew by %d, block burnt %d", diff, issuance.Burnt.ToBig())
+		}
+		prevIssued, prevBurnt = issued, burnt
+	}
+	return nil
+}
+
+func minU64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxU64(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
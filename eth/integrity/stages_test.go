@@ -0,0 +1,109 @@
+package integrity_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/integrity"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	stages2 "github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChainWithLogs - contract emitting a log on every call is deployed in block 1, it's called once in each of blocks 2-4
+func mockChainWithLogs(t *testing.T) (*stages2.MockSentry, *core.ChainPack, common.Address) {
+	var (
+		signer      = types.LatestSignerForChainID(nil)
+		bankKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		bankAddress = crypto.PubkeyToAddress(bankKey.PublicKey)
+		// PUSH1 0 PUSH1 0 LOG0 STOP, behind the code copying it
+		contract = hexutil.MustDecode("0x6006600c60003960066000f360006000a000")
+		gspec    = &core.Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc:  core.GenesisAlloc{bankAddress: {Balance: big.NewInt(1e18)}},
+		}
+	)
+	m := stages2.MockWithGenesis(t, gspec, bankKey, false)
+	contractAddr := crypto.CreateAddress(bankAddress, 0)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 4, func(i int, block *core.BlockGen) {
+		var txn types.Transaction
+		var err error
+		if i == 0 {
+			txn, err = types.SignTx(types.NewContractCreation(block.TxNonce(bankAddress), new(uint256.Int), 1e6, new(uint256.Int), contract), *signer, bankKey)
+		} else {
+			txn, err = types.SignTx(types.NewTransaction(block.TxNonce(bankAddress), contractAddr, new(uint256.Int), 50000, new(uint256.Int), nil), *signer, bankKey)
+		}
+		require.NoError(t, err)
+		block.AddTx(txn)
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+	return m, chain, contractAddr
+}
+
+func TestVerifyStages(t *testing.T) {
+	m, chain, contractAddr := mockChainWithLogs(t)
+	cfg := integrity.VerifyCfg{
+		ChainConfig: m.ChainConfig,
+		BlockReader: snapshotsync.NewBlockReader(),
+		Engine:      m.Engine,
+		Prune:       prune.DefaultMode,
+		Sample:      1,
+	}
+	ctx := context.Background()
+
+	// every corruption is made in its own transaction, which is rolled back
+	verify := func(corrupt func(tx kv.RwTx) error) []*integrity.Divergence {
+		tx, err := m.DB.BeginRw(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback()
+		require.NoError(t, corrupt(tx))
+		divergences, err := integrity.VerifyStages(ctx, tx, cfg, 0, chain.TopBlock.NumberU64())
+		require.NoError(t, err)
+		return divergences
+	}
+	requireDivergence := func(divergences []*integrity.Divergence, stage stages.SyncStage, block uint64) {
+		t.Helper()
+		for _, d := range divergences {
+			if d.Stage == stage {
+				require.Equal(t, block, d.Block, d.Error())
+				return
+			}
+		}
+		require.Failf(t, "no divergence", "stage %s, divergences: %v", stage, divergences)
+	}
+
+	require.Empty(t, verify(func(tx kv.RwTx) error { return nil }))
+
+	divergences := verify(func(tx kv.RwTx) error {
+		return tx.Delete(kv.TxLookup, chain.Blocks[2].Transactions()[0].Hash().Bytes())
+	})
+	require.Len(t, divergences, 1)
+	requireDivergence(divergences, stages.TxLookup, 3)
+
+	// re-execution of the block with the altered sender fails as well
+	divergences = verify(func(tx kv.RwTx) error {
+		block := chain.Blocks[1]
+		return rawdb.WriteSenders(tx, block.Hash(), block.NumberU64(), []common.Address{{0x1}})
+	})
+	requireDivergence(divergences, stages.Senders, 2)
+
+	divergences = verify(func(tx kv.RwTx) error {
+		return bitmapdb.TruncateRange(tx, kv.LogAddressIndex, contractAddr[:], 3)
+	})
+	require.Len(t, divergences, 1)
+	requireDivergence(divergences, stages.LogIndex, 3)
+}
@@ -17,6 +17,7 @@
 package eth_test
 
 import (
+	"context"
 	"math/big"
 	"testing"
 
@@ -31,6 +32,8 @@ import (
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/services"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 	"github.com/ledgerwatch/erigon/turbo/stages"
 	"github.com/stretchr/testify/require"
 )
@@ -122,6 +125,34 @@ func TestGetBlockReceipts(t *testing.T) {
 	sent := m.SentMessage(0)
 	require.Equal(t, eth.ToProto[m.SentryClient.Protocol()][eth.ReceiptsMsg], sent.Id)
 	require.Equal(t, expect, sent.Data)
+
+	// Once the receipts are retired from the db, they are served from the receipts snapshots
+	reader := &retiredReceiptsReader{FullBlockReader: snapshotsync.NewBlockReader(), receipts: map[uint64]types.Receipts{}}
+	require.NoError(t, m.DB.View(m.Ctx, func(tx kv.Tx) error {
+		for i := uint64(0); i < uint64(len(hashes)); i++ {
+			reader.receipts[i] = rawdb.ReadRawReceipts(tx, i)
+		}
+		return nil
+	}))
+	require.NoError(t, m.DB.Update(m.Ctx, func(tx kv.RwTx) error {
+		return rawdb.TruncateReceipts(tx, 0)
+	}))
+	require.NoError(t, m.DB.View(m.Ctx, func(tx kv.Tx) error {
+		retired, err := eth.AnswerGetReceiptsQuery(tx, hashes, reader)
+		require.NoError(t, err)
+		require.Equal(t, receipts, retired)
+		return nil
+	}))
+}
+
+// retiredReceiptsReader serves the receipts which are gone from the db, as the receipts snapshots do
+type retiredReceiptsReader struct {
+	services.FullBlockReader
+	receipts map[uint64]types.Receipts
+}
+
+func (r *retiredReceiptsReader) RawReceipts(ctx context.Context, tx kv.Getter, blockNum uint64) (types.Receipts, error) {
+	return r.receipts[blockNum], nil
 }
 
 // newTestBackend creates a chain with a number of explicitly defined blocks and
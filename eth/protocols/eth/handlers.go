@@ -165,7 +165,7 @@ func AnswerGetBlockBodiesQuery(db kv.Tx, query GetBlockBodiesPacket) []rlp.RawVa
 	return bodies
 }
 
-func AnswerGetReceiptsQuery(db kv.Tx, query GetReceiptsPacket) ([]rlp.RawValue, error) { //nolint:unparam
+func AnswerGetReceiptsQuery(db kv.Tx, query GetReceiptsPacket, blockReader services.FullBlockReader) ([]rlp.RawValue, error) { //nolint:unparam
 	// Gather state data until the fetch or network limits is reached
 	var (
 		bytes    int
@@ -176,20 +176,31 @@ func AnswerGetReceiptsQuery(db kv.Tx, query GetReceiptsPacket) ([]rlp.RawValue,
 			lookups >= 2*maxReceiptsServe {
 			break
 		}
-		// Retrieve the requested block's receipts
-		results, err := rawdb.ReadReceiptsByHash(db, hash)
+		// Retrieve the requested block's receipts, from the db or the receipts snapshots
+		number := rawdb.ReadHeaderNumber(db, hash)
+		if number == nil {
+			continue
+		}
+		block, senders, err := blockReader.BlockWithSenders(context.Background(), db, hash, *number)
 		if err != nil {
 			return nil, err
 		}
-		if results == nil {
-			header, err := rawdb.ReadHeaderByHash(db, hash)
-			if err != nil {
-				return nil, err
-			}
-			if header == nil || header.ReceiptHash != types.EmptyRootHash {
-				continue
+		if block == nil {
+			continue
+		}
+		results, err := blockReader.RawReceipts(context.Background(), db, *number)
+		if err != nil {
+			return nil, err
+		}
+		if results != nil {
+			if err := results.DeriveFields(hash, *number, block.Transactions(), senders); err != nil {
+				log.Error("Failed to derive block receipts fields", "hash", hash, "number", *number, "err", err)
+				results = nil
 			}
 		}
+		if results == nil && block.ReceiptHash() != types.EmptyRootHash {
+			continue
+		}
 		// If known, encode and queue for response packet
 		if encoded, err := rlp.EncodeToBytes(results); err != nil {
 			return nil, fmt.Errorf("failed to encode receipt: %w", err)
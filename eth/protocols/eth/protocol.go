@@ -434,8 +434,9 @@ type BlockBody struct {
 
 // BlockRawBody represents the data content of a single block.
 type BlockRawBody struct {
-	Transactions [][]byte        // Transactions contained within a block
-	Uncles       []*types.Header // Uncles contained within a block
+	Transactions [][]byte            // Transactions contained within a block
+	Uncles       []*types.Header     // Uncles contained within a block
+	Withdrawals  []*types.Withdrawal // Withdrawals contained within a block, nil for pre-Shanghai bodies
 }
 
 func (bb BlockBody) EncodeRLP(w io.Writer) error {
@@ -601,6 +602,23 @@ func (rb BlockRawBody) EncodeRLP(w io.Writer) error {
 		encodingSize += (bits.Len(uint(unclesLen)) + 7) / 8
 	}
 	encodingSize += unclesLen
+	// size of Withdrawals
+	var withdrawalsLen int
+	if rb.Withdrawals != nil {
+		encodingSize++
+		for _, withdrawal := range rb.Withdrawals {
+			withdrawalsLen++
+			withdrawalLen := withdrawal.EncodingSize()
+			if withdrawalLen >= 56 {
+				withdrawalsLen += (bits.Len(uint(withdrawalLen)) + 7) / 8
+			}
+			withdrawalsLen += withdrawalLen
+		}
+		if withdrawalsLen >= 56 {
+			encodingSize += (bits.Len(uint(withdrawalsLen)) + 7) / 8
+		}
+		encodingSize += withdrawalsLen
+	}
 	var b [33]byte
 	// prefix
 	if err := types.EncodeStructSizePrefix(encodingSize, w, b[:]); err != nil {
@@ -624,6 +642,17 @@ func (rb BlockRawBody) EncodeRLP(w io.Writer) error {
 			return err
 		}
 	}
+	// encode Withdrawals
+	if rb.Withdrawals != nil {
+		if err := types.EncodeStructSizePrefix(withdrawalsLen, w, b[:]); err != nil {
+			return err
+		}
+		for _, withdrawal := range rb.Withdrawals {
+			if err := withdrawal.EncodeRLP(w); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -668,20 +697,43 @@ func (rb *BlockRawBody) DecodeRLP(s *rlp.Stream) error {
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
+	// decode Withdrawals, missing for pre-Shanghai bodies
+	if _, err = s.List(); err != nil {
+		if errors.Is(err, rlp.EOL) {
+			return s.ListEnd()
+		}
+		return err
+	}
+	rb.Withdrawals = []*types.Withdrawal{}
+	for err == nil {
+		var withdrawal types.Withdrawal
+		if err = withdrawal.DecodeRLP(s); err != nil {
+			break
+		}
+		rb.Withdrawals = append(rb.Withdrawals, &withdrawal)
+	}
+	if !errors.Is(err, rlp.EOL) {
+		return err
+	}
+	// end of Withdrawals
+	if err = s.ListEnd(); err != nil {
+		return err
+	}
 	return s.ListEnd()
 }
 
-// Unpack retrieves the transactions and uncles from the range packet and returns
+// Unpack retrieves the transactions, uncles and withdrawals from the range packet and returns
 // them in a split flat format that's more consistent with the internal data structures.
-func (p *BlockRawBodiesPacket) Unpack() ([][][]byte, [][]*types.Header) {
+func (p *BlockRawBodiesPacket) Unpack() ([][][]byte, [][]*types.Header, [][]*types.Withdrawal) {
 	var (
-		txset    = make([][][]byte, len(*p))
-		uncleset = make([][]*types.Header, len(*p))
+		txset         = make([][][]byte, len(*p))
+		uncleset      = make([][]*types.Header, len(*p))
+		withdrawalset = make([][]*types.Withdrawal, len(*p))
 	)
 	for i, body := range *p {
-		txset[i], uncleset[i] = body.Transactions, body.Uncles
+		txset[i], uncleset[i], withdrawalset[i] = body.Transactions, body.Uncles, body.Withdrawals
 	}
-	return txset, uncleset
+	return txset, uncleset, withdrawalset
 }
 
 // GetNodeDataPacket represents a trie node data query.
@@ -66,7 +66,9 @@ func (s *StageState) ExecutionAt(db kv.Getter) (uint64, error) {
 // Unwinder allows the stage to cause an unwind.
 type Unwinder interface {
 	// UnwindTo begins staged sync unwind to the specified block.
-	UnwindTo(unwindPoint uint64, badBlock common.Hash)
+	// badBlock is not empty if the unwind is caused by a block that failed validation, badBlockErr explains why,
+	// both are kept for debug_getBadBlocks.
+	UnwindTo(unwindPoint uint64, badBlock common.Hash, badBlockErr error)
 }
 
 // UnwindState contains the information about unwind.
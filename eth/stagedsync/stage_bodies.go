@@ -186,7 +186,7 @@ Loop:
 			_, err := cfg.bd.VerifyUncles(header, rawBody.Uncles, cr)
 			if err != nil {
 				log.Error(fmt.Sprintf("[%s] Uncle verification failed", logPrefix), "number", blockHeight, "hash", header.Hash().String(), "err", err)
-				u.UnwindTo(blockHeight-1, header.Hash())
+				u.UnwindTo(blockHeight-1, header.Hash(), err)
 				break Loop
 			}
 
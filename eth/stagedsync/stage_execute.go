@@ -19,7 +19,6 @@ import (
 	ecom "github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/changeset"
 	"github.com/ledgerwatch/erigon/common/dbutils"
-	"github.com/ledgerwatch/erigon/common/math"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
@@ -312,7 +311,7 @@ Loop:
 					return err
 				}
 			}
-			u.UnwindTo(blockNum-1, block.Hash())
+			u.UnwindTo(blockNum-1, block.Hash(), err)
 			break Loop
 		}
 		stageProgress = blockNum
@@ -544,6 +543,14 @@ func unwindExecutionStage(u *UnwindState, s *StageState, tx kv.RwTx, quit <-chan
 		return err
 	}
 
+	if accumulator != nil {
+		// Logs of the unwound blocks go with the unwind change, subscribers get them again with Removed set
+		logs, err := ReadLogs(tx, u.UnwindPoint+1)
+		if err != nil {
+			return fmt.Errorf("read logs of unwound blocks: %w", err)
+		}
+		accumulator.RemoveLogs(logs)
+	}
 	if err := rawdb.TruncateReceipts(tx, u.UnwindPoint+1); err != nil {
 		return fmt.Errorf("truncate receipts: %w", err)
 	}
@@ -608,11 +615,8 @@ func PruneExecutionStage(s *PruneState, tx kv.RwTx, cfg ExecuteBlockCfg, ctx con
 	}
 
 	if cfg.prune.Receipts.Enabled() {
-		if err = rawdb.PruneTable(tx, kv.Receipts, cfg.prune.Receipts.PruneTo(s.ForwardProgress), ctx, math.MaxInt32); err != nil {
-			return err
-		}
 		// LogIndex.Prune will read everything what not pruned here
-		if err = rawdb.PruneTable(tx, kv.Log, cfg.prune.Receipts.PruneTo(s.ForwardProgress), ctx, math.MaxInt32); err != nil {
+		if err = rawdb.PruneReceipts(tx, cfg.prune.Receipts.PruneTo(s.ForwardProgress), ctx); err != nil {
 			return err
 		}
 	}
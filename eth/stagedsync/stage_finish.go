@@ -130,7 +130,7 @@ func PruneFinish(u *PruneState, tx kv.RwTx, cfg FinishCfg, ctx context.Context)
 	return nil
 }
 
-func NotifyNewHeaders(ctx context.Context, finishStageBeforeSync uint64, finishStageAfterSync uint64, unwindTo *uint64, notifier ChainEventNotifier, tx kv.Tx) error {
+func NotifyNewHeaders(ctx context.Context, finishStageBeforeSync uint64, finishStageAfterSync uint64, unwindTo *uint64, removedLogs []*remote.SubscribeLogsReply, notifier ChainEventNotifier, tx kv.Tx) error {
 	t := time.Now()
 	if notifier == nil {
 		log.Trace("RPC Daemon notification channel not set. No headers notifications will be sent")
@@ -138,10 +138,8 @@ func NotifyNewHeaders(ctx context.Context, finishStageBeforeSync uint64, finishS
 	}
 	// Notify all headers we have (either canonical or not) in a maximum range span of 1024
 	var notifyFrom uint64
-	var isUnwind bool
 	if unwindTo != nil && *unwindTo != 0 && (*unwindTo) < finishStageBeforeSync {
 		notifyFrom = *unwindTo
-		isUnwind = true
 	} else {
 		heightSpan := finishStageAfterSync - finishStageBeforeSync
 		if heightSpan > 1024 {
@@ -168,7 +166,11 @@ func NotifyNewHeaders(ctx context.Context, finishStageBeforeSync uint64, finishS
 	headerTiming := time.Since(t)
 	t = time.Now()
 	if notifier.HasLogSubsriptions() {
-		logs, err := ReadLogs(tx, notifyFrom, isUnwind)
+		// Logs of the abandoned blocks go first, with Removed set
+		if len(removedLogs) > 0 {
+			notifier.OnLogs(removedLogs)
+		}
+		logs, err := ReadLogs(tx, notifyFrom)
 		if err != nil {
 			return err
 		}
@@ -179,7 +181,7 @@ func NotifyNewHeaders(ctx context.Context, finishStageBeforeSync uint64, finishS
 	return nil
 }
 
-func ReadLogs(tx kv.Tx, from uint64, isUnwind bool) ([]*remote.SubscribeLogsReply, error) {
+func ReadLogs(tx kv.Tx, from uint64) ([]*remote.SubscribeLogsReply, error) {
 	logs, err := tx.Cursor(kv.Log)
 	if err != nil {
 		return nil, err
@@ -220,7 +222,6 @@ func ReadLogs(tx kv.Tx, from uint64, isUnwind bool) ([]*remote.SubscribeLogsRepl
 				Topics:           make([]*types2.H256, 0, len(l.Topics)),
 				TransactionHash:  gointerfaces.ConvertHashToH256(txHash),
 				TransactionIndex: txIndex,
-				Removed:          isUnwind,
 			}
 			logIndex++
 			for _, topic := range l.Topics {
@@ -365,7 +365,7 @@ func startHandlingForkChoice(
 		}
 	}
 
-	u.UnwindTo(forkingPoint, common.Hash{})
+	u.UnwindTo(forkingPoint, common.Hash{}, nil)
 
 	cfg.hd.SetUnsettledForkChoice(forkChoice, headerNumber)
 
@@ -891,7 +891,7 @@ Loop:
 		timer.Stop()
 	}
 	if headerInserter.Unwind() {
-		u.UnwindTo(headerInserter.UnwindPoint(), common.Hash{})
+		u.UnwindTo(headerInserter.UnwindPoint(), common.Hash{}, nil)
 	}
 	if headerInserter.GetHighest() != 0 {
 		if !headerInserter.Unwind() {
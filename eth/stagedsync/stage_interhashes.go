@@ -107,7 +107,7 @@ func SpawnIntermediateHashesStage(s *StageState, u Unwinder, tx kv.RwTx, cfg Tri
 			if to > s.BlockNumber {
 				unwindTo := (to + s.BlockNumber) / 2 // Binary search for the correct block, biased to the lower numbers
 				log.Warn("Unwinding due to incorrect root hash", "to", unwindTo)
-				u.UnwindTo(unwindTo, headerHash)
+				u.UnwindTo(unwindTo, headerHash, fmt.Errorf("wrong trie root of block %d: %x, expected (from header): %x", to, root, expectedRootHash))
 			}
 		} else if err = s.Update(tx, to); err != nil {
 			return trie.EmptyRoot, err
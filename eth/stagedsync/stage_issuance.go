@@ -1,23 +1,16 @@
 package stagedsync
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"math/big"
 	"time"
 
-	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/kv"
-	"github.com/ledgerwatch/erigon/common"
-	"github.com/ledgerwatch/erigon/common/dbutils"
-	"github.com/ledgerwatch/erigon/consensus/ethash"
-	"github.com/ledgerwatch/erigon/consensus/serenity"
+	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/params"
-	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/turbo/services"
 	"github.com/ledgerwatch/log/v3"
 )
@@ -55,7 +48,7 @@ func SpawnStageIssuance(cfg IssuanceCfg, s *StageState, tx kv.RwTx, ctx context.
 		return fmt.Errorf("getting headers progress: %w", err)
 	}
 
-	if cfg.chainConfig.Consensus != params.EtHashConsensus || !cfg.enabledIssuance || headNumber == s.BlockNumber {
+	if !cfg.enabledIssuance || headNumber == s.BlockNumber {
 		if !useExternalTx {
 			if err = tx.Commit(); err != nil {
 				return err
@@ -80,80 +73,45 @@ func SpawnStageIssuance(cfg IssuanceCfg, s *StageState, tx kv.RwTx, ctx context.
 
 	stopped := false
 	prevProgress := s.BlockNumber
-	currentBlockNumber := s.BlockNumber + 1
-	headerC, err := tx.Cursor(kv.Headers)
-	if err != nil {
-		return err
-	}
-	for k, v, err := headerC.Seek(dbutils.EncodeBlockNumber(currentBlockNumber)); k != nil && !stopped; k, v, err = headerC.Next() {
-		if err != nil {
-			return err
-		}
-
-		if len(k) != 40 {
-			continue
-		}
-
-		currentBlockNumber, err = dbutils.DecodeBlockNumber(k[:8])
+	currentBlockNumber := s.BlockNumber
+	// The headers are read through the block reader, the ones of the blocks in snapshots are not in the db any more
+	for blockNumber := s.BlockNumber + 1; blockNumber <= headNumber && !stopped; blockNumber++ {
+		hash, err := cfg.blockReader.CanonicalHash(ctx, tx, blockNumber)
 		if err != nil {
 			return err
 		}
-		if currentBlockNumber > headNumber {
-			currentBlockNumber = headNumber
-			break
-		}
-		// read body without transactions
-		hash, err := rawdb.ReadCanonicalHash(tx, currentBlockNumber)
+		header, err := cfg.blockReader.Header(ctx, tx, hash, blockNumber)
 		if err != nil {
 			return err
 		}
-
-		if hash != common.BytesToHash(k[8:]) {
-			continue
-		}
-		var header types.Header
-		if err := rlp.Decode(bytes.NewReader(v), &header); err != nil {
-			log.Error("Invalid block header RLP", "hash", hash, "err", err)
-			return nil
+		if header == nil {
+			return fmt.Errorf("header of block %d not found", blockNumber)
 		}
 
-		burnt := big.NewInt(0)
-		// burnt: len(Transactions) * baseFee * gasUsed
-		if header.BaseFee != nil {
-			burnt.Set(header.BaseFee)
-			burnt.Mul(burnt, big.NewInt(int64(header.GasUsed)))
-		}
-		// TotalIssued, BlockReward and UncleReward, depends on consensus engine
-		if header.Difficulty.Cmp(serenity.SerenityDifficulty) == 0 {
-			// Proof-of-stake is 0.3 ether per block
-			totalIssued.Add(totalIssued, serenity.RewardSerenity)
-		} else {
-			var blockReward uint256.Int
-			var uncleRewards []uint256.Int
-			if header.UncleHash == types.EmptyUncleHash {
-				blockReward, uncleRewards = ethash.AccumulateRewards(cfg.chainConfig, &header, nil)
-			} else {
-				body, err := cfg.blockReader.Body(ctx, tx, hash, currentBlockNumber)
-				if err != nil {
-					return err
-				}
-				blockReward, uncleRewards = ethash.AccumulateRewards(cfg.chainConfig, &header, body.Uncles)
+		// The body is only needed for the uncles and the withdrawals
+		var uncles []*types.Header
+		var withdrawals []*types.Withdrawal
+		if header.UncleHash != types.EmptyUncleHash || (header.WithdrawalsHash != nil && *header.WithdrawalsHash != types.EmptyRootHash) {
+			body, err := cfg.blockReader.Body(ctx, tx, hash, blockNumber)
+			if err != nil {
+				return err
 			}
-			// Set BlockReward
-			totalIssued.Add(totalIssued, blockReward.ToBig())
-			// Compute uncleRewards
-			for _, uncleReward := range uncleRewards {
-				totalIssued.Add(totalIssued, uncleReward.ToBig())
+			if body == nil {
+				return fmt.Errorf("body of block %d not found", blockNumber)
 			}
+			uncles, withdrawals = body.Uncles, body.Withdrawals
 		}
-		totalBurnt.Add(totalBurnt, burnt)
+		issuance := core.CalcBlockIssuance(cfg.chainConfig, header, uncles, withdrawals)
+		totalIssued.Add(totalIssued, issuance.Issued().ToBig())
+		totalBurnt.Add(totalBurnt, issuance.Burnt.ToBig())
 		// Write to database
-		if err := rawdb.WriteTotalIssued(tx, currentBlockNumber, totalIssued); err != nil {
+		if err := rawdb.WriteTotalIssued(tx, blockNumber, totalIssued); err != nil {
 			return err
 		}
-		if err := rawdb.WriteTotalBurnt(tx, currentBlockNumber, totalBurnt); err != nil {
+		if err := rawdb.WriteTotalBurnt(tx, blockNumber, totalBurnt); err != nil {
 			return err
 		}
+		currentBlockNumber = blockNumber
 		// Sleep and check for logs
 		select {
 		case <-ctx.Done():
@@ -165,7 +123,6 @@ func SpawnStageIssuance(cfg IssuanceCfg, s *StageState, tx kv.RwTx, ctx context.
 		default:
 			log.Trace("RequestQueueTime (header) ticked")
 		}
-		// Cleanup timer
 	}
 	if err = s.Update(tx, currentBlockNumber); err != nil {
 		return err
@@ -5,11 +5,14 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/services"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 	"github.com/stretchr/testify/assert"
 )
@@ -64,3 +67,54 @@ func TestIssuanceStage(t *testing.T) {
 	assert.NoError(err)
 	assert.Equal(ti, big.NewInt(900000000000000000))
 }
+
+// frozenHeadersReader serves the headers of the blocks which are in snapshots, they are not in the db
+type frozenHeadersReader struct {
+	services.FullBlockReader
+	frozen map[uint64]*types.Header
+}
+
+func (r frozenHeadersReader) CanonicalHash(ctx context.Context, tx kv.Getter, blockHeight uint64) (common.Hash, error) {
+	if h, ok := r.frozen[blockHeight]; ok {
+		return h.Hash(), nil
+	}
+	return r.FullBlockReader.CanonicalHash(ctx, tx, blockHeight)
+}
+
+func (r frozenHeadersReader) Header(ctx context.Context, tx kv.Getter, hash common.Hash, blockHeight uint64) (*types.Header, error) {
+	if h, ok := r.frozen[blockHeight]; ok && h.Hash() == hash {
+		return h, nil
+	}
+	return r.FullBlockReader.Header(ctx, tx, hash, blockHeight)
+}
+
+func TestIssuanceStageFrozenHeaders(t *testing.T) {
+	ctx, assert := context.Background(), assert.New(t)
+	db, tx := memdb.NewTestTx(t)
+
+	frozen := map[uint64]*types.Header{}
+	for i, baseFee := range []int64{10, 30} {
+		frozen[uint64(i+1)] = &types.Header{BaseFee: big.NewInt(baseFee), GasUsed: 1000, Number: big.NewInt(int64(i + 1)), Eip1559: true}
+	}
+	header3 := &types.Header{BaseFee: big.NewInt(100), GasUsed: 1000, Number: big.NewInt(3), Eip1559: true}
+	rawdb.WriteHeader(tx, header3)
+	rawdb.WriteCanonicalHash(tx, header3.Hash(), header3.Number.Uint64())
+	stages.SaveStageProgress(tx, stages.Bodies, 3)
+
+	blockReader := frozenHeadersReader{FullBlockReader: snapshotsync.NewBlockReader(), frozen: frozen}
+	err := SpawnStageIssuance(StageIssuanceCfg(db, &params.ChainConfig{
+		Consensus: params.EtHashConsensus,
+	}, blockReader, true), &StageState{
+		ID: stages.Issuance,
+	}, tx, ctx)
+	assert.NoError(err)
+
+	// The frozen blocks are counted as well
+	tb, err := rawdb.ReadTotalBurnt(tx, 3)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(140000), tb)
+
+	ti, err := rawdb.ReadTotalIssued(tx, 3)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(900000000000000000), ti)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/etl"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
 	"github.com/ledgerwatch/erigon/ethdb/cbor"
@@ -350,7 +351,8 @@ func truncateBitmaps(tx kv.RwTx, bucket string, inMem map[string]struct{}, to ui
 	return nil
 }
 
-func pruneOldLogChunks(tx kv.RwTx, bucket string, inMem *etl.Collector, pruneTo uint64, ctx context.Context) error {
+// pruneOldLogChunks deletes the chunks before pruneTo, but the blocks of keep stay in them
+func pruneOldLogChunks(tx kv.RwTx, bucket string, inMem *etl.Collector, pruneTo uint64, keep *roaring.Bitmap, ctx context.Context) error {
 	logEvery := time.NewTicker(logInterval)
 	defer logEvery.Stop()
 
@@ -361,7 +363,7 @@ func pruneOldLogChunks(tx kv.RwTx, bucket string, inMem *etl.Collector, pruneTo
 	defer c.Close()
 
 	if err := inMem.Load(tx, bucket, func(key, v []byte, table etl.CurrentTableReader, next etl.LoadNextFunc) error {
-		for k, _, err := c.Seek(key); k != nil; k, _, err = c.Next() {
+		for k, v, err := c.Seek(key); k != nil; k, v, err = c.Next() {
 			if err != nil {
 				return err
 			}
@@ -370,6 +372,23 @@ func pruneOldLogChunks(tx kv.RwTx, bucket string, inMem *etl.Collector, pruneTo
 				break
 			}
 
+			if !keep.IsEmpty() {
+				chunk := roaring.New()
+				if _, err = chunk.ReadFrom(bytes.NewReader(v)); err != nil {
+					return err
+				}
+				chunk.And(keep)
+				if !chunk.IsEmpty() {
+					buf := bytes.NewBuffer(make([]byte, 0, chunk.GetSerializedSizeInBytes()))
+					if _, err = chunk.WriteTo(buf); err != nil {
+						return err
+					}
+					if err = c.Put(libcommon.Copy(k), buf.Bytes()); err != nil {
+						return fmt.Errorf("failed put, block=%d: %w", blockNum, err)
+					}
+					continue
+				}
+			}
 			if err = c.DeleteCurrent(); err != nil {
 				return fmt.Errorf("failed delete, block=%d: %w", blockNum, err)
 			}
@@ -467,10 +486,27 @@ func pruneLogIndex(logPrefix string, tx kv.RwTx, tmpDir string, pruneTo uint64,
 		}
 	}
 
-	if err := pruneOldLogChunks(tx, kv.LogTopicIndex, topics, pruneTo, ctx); err != nil {
+	// Logs of the backfilled blocks are not pruned (see rawdb.PruneReceipts), so they stay in the index too
+	backfilled, err := rawdb.ReadReceiptsBackfill(tx)
+	if err != nil {
+		return err
+	}
+	keep := roaring.New()
+	for _, r := range backfilled {
+		if r.From >= pruneTo {
+			break
+		}
+		to := r.To
+		if to > pruneTo {
+			to = pruneTo
+		}
+		keep.AddRange(r.From, to)
+	}
+
+	if err := pruneOldLogChunks(tx, kv.LogTopicIndex, topics, pruneTo, keep, ctx); err != nil {
 		return err
 	}
-	if err := pruneOldLogChunks(tx, kv.LogAddressIndex, addrs, pruneTo, ctx); err != nil {
+	if err := pruneOldLogChunks(tx, kv.LogAddressIndex, addrs, pruneTo, keep, ctx); err != nil {
 		return err
 	}
 	return nil
@@ -156,6 +156,34 @@ func TestPruneLogIndex(t *testing.T) {
 	}
 }
 
+func TestPruneLogIndexKeepsBackfilled(t *testing.T) {
+	require, tmpDir, ctx := require.New(t), t.TempDir(), context.Background()
+	_, tx := memdb.NewTestTx(t)
+
+	_, _ = genReceipts(t, tx, 10_000)
+
+	cfg := StageLogIndexCfg(nil, prune.DefaultMode, "")
+	err := promoteLogIndex("logPrefix", tx, 0, 0, cfg, ctx)
+	require.NoError(err)
+	require.NoError(rawdb.WriteReceiptsBackfill(tx, 1000, 1100))
+
+	addr := common.Address{1}
+	before, err := bitmapdb.Get(tx, kv.LogAddressIndex, addr[:], 0, 10_000_000)
+	require.NoError(err)
+	require.True(before.Contains(501))
+
+	err = pruneLogIndex("", tx, tmpDir, 5000, ctx)
+	require.NoError(err)
+
+	after, err := bitmapdb.Get(tx, kv.LogAddressIndex, addr[:], 0, 10_000_000)
+	require.NoError(err)
+	require.False(after.Contains(501))
+	for blockNum := uint32(1000); blockNum < 1100; blockNum++ {
+		require.Equal(before.Contains(blockNum), after.Contains(blockNum))
+	}
+	require.True(after.Contains(9999))
+}
+
 func TestUnwindLogIndex(t *testing.T) {
 	require, tmpDir, ctx := require.New(t), t.TempDir(), context.Background()
 	_, tx := memdb.NewTestTx(t)
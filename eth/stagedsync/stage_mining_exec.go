@@ -127,6 +127,9 @@ func SpawnMiningExecStage(s *StageState, tx kv.RwTx, cfg MiningExecCfg, quit <-c
 	if current.Receipts == nil {
 		current.Receipts = types.Receipts{}
 	}
+	if cfg.chainConfig.IsShanghai(current.Header.Time) {
+		misc.ApplyWithdrawals(ibs, current.Withdrawals)
+	}
 
 	_, err := core.FinalizeBlockExecution(cfg.engine, stateReader, current.Header, current.Txs, current.Uncles, stateWriter,
 		&cfg.chainConfig, ibs, current.Receipts, epochReader{tx: tx}, chainReader{config: &cfg.chainConfig, tx: tx, blockReader: cfg.blockReader}, true)
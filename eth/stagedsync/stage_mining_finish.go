@@ -43,7 +43,13 @@ func SpawnMiningFinishStage(s *StageState, tx kv.RwTx, cfg MiningFinishCfg, quit
 	//	continue
 	//}
 
-	block := types.NewBlock(current.Header, current.Txs, current.Uncles, current.Receipts)
+	var block *types.Block
+	if current.Withdrawals != nil {
+		block = types.NewBlockWithWithdrawals(current.Header, current.Txs, current.Uncles, current.Receipts, current.Withdrawals)
+	} else {
+		block = types.NewBlock(current.Header, current.Txs, current.Uncles, current.Receipts)
+	}
+	blockWithReceipts := &types.BlockWithReceipts{Block: block, Receipts: current.Receipts}
 	*current = MiningBlock{} // hack to clean global data
 
 	//sealHash := engine.SealHash(block.Header())
@@ -55,7 +61,7 @@ func SpawnMiningFinishStage(s *StageState, tx kv.RwTx, cfg MiningFinishCfg, quit
 	//prev = sealHash
 
 	if cfg.miningState.MiningResultPOSCh != nil {
-		cfg.miningState.MiningResultPOSCh <- block
+		cfg.miningState.MiningResultPOSCh <- blockWithReceipts
 		return nil
 	}
 	// Tests may set pre-calculated nonce
@@ -266,7 +266,7 @@ Loop:
 			cfg.hd.ReportBadHeaderPoS(minBlockHash, minHeader.ParentHash)
 		}
 		if to > s.BlockNumber {
-			u.UnwindTo(minBlockNum-1, minBlockHash)
+			u.UnwindTo(minBlockNum-1, minBlockHash, minBlockErr)
 		}
 	} else {
 		if err := collectorSenders.Load(tx, kv.Senders, etl.IdentityLoadFunc, etl.TransformArgs{
@@ -0,0 +1,59 @@
+package stages
+
+import (
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+)
+
+// SyncProgress is a snapshot of the progress of all stages, it is what eth_syncing and eth_subscribe("syncing") report
+type SyncProgress struct {
+	Syncing      bool            `json:"syncing"`
+	CurrentBlock hexutil.Uint64  `json:"currentBlock"` // progress of the Finish stage
+	HighestBlock hexutil.Uint64  `json:"highestBlock"` // progress of the Headers stage
+	Stages       []StageProgress `json:"stages"`
+	Timings      []StageTiming   `json:"timings,omitempty"` // of the current sync cycle, in the order the stages ran
+}
+
+type StageProgress struct {
+	StageName   string         `json:"stage_name"`
+	BlockNumber hexutil.Uint64 `json:"block_number"`
+}
+
+// StageTiming is how long a forward run, an unwind or a prune of a stage took
+type StageTiming struct {
+	StageName string `json:"stage_name"`
+	Unwind    bool   `json:"unwind,omitempty"`
+	Prune     bool   `json:"prune,omitempty"`
+	Took      string `json:"took"`
+}
+
+// ReadSyncProgress reads the progress of AllStages and of custom stages, the sync is considered done once Finish has
+// caught up with Headers
+func ReadSyncProgress(db kv.Getter) (*SyncProgress, error) {
+	highestBlock, err := GetStageProgress(db, Headers)
+	if err != nil {
+		return nil, err
+	}
+	currentBlock, err := GetStageProgress(db, Finish)
+	if err != nil {
+		return nil, err
+	}
+	custom, err := CustomStages(db)
+	if err != nil {
+		return nil, err
+	}
+	progress := &SyncProgress{
+		Syncing:      currentBlock == 0 || currentBlock < highestBlock,
+		CurrentBlock: hexutil.Uint64(currentBlock),
+		HighestBlock: hexutil.Uint64(highestBlock),
+		Stages:       make([]StageProgress, 0, len(AllStages)+len(custom)),
+	}
+	for _, stage := range append(append([]SyncStage{}, AllStages...), custom...) {
+		blockNumber, err := GetStageProgress(db, stage)
+		if err != nil {
+			return nil, err
+		}
+		progress.Stages = append(progress.Stages, StageProgress{StageName: string(stage), BlockNumber: hexutil.Uint64(blockNumber)})
+	}
+	return progress, nil
+}
@@ -19,6 +19,7 @@ package stages
 import (
 	"encoding/binary"
 	"fmt"
+	"strings"
 
 	"github.com/ledgerwatch/erigon-lib/kv"
 )
@@ -68,6 +69,26 @@ var AllStages = []SyncStage{
 	Finish,
 }
 
+// IsCustom - stages registered by embedders of the staged sync (see stagedsync.Sync.AddStage) are named by reverse
+// domain name (`com.example.my-stage`), names of stages above have no dots
+func IsCustom(stage SyncStage) bool {
+	return strings.Contains(string(stage), ".") && !strings.HasPrefix(string(stage), "prune_")
+}
+
+// CustomStages - custom stages which saved their progress, ordered by name
+func CustomStages(db kv.Getter) ([]SyncStage, error) {
+	var res []SyncStage
+	if err := db.ForEach(kv.SyncStageProgress, nil, func(k, _ []byte) error {
+		if stage := SyncStage(k); IsCustom(stage) {
+			res = append(res, stage)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 // GetStageProgress retrieves saved progress of given sync stage from the database
 func GetStageProgress(db kv.Getter, stage SyncStage) (uint64, error) {
 	v, err := db.GetOne(kv.SyncStageProgress, []byte(stage))
@@ -3,12 +3,15 @@ package stagedsync
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/log/v3"
 )
@@ -17,6 +20,7 @@ type Sync struct {
 	unwindPoint     *uint64 // used to run stages
 	prevUnwindPoint *uint64 // used to get value from outside of staged sync after cycle (for example to notify RPCDaemon)
 	badBlock        common.Hash
+	badBlockErr     error
 
 	stages       []*Stage
 	unwindOrder  []*Stage
@@ -24,6 +28,9 @@ type Sync struct {
 	currentStage uint
 	timings      []Timing
 	logPrefixes  []string
+
+	onProgress   func(*stages.SyncProgress)
+	lastProgress []stages.StageProgress
 }
 
 type Timing struct {
@@ -33,6 +40,10 @@ type Timing struct {
 	took     time.Duration
 }
 
+// OnProgress sets the callback told about the progress of the stages every time a stage moves it forward or back,
+// the Finish stage included
+func (s *Sync) OnProgress(f func(*stages.SyncProgress)) { s.onProgress = f }
+
 func (s *Sync) Len() int                 { return len(s.stages) }
 func (s *Sync) PrevUnwindPoint() *uint64 { return s.prevUnwindPoint }
 
@@ -105,10 +116,43 @@ func (s *Sync) IsAfter(stage1, stage2 stages.SyncStage) bool {
 	return idx1 > idx2
 }
 
-func (s *Sync) UnwindTo(unwindPoint uint64, badBlock common.Hash) {
-	log.Info("UnwindTo", "block", unwindPoint, "bad_block_hash", badBlock.String())
+func (s *Sync) UnwindTo(unwindPoint uint64, badBlock common.Hash, badBlockErr error) {
+	if badBlockErr != nil {
+		log.Info("UnwindTo", "block", unwindPoint, "bad_block_hash", badBlock.String(), "err", badBlockErr)
+	} else {
+		log.Info("UnwindTo", "block", unwindPoint, "bad_block_hash", badBlock.String())
+	}
 	s.unwindPoint = &unwindPoint
 	s.badBlock = badBlock
+	s.badBlockErr = badBlockErr
+}
+
+// recordBadBlock saves the bad block which caused the unwind, before the unwind of the stages removes it
+func (s *Sync) recordBadBlock(db kv.RwDB, tx kv.RwTx) error {
+	if s.badBlock == (common.Hash{}) {
+		return nil
+	}
+	if tx == nil {
+		return db.Update(context.Background(), func(tx kv.RwTx) error { return s.recordBadBlock(db, tx) })
+	}
+	number := rawdb.ReadHeaderNumber(tx, s.badBlock)
+	if number == nil {
+		return nil
+	}
+	block := rawdb.ReadBlock(tx, s.badBlock, *number)
+	if block == nil {
+		// Rejected before its body was written
+		header := rawdb.ReadHeader(tx, s.badBlock, *number)
+		if header == nil {
+			return nil
+		}
+		block = types.NewBlockWithHeader(header)
+	}
+	var reason string
+	if s.badBlockErr != nil {
+		reason = s.badBlockErr.Error()
+	}
+	return rawdb.WriteBadBlock(tx, block, reason)
 }
 
 func (s *Sync) IsDone() bool {
@@ -151,18 +195,68 @@ func New(stagesList []*Stage, unwindOrder UnwindOrder, pruneOrder PruneOrder) *S
 			}
 		}
 	}
-	logPrefixes := make([]string, len(stagesList))
-	for i := range stagesList {
-		logPrefixes[i] = fmt.Sprintf("%d/%d %s", i+1, len(stagesList), stagesList[i].ID)
-	}
 
 	return &Sync{
 		stages:       stagesList,
 		currentStage: 0,
 		unwindOrder:  unwindStages,
 		pruningOrder: pruneStages,
-		logPrefixes:  logPrefixes,
+		logPrefixes:  logPrefixes(stagesList),
+	}
+}
+
+func logPrefixes(stagesList []*Stage) []string {
+	res := make([]string, len(stagesList))
+	for i := range stagesList {
+		res[i] = fmt.Sprintf("%d/%d %s", i+1, len(stagesList), stagesList[i].ID)
+	}
+	return res
+}
+
+// AddStage registers custom stage of embedder of the staged sync. It runs right after the stage `after`, and is unwound
+// and pruned right before it. Progress of the stage is saved under its ID, which must be reverse domain name
+// (`com.example.my-stage`), see stages.IsCustom. Must be called before first cycle of the sync.
+func (s *Sync) AddStage(stage *Stage, after stages.SyncStage) error {
+	if !stages.IsCustom(stage.ID) {
+		return fmt.Errorf("custom stage id must be reverse domain name, like com.example.my-stage, got: %q", stage.ID)
+	}
+	if stage.Forward == nil || stage.Unwind == nil {
+		return fmt.Errorf("custom stage %s: Forward and Unwind must not be nil", stage.ID)
+	}
+	pos := -1
+	for i, st := range s.stages {
+		if st.ID == stage.ID {
+			return fmt.Errorf("stage already exists with id: %v", stage.ID)
+		}
+		if st.ID == after {
+			pos = i + 1
+		}
+	}
+	if pos < 0 {
+		return fmt.Errorf("stage not found with id: %v", after)
 	}
+	s.stages = insertStage(s.stages, pos, stage)
+	s.unwindOrder = insertStage(s.unwindOrder, stageIndex(s.unwindOrder, after), stage)
+	s.pruningOrder = insertStage(s.pruningOrder, stageIndex(s.pruningOrder, after), stage)
+	s.logPrefixes = logPrefixes(s.stages)
+	return nil
+}
+
+// stageIndex - position of the stage in the order, 0 if the stage isn't there
+func stageIndex(order []*Stage, id stages.SyncStage) int {
+	for i, st := range order {
+		if st != nil && st.ID == id {
+			return i
+		}
+	}
+	return 0
+}
+
+func insertStage(list []*Stage, pos int, stage *Stage) []*Stage {
+	res := make([]*Stage, 0, len(list)+1)
+	res = append(res, list[:pos]...)
+	res = append(res, stage)
+	return append(res, list[pos:]...)
 }
 
 func (s *Sync) StageState(stage stages.SyncStage, tx kv.Tx, db kv.RoDB) (*StageState, error) {
@@ -189,10 +283,47 @@ func (s *Sync) StageState(stage stages.SyncStage, tx kv.Tx, db kv.RoDB) (*StageS
 	return &StageState{s, stage, blockNum}, nil
 }
 
+// UnwindFrom - unwinds given stage and all stages after it to unwindPoint, earlier stages keep their progress.
+// Allows to re-build output of one stage from its input.
+func (s *Sync) UnwindFrom(id stages.SyncStage, unwindPoint uint64, db kv.RwDB, tx kv.RwTx) error {
+	from := -1
+	for i, stage := range s.stages {
+		if stage.ID == id {
+			from = i
+			break
+		}
+	}
+	if from < 0 {
+		return fmt.Errorf("stage not found with id: %v", id)
+	}
+	unwound := make(map[stages.SyncStage]struct{}, len(s.stages)-from)
+	for _, stage := range s.stages[from:] {
+		unwound[stage.ID] = struct{}{}
+	}
+
+	s.unwindPoint = &unwindPoint
+	defer func() { s.unwindPoint = nil }()
+	for _, stage := range s.unwindOrder {
+		if stage == nil || stage.Disabled || stage.Unwind == nil {
+			continue
+		}
+		if _, ok := unwound[stage.ID]; !ok {
+			continue
+		}
+		if err := s.unwindStage(false, stage, db, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Sync) RunUnwind(db kv.RwDB, tx kv.RwTx) error {
 	if s.unwindPoint == nil {
 		return nil
 	}
+	if err := s.recordBadBlock(db, tx); err != nil {
+		return err
+	}
 	for j := 0; j < len(s.unwindOrder); j++ {
 		if s.unwindOrder[j] == nil || s.unwindOrder[j].Disabled || s.unwindOrder[j].Unwind == nil {
 			continue
@@ -204,6 +335,7 @@ func (s *Sync) RunUnwind(db kv.RwDB, tx kv.RwTx) error {
 	s.prevUnwindPoint = s.unwindPoint
 	s.unwindPoint = nil
 	s.badBlock = common.Hash{}
+	s.badBlockErr = nil
 	if err := s.SetCurrentStage(s.stages[0].ID); err != nil {
 		return err
 	}
@@ -216,6 +348,9 @@ func (s *Sync) Run(db kv.RwDB, tx kv.RwTx, firstCycle bool) error {
 	for !s.IsDone() {
 		var badBlockUnwind bool
 		if s.unwindPoint != nil {
+			if err := s.recordBadBlock(db, tx); err != nil {
+				return err
+			}
 			for j := 0; j < len(s.unwindOrder); j++ {
 				if s.unwindOrder[j] == nil || s.unwindOrder[j].Disabled || s.unwindOrder[j].Unwind == nil {
 					continue
@@ -224,12 +359,16 @@ func (s *Sync) Run(db kv.RwDB, tx kv.RwTx, firstCycle bool) error {
 					return err
 				}
 			}
+			if err := s.notifyProgress(db, tx); err != nil {
+				return err
+			}
 			s.prevUnwindPoint = s.unwindPoint
 			s.unwindPoint = nil
 			if s.badBlock != (common.Hash{}) {
 				badBlockUnwind = true
 			}
 			s.badBlock = common.Hash{}
+			s.badBlockErr = nil
 			if err := s.SetCurrentStage(s.stages[0].ID); err != nil {
 				return err
 			}
@@ -255,6 +394,9 @@ func (s *Sync) Run(db kv.RwDB, tx kv.RwTx, firstCycle bool) error {
 		if err := s.runStage(stage, db, tx, firstCycle, badBlockUnwind); err != nil {
 			return err
 		}
+		if err := s.notifyProgress(db, tx); err != nil {
+			return err
+		}
 
 		s.NextStage()
 	}
@@ -278,6 +420,41 @@ func (s *Sync) Run(db kv.RwDB, tx kv.RwTx, firstCycle bool) error {
 	return nil
 }
 
+// notifyProgress passes the progress of the stages to the onProgress callback, unless no stage has moved since the last call
+func (s *Sync) notifyProgress(db kv.RoDB, tx kv.Tx) error {
+	if s.onProgress == nil {
+		return nil
+	}
+	var progress *stages.SyncProgress
+	var err error
+	if tx != nil {
+		progress, err = stages.ReadSyncProgress(tx)
+	} else {
+		err = db.View(context.Background(), func(tx kv.Tx) error {
+			progress, err = stages.ReadSyncProgress(tx)
+			return err
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(progress.Stages, s.lastProgress) {
+		return nil
+	}
+	s.lastProgress = progress.Stages
+	progress.Timings = make([]stages.StageTiming, len(s.timings))
+	for i, t := range s.timings {
+		progress.Timings[i] = stages.StageTiming{
+			StageName: string(t.stage),
+			Unwind:    t.isUnwind,
+			Prune:     t.isPrune,
+			Took:      t.took.Truncate(time.Millisecond).String(),
+		}
+	}
+	s.onProgress(progress)
+	return nil
+}
+
 func printLogs(db kv.RoDB, tx kv.RwTx, timings []Timing) error {
 	var logCtx []interface{}
 	count := 0
@@ -177,7 +177,7 @@ func TestUnwindSomeStagesBehindUnwindPoint(t *testing.T) {
 				flow = append(flow, stages.Senders)
 				if !unwound {
 					unwound = true
-					u.UnwindTo(1500, common.Hash{})
+					u.UnwindTo(1500, common.Hash{}, nil)
 					return nil
 				}
 				return nil
@@ -270,7 +270,7 @@ func TestUnwind(t *testing.T) {
 				flow = append(flow, stages.Senders)
 				if !unwound {
 					unwound = true
-					u.UnwindTo(500, common.Hash{})
+					u.UnwindTo(500, common.Hash{}, nil)
 					return s.Update(tx, 3000)
 				}
 				return nil
@@ -324,7 +324,7 @@ func TestUnwind(t *testing.T) {
 	//check that at unwind disabled stage not appear
 	flow = flow[:0]
 	state.unwindOrder = []*Stage{s[3], s[2], s[1], s[0]}
-	state.UnwindTo(100, common.Hash{})
+	state.UnwindTo(100, common.Hash{}, nil)
 	err = state.Run(db, tx, true)
 	assert.NoError(t, err)
 
@@ -337,6 +337,90 @@ func TestUnwind(t *testing.T) {
 
 }
 
+func TestUnwindFrom(t *testing.T) {
+	flow := make([]stages.SyncStage, 0)
+	newStage := func(id stages.SyncStage) *Stage {
+		return &Stage{
+			ID: id,
+			Forward: func(firstCycle bool, badBlockUnwind bool, s *StageState, u Unwinder, tx kv.RwTx) error {
+				flow = append(flow, id)
+				return s.Update(tx, 2000)
+			},
+			Unwind: func(firstCycle bool, u *UnwindState, s *StageState, tx kv.RwTx) error {
+				flow = append(flow, unwindOf(id))
+				return u.Done(tx)
+			},
+		}
+	}
+	s := []*Stage{newStage(stages.Headers), newStage(stages.Bodies), newStage(stages.Senders), newStage(stages.Execution)}
+	state := New(s, []stages.SyncStage{s[3].ID, s[2].ID, s[1].ID, s[0].ID}, nil)
+	db, tx := memdb.NewTestTx(t)
+	err := state.Run(db, tx, true)
+	assert.NoError(t, err)
+
+	flow = flow[:0]
+	err = state.UnwindFrom(stages.Senders, 500, db, tx)
+	assert.NoError(t, err)
+	assert.Equal(t, []stages.SyncStage{unwindOf(stages.Execution), unwindOf(stages.Senders)}, flow)
+
+	for id, expect := range map[stages.SyncStage]uint64{stages.Headers: 2000, stages.Bodies: 2000, stages.Senders: 500, stages.Execution: 500} {
+		progress, err := stages.GetStageProgress(tx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, progress, id)
+	}
+
+	err = state.UnwindFrom(stages.TxLookup, 500, db, tx)
+	assert.Error(t, err)
+}
+
+func TestAddStage(t *testing.T) {
+	const custom stages.SyncStage = "com.example.indexer"
+	flow := make([]stages.SyncStage, 0)
+	newStage := func(id stages.SyncStage) *Stage {
+		return &Stage{
+			ID: id,
+			Forward: func(firstCycle bool, badBlockUnwind bool, s *StageState, u Unwinder, tx kv.RwTx) error {
+				flow = append(flow, id)
+				if id == stages.Senders && s.BlockNumber == 0 {
+					u.UnwindTo(500, common.Hash{}, nil)
+				}
+				return s.Update(tx, 2000)
+			},
+			Unwind: func(firstCycle bool, u *UnwindState, s *StageState, tx kv.RwTx) error {
+				flow = append(flow, unwindOf(id))
+				return u.Done(tx)
+			},
+			Prune: func(firstCycle bool, p *PruneState, tx kv.RwTx) error {
+				flow = append(flow, prunedOf(id))
+				return nil
+			},
+		}
+	}
+	s := []*Stage{newStage(stages.Headers), newStage(stages.Execution), newStage(stages.Senders)}
+	state := New(s, []stages.SyncStage{s[2].ID, s[1].ID, s[0].ID}, []stages.SyncStage{s[2].ID, s[1].ID, s[0].ID})
+	assert.Error(t, state.AddStage(newStage("indexer"), stages.Execution))
+	assert.Error(t, state.AddStage(newStage(custom), stages.TxLookup))
+	assert.NoError(t, state.AddStage(newStage(custom), stages.Execution))
+	assert.Error(t, state.AddStage(newStage(custom), stages.Execution))
+
+	db, tx := memdb.NewTestTx(t)
+	err := state.Run(db, tx, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []stages.SyncStage{
+		stages.Headers, stages.Execution, custom, stages.Senders,
+		unwindOf(stages.Senders), unwindOf(custom), unwindOf(stages.Execution), unwindOf(stages.Headers),
+		stages.Headers, stages.Execution, custom, stages.Senders,
+		prunedOf(stages.Senders), prunedOf(custom), prunedOf(stages.Execution), prunedOf(stages.Headers),
+	}, flow)
+
+	customStages, err := stages.CustomStages(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, []stages.SyncStage{custom}, customStages)
+	progress, err := stages.ReadSyncProgress(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, stages.StageProgress{StageName: string(custom), BlockNumber: 2000}, progress.Stages[len(progress.Stages)-1])
+}
+
 func TestUnwindEmptyUnwinder(t *testing.T) {
 	flow := make([]stages.SyncStage, 0)
 	unwound := false
@@ -374,7 +458,7 @@ func TestUnwindEmptyUnwinder(t *testing.T) {
 				flow = append(flow, stages.Senders)
 				if !unwound {
 					unwound = true
-					u.UnwindTo(500, common.Hash{})
+					u.UnwindTo(500, common.Hash{}, nil)
 					return s.Update(tx, 3000)
 				}
 				return nil
@@ -562,7 +646,7 @@ func TestSyncInterruptLongUnwind(t *testing.T) {
 				flow = append(flow, stages.Senders)
 				if !unwound {
 					unwound = true
-					u.UnwindTo(500, common.Hash{})
+					u.UnwindTo(500, common.Hash{}, nil)
 					return s.Update(tx, 3000)
 				}
 				return nil
@@ -587,7 +671,7 @@ func TestSyncInterruptLongUnwind(t *testing.T) {
 	//state.unwindOrder = []*Stage{s[0], s[1], s[2]}
 	//err = state.LoadUnwindInfo(tx)
 	//assert.NoError(t, err)
-	//state.UnwindTo(500, common.Hash{})
+	//state.UnwindTo(500, common.Hash{}, nil)
 	err = state.Run(db, tx, true)
 	assert.NoError(t, err)
 
@@ -616,3 +700,7 @@ func TestSyncInterruptLongUnwind(t *testing.T) {
 func unwindOf(s stages.SyncStage) stages.SyncStage {
 	return stages.SyncStage(append([]byte(s), 0xF0))
 }
+
+func prunedOf(s stages.SyncStage) stages.SyncStage {
+	return stages.SyncStage(append([]byte(s), 0xF1))
+}
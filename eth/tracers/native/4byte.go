@@ -0,0 +1,122 @@
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+)
+
+func init() {
+	tracers.RegisterNative("4byteTracer", newFourByteTracer)
+}
+
+// fourByteTracer is a native port of 4byte_tracer.js. It searches for 4byte
+// identifiers and collects them for post-processing, together with the size
+// of the supplied data, so a reversed signature can be matched against the
+// size of the data.
+//
+// Example:
+//
+//	> debug.traceTransaction( "0x214e597e35da083692f5386141e69f47e973b2c56e7a8073b1ea08fd7571e9de", {tracer: "4byteTracer"})
+//	{
+//	  0x27dc297e-128: 1,
+//	  0x38cc4831-0: 2,
+//	  0x524f3889-96: 1,
+//	  0xadf59f99-288: 1,
+//	  0xc281d19e-0: 1
+//	}
+type fourByteTracer struct {
+	ids         map[string]int   // ids aggregates the 4byte ids found
+	precompiles []common.Address // Updated on CaptureStart based on given rules
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newFourByteTracer(_ *tracers.Context, _ json.RawMessage) (tracers.ResultTracer, error) {
+	return &fourByteTracer{ids: make(map[string]int)}, nil
+}
+
+// store saves the given identifier and datasize.
+func (t *fourByteTracer) store(id []byte, size int) {
+	key := hexutil.Encode(id) + "-" + strconv.Itoa(size)
+	t.ids[key]++
+}
+
+func (t *fourByteTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, calltype vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if depth != 0 {
+		return
+	}
+	t.precompiles = vm.ActivePrecompiles(env.ChainRules())
+	if len(input) >= 4 {
+		t.store(input[0:4], len(input)-4)
+	}
+}
+
+func (t *fourByteTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if err != nil || atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	// Stack position of the input memory offset
+	var inPos int
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		inPos = 3
+	case vm.DELEGATECALL, vm.STATICCALL:
+		inPos = 2
+	default:
+		return
+	}
+	stack := scope.Stack
+	to := common.Address(stack.Back(1).Bytes20())
+	for _, p := range t.precompiles {
+		if p == to {
+			return
+		}
+	}
+	inSize := stack.Back(inPos + 1).Uint64()
+	if inSize >= 4 {
+		inOff := stack.Back(inPos).Uint64()
+		if id := memorySlice(scope.Memory, inOff, 4); len(id) == 4 {
+			t.store(id, int(inSize-4))
+		}
+	}
+}
+
+func (t *fourByteTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *fourByteTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+}
+
+func (t *fourByteTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+}
+
+func (t *fourByteTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (t *fourByteTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
+
+// GetResult returns the json-encoded 4byte identifiers and their counters.
+func (t *fourByteTracer) GetResult() (json.RawMessage, error) {
+	if t.reason != nil {
+		return nil, t.reason
+	}
+	return json.Marshal(t.ids)
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *fourByteTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
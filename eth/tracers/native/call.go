@@ -0,0 +1,353 @@
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+)
+
+func init() {
+	tracers.RegisterNative("callTracer", newCallTracer)
+}
+
+type callLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// callFrame mirrors the output of the JavaScript callTracer, the fields are
+// serialised in the same order and omitted under the same conditions.
+type callFrame struct {
+	Type    string          `json:"type"`
+	From    common.Address  `json:"from"`
+	To      *common.Address `json:"to,omitempty"`
+	Value   *hexutil.Big    `json:"value,omitempty"`
+	Gas     *hexutil.Uint64 `json:"gas,omitempty"`
+	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Input   hexutil.Bytes   `json:"input"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Logs    []callLog       `json:"logs,omitempty"`
+	Time    string          `json:"time,omitempty"`
+	Calls   []*callFrame    `json:"calls,omitempty"`
+
+	gasIn   uint64 // Gas available before the call opcode
+	gasCost uint64 // Cost of the call opcode, including gas passed to the callee
+	outOff  uint64 // Memory offset of the return data in the caller
+	outLen  uint64 // Memory size of the return data in the caller
+}
+
+type callTracerConfig struct {
+	OnlyTopCall bool `json:"onlyTopCall"` // If true, call tracer won't collect any subcalls
+	WithLog     bool `json:"withLog"`     // If true, call tracer will collect event logs
+}
+
+// callTracer is a native port of call_tracer.js. It reconstructs the call tree
+// from the executed opcodes exactly like the JavaScript version does, so the
+// results are interchangeable.
+type callTracer struct {
+	cfg       callTracerConfig
+	env       *vm.EVM
+	callstack []*callFrame
+	descended bool
+
+	top         callFrame // Top level call, populated from CaptureStart/CaptureEnd
+	topErr      error
+	precompiles []common.Address
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newCallTracer(_ *tracers.Context, cfg json.RawMessage) (tracers.ResultTracer, error) {
+	var config callTracerConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	return &callTracer{cfg: config, callstack: []*callFrame{{}}}, nil
+}
+
+func (t *callTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, calltype vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if depth != 0 {
+		return
+	}
+	t.env = env
+	t.precompiles = vm.ActivePrecompiles(env.ChainRules())
+	t.top.Type = vm.CALL.String()
+	if create {
+		t.top.Type = vm.CREATE.String()
+	}
+	t.top.From = from
+	t.top.To = &to
+	t.top.Input = common.CopyBytes(input)
+	t.top.Gas = (*hexutil.Uint64)(&gas)
+	if value != nil {
+		t.top.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	} else {
+		t.top.Value = (*hexutil.Big)(new(big.Int))
+	}
+}
+
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	if err != nil {
+		t.fault(err)
+		return
+	}
+	if t.cfg.OnlyTopCall {
+		if t.cfg.WithLog && depth == 1 {
+			t.captureLog(op, scope)
+		}
+		return
+	}
+	stack := scope.Stack
+	switch op {
+	case vm.CREATE, vm.CREATE2:
+		inOff := stack.Back(1).Uint64()
+		t.callstack = append(t.callstack, &callFrame{
+			Type:    op.String(),
+			From:    scope.Contract.Address(),
+			Input:   memorySlice(scope.Memory, inOff, stack.Back(2).Uint64()),
+			Value:   bigFromUint256(stack.Back(0)),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+		t.descended = true
+		return
+
+	case vm.SELFDESTRUCT:
+		to := common.Address(stack.Back(0).Bytes20())
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, &callFrame{
+			Type:  op.String(),
+			From:  scope.Contract.Address(),
+			To:    &to,
+			Value: bigFromUint256(env.IntraBlockState().GetBalance(scope.Contract.Address())),
+		})
+		return
+
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		to := common.Address(stack.Back(1).Bytes20())
+		if t.isPrecompiled(to) {
+			return
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		inOff := stack.Back(2 + off).Uint64()
+		call := &callFrame{
+			Type:    op.String(),
+			From:    scope.Contract.Address(),
+			To:      &to,
+			Input:   memorySlice(scope.Memory, inOff, stack.Back(3+off).Uint64()),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  stack.Back(4 + off).Uint64(),
+			outLen:  stack.Back(5 + off).Uint64(),
+		}
+		if op == vm.CALL || op == vm.CALLCODE {
+			call.Value = bigFromUint256(stack.Back(2))
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return
+	}
+	// If we've just descended into an inner call, retrieve its true allowance
+	if t.descended {
+		if depth >= len(t.callstack) {
+			t.callstack[len(t.callstack)-1].Gas = newUint64(gas)
+		}
+		t.descended = false
+	}
+	if op == vm.REVERT {
+		t.callstack[len(t.callstack)-1].Error = vm.ErrExecutionReverted.Error()
+		return
+	}
+	// If we've just returned from an inner call, pop it off and attach it to the parent
+	if depth == len(t.callstack)-1 {
+		call := t.callstack[len(t.callstack)-1]
+		t.callstack = t.callstack[:len(t.callstack)-1]
+
+		ret := stack.Back(0)
+		if call.Type == vm.CREATE.String() || call.Type == vm.CREATE2.String() {
+			call.GasUsed = newUint64(call.gasIn - call.gasCost - gas)
+			if !ret.IsZero() {
+				to := common.Address(ret.Bytes20())
+				call.To = &to
+				call.Output = newBytes(env.IntraBlockState().GetCode(to))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		} else {
+			if call.Gas != nil {
+				call.GasUsed = newUint64(call.gasIn - call.gasCost + uint64(*call.Gas) - gas)
+			}
+			if !ret.IsZero() {
+				call.Output = newBytes(memorySlice(scope.Memory, call.outOff, call.outLen))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		}
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+	}
+	if t.cfg.WithLog {
+		t.captureLog(op, scope)
+	}
+}
+
+// fault marks the currently executing call as failed and attaches it to its parent.
+func (t *callTracer) fault(err error) {
+	if t.cfg.OnlyTopCall || t.callstack[len(t.callstack)-1].Error != "" {
+		return
+	}
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	call.Error = err.Error()
+	if call.Gas != nil {
+		call.GasUsed = newUint64(uint64(*call.Gas))
+	}
+	if len(t.callstack) > 0 {
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+		return
+	}
+	t.callstack = append(t.callstack, call)
+}
+
+func (t *callTracer) captureLog(op vm.OpCode, scope *vm.ScopeContext) {
+	if op < vm.LOG0 || op > vm.LOG4 {
+		return
+	}
+	stack := scope.Stack
+	topics := make([]common.Hash, int(op-vm.LOG0))
+	for i := range topics {
+		topics[i] = common.Hash(stack.Back(2 + i).Bytes32())
+	}
+	frame := t.callstack[len(t.callstack)-1]
+	frame.Logs = append(frame.Logs, callLog{
+		Address: scope.Contract.Address(),
+		Topics:  topics,
+		Data:    memorySlice(scope.Memory, stack.Back(0).Uint64(), stack.Back(1).Uint64()),
+	})
+}
+
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	t.fault(err)
+}
+
+func (t *callTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+	if depth != 0 {
+		return
+	}
+	t.top.Output = newBytes(common.CopyBytes(output))
+	t.top.GasUsed = newUint64(startGas - endGas)
+	t.top.Time = d.String()
+	t.topErr = err
+}
+
+func (t *callTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+}
+
+func (t *callTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (t *callTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
+
+// GetResult returns the json-encoded call tree, or any accumulated error.
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	if t.reason != nil {
+		return nil, t.reason
+	}
+	if t.env == nil {
+		return nil, errors.New("callTracer: no transaction traced")
+	}
+	result := t.top
+	root := t.callstack[0]
+	result.Calls = root.Calls
+	result.Logs = root.Logs
+	if root.Error != "" {
+		result.Error = root.Error
+	} else if t.topErr != nil {
+		result.Error = t.topErr.Error()
+	}
+	if result.Error != "" && (result.Error != vm.ErrExecutionReverted.Error() || result.Output == nil || len(*result.Output) == 0) {
+		result.Output = nil
+	}
+	if t.cfg.WithLog {
+		clearFailedLogs(&result, false)
+	}
+	return json.Marshal(&result)
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *callTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+func (t *callTracer) isPrecompiled(addr common.Address) bool {
+	for _, p := range t.precompiles {
+		if p == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// clearFailedLogs drops the logs of the failed calls and their subcalls, as
+// those are reverted and never make it into the receipts.
+func clearFailedLogs(call *callFrame, parentFailed bool) {
+	failed := call.Error != "" || parentFailed
+	if failed {
+		call.Logs = nil
+	}
+	for _, sub := range call.Calls {
+		clearFailedLogs(sub, failed)
+	}
+}
+
+// memorySlice returns a copy of size bytes of memory at offset, an empty slice
+// if size is 0, or nil if the range is out of bounds.
+func memorySlice(mem *vm.Memory, offset, size uint64) []byte {
+	if size == 0 {
+		return []byte{}
+	}
+	end := offset + size
+	if end < offset || uint64(mem.Len()) < end {
+		return nil
+	}
+	return mem.GetCopy(offset, size)
+}
+
+func newUint64(v uint64) *hexutil.Uint64 {
+	return (*hexutil.Uint64)(&v)
+}
+
+func newBytes(b []byte) *hexutil.Bytes {
+	return (*hexutil.Bytes)(&b)
+}
+
+func bigFromUint256(v *uint256.Int) *hexutil.Big {
+	return (*hexutil.Big)(v.ToBig())
+}
@@ -0,0 +1,281 @@
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+)
+
+func init() {
+	tracers.RegisterNative("prestateTracer", newPrestateTracer)
+}
+
+type account struct {
+	balance *big.Int
+	nonce   uint64
+	code    []byte
+	storage map[common.Hash]common.Hash
+}
+
+func (a *account) empty() bool {
+	return a.balance.Sign() == 0 && a.nonce == 0 && len(a.code) == 0
+}
+
+// prestateAccount is serialised the same way prestate_tracer.js does it.
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// diffAccount is used in diff mode, where the zero and unchanged fields are omitted.
+type diffAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+type prestateTracerConfig struct {
+	DiffMode bool `json:"diffMode"` // If true, this tracer will return the state modifications
+}
+
+// prestateTracer is a native port of prestate_tracer.js. It collects every
+// account and storage slot touched by the transaction together with the
+// values they had before the transaction. In diff mode it reports the values
+// before and after the transaction, for the modified accounts and slots only.
+type prestateTracer struct {
+	cfg      prestateTracerConfig
+	env      *vm.EVM
+	pre      map[common.Address]*account
+	create   bool
+	to       common.Address
+	gasLimit uint64 // Gas limit of the transaction, all of it is bought before the execution
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newPrestateTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.ResultTracer, error) {
+	var config prestateTracerConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	return &prestateTracer{
+		cfg:      config,
+		pre:      make(map[common.Address]*account),
+		gasLimit: ctx.GasLimit,
+	}, nil
+}
+
+func (t *prestateTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, calltype vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if depth != 0 {
+		return
+	}
+	t.env = env
+	t.create = create
+	t.to = to
+	t.lookupAccount(from)
+	t.lookupAccount(to)
+	if t.cfg.DiffMode {
+		// The fees are only paid to the miner after the execution
+		t.lookupAccount(env.Context().Coinbase)
+	}
+
+	// The gas has already been bought by the state transition and, for calls,
+	// the sender nonce has been bumped. Rewind those to get the pre-tx values.
+	if gasPrice := env.TxContext().GasPrice; gasPrice != nil {
+		bought := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(t.gasLimit))
+		t.pre[from].balance.Add(t.pre[from].balance, bought)
+	}
+	if !create && t.pre[from].nonce > 0 {
+		t.pre[from].nonce--
+	}
+}
+
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if err != nil || atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	stack := scope.Stack
+	caller := scope.Contract.Address()
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.EXTCODEHASH, vm.BALANCE, vm.SELFDESTRUCT:
+		t.lookupAccount(common.Address(stack.Back(0).Bytes20()))
+	case vm.CREATE:
+		t.lookupAccount(crypto.CreateAddress(caller, env.IntraBlockState().GetNonce(caller)))
+	case vm.CREATE2:
+		initCode := memorySlice(scope.Memory, stack.Back(1).Uint64(), stack.Back(2).Uint64())
+		t.lookupAccount(crypto.CreateAddress2(caller, stack.Back(3).Bytes32(), crypto.Keccak256(initCode)))
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.lookupAccount(common.Address(stack.Back(1).Bytes20()))
+	case vm.SSTORE, vm.SLOAD:
+		t.lookupStorage(caller, common.Hash(stack.Back(0).Bytes32()))
+	}
+}
+
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *prestateTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+}
+
+func (t *prestateTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+}
+
+func (t *prestateTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (t *prestateTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
+
+// GetResult returns the json-encoded prestate, or the pre and post states in
+// diff mode. It has to be called once the transaction has been fully applied,
+// so that the refunds and fees are reflected in the post state.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.reason != nil {
+		return nil, t.reason
+	}
+	if t.cfg.DiffMode {
+		pre, post := t.diff()
+		return json.Marshal(struct {
+			Pre  map[common.Address]*diffAccount `json:"pre"`
+			Post map[common.Address]*diffAccount `json:"post"`
+		}{pre, post})
+	}
+	res := make(map[common.Address]*prestateAccount, len(t.pre))
+	for addr, acc := range t.pre {
+		if t.create && addr == t.to {
+			// The created contract did not exist before the transaction
+			continue
+		}
+		res[addr] = &prestateAccount{
+			Balance: (*hexutil.Big)(acc.balance),
+			Nonce:   acc.nonce,
+			Code:    acc.code,
+			Storage: acc.storage,
+		}
+	}
+	return json.Marshal(res)
+}
+
+// diff compares the collected prestate against the current state and returns
+// the modified accounts only. The pre state holds the full accounts (with the
+// modified slots only), the post state holds the changed fields only.
+func (t *prestateTracer) diff() (map[common.Address]*diffAccount, map[common.Address]*diffAccount) {
+	pre := make(map[common.Address]*diffAccount)
+	post := make(map[common.Address]*diffAccount)
+	if t.env == nil {
+		return pre, post
+	}
+	ibs := t.env.IntraBlockState()
+	for addr, prev := range t.pre {
+		if ibs.HasSuicided(addr) || !ibs.Exist(addr) {
+			// Destructed accounts only show up in the pre state
+			if !prev.empty() {
+				pre[addr] = newDiffAccount(prev.balance, prev.nonce, prev.code, prev.storage)
+			}
+			continue
+		}
+		var (
+			modified bool
+			after    = &diffAccount{}
+			storage  map[common.Hash]common.Hash
+		)
+		if balance := ibs.GetBalance(addr).ToBig(); balance.Cmp(prev.balance) != 0 {
+			modified = true
+			after.Balance = (*hexutil.Big)(balance)
+		}
+		if nonce := ibs.GetNonce(addr); nonce != prev.nonce {
+			modified = true
+			after.Nonce = nonce
+		}
+		if code := ibs.GetCode(addr); !bytes.Equal(code, prev.code) {
+			modified = true
+			after.Code = common.CopyBytes(code)
+		}
+		for key, val := range prev.storage {
+			key := key
+			var value uint256.Int
+			ibs.GetState(addr, &key, &value)
+			if newVal := common.Hash(value.Bytes32()); newVal != val {
+				modified = true
+				if storage == nil {
+					storage = make(map[common.Hash]common.Hash)
+					after.Storage = make(map[common.Hash]common.Hash)
+				}
+				storage[key] = val
+				if !value.IsZero() {
+					after.Storage[key] = newVal
+				}
+			}
+		}
+		if !modified {
+			continue
+		}
+		// Accounts which did not exist before the transaction are not in the pre state
+		if !prev.empty() {
+			pre[addr] = newDiffAccount(prev.balance, prev.nonce, prev.code, storage)
+		}
+		post[addr] = after
+	}
+	return pre, post
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// lookupAccount fetches details of an account and adds it to the prestate
+// if it doesn't exist there yet.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.pre[addr]; ok {
+		return
+	}
+	ibs := t.env.IntraBlockState()
+	t.pre[addr] = &account{
+		balance: ibs.GetBalance(addr).ToBig(),
+		nonce:   ibs.GetNonce(addr),
+		code:    common.CopyBytes(ibs.GetCode(addr)),
+		storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+// lookupStorage fetches the requested storage slot and adds it to the
+// prestate of the given contract.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+	if _, ok := t.pre[addr].storage[key]; ok {
+		return
+	}
+	var value uint256.Int
+	t.env.IntraBlockState().GetState(addr, &key, &value)
+	t.pre[addr].storage[key] = common.Hash(value.Bytes32())
+}
+
+func newDiffAccount(balance *big.Int, nonce uint64, code []byte, storage map[common.Hash]common.Hash) *diffAccount {
+	acc := &diffAccount{Nonce: nonce, Code: code}
+	if balance.Sign() != 0 {
+		acc.Balance = (*hexutil.Big)(balance)
+	}
+	if len(storage) > 0 {
+		acc.Storage = storage
+	}
+	return acc
+}
@@ -0,0 +1,345 @@
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/common/math"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/tests"
+	"github.com/stretchr/testify/require"
+)
+
+// callTrace is the result of a callTracer run.
+type callTrace struct {
+	Type    string          `json:"type"`
+	From    common.Address  `json:"from"`
+	To      common.Address  `json:"to"`
+	Input   hexutil.Bytes   `json:"input"`
+	Output  hexutil.Bytes   `json:"output"`
+	Gas     *hexutil.Uint64 `json:"gas,omitempty"`
+	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Value   *hexutil.Big    `json:"value,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Calls   []callTrace     `json:"calls,omitempty"`
+}
+
+type callContext struct {
+	Number     math.HexOrDecimal64   `json:"number"`
+	Difficulty *math.HexOrDecimal256 `json:"difficulty"`
+	Time       math.HexOrDecimal64   `json:"timestamp"`
+	GasLimit   math.HexOrDecimal64   `json:"gasLimit"`
+	Miner      common.Address        `json:"miner"`
+}
+
+// callTracerTest defines a single test to check the call tracer against.
+type callTracerTest struct {
+	Genesis *core.Genesis `json:"genesis"`
+	Context *callContext  `json:"context"`
+	Input   string        `json:"input"`
+	Result  *callTrace    `json:"result"`
+}
+
+// Runs the native callTracer against the same datasets as the JavaScript one,
+// the results have to be identical.
+func TestCallTracer(t *testing.T) {
+	files, err := os.ReadDir(filepath.Join("..", "testdata"))
+	require.NoError(t, err)
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), "call_tracer_") {
+			continue
+		}
+		file := file // capture range variable
+		t.Run(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json"), func(t *testing.T) {
+			t.Parallel()
+
+			blob, err := os.ReadFile(filepath.Join("..", "testdata", file.Name()))
+			require.NoError(t, err)
+			test := new(callTracerTest)
+			require.NoError(t, json.Unmarshal(blob, test))
+
+			txn, err := types.DecodeTransaction(rlp.NewStream(bytes.NewReader(common.FromHex(test.Input)), 0))
+			require.NoError(t, err)
+			signer := types.MakeSigner(test.Genesis.Config, uint64(test.Context.Number))
+			origin, _ := signer.Sender(txn)
+			txContext := vm.TxContext{
+				Origin:   origin,
+				GasPrice: big.NewInt(int64(txn.GetPrice().Uint64())),
+			}
+			context := vm.BlockContext{
+				CanTransfer:     core.CanTransfer,
+				Transfer:        core.Transfer,
+				Coinbase:        test.Context.Miner,
+				BlockNumber:     uint64(test.Context.Number),
+				Time:            uint64(test.Context.Time),
+				Difficulty:      (*big.Int)(test.Context.Difficulty),
+				GasLimit:        uint64(test.Context.GasLimit),
+				ContractHasTEVM: func(common.Hash) (bool, error) { return false, nil },
+			}
+
+			_, tx := memdb.NewTestTx(t)
+			rules := &params.Rules{}
+			statedb, err := tests.MakePreState(rules, tx, test.Genesis.Alloc, uint64(test.Context.Number))
+			require.NoError(t, err)
+
+			tracer, err := tracers.NewTracer("callTracer", new(tracers.Context), nil)
+			require.NoError(t, err)
+			_, isNative := tracer.(*callTracer)
+			require.True(t, isNative, "native tracer expected to take precedence")
+			evm := vm.NewEVM(context, txContext, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tracer})
+
+			msg, err := txn.AsMessage(*signer, nil, rules)
+			require.NoError(t, err)
+			st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(txn.GetGas()))
+			_, err = st.TransitionDb(false, false)
+			require.NoError(t, err)
+
+			res, err := tracer.GetResult()
+			require.NoError(t, err)
+			ret := new(callTrace)
+			require.NoError(t, json.Unmarshal(res, ret))
+			if !jsonEqual(ret, test.Result) {
+				t.Fatalf("trace mismatch: \nhave %+v\nwant %+v", ret, test.Result)
+			}
+		})
+	}
+}
+
+func TestPrestateTracerDiffMode(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	var (
+		from     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		coinbase = common.HexToAddress("0x00000000000000000000000000000000c0ffee00")
+		slot     = common.Hash{}
+	)
+	alloc := core.GenesisAlloc{
+		// PUSH1 0x2a PUSH1 0x00 SSTORE STOP
+		contract: {Code: common.FromHex("0x602a60005500"), Balance: big.NewInt(0)},
+		from:     {Nonce: 1, Balance: big.NewInt(params.Ether)},
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	txn, err := types.SignTx(types.NewTransaction(1, contract, uint256.NewInt(1), 100000, uint256.NewInt(1), nil), *signer, key)
+	require.NoError(t, err)
+
+	context := vm.BlockContext{
+		CanTransfer:     core.CanTransfer,
+		Transfer:        core.Transfer,
+		Coinbase:        coinbase,
+		BlockNumber:     8000000,
+		Time:            5,
+		Difficulty:      big.NewInt(0x30000),
+		GasLimit:        uint64(6000000),
+		ContractHasTEVM: func(common.Hash) (bool, error) { return false, nil },
+	}
+	_, tx := memdb.NewTestTx(t)
+	rules := &params.Rules{}
+	statedb, err := tests.MakePreState(rules, tx, alloc, context.BlockNumber)
+	require.NoError(t, err)
+
+	tracer, err := tracers.NewTracer("prestateTracer", &tracers.Context{GasLimit: txn.GetGas()}, json.RawMessage(`{"diffMode": true}`))
+	require.NoError(t, err)
+	evm := vm.NewEVM(context, vm.TxContext{Origin: from, GasPrice: big.NewInt(1)}, statedb, params.MainnetChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := txn.AsMessage(*signer, nil, rules)
+	require.NoError(t, err)
+	res, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(txn.GetGas()), true /* refunds */, false /* gasBailout */)
+	require.NoError(t, err)
+
+	raw, err := tracer.GetResult()
+	require.NoError(t, err)
+	var ret struct {
+		Pre  map[common.Address]*diffAccount `json:"pre"`
+		Post map[common.Address]*diffAccount `json:"post"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &ret))
+
+	fee := new(big.Int).SetUint64(res.UsedGas)
+	require.Equal(t, uint64(1), ret.Pre[from].Nonce)
+	require.Equal(t, big.NewInt(params.Ether), ret.Pre[from].Balance.ToInt())
+	require.Equal(t, uint64(2), ret.Post[from].Nonce)
+	require.Equal(t, new(big.Int).Sub(big.NewInt(params.Ether-1), fee), ret.Post[from].Balance.ToInt())
+	require.Equal(t, common.Hash{}, ret.Pre[contract].Storage[slot])
+	require.Equal(t, common.BigToHash(big.NewInt(42)), ret.Post[contract].Storage[slot])
+	require.Equal(t, big.NewInt(1), ret.Post[contract].Balance.ToInt())
+	require.Nil(t, ret.Post[contract].Code, "unchanged code is not reported")
+	require.Equal(t, fee, ret.Post[coinbase].Balance.ToInt())
+}
+
+// The gas of the access list is bought as well, the balance of the sender before the transaction includes it
+func TestPrestateTracerAccessList(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	var (
+		from     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	)
+	alloc := core.GenesisAlloc{
+		// PUSH1 0x2a PUSH1 0x00 SSTORE STOP
+		contract: {Code: common.FromHex("0x602a60005500"), Balance: big.NewInt(0)},
+		from:     {Balance: big.NewInt(params.Ether)},
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	txn, err := types.SignTx(&types.AccessListTx{
+		LegacyTx: types.LegacyTx{
+			CommonTx: types.CommonTx{
+				To:    &contract,
+				Value: uint256.NewInt(0),
+				Gas:   100000,
+			},
+			GasPrice: uint256.NewInt(1),
+		},
+		ChainID:    uint256.NewInt(1),
+		AccessList: types.AccessList{{Address: contract, StorageKeys: []common.Hash{{}}}},
+	}, *signer, key)
+	require.NoError(t, err)
+
+	context := vm.BlockContext{
+		CanTransfer:     core.CanTransfer,
+		Transfer:        core.Transfer,
+		BlockNumber:     8000000,
+		Time:            5,
+		Difficulty:      big.NewInt(0x30000),
+		GasLimit:        uint64(6000000),
+		ContractHasTEVM: func(common.Hash) (bool, error) { return false, nil },
+	}
+	_, tx := memdb.NewTestTx(t)
+	rules := &params.Rules{IsBerlin: true}
+	statedb, err := tests.MakePreState(rules, tx, alloc, context.BlockNumber)
+	require.NoError(t, err)
+
+	tracer, err := tracers.NewTracer("prestateTracer", &tracers.Context{GasLimit: txn.GetGas()}, nil)
+	require.NoError(t, err)
+	evm := vm.NewEVM(context, vm.TxContext{Origin: from, GasPrice: big.NewInt(1)}, statedb, params.MainnetChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := txn.AsMessage(*signer, nil, rules)
+	require.NoError(t, err)
+	_, err = core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(txn.GetGas()), true /* refunds */, false /* gasBailout */)
+	require.NoError(t, err)
+
+	raw, err := tracer.GetResult()
+	require.NoError(t, err)
+	var pre map[common.Address]*prestateAccount
+	require.NoError(t, json.Unmarshal(raw, &pre))
+	require.Equal(t, big.NewInt(params.Ether), pre[from].Balance.ToInt())
+	require.Equal(t, uint64(0), pre[from].Nonce)
+}
+
+var (
+	nestedCallKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	nestedCallFrom   = crypto.PubkeyToAddress(nestedCallKey.PublicKey)
+	nestedCallA      = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	nestedCallB      = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+)
+
+// traceNestedCall - runs the tracer on the transaction calling contract A with 0x11223344 and 32 bytes of data,
+// A calls B with 0xaabbccdd and 32 bytes of data, B emits a log with topic 0x01 and data 42
+func traceNestedCall(t *testing.T, name string, cfg json.RawMessage) json.RawMessage {
+	t.Helper()
+	alloc := core.GenesisAlloc{
+		// PUSH4 0xaabbccdd PUSH1 0xe0 SHL PUSH1 0 MSTORE; CALL(0xffff, B, 0, 0, 0x24, 0, 0) STOP
+		nestedCallA: {Code: common.FromHex("0x63aabbccdd60e01b60005260006000602460006000" + "73" + common.Bytes2Hex(nestedCallB[:]) + "61fffff100"), Balance: big.NewInt(0)},
+		// PUSH1 0x2a PUSH1 0 MSTORE; LOG1(0, 0x20, 0x01) STOP
+		nestedCallB:    {Code: common.FromHex("0x602a600052600160206000a100"), Balance: big.NewInt(0)},
+		nestedCallFrom: {Balance: big.NewInt(params.Ether)},
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	input := append(common.FromHex("0x11223344"), make([]byte, 32)...)
+	txn, err := types.SignTx(types.NewTransaction(0, nestedCallA, uint256.NewInt(0), 200000, uint256.NewInt(1), input), *signer, nestedCallKey)
+	require.NoError(t, err)
+
+	context := vm.BlockContext{
+		CanTransfer:     core.CanTransfer,
+		Transfer:        core.Transfer,
+		BlockNumber:     8000000,
+		Time:            5,
+		Difficulty:      big.NewInt(0x30000),
+		GasLimit:        uint64(6000000),
+		ContractHasTEVM: func(common.Hash) (bool, error) { return false, nil },
+	}
+	_, tx := memdb.NewTestTx(t)
+	rules := &params.Rules{}
+	statedb, err := tests.MakePreState(rules, tx, alloc, context.BlockNumber)
+	require.NoError(t, err)
+
+	tracer, err := tracers.NewTracer(name, new(tracers.Context), cfg)
+	require.NoError(t, err)
+	evm := vm.NewEVM(context, vm.TxContext{Origin: nestedCallFrom, GasPrice: big.NewInt(1)}, statedb, params.MainnetChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := txn.AsMessage(*signer, nil, rules)
+	require.NoError(t, err)
+	_, err = core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(txn.GetGas()), true /* refunds */, false /* gasBailout */)
+	require.NoError(t, err)
+
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+	return res
+}
+
+func TestFourByteTracer(t *testing.T) {
+	var ids map[string]int
+	require.NoError(t, json.Unmarshal(traceNestedCall(t, "4byteTracer", nil), &ids))
+	require.Equal(t, map[string]int{"0x11223344-32": 1, "0xaabbccdd-32": 1}, ids)
+}
+
+func TestCallTracerOnlyTopCall(t *testing.T) {
+	var frame callFrame
+	require.NoError(t, json.Unmarshal(traceNestedCall(t, "callTracer", json.RawMessage(`{"onlyTopCall": true}`)), &frame))
+	require.Equal(t, nestedCallFrom, frame.From)
+	require.Equal(t, nestedCallA, *frame.To)
+	require.Empty(t, frame.Calls)
+	require.Empty(t, frame.Logs)
+
+	require.NoError(t, json.Unmarshal(traceNestedCall(t, "callTracer", nil), &frame))
+	require.Len(t, frame.Calls, 1)
+	require.Empty(t, frame.Calls[0].Logs, "logs are collected only withLog")
+}
+
+func TestCallTracerWithLog(t *testing.T) {
+	var frame callFrame
+	require.NoError(t, json.Unmarshal(traceNestedCall(t, "callTracer", json.RawMessage(`{"withLog": true}`)), &frame))
+	require.Empty(t, frame.Logs)
+	require.Len(t, frame.Calls, 1)
+	call := frame.Calls[0]
+	require.Equal(t, nestedCallB, *call.To)
+	require.Equal(t, hexutil.Bytes(append(common.FromHex("0xaabbccdd"), make([]byte, 32)...)), call.Input)
+	require.Equal(t, []callLog{{
+		Address: nestedCallB,
+		Topics:  []common.Hash{common.BigToHash(big.NewInt(1))},
+		Data:    common.BigToHash(big.NewInt(42)).Bytes(),
+	}}, call.Logs)
+}
+
+// jsonEqual is similar to reflect.DeepEqual, but does a 'bounce' via json prior to
+// comparison
+func jsonEqual(x, y interface{}) bool {
+	xTrace := new(callTrace)
+	yTrace := new(callTrace)
+	if xj, err := json.Marshal(x); err == nil {
+		if err = json.Unmarshal(xj, xTrace); err != nil {
+			panic(err)
+		}
+	} else {
+		return false
+	}
+	if yj, err := json.Marshal(y); err == nil {
+		if err = json.Unmarshal(yj, yTrace); err != nil {
+			panic(err)
+		}
+	} else {
+		return false
+	}
+	return reflect.DeepEqual(xTrace, yTrace)
+}
@@ -18,12 +18,50 @@
 package tracers
 
 import (
+	"encoding/json"
 	"strings"
 	"unicode"
 
+	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/eth/tracers/internal/tracers"
 )
 
+// ResultTracer is a vm.Tracer which accumulates its result in memory and can
+// be interrupted. Both the JavaScript tracer and the native Go tracers
+// implement it.
+type ResultTracer interface {
+	vm.Tracer
+	GetResult() (json.RawMessage, error)
+	// Stop terminates execution of the tracer at the first opportune moment.
+	Stop(err error)
+}
+
+// NativeCtor constructs a native tracer, cfg is the raw tracerConfig supplied
+// by the user (may be empty).
+type NativeCtor func(ctx *Context, cfg json.RawMessage) (ResultTracer, error)
+
+// native contains all the registered native Go tracers by name.
+var native = make(map[string]NativeCtor)
+
+// RegisterNative makes a native tracer available under the given name. Native
+// tracers take precedence over the JavaScript ones with the same name.
+func RegisterNative(name string, ctor NativeCtor) {
+	native[name] = ctor
+}
+
+// NewTracer resolves the tracer by name against the native tracers first and
+// falls back to the JavaScript tracers (built-in by name or custom code).
+func NewTracer(code string, ctx *Context, cfg json.RawMessage) (ResultTracer, error) {
+	if ctor, ok := native[code]; ok {
+		return ctor(ctx, cfg)
+	}
+	tracer, err := New(code, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tracer, nil
+}
+
 // all contains all the built in JavaScript tracers by name.
 var all = make(map[string]string)
 
@@ -117,6 +117,49 @@ func TruncateRange(db kv.RwTx, bucket string, key []byte, to uint32) error {
 	})
 }
 
+// Merge - adds the bitmap to the existing one in db, unlike the appending of the stages the numbers may be
+// lower than the end of the existing bitmap. Rewrites the shards starting from the one which has m.Minimum()
+func Merge(db kv.RwTx, bucket string, key []byte, m *roaring.Bitmap) error {
+	if m.IsEmpty() {
+		return nil
+	}
+	from := m.Minimum()
+	chunkKey := make([]byte, len(key)+4)
+	copy(chunkKey, key)
+	binary.BigEndian.PutUint32(chunkKey[len(chunkKey)-4:], from)
+	bm, err := Get(db, bucket, key, from, math.MaxUint32)
+	if err != nil {
+		return err
+	}
+	bm.Or(m)
+
+	c, err := db.Cursor(bucket)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err := ethdb.Walk(c, chunkKey, 0, func(k, v []byte) (bool, error) {
+		if !bytes.HasPrefix(k, key) {
+			return false, nil
+		}
+		if err := db.Delete(bucket, k); err != nil {
+			return false, err
+		}
+		return true, nil
+	}); err != nil {
+		return err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	return WalkChunkWithKeys(key, bm, ChunkLimit, func(chunkKey []byte, chunk *roaring.Bitmap) error {
+		buf.Reset()
+		if _, err := chunk.WriteTo(buf); err != nil {
+			return err
+		}
+		return db.Put(bucket, chunkKey, libcommon.Copy(buf.Bytes()))
+	})
+}
+
 // Get - reading as much chunks as needed to satisfy [from, to] condition
 // join all chunks to 1 bitmap by Or operator
 func Get(db kv.Tx, bucket string, key []byte, from, to uint32) (*roaring.Bitmap, error) {
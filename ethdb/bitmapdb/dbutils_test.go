@@ -1,9 +1,15 @@
 package bitmapdb_test
 
 import (
+	"bytes"
+	"encoding/binary"
 	"testing"
 
 	"github.com/RoaringBitmap/roaring"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/math"
 	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
 	"github.com/stretchr/testify/require"
 )
@@ -53,3 +59,44 @@ func TestCutLeft(t *testing.T) {
 	require.True(t, lft == nil)
 	require.True(t, bm.GetCardinality() == 0)
 }
+
+func TestMerge(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	key := []byte{1}
+
+	bm := roaring.New()
+	for j := 0; j < 10_000; j += 20 {
+		bm.AddRange(uint64(j), uint64(j+10))
+	}
+	expect := bm.Clone()
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, bitmapdb.WalkChunkWithKeys(key, bm, 256, func(chunkKey []byte, chunk *roaring.Bitmap) error {
+		buf.Reset()
+		if _, err := chunk.WriteTo(buf); err != nil {
+			return err
+		}
+		return tx.Put(kv.LogAddressIndex, chunkKey, common.CopyBytes(buf.Bytes()))
+	}))
+
+	// The numbers go into the middle of the existing bitmap, and after its end
+	m := roaring.New()
+	m.AddRange(5_010, 5_020)
+	m.Add(20_000)
+	require.NoError(t, bitmapdb.Merge(tx, kv.LogAddressIndex, key, m))
+	expect.Or(m)
+
+	got, err := bitmapdb.Get(tx, kv.LogAddressIndex, key, 0, math.MaxUint32)
+	require.NoError(t, err)
+	require.True(t, expect.Equals(got))
+	got, err = bitmapdb.Get(tx, kv.LogAddressIndex, key, 5_015, 5_015)
+	require.NoError(t, err)
+	require.True(t, got.Contains(5_015))
+
+	// Only the last chunk has no upper bound
+	var last []byte
+	require.NoError(t, tx.ForPrefix(kv.LogAddressIndex, key, func(k, _ []byte) error {
+		last = common.CopyBytes(k)
+		return nil
+	}))
+	require.Equal(t, ^uint32(0), binary.BigEndian.Uint32(last[len(key):]))
+}
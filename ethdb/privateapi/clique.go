@@ -0,0 +1,82 @@
+package privateapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon/consensus/clique"
+	"google.golang.org/grpc"
+)
+
+// CliqueServer - signer voting of the clique engine, used by rpcdaemon to serve clique_propose, clique_discard
+// and clique_proposals. It is registered on the private API server next to ETHBACKEND, the read-only part
+// of the clique_ namespace doesn't need it - rpcdaemon reads the clique db.
+type CliqueServer struct {
+	remote.UnimplementedCLIQUEServer
+	clique *clique.Clique
+}
+
+// NewCliqueServer - engine is nil if the node doesn't run clique, then all methods return an error
+func NewCliqueServer(engine *clique.Clique) *CliqueServer {
+	return &CliqueServer{clique: engine}
+}
+
+var errNotClique = errors.New("not supported, consensus engine is not clique")
+
+func (s *CliqueServer) Propose(_ context.Context, req *remote.CliqueProposeRequest) (*remote.CliqueProposalsReply, error) {
+	if s.clique == nil {
+		return nil, errNotClique
+	}
+	s.clique.Propose(gointerfaces.ConvertH160toAddress(req.Address), req.Authorize)
+	return s.proposals(), nil
+}
+
+func (s *CliqueServer) Discard(_ context.Context, req *remote.CliqueDiscardRequest) (*remote.CliqueProposalsReply, error) {
+	if s.clique == nil {
+		return nil, errNotClique
+	}
+	s.clique.Discard(gointerfaces.ConvertH160toAddress(req.Address))
+	return s.proposals(), nil
+}
+
+func (s *CliqueServer) Proposals(_ context.Context, _ *remote.CliqueProposalsRequest) (*remote.CliqueProposalsReply, error) {
+	if s.clique == nil {
+		return nil, errNotClique
+	}
+	return s.proposals(), nil
+}
+
+// proposals - the proposals the node votes on, every method of the service replies with them
+func (s *CliqueServer) proposals() *remote.CliqueProposalsReply {
+	reply := &remote.CliqueProposalsReply{}
+	for address, authorize := range s.clique.Proposals() {
+		reply.Proposals = append(reply.Proposals, &remote.CliqueProposal{
+			Address:   gointerfaces.ConvertAddressToH160(address),
+			Authorize: authorize,
+		})
+	}
+	return reply
+}
+
+type cliqueClientDirect struct {
+	server remote.CLIQUEServer
+}
+
+// NewCliqueClientDirect - in-process client of the server, for rpcdaemon embedded into Erigon
+func NewCliqueClientDirect(server remote.CLIQUEServer) remote.CLIQUEClient {
+	return &cliqueClientDirect{server: server}
+}
+
+func (c *cliqueClientDirect) Propose(ctx context.Context, in *remote.CliqueProposeRequest, _ ...grpc.CallOption) (*remote.CliqueProposalsReply, error) {
+	return c.server.Propose(ctx, in)
+}
+
+func (c *cliqueClientDirect) Discard(ctx context.Context, in *remote.CliqueDiscardRequest, _ ...grpc.CallOption) (*remote.CliqueProposalsReply, error) {
+	return c.server.Discard(ctx, in)
+}
+
+func (c *cliqueClientDirect) Proposals(ctx context.Context, in *remote.CliqueProposalsRequest, _ ...grpc.CallOption) (*remote.CliqueProposalsReply, error) {
+	return c.server.Proposals(ctx, in)
+}
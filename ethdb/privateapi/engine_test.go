@@ -251,3 +251,30 @@ func TestNoTTD(t *testing.T) {
 
 	require.Equal(err.Error(), "not a proof-of-stake chain")
 }
+
+func TestNewPayloadV2WithdrawalsMismatch(t *testing.T) {
+	db := memdb.New()
+	ctx := context.Background()
+	require := require.New(t)
+
+	makeTestDb(ctx, db)
+
+	hd := headerdownload.NewHeaderDownload(0, 0, nil, nil)
+
+	events := NewEvents()
+	backend := NewEthBackendServer(ctx, nil, db, events, nil, &params.ChainConfig{TerminalTotalDifficulty: common.Big1, ShanghaiTime: common.Big0}, nil, hd, false)
+
+	// Shanghai payloads must carry withdrawals
+	_, err := backend.EngineNewPayloadV2(ctx, mockPayload3, nil)
+	require.Equal(&InvalidParamsErr, err)
+
+	_, err = backend.EngineForkChoiceUpdatedV2(ctx, &remote.EngineForkChoiceUpdatedRequest{
+		ForkchoiceState: &remote.EngineForkChoiceState{
+			HeadBlockHash:      gointerfaces.ConvertHashToH256(startingHeadHash),
+			SafeBlockHash:      gointerfaces.ConvertHashToH256(startingHeadHash),
+			FinalizedBlockHash: gointerfaces.ConvertHashToH256(startingHeadHash),
+		},
+		PayloadAttributes: &remote.EnginePayloadAttributes{Timestamp: 5},
+	}, nil)
+	require.Equal(&InvalidParamsErr, err)
+}
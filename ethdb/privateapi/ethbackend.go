@@ -43,6 +43,8 @@ const MaxBuilders = 128
 var UnknownPayloadErr = rpc.CustomError{Code: -38001, Message: "Unknown payload"}
 var InvalidForkchoiceStateErr = rpc.CustomError{Code: -38002, Message: "Invalid forkchoice state"}
 var InvalidPayloadAttributesErr = rpc.CustomError{Code: -38003, Message: "Invalid payload attributes"}
+var TooLargeRequestErr = rpc.CustomError{Code: -38004, Message: "Too large request"}
+var InvalidParamsErr = rpc.CustomError{Code: -32602, Message: "Invalid params"}
 
 type EthBackendServer struct {
 	remote.UnimplementedETHBACKENDServer // must be embedded to have forward compatible implementations.
@@ -64,6 +66,16 @@ type EthBackendServer struct {
 	hd          *headerdownload.HeaderDownload
 }
 
+// EngineV2Server - Engine API methods which are not part of the ETHBACKEND gRPC protocol,
+// because it has no notion of withdrawals and block value yet. They are served in-process only.
+type EngineV2Server interface {
+	EngineNewPayloadV2(ctx context.Context, req *types2.ExecutionPayload, withdrawals []*types.Withdrawal) (*remote.EnginePayloadStatus, error)
+	EngineForkChoiceUpdatedV2(ctx context.Context, req *remote.EngineForkChoiceUpdatedRequest, withdrawals []*types.Withdrawal) (*remote.EngineForkChoiceUpdatedReply, error)
+	EngineGetPayloadV2(ctx context.Context, req *remote.EngineGetPayloadRequest) (*engineapi.ExecutionPayloadV2, error)
+}
+
+var _ EngineV2Server = (*EthBackendServer)(nil)
+
 type EthBackend interface {
 	Etherbase() (common.Address, error)
 	NetVersion() (uint64, error)
@@ -145,6 +157,8 @@ func (s *EthBackendServer) Subscribe(r *remote.SubscribeRequest, subscribeServer
 	defer clean()
 	newSnCh, newSnClean := s.events.AddNewSnapshotSubscription()
 	defer newSnClean()
+	syncProgressCh, syncProgressClean := s.events.AddSyncProgressSubscription()
+	defer syncProgressClean()
 	log.Info("new subscription to newHeaders established")
 	defer func() {
 		if err != nil {
@@ -175,6 +189,11 @@ func (s *EthBackendServer) Subscribe(r *remote.SubscribeRequest, subscribeServer
 			if err = subscribeServer.Send(&remote.SubscribeReply{Type: remote.Event_NEW_SNAPSHOT}); err != nil {
 				return err
 			}
+		case progressJson := <-syncProgressCh:
+			// SYNC_PROGRESS replies carry a JSON encoded stages.SyncProgress
+			if err = subscribeServer.Send(&remote.SubscribeReply{Type: remote.Event_SYNC_PROGRESS, Data: progressJson}); err != nil {
+				return err
+			}
 		}
 	}
 }
@@ -258,6 +277,20 @@ func (s *EthBackendServer) stageLoopIsBusy() bool {
 
 // EngineNewPayloadV1 validates and possibly executes payload
 func (s *EthBackendServer) EngineNewPayloadV1(ctx context.Context, req *types2.ExecutionPayload) (*remote.EnginePayloadStatus, error) {
+	return s.engineNewPayload(req, nil)
+}
+
+// EngineNewPayloadV2 is like EngineNewPayloadV1, but also accepts Shanghai payloads carrying withdrawals.
+// Withdrawals must be nil for pre-Shanghai payloads and non-nil afterwards.
+// The ETHBACKEND gRPC protocol has no notion of withdrawals, so this method is only available in-process.
+func (s *EthBackendServer) EngineNewPayloadV2(ctx context.Context, req *types2.ExecutionPayload, withdrawals []*types.Withdrawal) (*remote.EnginePayloadStatus, error) {
+	if s.config.IsShanghai(req.Timestamp) != (withdrawals != nil) {
+		return nil, &InvalidParamsErr
+	}
+	return s.engineNewPayload(req, withdrawals)
+}
+
+func (s *EthBackendServer) engineNewPayload(req *types2.ExecutionPayload, withdrawals []*types.Withdrawal) (*remote.EnginePayloadStatus, error) {
 	var baseFee *big.Int
 	eip1559 := false
 
@@ -284,6 +317,10 @@ func (s *EthBackendServer) EngineNewPayloadV1(ctx context.Context, req *types2.E
 		ReceiptHash: gointerfaces.ConvertH256ToHash(req.ReceiptRoot),
 		TxHash:      types.DeriveSha(types.BinaryTransactions(req.Transactions)),
 	}
+	if withdrawals != nil {
+		withdrawalsHash := types.DeriveSha(types.Withdrawals(withdrawals))
+		header.WithdrawalsHash = &withdrawalsHash
+	}
 
 	blockHash := gointerfaces.ConvertH256ToHash(req.BlockHash)
 	if header.Hash() != blockHash {
@@ -311,7 +348,7 @@ func (s *EthBackendServer) EngineNewPayloadV1(ctx context.Context, req *types2.E
 			ValidationError: err.Error(),
 		}, nil
 	}
-	block := types.NewBlockFromStorage(blockHash, &header, transactions, nil)
+	block := types.NewBlockFromStorage(blockHash, &header, transactions, nil).WithWithdrawals(withdrawals)
 
 	possibleStatus, err := s.getPayloadStatusFromHashIfPossible(blockHash, req.BlockNumber, header.ParentHash, true)
 	if err != nil {
@@ -466,6 +503,21 @@ func (s *EthBackendServer) getPayloadStatusFromHashIfPossible(blockHash common.H
 
 // EngineGetPayloadV1 retrieves previously assembled payload (Validators only)
 func (s *EthBackendServer) EngineGetPayloadV1(ctx context.Context, req *remote.EngineGetPayloadRequest) (*types2.ExecutionPayload, error) {
+	payload, err := s.engineGetPayload(req.PayloadId)
+	if err != nil {
+		return nil, err
+	}
+	return payload.ExecutionPayload, nil
+}
+
+// EngineGetPayloadV2 retrieves previously assembled payload together with its withdrawals
+// and the value of the block, i.e. the fees it pays to the fee recipient.
+// The ETHBACKEND gRPC protocol has no notion of withdrawals, so this method is only available in-process.
+func (s *EthBackendServer) EngineGetPayloadV2(ctx context.Context, req *remote.EngineGetPayloadRequest) (*engineapi.ExecutionPayloadV2, error) {
+	return s.engineGetPayload(req.PayloadId)
+}
+
+func (s *EthBackendServer) engineGetPayload(payloadId uint64) (*engineapi.ExecutionPayloadV2, error) {
 	if !s.proposing {
 		return nil, fmt.Errorf("execution layer not running as a proposer. enable proposer by taking out the --proposer.disable flag on startup")
 	}
@@ -479,13 +531,14 @@ func (s *EthBackendServer) EngineGetPayloadV1(ctx context.Context, req *remote.E
 	defer s.lock.Unlock()
 	log.Debug("[GetPayload] lock acquired")
 
-	builder, ok := s.builders[req.PayloadId]
+	builder, ok := s.builders[payloadId]
 	if !ok {
-		log.Warn("Payload not stored", "payloadId", req.PayloadId)
+		log.Warn("Payload not stored", "payloadId", payloadId)
 		return nil, &UnknownPayloadErr
 	}
 
-	block := builder.Stop()
+	blockWithReceipts := builder.Stop()
+	block := blockWithReceipts.Block
 
 	var baseFeeReply *types2.H256
 	if block.Header().BaseFee != nil {
@@ -500,7 +553,18 @@ func (s *EthBackendServer) EngineGetPayloadV1(ctx context.Context, req *remote.E
 	}
 	log.Info("Block request successful", "hash", block.Header().Hash(), "transactions count", len(encodedTransactions), "number", block.NumberU64())
 
-	return &types2.ExecutionPayload{
+	// The block value is the sum of the priority fees paid by the transactions of the block
+	blockValue := new(uint256.Int)
+	var baseFee *uint256.Int
+	if block.BaseFee() != nil {
+		baseFee, _ = uint256.FromBig(block.BaseFee())
+	}
+	for i, receipt := range blockWithReceipts.Receipts {
+		tip := block.Transactions()[i].GetEffectiveGasTip(baseFee)
+		blockValue.Add(blockValue, new(uint256.Int).Mul(tip, uint256.NewInt(receipt.GasUsed)))
+	}
+
+	payload := &types2.ExecutionPayload{
 		ParentHash:    gointerfaces.ConvertHashToH256(block.Header().ParentHash),
 		Coinbase:      gointerfaces.ConvertAddressToH160(block.Header().Coinbase),
 		Timestamp:     block.Header().Time,
@@ -515,11 +579,30 @@ func (s *EthBackendServer) EngineGetPayloadV1(ctx context.Context, req *remote.E
 		BaseFeePerGas: baseFeeReply,
 		BlockHash:     gointerfaces.ConvertHashToH256(block.Header().Hash()),
 		Transactions:  encodedTransactions,
+	}
+	return &engineapi.ExecutionPayloadV2{
+		ExecutionPayload: payload,
+		Withdrawals:      block.Withdrawals(),
+		BlockValue:       blockValue,
 	}, nil
 }
 
 // EngineForkChoiceUpdatedV1 either states new block head or request the assembling of a new block
 func (s *EthBackendServer) EngineForkChoiceUpdatedV1(ctx context.Context, req *remote.EngineForkChoiceUpdatedRequest) (*remote.EngineForkChoiceUpdatedReply, error) {
+	return s.engineForkChoiceUpdated(ctx, req, nil)
+}
+
+// EngineForkChoiceUpdatedV2 is like EngineForkChoiceUpdatedV1, but the payload to assemble may
+// carry withdrawals. Withdrawals must be nil for pre-Shanghai payloads and non-nil afterwards.
+// The ETHBACKEND gRPC protocol has no notion of withdrawals, so this method is only available in-process.
+func (s *EthBackendServer) EngineForkChoiceUpdatedV2(ctx context.Context, req *remote.EngineForkChoiceUpdatedRequest, withdrawals []*types.Withdrawal) (*remote.EngineForkChoiceUpdatedReply, error) {
+	if req.PayloadAttributes != nil && s.config.IsShanghai(req.PayloadAttributes.Timestamp) != (withdrawals != nil) {
+		return nil, &InvalidParamsErr
+	}
+	return s.engineForkChoiceUpdated(ctx, req, withdrawals)
+}
+
+func (s *EthBackendServer) engineForkChoiceUpdated(ctx context.Context, req *remote.EngineForkChoiceUpdatedRequest, withdrawals []*types.Withdrawal) (*remote.EngineForkChoiceUpdatedReply, error) {
 	forkChoice := engineapi.ForkChoiceMessage{
 		HeadBlockHash:      gointerfaces.ConvertH256ToHash(req.ForkchoiceState.HeadBlockHash),
 		SafeBlockHash:      gointerfaces.ConvertH256ToHash(req.ForkchoiceState.SafeBlockHash),
@@ -600,12 +683,18 @@ func (s *EthBackendServer) EngineForkChoiceUpdatedV1(ctx context.Context, req *r
 	emptyHeader := core.MakeEmptyHeader(headHeader, s.config, req.PayloadAttributes.Timestamp, nil)
 	emptyHeader.Coinbase = gointerfaces.ConvertH160toAddress(req.PayloadAttributes.SuggestedFeeRecipient)
 	emptyHeader.MixDigest = gointerfaces.ConvertH256ToHash(req.PayloadAttributes.PrevRandao)
+	if withdrawals != nil {
+		// empty block has no withdrawals, as its state root is the parent's one
+		emptyHeader.WithdrawalsHash = new(common.Hash)
+		*emptyHeader.WithdrawalsHash = types.EmptyRootHash
+	}
 
 	param := core.BlockBuilderParameters{
 		ParentHash:            forkChoice.HeadBlockHash,
 		Timestamp:             req.PayloadAttributes.Timestamp,
 		PrevRandao:            emptyHeader.MixDigest,
 		SuggestedFeeRecipient: emptyHeader.Coinbase,
+		Withdrawals:           withdrawals,
 	}
 
 	s.builders[s.payloadId] = builder.NewBlockBuilder(s.builderFunc, &param, emptyHeader)
@@ -1,11 +1,14 @@
 package privateapi
 
 import (
+	"encoding/json"
 	"sync"
 
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/log/v3"
 )
 
 type RpcEventType uint64
@@ -26,6 +29,7 @@ type Events struct {
 	pendingBlockSubscriptions map[int]PendingBlockSubscription
 	pendingTxsSubscriptions   map[int]PendingTxsSubscription
 	logsSubscriptions         map[int]chan []*remote.SubscribeLogsReply
+	syncProgressSubscriptions map[int]chan []byte
 	hasLogSubscriptions       bool
 	lock                      sync.RWMutex
 }
@@ -38,6 +42,7 @@ func NewEvents() *Events {
 		pendingTxsSubscriptions:   map[int]PendingTxsSubscription{},
 		logsSubscriptions:         map[int]chan []*remote.SubscribeLogsReply{},
 		newSnapshotSubscription:   map[int]chan struct{}{},
+		syncProgressSubscriptions: map[int]chan []byte{},
 	}
 }
 
@@ -67,6 +72,20 @@ func (e *Events) AddNewSnapshotSubscription() (chan struct{}, func()) {
 	}
 }
 
+// AddSyncProgressSubscription - the channel receives JSON encoded stages.SyncProgress
+func (e *Events) AddSyncProgressSubscription() (chan []byte, func()) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	ch := make(chan []byte, 8)
+	e.id++
+	id := e.id
+	e.syncProgressSubscriptions[id] = ch
+	return ch, func() {
+		delete(e.syncProgressSubscriptions, id)
+		close(ch)
+	}
+}
+
 func (e *Events) AddLogsSubscription() (chan []*remote.SubscribeLogsReply, func()) {
 	e.lock.Lock()
 	defer e.lock.Unlock()
@@ -137,3 +156,19 @@ func (e *Events) OnLogs(logs []*remote.SubscribeLogsReply) {
 		common.PrioritizedSend(ch, logs)
 	}
 }
+
+func (e *Events) OnSyncProgress(progress *stages.SyncProgress) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if len(e.syncProgressSubscriptions) == 0 {
+		return
+	}
+	progressJson, err := json.Marshal(progress)
+	if err != nil {
+		log.Warn("failed to encode sync progress", "err", err)
+		return
+	}
+	for _, ch := range e.syncProgressSubscriptions {
+		common.PrioritizedSend(ch, progressJson)
+	}
+}
@@ -2,8 +2,14 @@ package ethapi
 
 import (
 	"bytes"
+	"encoding/binary"
+	"sort"
 
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/changeset"
+	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/core/types/accounts"
 	"github.com/ledgerwatch/erigon/turbo/trie"
@@ -25,162 +31,216 @@ type StorageResult struct {
 	Proof []string     `json:"proof"`
 }
 
-/*TODO: to support proofs
-func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNr rpc.BlockNumber) (*AccountResult, error) {
-	block := uint64(blockNr.Int64()) + 1
-	db := s.b.ChainDb()
-	ts := dbutils.EncodeBlockNumber(block)
-	accountMap := make(map[string]*accounts.Account)
-	if err := changeset.Walk(db, dbutils.AccountChangeSetBucket, ts, 0, func(blockN uint64, a, v []byte) (bool, error) {
-		a, v = common.CopyBytes(a), common.CopyBytes(v)
-		var kHash, err = common.HashData(a)
+type accountOverride struct {
+	nibbles  []byte
+	addrHash common.Hash
+	account  *accounts.Account // nil - account did not exist
+}
+
+type storageOverride struct {
+	nibbles []byte // nibbles of the hashed location
+	value   []byte // empty - slot was empty
+}
+
+// StateOverlay - difference between hashed state in db (at the progress of IntermediateHashes stage)
+// and hashed state at some earlier block. Built from AccountChangeSet and StorageChangeSet.
+type StateOverlay struct {
+	accounts []*accountOverride            // sorted by nibbles
+	storage  map[string][]*storageOverride // key: addrHash+incarnation, values sorted by nibbles
+}
+
+// NewStateOverlay - collects values of all keys changed in blocks (blockNum, latestBlock] as they were at the end of blockNum.
+// Keys of all changed accounts and storage slots are added to `rl`, so the loader doesn't use intermediate hashes for them.
+func NewStateOverlay(tx kv.Tx, blockNum, latestBlock uint64, rl *trie.RetainList) (*StateOverlay, error) {
+	o := &StateOverlay{storage: map[string][]*storageOverride{}}
+
+	byAddress := map[common.Address]*accountOverride{}
+	if err := changeset.ForRange(tx, kv.AccountChangeSet, blockNum+1, latestBlock+1, func(_ uint64, k, v []byte) error {
+		addr := common.BytesToAddress(k)
+		if _, ok := byAddress[addr]; ok { // only oldest change matters
+			return nil
+		}
+		addrHash, err := common.HashData(addr[:])
 		if err != nil {
-			return false, err
+			return err
 		}
-		k := kHash[:]
-		if _, ok := accountMap[string(k)]; !ok {
-			if len(v) > 0 {
-				var a accounts.Account
-				if innerErr := a.DecodeForStorage(v); innerErr != nil {
-					return false, innerErr
+		mod := &accountOverride{addrHash: addrHash}
+		hexutil.DecompressNibbles(addrHash[:], &mod.nibbles)
+		if len(v) > 0 {
+			var a accounts.Account
+			if err := a.DecodeForStorage(v); err != nil {
+				return err
+			}
+			//restore codehash
+			if a.Incarnation > 0 && a.IsEmptyCodeHash() {
+				codeHash, err := tx.GetOne(kv.PlainContractCode, dbutils.PlainGenerateStoragePrefix(addr[:], a.Incarnation))
+				if err != nil {
+					return err
+				}
+				if len(codeHash) > 0 {
+					a.CodeHash = common.BytesToHash(codeHash)
 				}
-				accountMap[string(k)] = &a
-			} else {
-				accountMap[string(k)] = nil
 			}
+			mod.account = &a
 		}
-		return true, nil
+		byAddress[addr] = mod
+		o.accounts = append(o.accounts, mod)
+		rl.AddKey(addrHash[:])
+		return nil
 	}); err != nil {
 		return nil, err
 	}
 
-	storageMap := make(map[string][]byte)
-	if err := changeset.Walk(db, dbutils.AccountChangeSetBucket, ts, 0, func(blockN uint64, a, v []byte) (bool, error) {
-		a, v = common.CopyBytes(a), common.CopyBytes(v)
-		var kHash, err = common.HashData(a)
+	// incarnation of the account at blockNum, 0 - if account didn't exist or has no storage
+	incarnations := map[common.Address]uint64{}
+	incarnationOf := func(addr common.Address) (uint64, error) {
+		if inc, ok := incarnations[addr]; ok {
+			return inc, nil
+		}
+		var inc uint64
+		if mod, ok := byAddress[addr]; ok {
+			if mod.account != nil {
+				inc = mod.account.Incarnation
+			}
+		} else {
+			enc, err := tx.GetOne(kv.PlainState, addr[:])
+			if err != nil {
+				return 0, err
+			}
+			if len(enc) > 0 {
+				var a accounts.Account
+				if err := a.DecodeForStorage(enc); err != nil {
+					return 0, err
+				}
+				inc = a.Incarnation
+			}
+		}
+		incarnations[addr] = inc
+		return inc, nil
+	}
+
+	seen := map[string]struct{}{}
+	if err := changeset.ForRange(tx, kv.StorageChangeSet, blockNum+1, latestBlock+1, func(_ uint64, k, v []byte) error {
+		if _, ok := seen[string(k)]; ok { // only oldest change matters
+			return nil
+		}
+		seen[string(k)] = struct{}{}
+		addr := common.BytesToAddress(k[:common.AddressLength])
+		incarnation := binary.BigEndian.Uint64(k[common.AddressLength:])
+		inc, err := incarnationOf(addr)
+		if err != nil {
+			return err
+		}
+		if inc == 0 || inc != incarnation { // slot of incarnation which was not alive at blockNum
+			return nil
+		}
+		addrHash, err := common.HashData(addr[:])
 		if err != nil {
-			return true, err
+			return err
 		}
-		k := kHash[:]
-		if _, ok := storageMap[string(k)]; !ok {
-			storageMap[string(k)] = v
+		locHash, err := common.HashData(k[common.AddressLength+common.IncarnationLength:])
+		if err != nil {
+			return err
 		}
-		return true, nil
+		mod := &storageOverride{value: common.CopyBytes(v)}
+		hexutil.DecompressNibbles(locHash[:], &mod.nibbles)
+		accWithInc := dbutils.GenerateStoragePrefix(addrHash[:], inc)
+		o.storage[string(accWithInc)] = append(o.storage[string(accWithInc)], mod)
+		rl.AddKey(dbutils.GenerateCompositeStorageKey(addrHash, inc, locHash))
+		return nil
 	}); err != nil {
 		return nil, err
 	}
-	var unfurlList = make([]string, len(accountMap)+len(storageMap))
-	unfurl := trie.NewRetainList(0)
-	i := 0
-	for ks, acc := range accountMap {
-		unfurlList[i] = ks
-		i++
-		unfurl.AddKey([]byte(ks))
-		if acc != nil {
-			// Fill the code hashes
-			if acc.Incarnation > 0 && acc.IsEmptyCodeHash() {
-				if codeHash, err1 := db.Get(dbutils.ContractCodeBucket, dbutils.GenerateStoragePrefix([]byte(ks), acc.Incarnation)); err1 == nil {
-					copy(acc.CodeHash[:], codeHash)
-				} else {
-					return nil, err1
-				}
-			}
-		}
-	}
-	for ks := range storageMap {
-		unfurlList[i] = ks
-		i++
-		var sk [64]byte
-		copy(sk[:], []byte(ks)[:common.HashLength])
-		copy(sk[common.HashLength:], []byte(ks)[common.HashLength+common.IncarnationLength:])
-		unfurl.AddKey(sk[:])
+
+	sort.Slice(o.accounts, func(i, j int) bool { return bytes.Compare(o.accounts[i].nibbles, o.accounts[j].nibbles) < 0 })
+	for _, mods := range o.storage {
+		sort.Slice(mods, func(i, j int) bool { return bytes.Compare(mods[i].nibbles, mods[j].nibbles) < 0 })
 	}
-	rl := trie.NewRetainList(0)
-	addrHash, err := common.HashData(address[:])
-	if err != nil {
-		return nil, err
+	return o, nil
+}
+
+// Override - replaces the values of the overlay by the given ones, so the trie of a state which was never written to db
+// (e.g. of simulated blocks) can be calculated. nil accounts are deleted, the storage is the one of the incarnation in accounts.
+func (o *StateOverlay) Override(accs map[common.Address]*accounts.Account, storage map[common.Address]map[common.Hash]uint256.Int, rl *trie.RetainList) error {
+	byNibbles := make(map[string]*accountOverride, len(o.accounts))
+	for _, mod := range o.accounts {
+		byNibbles[string(mod.nibbles)] = mod
 	}
-	rl.AddKey(addrHash[:])
-	unfurl.AddKey(addrHash[:])
-	for _, key := range storageKeys {
-		keyAsHash := common.HexToHash(key)
-		if keyHash, err1 := common.HashData(keyAsHash[:]); err1 == nil {
-			trieKey := append(addrHash[:], keyHash[:]...)
-			rl.AddKey(trieKey)
-			unfurl.AddKey(trieKey)
+	for addr, acc := range accs {
+		addrHash, err := common.HashData(addr[:])
+		if err != nil {
+			return err
+		}
+		mod := &accountOverride{addrHash: addrHash}
+		hexutil.DecompressNibbles(addrHash[:], &mod.nibbles)
+		if acc != nil {
+			a := *acc
+			mod.account = &a
+		}
+		if old, ok := byNibbles[string(mod.nibbles)]; ok {
+			*old = *mod
 		} else {
-			return nil, err1
+			o.accounts = append(o.accounts, mod)
 		}
+		rl.AddKey(addrHash[:])
+		if acc == nil || acc.Incarnation == 0 {
+			continue
+		}
+
+		accWithInc := dbutils.GenerateStoragePrefix(addrHash[:], acc.Incarnation)
+		mods := o.storage[string(accWithInc)]
+		for key, value := range storage[addr] {
+			key, value := key, value
+			locHash, err := common.HashData(key[:])
+			if err != nil {
+				return err
+			}
+			sMod := &storageOverride{value: value.Bytes()}
+			hexutil.DecompressNibbles(locHash[:], &sMod.nibbles)
+			replaced := false
+			for i := range mods {
+				if bytes.Equal(mods[i].nibbles, sMod.nibbles) {
+					mods[i], replaced = sMod, true
+					break
+				}
+			}
+			if !replaced {
+				mods = append(mods, sMod)
+			}
+			rl.AddKey(dbutils.GenerateCompositeStorageKey(addrHash, acc.Incarnation, locHash))
+		}
+		o.storage[string(accWithInc)] = mods
 	}
-	sort.Strings(unfurlList)
-	loader := trie.NewFlatDBTrieLoader("checkRoots")
-	if err = loader.Reset(unfurl, nil, nil, false); err != nil {
-		panic(err)
-	}
-	r := &Receiver{defaultReceiver: trie.NewDefaultReceiver(), unfurlList: unfurlList, accountMap: accountMap, storageMap: storageMap}
-	r.defaultReceiver.Reset(rl, nil, false)
-	loader.SetStreamReceiver(r)
-	_, err = loader.CalcTrieRoot(db.(ethdb.HasTx).Tx().(ethdb.RwTx), []byte{}, nil)
-	if err != nil {
-		panic(err)
-	}
-	hash, err := rawdb.ReadCanonicalHash(db, block-1)
-	if err != nil {
-		return nil, err
-	}
-	header := rawdb.ReadHeader(db, hash, block-1)
-	tr := trie.New(header.Root)
-	if err = tr.HookSubTries(subTries, [][]byte{nil}); err != nil {
-		return nil, err
+
+	sort.Slice(o.accounts, func(i, j int) bool { return bytes.Compare(o.accounts[i].nibbles, o.accounts[j].nibbles) < 0 })
+	for _, mods := range o.storage {
+		sort.Slice(mods, func(i, j int) bool { return bytes.Compare(mods[i].nibbles, mods[j].nibbles) < 0 })
 	}
-	accountProof, err2 := tr.Prove(addrHash[:], 0, false)
-	if err2 != nil {
-		return nil, err2
-	}
-	storageProof := make([]StorageResult, len(storageKeys))
-	for i, key := range storageKeys {
-		keyAsHash := common.HexToHash(key)
-		if keyHash, err1 := common.HashData(keyAsHash[:]); err1 == nil {
-			trieKey := append(addrHash[:], keyHash[:]...)
-			if proof, err3 := tr.Prove(trieKey, 64 , true); err3 == nil {
-				v, _ := tr.Get(trieKey)
-				bv := new(big.Int)
-				bv.SetBytes(v)
-				storageProof[i] = StorageResult{key, (*hexutil.Big)(bv), toHexSlice(proof)}
-			} else {
-				return nil, err3
-			}
-		} else {
-			return nil, err1
-		}
-	}
-	acc, found := tr.GetAccount(addrHash[:])
-	if !found {
-		return nil, nil
-	}
-	return &AccountResult{
-		Address:      address,
-		AccountProof: toHexSlice(accountProof),
-		Balance:      (*hexutil.Big)(acc.Balance.ToBig()),
-		CodeHash:     acc.CodeHash,
-		Nonce:        hexutil.Uint64(acc.Nonce),
-		StorageHash:  acc.Root,
-		StorageProof: storageProof,
-	}, nil
-	return &AccountResult{}, nil
+	return nil
+}
+
+// StateOverlayReceiver - sits between FlatDBTrieLoader and RootHashAggregator and replaces values
+// of the stream by values of StateOverlay, so the aggregator calculates the trie of an earlier block.
+type StateOverlayReceiver struct {
+	tx              kv.Tx
+	overlay         *StateOverlay
+	defaultReceiver trie.StreamReceiver
+	accIdx          int
+
+	accWithInc  []byte             // storage prefix of the current account
+	storageMods []*storageOverride // not yet emitted overrides for storage of the current account
+	dropStorage bool               // storage items of the db stream don't belong to the current account
 }
-*/
-
-type Receiver struct {
-	defaultReceiver *trie.RootHashAggregator
-	accountMap      map[string]*accounts.Account
-	storageMap      map[string][]byte
-	unfurlList      []string
-	currentIdx      int
+
+func NewStateOverlayReceiver(tx kv.Tx, overlay *StateOverlay, defaultReceiver trie.StreamReceiver) *StateOverlayReceiver {
+	return &StateOverlayReceiver{tx: tx, overlay: overlay, defaultReceiver: defaultReceiver}
 }
 
-func (r *Receiver) Root() common.Hash { panic("don't call me") }
-func (r *Receiver) Receive(
+func (r *StateOverlayReceiver) Root() common.Hash { return r.defaultReceiver.Root() }
+
+func (r *StateOverlayReceiver) Result() trie.SubTries { return r.defaultReceiver.Result() }
+
+func (r *StateOverlayReceiver) Receive(
 	itemType trie.StreamItem,
 	accountKey []byte,
 	storageKey []byte,
@@ -190,45 +250,149 @@ func (r *Receiver) Receive(
 	hasTree bool,
 	cutoff int,
 ) error {
-	for r.currentIdx < len(r.unfurlList) {
-		ks := r.unfurlList[r.currentIdx]
-		k := []byte(ks)
-		var c int
-		switch itemType {
-		case trie.StorageStreamItem, trie.SHashStreamItem:
-			c = bytes.Compare(k, storageKey)
-		case trie.AccountStreamItem, trie.AHashStreamItem:
-			c = bytes.Compare(k, accountKey)
-		case trie.CutoffStreamItem:
-			c = -1
-		}
-		if c > 0 {
-			return r.defaultReceiver.Receive(itemType, accountKey, storageKey, accountValue, storageValue, hash, hasTree, cutoff)
-		}
-		if len(k) > common.HashLength {
-			v := r.storageMap[ks]
-			if c <= 0 && len(v) > 0 {
-				if err := r.defaultReceiver.Receive(trie.StorageStreamItem, nil, k, nil, v, nil, hasTree, 0); err != nil {
-					return err
-				}
+	switch itemType {
+	case trie.AccountStreamItem:
+		if err := r.finishAccount(); err != nil {
+			return err
+		}
+		if err := r.emitAccountsBefore(accountKey); err != nil {
+			return err
+		}
+		if r.accIdx < len(r.overlay.accounts) && bytes.Equal(r.overlay.accounts[r.accIdx].nibbles, accountKey) {
+			mod := r.overlay.accounts[r.accIdx]
+			r.accIdx++
+			if mod.account == nil || mod.account.Incarnation != accountValue.Incarnation {
+				r.dropStorage = true
+				return r.emitAccount(mod)
 			}
-		} else {
-			v := r.accountMap[ks]
-			if c <= 0 && v != nil {
-				if err := r.defaultReceiver.Receive(trie.AccountStreamItem, k, nil, v, nil, nil, hasTree, 0); err != nil {
-					return err
-				}
+			if err := r.defaultReceiver.Receive(trie.AccountStreamItem, accountKey, nil, mod.account, nil, nil, false, 0); err != nil {
+				return err
 			}
+			r.startStorage(mod.addrHash[:], mod.account.Incarnation)
+			return nil
 		}
-		r.currentIdx++
-		if c == 0 {
+		if err := r.defaultReceiver.Receive(itemType, accountKey, storageKey, accountValue, storageValue, hash, hasTree, cutoff); err != nil {
+			return err
+		}
+		var addrHash []byte
+		hexutil.CompressNibbles(accountKey, &addrHash)
+		r.startStorage(addrHash, accountValue.Incarnation)
+		return nil
+	case trie.StorageStreamItem, trie.SHashStreamItem:
+		if r.dropStorage {
 			return nil
 		}
+		for len(r.storageMods) > 0 && bytes.Compare(r.storageMods[0].nibbles, storageKey) < 0 {
+			if err := r.emitStorage(accountKey, r.storageMods[0]); err != nil {
+				return err
+			}
+			r.storageMods = r.storageMods[1:]
+		}
+		if itemType == trie.StorageStreamItem && len(r.storageMods) > 0 && bytes.Equal(r.storageMods[0].nibbles, storageKey) {
+			mod := r.storageMods[0]
+			r.storageMods = r.storageMods[1:]
+			return r.emitStorage(accountKey, mod)
+		}
+	case trie.AHashStreamItem:
+		if err := r.finishAccount(); err != nil {
+			return err
+		}
+		if err := r.emitAccountsBefore(accountKey); err != nil {
+			return err
+		}
+	case trie.CutoffStreamItem:
+		if err := r.finishAccount(); err != nil {
+			return err
+		}
+		for ; r.accIdx < len(r.overlay.accounts); r.accIdx++ {
+			if err := r.emitAccount(r.overlay.accounts[r.accIdx]); err != nil {
+				return err
+			}
+		}
 	}
-	// We ran out of modifications, simply pass through
 	return r.defaultReceiver.Receive(itemType, accountKey, storageKey, accountValue, storageValue, hash, hasTree, cutoff)
 }
 
-func (r *Receiver) Result() trie.SubTries {
-	return r.defaultReceiver.Result()
+// emitAccountsBefore - emits overrides of accounts which are absent in db and located before `key` in the trie
+func (r *StateOverlayReceiver) emitAccountsBefore(key []byte) error {
+	for ; r.accIdx < len(r.overlay.accounts) && bytes.Compare(r.overlay.accounts[r.accIdx].nibbles, key) < 0; r.accIdx++ {
+		if err := r.emitAccount(r.overlay.accounts[r.accIdx]); err != nil {
+			return err
+		}
+		if err := r.finishAccount(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emitAccount - emits account from overlay together with all its storage, because db stream
+// can't be used for it (account is absent in db or has another incarnation there)
+func (r *StateOverlayReceiver) emitAccount(mod *accountOverride) error {
+	if mod.account == nil {
+		return nil
+	}
+	if err := r.defaultReceiver.Receive(trie.AccountStreamItem, mod.nibbles, nil, mod.account, nil, nil, false, 0); err != nil {
+		return err
+	}
+	if mod.account.Incarnation == 0 {
+		return nil
+	}
+	accWithInc := dbutils.GenerateStoragePrefix(mod.addrHash[:], mod.account.Incarnation)
+	mods := r.overlay.storage[string(accWithInc)]
+	if err := r.tx.ForPrefix(kv.HashedStorage, accWithInc, func(k, v []byte) error {
+		var nibbles []byte
+		hexutil.DecompressNibbles(v[:common.HashLength], &nibbles)
+		for len(mods) > 0 && bytes.Compare(mods[0].nibbles, nibbles) < 0 {
+			if err := r.emitStorage(accWithInc, mods[0]); err != nil {
+				return err
+			}
+			mods = mods[1:]
+		}
+		if len(mods) > 0 && bytes.Equal(mods[0].nibbles, nibbles) {
+			mod := mods[0]
+			mods = mods[1:]
+			return r.emitStorage(accWithInc, mod)
+		}
+		return r.defaultReceiver.Receive(trie.StorageStreamItem, accWithInc, nibbles, nil, v[common.HashLength:], nil, false, 0)
+	}); err != nil {
+		return err
+	}
+	for _, mod := range mods {
+		if err := r.emitStorage(accWithInc, mod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *StateOverlayReceiver) emitStorage(accWithInc []byte, mod *storageOverride) error {
+	if len(mod.value) == 0 {
+		return nil
+	}
+	return r.defaultReceiver.Receive(trie.StorageStreamItem, accWithInc, mod.nibbles, nil, mod.value, nil, false, 0)
+}
+
+func (r *StateOverlayReceiver) startStorage(addrHash []byte, incarnation uint64) {
+	r.dropStorage = false
+	r.accWithInc, r.storageMods = nil, nil
+	if incarnation == 0 {
+		return
+	}
+	r.accWithInc = dbutils.GenerateStoragePrefix(addrHash, incarnation)
+	r.storageMods = r.overlay.storage[string(r.accWithInc)]
+}
+
+// finishAccount - emits overrides which are located after all storage items of the current account
+func (r *StateOverlayReceiver) finishAccount() error {
+	if !r.dropStorage {
+		for _, mod := range r.storageMods {
+			if err := r.emitStorage(r.accWithInc, mod); err != nil {
+				return err
+			}
+		}
+	}
+	r.accWithInc, r.storageMods = nil, nil
+	r.dropStorage = false
+	return nil
 }
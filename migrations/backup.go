@@ -0,0 +1,106 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/node/nodecfg/datadir"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// backupDir - MDBX with copy of migration's tables, made before migration was applied
+func backupDir(dirs datadir.Dirs, name string) string {
+	return filepath.Join(dirs.DataDir, "migrations", name, "backup")
+}
+
+// backupTables - copies tables to new MDBX at path. Copy is made in tmp dir and renamed to path when completed,
+// existing path means backup is ready.
+func backupTables(db kv.RoDB, path string, tables []string) error {
+	if common.FileExist(path) {
+		return nil
+	}
+	cfg, err := tablesCfg(db, tables)
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return err
+	}
+	log.Info("[migrations] Backup tables", "tables", tables, "path", path)
+	backup, err := mdbx.NewMDBX(log.New()).Path(tmpPath).WithTablessCfg(func(kv.TableCfg) kv.TableCfg { return cfg }).Open()
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+	if err := db.View(context.Background(), func(tx kv.Tx) error {
+		return backup.Update(context.Background(), func(backupTx kv.RwTx) error {
+			return copyTables(tx, backupTx, tables)
+		})
+	}); err != nil {
+		return err
+	}
+	backup.Close()
+	return os.Rename(tmpPath, path)
+}
+
+// restoreTables - replaces content of tables by their backup at path, and calls beforeCommit in the same transaction
+func restoreTables(db kv.RwDB, path string, tables []string, beforeCommit func(tx kv.RwTx) error) error {
+	cfg, err := tablesCfg(db, tables)
+	if err != nil {
+		return err
+	}
+	log.Info("[migrations] Restore tables", "tables", tables, "path", path)
+	backup, err := mdbx.NewMDBX(log.New()).Path(path).Readonly().WithTablessCfg(func(kv.TableCfg) kv.TableCfg { return cfg }).Open()
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+	return backup.View(context.Background(), func(backupTx kv.Tx) error {
+		return db.Update(context.Background(), func(tx kv.RwTx) error {
+			for _, table := range tables {
+				if err := tx.ClearBucket(table); err != nil {
+					return err
+				}
+			}
+			if err := copyTables(backupTx, tx, tables); err != nil {
+				return err
+			}
+			return beforeCommit(tx)
+		})
+	})
+}
+
+func tablesCfg(db kv.RoDB, tables []string) (kv.TableCfg, error) {
+	all := db.AllBuckets()
+	cfg := make(kv.TableCfg, len(tables))
+	for _, table := range tables {
+		tableCfg, ok := all[table]
+		if !ok {
+			return nil, fmt.Errorf("unknown table: %s", table)
+		}
+		cfg[table] = tableCfg
+	}
+	return cfg, nil
+}
+
+func copyTables(from kv.Tx, to kv.RwTx, tables []string) error {
+	for _, table := range tables {
+		c, err := to.RwCursor(table)
+		if err != nil {
+			return err
+		}
+		if err := from.ForEach(table, nil, func(k, v []byte) error {
+			return c.Put(k, v)
+		}); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+		c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package migrations
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// TableChanges - rows in table before and after migration
+type TableChanges struct {
+	Table                 string
+	RowsBefore, RowsAfter uint64
+}
+
+// DryRun - applies pending migrations (until given one, inclusive) in one transaction and rolls it back.
+// Returns tables touched by them. Side effects outside of DB (files in datadir) are not rolled back.
+func (m *Migrator) DryRun(db kv.RwDB, dataDir string, until string) ([]TableChanges, error) {
+	tx, err := db.BeginRw(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	dryTx := &dryRunTx{RwTx: tx, rowsBefore: map[string]uint64{}}
+	dry := &Migrator{Migrations: m.Migrations} // no backups of changes which will be rolled back
+	if err := dry.ApplyUntil(&dryRunDB{RwDB: db, tx: dryTx}, dataDir, until); err != nil {
+		return nil, err
+	}
+	return dryTx.changes()
+}
+
+// dryRunDB - all transactions of migration are the same write transaction, which is never committed
+type dryRunDB struct {
+	kv.RwDB
+	tx *dryRunTx
+}
+
+func (db *dryRunDB) BeginRo(context.Context) (kv.Tx, error)   { return db.tx, nil }
+func (db *dryRunDB) BeginRw(context.Context) (kv.RwTx, error) { return db.tx, nil }
+func (db *dryRunDB) View(_ context.Context, f func(tx kv.Tx) error) error {
+	return f(db.tx)
+}
+func (db *dryRunDB) Update(_ context.Context, f func(tx kv.RwTx) error) error {
+	return f(db.tx)
+}
+
+// dryRunTx - remembers amount of rows in tables before first write to them
+type dryRunTx struct {
+	kv.RwTx
+	rowsBefore map[string]uint64
+	err        error
+}
+
+func (tx *dryRunTx) Commit() error { return tx.err }
+func (tx *dryRunTx) Rollback()     {}
+
+func (tx *dryRunTx) touch(table string) {
+	if _, ok := tx.rowsBefore[table]; ok || tx.err != nil {
+		return
+	}
+	tx.rowsBefore[table], tx.err = tx.count(table)
+}
+
+func (tx *dryRunTx) count(table string) (uint64, error) {
+	c, err := tx.RwTx.Cursor(table)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+	return c.Count()
+}
+
+func (tx *dryRunTx) changes() ([]TableChanges, error) {
+	if tx.err != nil {
+		return nil, tx.err
+	}
+	changes := make([]TableChanges, 0, len(tx.rowsBefore))
+	for table, before := range tx.rowsBefore {
+		after, err := tx.count(table)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, TableChanges{Table: table, RowsBefore: before, RowsAfter: after})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Table < changes[j].Table })
+	return changes, nil
+}
+
+func (tx *dryRunTx) Put(table string, k, v []byte) error {
+	tx.touch(table)
+	return tx.RwTx.Put(table, k, v)
+}
+func (tx *dryRunTx) Delete(table string, k []byte) error {
+	tx.touch(table)
+	return tx.RwTx.Delete(table, k)
+}
+func (tx *dryRunTx) Append(table string, k, v []byte) error {
+	tx.touch(table)
+	return tx.RwTx.Append(table, k, v)
+}
+func (tx *dryRunTx) AppendDup(table string, k, v []byte) error {
+	tx.touch(table)
+	return tx.RwTx.AppendDup(table, k, v)
+}
+func (tx *dryRunTx) ClearBucket(table string) error {
+	tx.touch(table)
+	return tx.RwTx.ClearBucket(table)
+}
+func (tx *dryRunTx) IncrementSequence(table string, amount uint64) (uint64, error) {
+	tx.touch(table)
+	return tx.RwTx.IncrementSequence(table, amount)
+}
+func (tx *dryRunTx) RwCursor(table string) (kv.RwCursor, error) {
+	tx.touch(table)
+	return tx.RwTx.RwCursor(table)
+}
+func (tx *dryRunTx) RwCursorDupSort(table string) (kv.RwCursorDupSort, error) {
+	tx.touch(table)
+	return tx.RwTx.RwCursorDupSort(table)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/ledgerwatch/erigon-lib/kv"
@@ -30,11 +31,16 @@ import (
 // - in the end:drop old bucket (not in defer!).
 // - if you need migrate multiple buckets - create separate migration for each bucket
 // - write test - and check that it's safe to apply same migration twice
+//
+// Reversibility:
+// - provide Down if migration can be reverted by code, it's used by `integration revert_migration`
+// - else list in Tables all tables Up writes to - they are copied to backup before Up and restored from it on revert
 var migrations = map[kv.Label][]Migration{
 	kv.ChainDB: {
 		dbSchemaVersion5,
 		txsBeginEnd,
 		resetBlocks,
+		resetIssuance,
 	},
 	kv.TxPoolDB: {},
 	kv.SentryDB: {},
@@ -44,22 +50,31 @@ type Callback func(tx kv.RwTx, progress []byte, isDone bool) error
 type Migration struct {
 	Name string
 	Up   func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) error
+	// Down - optional, reverts Up. BeforeCommit with isDone=true removes the mark of applied migration
+	Down func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) error
+	// Tables - optional, tables which Up writes to. Backup of them is made before Up
+	Tables []string
 }
 
 var (
 	ErrMigrationNonUniqueName   = fmt.Errorf("please provide unique migration name")
 	ErrMigrationCommitNotCalled = fmt.Errorf("migration before-commit function was not called")
 	ErrMigrationETLFilesDeleted = fmt.Errorf("db migration progress was interrupted after extraction step and ETL files was deleted, please contact development team for help or re-sync from scratch")
+	ErrMigrationNotFound        = fmt.Errorf("migration not found")
+	ErrMigrationNotApplied      = fmt.Errorf("migration is not applied")
+	ErrMigrationNotReversible   = fmt.Errorf("migration has no Down function and no backup")
 )
 
 func NewMigrator(label kv.Label) *Migrator {
 	return &Migrator{
 		Migrations: migrations[label],
+		Backup:     true,
 	}
 }
 
 type Migrator struct {
 	Migrations []Migration
+	Backup     bool // copy Tables of migration before apply it, to be able restore them on revert
 }
 
 func AppliedMigrations(tx kv.Tx, withPayload bool) (map[string][]byte, error) {
@@ -153,10 +168,29 @@ func (m *Migrator) VerifyVersion(db kv.RwDB) error {
 	return nil
 }
 
+func (m *Migrator) migration(name string) (Migration, int, error) {
+	for i := range m.Migrations {
+		if m.Migrations[i].Name == name {
+			return m.Migrations[i], i, nil
+		}
+	}
+	return Migration{}, 0, fmt.Errorf("%w: %s", ErrMigrationNotFound, name)
+}
+
 func (m *Migrator) Apply(db kv.RwDB, dataDir string) error {
+	return m.ApplyUntil(db, dataDir, "")
+}
+
+// ApplyUntil - applies pending migrations in order, until given one (inclusive). Empty until - applies all of them
+func (m *Migrator) ApplyUntil(db kv.RwDB, dataDir string, until string) error {
 	if len(m.Migrations) == 0 {
 		return nil
 	}
+	if until != "" {
+		if _, _, err := m.migration(until); err != nil {
+			return err
+		}
+	}
 	dirs := datadir.New(dataDir)
 
 	var applied map[string][]byte
@@ -187,6 +221,9 @@ func (m *Migrator) Apply(db kv.RwDB, dataDir string) error {
 	for i := range m.Migrations {
 		v := m.Migrations[i]
 		if _, ok := applied[v.Name]; ok {
+			if v.Name == until {
+				break
+			}
 			continue
 		}
 
@@ -202,6 +239,11 @@ func (m *Migrator) Apply(db kv.RwDB, dataDir string) error {
 		}
 
 		dirs.Tmp = filepath.Join(dirs.DataDir, "migrations", v.Name)
+		if m.Backup && len(v.Tables) > 0 && progress == nil {
+			if err := backupTables(db, backupDir(dirs, v.Name), v.Tables); err != nil {
+				return fmt.Errorf("migrator.Apply.backup: %s, %w", v.Name, err)
+			}
+		}
 		if err := v.Up(db, dirs, progress, func(tx kv.RwTx, key []byte, isDone bool) error {
 			if !isDone {
 				if key != nil {
@@ -236,6 +278,9 @@ func (m *Migrator) Apply(db kv.RwDB, dataDir string) error {
 			return fmt.Errorf("%w: %s", ErrMigrationCommitNotCalled, v.Name)
 		}
 		log.Info("Applied migration", "name", v.Name)
+		if v.Name == until {
+			break
+		}
 	}
 	// Write DB schema version
 	var version [12]byte
@@ -254,6 +299,76 @@ func (m *Migrator) Apply(db kv.RwDB, dataDir string) error {
 	return nil
 }
 
+// Revert - reverts applied migration by its Down function, or restores its tables from the backup made before apply.
+// Migrations applied after it must be reverted first.
+func (m *Migrator) Revert(db kv.RwDB, dataDir string, name string) error {
+	v, idx, err := m.migration(name)
+	if err != nil {
+		return err
+	}
+	dirs := datadir.New(dataDir)
+	backup := backupDir(dirs, v.Name)
+	if v.Down == nil && !common.FileExist(backup) {
+		return fmt.Errorf("%w: %s", ErrMigrationNotReversible, v.Name)
+	}
+
+	var applied map[string][]byte
+	var progress []byte
+	if err := db.View(context.Background(), func(tx kv.Tx) (err error) {
+		if applied, err = AppliedMigrations(tx, false); err != nil {
+			return err
+		}
+		progress, err = tx.GetOne(kv.Migrations, []byte("_progress_down_"+v.Name))
+		return err
+	}); err != nil {
+		return fmt.Errorf("migrator.Revert: %w", err)
+	}
+	if _, ok := applied[v.Name]; !ok {
+		return fmt.Errorf("%w: %s", ErrMigrationNotApplied, v.Name)
+	}
+	for _, later := range m.Migrations[idx+1:] {
+		if _, ok := applied[later.Name]; ok {
+			return fmt.Errorf("migrator.Revert: %s, revert migration applied after it first: %s", v.Name, later.Name)
+		}
+	}
+
+	log.Info("Revert migration", "name", v.Name)
+	if v.Down != nil {
+		callbackCalled := false
+		dirs.Tmp = filepath.Join(dirs.DataDir, "migrations", v.Name)
+		if err := v.Down(db, dirs, progress, func(tx kv.RwTx, key []byte, isDone bool) error {
+			if !isDone {
+				if key != nil {
+					return tx.Put(kv.Migrations, []byte("_progress_down_"+v.Name), key)
+				}
+				return nil
+			}
+			callbackCalled = true
+			if err := tx.Delete(kv.Migrations, []byte(v.Name)); err != nil {
+				return err
+			}
+			return tx.Delete(kv.Migrations, []byte("_progress_down_"+v.Name))
+		}); err != nil {
+			return fmt.Errorf("migrator.Revert.Down: %s, %w", v.Name, err)
+		}
+		if !callbackCalled {
+			return fmt.Errorf("%w: %s", ErrMigrationCommitNotCalled, v.Name)
+		}
+	} else {
+		if err := restoreTables(db, backup, v.Tables, func(tx kv.RwTx) error {
+			return tx.Delete(kv.Migrations, []byte(v.Name))
+		}); err != nil {
+			return fmt.Errorf("migrator.Revert.restore: %s, %w", v.Name, err)
+		}
+	}
+	// backup is outdated after revert, next apply will make new one
+	if err := os.RemoveAll(backup); err != nil {
+		return err
+	}
+	log.Info("Reverted migration", "name", v.Name)
+	return nil
+}
+
 func MarshalMigrationPayload(db kv.Getter) ([]byte, error) {
 	s := map[string][]byte{}
 
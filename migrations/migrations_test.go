@@ -7,6 +7,7 @@ import (
 
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/node/nodecfg/datadir"
 
@@ -17,8 +18,8 @@ func TestApplyWithInit(t *testing.T) {
 	require, db := require.New(t), memdb.NewTestDB(t)
 	m := []Migration{
 		{
-			"one",
-			func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
+			Name: "one",
+			Up: func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
 				tx, err := db.BeginRw(context.Background())
 				if err != nil {
 					return err
@@ -32,8 +33,8 @@ func TestApplyWithInit(t *testing.T) {
 			},
 		},
 		{
-			"two",
-			func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
+			Name: "two",
+			Up: func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
 				tx, err := db.BeginRw(context.Background())
 				if err != nil {
 					return err
@@ -81,15 +82,15 @@ func TestApplyWithoutInit(t *testing.T) {
 	require, db := require.New(t), memdb.NewTestDB(t)
 	m := []Migration{
 		{
-			"one",
-			func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
+			Name: "one",
+			Up: func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
 				t.Fatal("shouldn't been executed")
 				return nil
 			},
 		},
 		{
-			"two",
-			func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
+			Name: "two",
+			Up: func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
 				tx, err := db.BeginRw(context.Background())
 				if err != nil {
 					return err
@@ -145,8 +146,8 @@ func TestWhenNonFirstMigrationAlreadyApplied(t *testing.T) {
 	require, db := require.New(t), memdb.NewTestDB(t)
 	m := []Migration{
 		{
-			"one",
-			func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
+			Name: "one",
+			Up: func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
 				tx, err := db.BeginRw(context.Background())
 				if err != nil {
 					return err
@@ -160,8 +161,8 @@ func TestWhenNonFirstMigrationAlreadyApplied(t *testing.T) {
 			},
 		},
 		{
-			"two",
-			func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
+			Name: "two",
+			Up: func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
 				t.Fatal("shouldn't been executed")
 				return nil
 			},
@@ -296,3 +297,119 @@ func TestCommitCallRequired(t *testing.T) {
 	})
 	require.NoError(err)
 }
+
+func putMigration(name string, k, v []byte) Migration {
+	return Migration{
+		Name: name,
+		Up: func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
+			tx, err := db.BeginRw(context.Background())
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback()
+
+			if err := tx.Put(kv.HeaderNumber, k, v); err != nil {
+				return err
+			}
+			if err := BeforeCommit(tx, nil, true); err != nil {
+				return err
+			}
+			return tx.Commit()
+		},
+		Tables: []string{kv.HeaderNumber},
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	require, db := require.New(t), memdb.NewTestDB(t)
+	err := db.Update(context.Background(), func(tx kv.RwTx) error {
+		return tx.Put(kv.HeaderNumber, []byte{1}, []byte{1})
+	})
+	require.NoError(err)
+
+	migrator := NewMigrator(kv.ChainDB)
+	migrator.Migrations = []Migration{putMigration("one", []byte{2}, []byte{2}), putMigration("two", []byte{3}, []byte{3})}
+	changes, err := migrator.DryRun(db, t.TempDir(), "one")
+	require.NoError(err)
+	require.Contains(changes, TableChanges{Table: kv.HeaderNumber, RowsBefore: 1, RowsAfter: 2})
+
+	changes, err = migrator.DryRun(db, t.TempDir(), "")
+	require.NoError(err)
+	require.Contains(changes, TableChanges{Table: kv.HeaderNumber, RowsBefore: 1, RowsAfter: 3})
+
+	// nothing is applied
+	err = db.View(context.Background(), func(tx kv.Tx) error {
+		applied, err := AppliedMigrations(tx, false)
+		require.NoError(err)
+		require.Equal(0, len(applied))
+		v, err := tx.GetOne(kv.HeaderNumber, []byte{2})
+		require.NoError(err)
+		require.Nil(v)
+		return nil
+	})
+	require.NoError(err)
+
+	_, err = migrator.DryRun(db, t.TempDir(), "three")
+	require.True(errors.Is(err, ErrMigrationNotFound))
+}
+
+func TestRevert(t *testing.T) {
+	require, db := require.New(t), memdb.NewTestDB(t)
+	dataDir := t.TempDir()
+	err := db.Update(context.Background(), func(tx kv.RwTx) error {
+		return tx.Put(kv.HeaderNumber, []byte{1}, []byte{1})
+	})
+	require.NoError(err)
+
+	downCalled := false
+	withDown := putMigration("two", []byte{3}, []byte{3})
+	withDown.Tables = nil
+	withDown.Down = func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
+		downCalled = true
+		tx, err := db.BeginRw(context.Background())
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := tx.Delete(kv.HeaderNumber, []byte{3}); err != nil {
+			return err
+		}
+		if err := BeforeCommit(tx, nil, true); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	migrator := NewMigrator(kv.ChainDB)
+	migrator.Migrations = []Migration{putMigration("one", []byte{2}, []byte{2}), withDown}
+
+	require.NoError(migrator.ApplyUntil(db, dataDir, "one"))
+	err = migrator.Revert(db, dataDir, "two")
+	require.True(errors.Is(err, ErrMigrationNotApplied))
+	require.NoError(migrator.Apply(db, dataDir))
+
+	// "two" must be reverted first
+	require.Error(migrator.Revert(db, dataDir, "one"))
+	require.NoError(migrator.Revert(db, dataDir, "two"))
+	require.True(downCalled)
+
+	// "one" has no Down, its table is restored from backup
+	require.NoError(migrator.Revert(db, dataDir, "one"))
+	err = db.View(context.Background(), func(tx kv.Tx) error {
+		applied, err := AppliedMigrations(tx, false)
+		require.NoError(err)
+		require.Equal(0, len(applied))
+		var keys [][]byte
+		require.NoError(tx.ForEach(kv.HeaderNumber, nil, func(k, v []byte) error {
+			keys = append(keys, common.CopyBytes(k))
+			return nil
+		}))
+		require.Equal([][]byte{{1}}, keys)
+		return nil
+	})
+	require.NoError(err)
+
+	// backup is removed after revert
+	err = migrator.Revert(db, dataDir, "one")
+	require.True(errors.Is(err, ErrMigrationNotReversible))
+}
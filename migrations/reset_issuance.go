@@ -0,0 +1,104 @@
+package migrations
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/serenity"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/rawdb/rawdbreset"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/node/nodecfg/datadir"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// resetIssuance makes the Issuance stage compute the totals again: they used to count 0.3 ether for every
+// proof-of-stake block and nothing for withdrawals, and were not written at all for the other consensus engines
+var resetIssuance = Migration{
+	Name: "reset_issuance",
+	Up: func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
+		tx, err := db.BeginRw(context.Background())
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		genesisBlock := rawdb.ReadHeaderByNumber(tx, 0)
+		if genesisBlock == nil {
+			if err := BeforeCommit(tx, nil, true); err != nil {
+				return err
+			}
+			return tx.Commit()
+		}
+		chainConfig, err := rawdb.ReadChainConfig(tx, genesisBlock.Hash())
+		if err != nil {
+			return err
+		}
+		issuanceProgress, _ := stages.GetStageProgress(tx, stages.Issuance)
+		if issuanceProgress > 0 {
+			log.Warn("NOTE: this migration will remove the issuance totals to fix them, they are computed again with --watch-the-burn")
+		}
+
+		// The totals of the genesis are written with the genesis block only, fix them here
+		totalIssued, err := rawdb.ReadTotalIssued(tx, 0)
+		if err != nil {
+			return err
+		}
+		if g := core.DefaultGenesisBlockByChainName(chainConfig.ChainName); g != nil {
+			totalIssued.SetUint64(0)
+			for _, account := range g.Alloc {
+				totalIssued.Add(totalIssued, account.Balance)
+			}
+			issuance := core.CalcBlockIssuance(chainConfig, genesisBlock, nil, nil)
+			totalIssued.Add(totalIssued, issuance.Issued().ToBig())
+		} else if chainConfig.Consensus == params.EtHashConsensus && genesisBlock.Difficulty.Cmp(serenity.SerenityDifficulty) == 0 && totalIssued.Cmp(serenity.RewardSerenity) >= 0 {
+			totalIssued.Sub(totalIssued, serenity.RewardSerenity)
+		}
+
+		if err := rawdbreset.ResetIssuance(tx); err != nil {
+			return err
+		}
+		if err := rawdb.WriteTotalIssued(tx, 0, totalIssued); err != nil {
+			return err
+		}
+		if err := rawdb.WriteTotalBurnt(tx, 0, big.NewInt(0)); err != nil {
+			return err
+		}
+
+		if err := BeforeCommit(tx, nil, true); err != nil {
+			return err
+		}
+		return tx.Commit()
+	},
+	// Down restores the totals from the backup, and moves the Issuance stage back to the last block they are known for.
+	// The progress of the other stages is left as it is, they may have moved on since the migration
+	Down: func(db kv.RwDB, dirs datadir.Dirs, progress []byte, BeforeCommit Callback) (err error) {
+		backup := backupDir(dirs, "reset_issuance")
+		if !common.FileExist(backup) {
+			return fmt.Errorf("%w: reset_issuance", ErrMigrationNotReversible)
+		}
+		return restoreTables(db, backup, []string{kv.Issuance}, func(tx kv.RwTx) error {
+			var issuanceProgress uint64
+			if err := tx.ForEach(kv.Issuance, nil, func(k, _ []byte) error {
+				// The totals burnt are keyed by "burnt" and the block number, the totals issued by the block number only
+				if len(k) == 8 && binary.BigEndian.Uint64(k) > issuanceProgress {
+					issuanceProgress = binary.BigEndian.Uint64(k)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			if err := stages.SaveStageProgress(tx, stages.Issuance, issuanceProgress); err != nil {
+				return err
+			}
+			return BeforeCommit(tx, nil, true)
+		})
+	},
+	Tables: []string{kv.Issuance},
+}
@@ -0,0 +1,102 @@
+package migrations
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/serenity"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetIssuance(t *testing.T) {
+	require, tmpDir, db := require.New(t), t.TempDir(), memdb.NewTestDB(t)
+	alloc := big.NewInt(1e18)
+	gspec := &core.Genesis{
+		Config:     params.AllEthashProtocolChanges,
+		Difficulty: big.NewInt(0),
+		Alloc:      core.GenesisAlloc{common.HexToAddress("0x01"): {Balance: alloc}},
+	}
+	gspec.MustCommit(db)
+
+	// The totals as they were written before: 0.3 ether for every proof-of-stake block, the genesis one included
+	err := db.Update(context.Background(), func(tx kv.RwTx) error {
+		total := new(big.Int).Set(alloc)
+		for i := uint64(0); i < 4; i++ {
+			total.Add(total, serenity.RewardSerenity)
+			if err := rawdb.WriteTotalIssued(tx, i, total); err != nil {
+				return err
+			}
+			if err := rawdb.WriteTotalBurnt(tx, i, big.NewInt(int64(i))); err != nil {
+				return err
+			}
+		}
+		return stages.SaveStageProgress(tx, stages.Issuance, 3)
+	})
+	require.NoError(err)
+
+	migrator := NewMigrator(kv.ChainDB)
+	migrator.Migrations = []Migration{resetIssuance}
+	err = migrator.Apply(db, tmpDir)
+	require.NoError(err)
+
+	err = db.View(context.Background(), func(tx kv.Tx) error {
+		totalIssued, err := rawdb.ReadTotalIssued(tx, 0)
+		require.NoError(err)
+		require.Equal(alloc, totalIssued)
+		for i := uint64(1); i < 4; i++ {
+			totalIssued, err := rawdb.ReadTotalIssued(tx, i)
+			require.NoError(err)
+			require.Zero(totalIssued.Sign())
+			totalBurnt, err := rawdb.ReadTotalBurnt(tx, i)
+			require.NoError(err)
+			require.Zero(totalBurnt.Sign())
+		}
+		progress, err := stages.GetStageProgress(tx, stages.Issuance)
+		require.NoError(err)
+		require.Zero(progress)
+		return nil
+	})
+	require.NoError(err)
+
+	// The node syncs on after the migration, the revert only moves the Issuance stage back
+	err = db.Update(context.Background(), func(tx kv.RwTx) error {
+		if err := rawdb.WriteTotalIssued(tx, 5, alloc); err != nil {
+			return err
+		}
+		if err := stages.SaveStageProgress(tx, stages.Execution, 10); err != nil {
+			return err
+		}
+		return stages.SaveStageProgress(tx, stages.Issuance, 5)
+	})
+	require.NoError(err)
+	require.NoError(migrator.Revert(db, tmpDir, resetIssuance.Name))
+
+	err = db.View(context.Background(), func(tx kv.Tx) error {
+		total := new(big.Int).Set(alloc)
+		for i := uint64(0); i < 4; i++ {
+			total.Add(total, serenity.RewardSerenity)
+			totalIssued, err := rawdb.ReadTotalIssued(tx, i)
+			require.NoError(err)
+			require.Equal(total, totalIssued)
+		}
+		totalIssued, err := rawdb.ReadTotalIssued(tx, 5)
+		require.NoError(err)
+		require.Zero(totalIssued.Sign())
+		progress, err := stages.GetStageProgress(tx, stages.Issuance)
+		require.NoError(err)
+		require.Equal(uint64(3), progress)
+		progress, err = stages.GetStageProgress(tx, stages.Execution)
+		require.NoError(err)
+		require.Equal(uint64(10), progress)
+		return nil
+	})
+	require.NoError(err)
+}
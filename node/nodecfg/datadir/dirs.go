@@ -16,6 +16,7 @@ type Dirs struct {
 	Snap            string
 	TxPool          string
 	Nodes           string
+	Keystore        string
 }
 
 func New(datadir string) Dirs {
@@ -37,5 +38,6 @@ func New(datadir string) Dirs {
 		Snap:            filepath.Join(datadir, "snapshots"),
 		TxPool:          filepath.Join(datadir, "txpool"),
 		Nodes:           filepath.Join(datadir, "nodes"),
+		Keystore:        filepath.Join(datadir, "keystore"),
 	}
 }
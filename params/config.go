@@ -211,7 +211,7 @@ var (
 		Aura:                  &AuRaConfig{},
 	}
 
-	TestRules = TestChainConfig.Rules(0)
+	TestRules = TestChainConfig.Rules(0, 0)
 )
 
 // ChainConfig is the core config which determines the blockchain settings.
@@ -259,6 +259,10 @@ type ChainConfig struct {
 	TerminalBlockHash       common.Hash `json:"terminalBlockHash,omitempty"`       // Enforce particular terminal block; see TERMINAL_BLOCK_HASH in EIP-3675
 	MergeNetsplitBlock      *big.Int    `json:"mergeNetsplitBlock,omitempty"`      // Virtual fork after The Merge to use as a network splitter; see FORK_NEXT_VALUE in EIP-3675
 
+	// Post-merge forks are scheduled by block timestamp rather than by block number
+	ShanghaiTime *big.Int `json:"shanghaiTime,omitempty"` // Shanghai switch time (nil = no fork, 0 = already activated)
+	CancunTime   *big.Int `json:"cancunTime,omitempty"`   // Cancun switch time (nil = no fork, 0 = already activated)
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
@@ -405,7 +409,7 @@ func (c *ChainConfig) String() string {
 		)
 	}
 
-	return fmt.Sprintf("{ChainID: %v, Homestead: %v, DAO: %v, DAO Support: %v, Tangerine Whistle: %v, Spurious Dragon: %v, Byzantium: %v, Constantinople: %v, Petersburg: %v, Istanbul: %v, Muir Glacier: %v, Berlin: %v, London: %v, Arrow Glacier: %v, Gray Glacier: %v, Terminal Total Difficulty: %v, Merge Netsplit: %v, Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v, Homestead: %v, DAO: %v, DAO Support: %v, Tangerine Whistle: %v, Spurious Dragon: %v, Byzantium: %v, Constantinople: %v, Petersburg: %v, Istanbul: %v, Muir Glacier: %v, Berlin: %v, London: %v, Arrow Glacier: %v, Gray Glacier: %v, Terminal Total Difficulty: %v, Merge Netsplit: %v, Shanghai: %v, Cancun: %v, Engine: %v}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -423,6 +427,8 @@ func (c *ChainConfig) String() string {
 		c.GrayGlacierBlock,
 		c.TerminalTotalDifficulty,
 		c.MergeNetsplitBlock,
+		c.ShanghaiTime,
+		c.CancunTime,
 		engine,
 	)
 }
@@ -571,20 +577,34 @@ func (c *ChainConfig) IsGrayGlacier(num uint64) bool {
 	return isForked(c.GrayGlacierBlock, num)
 }
 
+// IsShanghai returns whether time is either equal to the Shanghai fork time or greater.
+func (c *ChainConfig) IsShanghai(time uint64) bool {
+	return isForked(c.ShanghaiTime, time)
+}
+
+// IsCancun returns whether time is either equal to the Cancun fork time or greater.
+func (c *ChainConfig) IsCancun(time uint64) bool {
+	return isForked(c.CancunTime, time)
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
-func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
-	bhead := height
+func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height, time uint64) *ConfigCompatError {
+	bhead, btime := height, time
 
 	// Iterate checkCompatible to find the lowest conflict.
 	var lasterr *ConfigCompatError
 	for {
-		err := c.checkCompatible(newcfg, bhead)
-		if err == nil || (lasterr != nil && err.RewindTo == lasterr.RewindTo) {
+		err := c.checkCompatible(newcfg, bhead, btime)
+		if err == nil || (lasterr != nil && err.RewindTo == lasterr.RewindTo && err.RewindToTime == lasterr.RewindToTime) {
 			break
 		}
 		lasterr = err
-		bhead = err.RewindTo
+		if err.RewindToTime > 0 {
+			btime = err.RewindToTime
+		} else {
+			bhead = err.RewindTo
+		}
 	}
 	return lasterr
 }
@@ -636,10 +656,19 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	// Time based forks
+	if c.CancunTime != nil {
+		if c.ShanghaiTime == nil {
+			return fmt.Errorf("unsupported fork ordering: shanghaiTime not enabled, but cancunTime enabled at %v", c.CancunTime)
+		}
+		if c.ShanghaiTime.Cmp(c.CancunTime) > 0 {
+			return fmt.Errorf("unsupported fork ordering: shanghaiTime enabled at %v, but cancunTime enabled at %v", c.ShanghaiTime, c.CancunTime)
+		}
+	}
 	return nil
 }
 
-func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head uint64) *ConfigCompatError {
+func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head, headTime uint64) *ConfigCompatError {
 	// Ethereum mainnet forks
 	if isForkIncompatible(c.HomesteadBlock, newcfg.HomesteadBlock, head) {
 		return newCompatError("Homestead fork block", c.HomesteadBlock, newcfg.HomesteadBlock)
@@ -693,6 +722,12 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head uint64) *ConfigC
 	if isForkIncompatible(c.MergeNetsplitBlock, newcfg.MergeNetsplitBlock, head) {
 		return newCompatError("Merge netsplit block", c.MergeNetsplitBlock, newcfg.MergeNetsplitBlock)
 	}
+	if isForkIncompatible(c.ShanghaiTime, newcfg.ShanghaiTime, headTime) {
+		return newTimestampCompatError("Shanghai fork timestamp", c.ShanghaiTime, newcfg.ShanghaiTime)
+	}
+	if isForkIncompatible(c.CancunTime, newcfg.CancunTime, headTime) {
+		return newTimestampCompatError("Cancun fork timestamp", c.CancunTime, newcfg.CancunTime)
+	}
 
 	// Parlia forks
 	if isForkIncompatible(c.RamanujanBlock, newcfg.RamanujanBlock, head) {
@@ -743,28 +778,46 @@ type ConfigCompatError struct {
 	What string
 	// block numbers of the stored and new configurations
 	StoredConfig, NewConfig *big.Int
+	// timestamps of the stored and new configurations, for the time based forks
+	StoredTime, NewTime *big.Int
 	// the block number to which the local chain must be rewound to correct the error
 	RewindTo uint64
+	// the timestamp to which the local chain must be rewound to correct the error
+	RewindToTime uint64
 }
 
 func newCompatError(what string, storedblock, newblock *big.Int) *ConfigCompatError {
-	var rew *big.Int
-	switch {
-	case storedblock == nil:
-		rew = newblock
-	case newblock == nil || storedblock.Cmp(newblock) < 0:
-		rew = storedblock
-	default:
-		rew = newblock
-	}
-	err := &ConfigCompatError{what, storedblock, newblock, 0}
-	if rew != nil && rew.Sign() > 0 {
+	err := &ConfigCompatError{What: what, StoredConfig: storedblock, NewConfig: newblock}
+	if rew := lowestFork(storedblock, newblock); rew != nil && rew.Sign() > 0 {
 		err.RewindTo = rew.Uint64() - 1
 	}
 	return err
 }
 
+func newTimestampCompatError(what string, storedtime, newtime *big.Int) *ConfigCompatError {
+	err := &ConfigCompatError{What: what, StoredTime: storedtime, NewTime: newtime}
+	if rew := lowestFork(storedtime, newtime); rew != nil && rew.Sign() > 0 {
+		err.RewindToTime = rew.Uint64() - 1
+	}
+	return err
+}
+
+// lowestFork returns the earliest of the two fork activation points, nil meaning never.
+func lowestFork(stored, next *big.Int) *big.Int {
+	switch {
+	case stored == nil:
+		return next
+	case next == nil || stored.Cmp(next) < 0:
+		return stored
+	default:
+		return next
+	}
+}
+
 func (err *ConfigCompatError) Error() string {
+	if err.StoredTime != nil || err.NewTime != nil {
+		return fmt.Sprintf("mismatching %s in database (have timestamp %d, want timestamp %d, rewindto timestamp %d)", err.What, err.StoredTime, err.NewTime, err.RewindToTime)
+	}
 	return fmt.Sprintf("mismatching %s in database (have %d, want %d, rewindto %d)", err.What, err.StoredConfig, err.NewConfig, err.RewindTo)
 }
 
@@ -777,12 +830,12 @@ type Rules struct {
 	ChainID                                                 *big.Int
 	IsHomestead, IsTangerineWhistle, IsSpuriousDragon       bool
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
-	IsBerlin, IsLondon                                      bool
+	IsBerlin, IsLondon, IsShanghai, IsCancun                bool
 	IsParlia, IsStarknet                                    bool
 }
 
 // Rules ensures c's ChainID is not nil.
-func (c *ChainConfig) Rules(num uint64) *Rules {
+func (c *ChainConfig) Rules(num uint64, time uint64) *Rules {
 	chainID := c.ChainID
 	if chainID == nil {
 		chainID = new(big.Int)
@@ -798,6 +851,8 @@ func (c *ChainConfig) Rules(num uint64) *Rules {
 		IsIstanbul:         c.IsIstanbul(num),
 		IsBerlin:           c.IsBerlin(num),
 		IsLondon:           c.IsLondon(num),
+		IsShanghai:         c.IsShanghai(time),
+		IsCancun:           c.IsCancun(time),
 		IsParlia:           c.Parlia != nil,
 	}
 }
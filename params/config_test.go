@@ -26,6 +26,7 @@ func TestCheckCompatible(t *testing.T) {
 	type test struct {
 		stored, new *ChainConfig
 		head        uint64
+		headTime    uint64
 		wantErr     *ConfigCompatError
 	}
 	tests := []test{
@@ -87,12 +88,43 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     30,
 			},
 		},
+		{
+			stored:   &ChainConfig{ShanghaiTime: big.NewInt(10)},
+			new:      &ChainConfig{ShanghaiTime: big.NewInt(20)},
+			head:     80,
+			headTime: 9,
+			wantErr:  nil,
+		},
+		{
+			stored:   &ChainConfig{ShanghaiTime: big.NewInt(10)},
+			new:      &ChainConfig{ShanghaiTime: big.NewInt(20)},
+			head:     80,
+			headTime: 25,
+			wantErr: &ConfigCompatError{
+				What:         "Shanghai fork timestamp",
+				StoredTime:   big.NewInt(10),
+				NewTime:      big.NewInt(20),
+				RewindToTime: 9,
+			},
+		},
+		{
+			stored:   &ChainConfig{ShanghaiTime: big.NewInt(10), CancunTime: big.NewInt(30)},
+			new:      &ChainConfig{ShanghaiTime: big.NewInt(10)},
+			head:     80,
+			headTime: 35,
+			wantErr: &ConfigCompatError{
+				What:         "Cancun fork timestamp",
+				StoredTime:   big.NewInt(30),
+				NewTime:      nil,
+				RewindToTime: 29,
+			},
+		},
 	}
 
 	for _, test := range tests {
-		err := test.stored.CheckCompatible(test.new, test.head)
+		err := test.stored.CheckCompatible(test.new, test.head, test.headTime)
 		if !reflect.DeepEqual(err, test.wantErr) {
-			t.Errorf("error mismatch:\nstored: %v\nnew: %v\nhead: %v\nerr: %v\nwant: %v", test.stored, test.new, test.head, err, test.wantErr)
+			t.Errorf("error mismatch:\nstored: %v\nnew: %v\nhead: %v\nheadTime: %v\nerr: %v\nwant: %v", test.stored, test.new, test.head, test.headTime, err, test.wantErr)
 		}
 	}
 }
@@ -67,6 +67,7 @@ type handler struct {
 
 	allowList     AllowList // a list of explicitly allowed methods, if empty -- everything is allowed
 	forbiddenList ForbiddenList
+	limits        *clientLimits // rate limits and size limits of the client, nil if there are none
 
 	subLock             sync.Mutex
 	serverSubs          map[ID]*Subscription
@@ -79,7 +80,7 @@ type callProc struct {
 	notifiers []*Notifier
 }
 
-func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, allowList AllowList, maxBatchConcurrency uint, traceRequests bool) *handler {
+func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, allowList AllowList, limits *clientLimits, maxBatchConcurrency uint, traceRequests bool) *handler {
 	rootCtx, cancelRoot := context.WithCancel(connCtx)
 	forbiddenList := newForbiddenList()
 	h := &handler{
@@ -95,6 +96,7 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 		log:            log.Root(),
 		allowList:      allowList,
 		forbiddenList:  forbiddenList,
+		limits:         limits,
 
 		maxBatchConcurrency: maxBatchConcurrency,
 		traceRequests:       traceRequests,
@@ -116,6 +118,13 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		})
 		return
 	}
+	if maxLength := h.limits.maxBatchLength(); maxLength > 0 && len(msgs) > maxLength {
+		batchTooLongCounter.Inc()
+		h.startCallProc(func(cp *callProc) {
+			h.conn.writeJSON(cp.ctx, errorMessage(&invalidRequestError{fmt.Sprintf("batch too long, limit is %d", maxLength)}))
+		})
+		return
+	}
 
 	// Handle non-call messages first:
 	calls := make([]*jsonrpcMessage, 0, len(msgs))
@@ -393,6 +402,10 @@ func (h *handler) isMethodAllowedByGranularControl(method string) bool {
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage, stream *jsoniter.Stream) *jsonrpcMessage {
+	if !msg.isUnsubscribe() && !h.limits.allowCall(msg.Method) {
+		rateLimitedCounter.Inc()
+		return msg.errorResponse(&rateLimitError{method: msg.Method})
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg, stream)
 	}
@@ -459,14 +472,41 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage, stream *jso
 
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value, stream *jsoniter.Stream) *jsonrpcMessage {
+	maxSize := h.limits.maxResponseSize()
 	if !callb.streamable {
 		result, err := callb.call(ctx, msg.Method, args, stream)
 		if err != nil {
 			return msg.errorResponse(err)
 		}
-		return msg.response(result)
+		answer := msg.response(result)
+		if maxSize > 0 && len(answer.Result) > maxSize {
+			responseTooLargeCounter.Inc()
+			return msg.errorResponse(&responseTooLargeError{limit: maxSize})
+		}
+		return answer
+	}
+	if maxSize == 0 {
+		h.streamMethod(ctx, msg, callb, args, stream)
+		return nil
 	}
 
+	// The response is held back until it is complete, as soon as it grows past the limit the method is stopped
+	// and the whole response is replaced with the error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	limit := &limitedWriter{limit: maxSize, onOverflow: cancel}
+	h.streamMethod(ctx, msg, callb, args, jsoniter.NewStream(jsoniter.ConfigDefault, limit, 4096))
+	if limit.overflow {
+		responseTooLargeCounter.Inc()
+		return msg.errorResponse(&responseTooLargeError{limit: maxSize})
+	}
+	stream.Write(limit.buf)
+	stream.Flush()
+	return nil
+}
+
+// streamMethod runs a streamable callback, which writes its result to the stream by itself.
+func (h *handler) streamMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value, stream *jsoniter.Stream) {
 	stream.WriteObjectStart()
 	stream.WriteObjectField("jsonrpc")
 	stream.WriteString("2.0")
@@ -509,7 +549,6 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 	}
 	stream.WriteObjectEnd()
 	stream.Flush()
-	return nil
 }
 
 // unsubscribe is the callback function for all *_unsubscribe calls.
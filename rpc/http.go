@@ -227,7 +227,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if !s.disableStreaming {
 		stream = jsoniter.NewStream(jsoniter.ConfigDefault, w, 4096)
 	}
-	s.serveSingleRequest(ctx, codec, stream)
+	s.serveSingleRequest(ctx, codec, stream, s.rateLimit.forRequest(r))
 }
 
 // validateRequest returns a non-zero response code and error message if the
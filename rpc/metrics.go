@@ -25,6 +25,10 @@ import (
 var (
 	rpcRequestGauge    = metrics.GetOrCreateCounter("rpc_total")
 	failedReqeustGauge = metrics.GetOrCreateCounter("rpc_failure")
+
+	rateLimitedCounter      = metrics.GetOrCreateCounter(`rpc_rejected{reason="rate_limit"}`)
+	batchTooLongCounter     = metrics.GetOrCreateCounter(`rpc_rejected{reason="batch_length"}`)
+	responseTooLargeCounter = metrics.GetOrCreateCounter(`rpc_rejected{reason="response_size"}`)
 )
 
 func newRPCServingTimerMS(method string, valid bool) *metrics.Summary {
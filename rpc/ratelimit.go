@@ -0,0 +1,203 @@
+package rpc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig is the policy of the rpc server towards its clients, it is read from the --rpc.ratelimit file:
+//
+//	{
+//	    "perAddress": {"requestsPerSecond": 50, "burst": 100},
+//	    "perSubject": {"requestsPerSecond": 500, "burst": 1000},
+//	    "methodCosts": {"debug_traceTransaction": 20, "eth_getLogs": 10},
+//	    "maxBatchLength": 100,
+//	    "maxResponseSize": 25000000
+//	}
+//
+// Clients that present a JWT signed with the server's secret and carrying a `sub` claim get the perSubject bucket of
+// that subject, all others share the perAddress bucket of their IP address. Zero values mean no limit.
+type RateLimitConfig struct {
+	PerAddress      TokenBucket    `json:"perAddress"`
+	PerSubject      TokenBucket    `json:"perSubject"`
+	MethodCosts     map[string]int `json:"methodCosts"` // tokens taken by a call, 1 for the methods not listed
+	MaxBatchLength  int            `json:"maxBatchLength"`
+	MaxResponseSize int            `json:"maxResponseSize"` // in bytes
+}
+
+type TokenBucket struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+}
+
+func (b TokenBucket) unlimited() bool { return b.RequestsPerSecond <= 0 }
+
+// Validate checks that every method can be called at least once with a full bucket
+func (c *RateLimitConfig) Validate() error {
+	for _, bucket := range []TokenBucket{c.PerAddress, c.PerSubject} {
+		if bucket.unlimited() {
+			continue
+		}
+		if bucket.Burst <= 0 {
+			return fmt.Errorf("burst must be positive when requestsPerSecond is set")
+		}
+		for method, cost := range c.MethodCosts {
+			if cost < 0 || cost > bucket.Burst {
+				return fmt.Errorf("cost %d of %s is not within [0, %d]", cost, method, bucket.Burst)
+			}
+		}
+	}
+	if c.MaxBatchLength < 0 || c.MaxResponseSize < 0 {
+		return fmt.Errorf("maxBatchLength and maxResponseSize must not be negative")
+	}
+	return nil
+}
+
+// rateLimitSweepInterval is how often the buckets of the clients that went quiet are dropped
+const rateLimitSweepInterval = time.Minute
+
+// rateLimiter keeps a token bucket per client, it is shared by all connections of a server
+type rateLimiter struct {
+	cfg       RateLimitConfig
+	jwtSecret []byte
+
+	lock      sync.Mutex
+	buckets   map[string]*clientBucket
+	lastSweep time.Time
+}
+
+type clientBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+	refill   time.Duration // time it takes for an empty bucket to be full again
+}
+
+func newRateLimiter(cfg RateLimitConfig, jwtSecret []byte) *rateLimiter {
+	return &rateLimiter{cfg: cfg, jwtSecret: jwtSecret, buckets: map[string]*clientBucket{}, lastSweep: time.Now()}
+}
+
+// forRequest identifies the client of an http or websocket request, by the subject of its JWT if there is a valid one
+func (l *rateLimiter) forRequest(r *http.Request) *clientLimits {
+	if l == nil {
+		return nil
+	}
+	if subject := l.jwtSubject(r); subject != "" {
+		return &clientLimits{rateLimiter: l, key: "sub:" + subject, bucket: l.cfg.PerSubject}
+	}
+	return l.forAddress(r.RemoteAddr)
+}
+
+func (l *rateLimiter) forAddress(addr string) *clientLimits {
+	if l == nil {
+		return nil
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return &clientLimits{rateLimiter: l, key: "addr:" + addr, bucket: l.cfg.PerAddress}
+}
+
+func (l *rateLimiter) jwtSubject(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if l.jwtSecret == nil || !strings.HasPrefix(auth, "Bearer ") {
+		return ""
+	}
+	claims := jwt.RegisteredClaims{}
+	// The claims are validated as well, so that a token past its `exp` or before its `nbf` falls back to the
+	// bucket of the address
+	token, err := jwt.ParseWithClaims(strings.TrimPrefix(auth, "Bearer "), &claims, func(token *jwt.Token) (interface{}, error) {
+		return l.jwtSecret, nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	if err != nil || !token.Valid {
+		return ""
+	}
+	return claims.Subject
+}
+
+func (l *rateLimiter) allow(key string, bucket TokenBucket, cost int) bool {
+	now := time.Now()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if now.Sub(l.lastSweep) > rateLimitSweepInterval {
+		// A bucket that had the time to refill is no different from a new one
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > b.refill {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &clientBucket{
+			limiter: rate.NewLimiter(rate.Limit(bucket.RequestsPerSecond), bucket.Burst),
+			refill:  time.Duration(float64(bucket.Burst) / bucket.RequestsPerSecond * float64(time.Second)),
+		}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b.limiter.AllowN(now, cost)
+}
+
+// clientLimits is the policy applied by the handler of one connection, nil means no limits
+type clientLimits struct {
+	*rateLimiter
+	key    string
+	bucket TokenBucket
+}
+
+// allowCall takes the cost of the method from the bucket of the client
+func (c *clientLimits) allowCall(method string) bool {
+	if c == nil || c.bucket.unlimited() {
+		return true
+	}
+	cost, ok := c.cfg.MethodCosts[method]
+	if !ok {
+		cost = 1
+	}
+	return c.allow(c.key, c.bucket, cost)
+}
+
+func (c *clientLimits) maxBatchLength() int {
+	if c == nil {
+		return 0
+	}
+	return c.cfg.MaxBatchLength
+}
+
+func (c *clientLimits) maxResponseSize() int {
+	if c == nil {
+		return 0
+	}
+	return c.cfg.MaxResponseSize
+}
+
+// limitedWriter holds back a streamed response and calls onOverflow once it grows past limit. The response is
+// dropped then, and the rest of it is discarded while the stopped method returns.
+type limitedWriter struct {
+	buf        []byte
+	limit      int
+	overflow   bool
+	onOverflow func()
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.overflow {
+		return len(p), nil
+	}
+	if len(w.buf)+len(p) > w.limit {
+		w.overflow = true
+		w.buf = nil
+		w.onOverflow()
+		return len(p), nil
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
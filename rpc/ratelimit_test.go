@@ -0,0 +1,194 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+type streamService struct{}
+
+func (streamService) Items(ctx context.Context, n int, stream *jsoniter.Stream) error {
+	stream.WriteArrayStart()
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			stream.WriteArrayEnd()
+			return ctx.Err()
+		}
+		if i > 0 {
+			stream.WriteMore()
+		}
+		stream.WriteString("item")
+		stream.Flush()
+	}
+	stream.WriteArrayEnd()
+	return nil
+}
+
+// Unclosed leaves the array open when it is stopped
+func (streamService) Unclosed(ctx context.Context, stream *jsoniter.Stream) error {
+	stream.WriteArrayStart()
+	for i := 0; ctx.Err() == nil; i++ {
+		if i > 0 {
+			stream.WriteMore()
+		}
+		stream.WriteString("item")
+		stream.Flush()
+	}
+	return ctx.Err()
+}
+
+func newRateLimitedServer(t *testing.T, cfg *RateLimitConfig, jwtSecret []byte) *httptest.Server {
+	s := newTestServer()
+	require.NoError(t, s.RegisterName("large", largeRespService{1000}))
+	require.NoError(t, s.RegisterName("stream", streamService{}))
+	require.NoError(t, s.SetRateLimit(cfg, jwtSecret))
+	ts := httptest.NewServer(s)
+	t.Cleanup(func() {
+		ts.Close()
+		s.Stop()
+	})
+	return ts
+}
+
+func requireErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	require.Error(t, err)
+	rpcErr, ok := err.(Error)
+	require.True(t, ok, "not a json-rpc error: %v", err)
+	require.Equal(t, code, rpcErr.ErrorCode())
+}
+
+func TestRateLimitPerAddress(t *testing.T) {
+	ts := newRateLimitedServer(t, &RateLimitConfig{
+		PerAddress:  TokenBucket{RequestsPerSecond: 0.001, Burst: 3},
+		MethodCosts: map[string]int{"test_rets": 2},
+	}, nil)
+	c, err := DialHTTP(ts.URL)
+	require.NoError(t, err)
+	defer c.Close()
+
+	var res string
+	require.NoError(t, c.Call(&res, "test_rets"))
+	require.NoError(t, c.Call(nil, "test_noArgsRets"))
+	requireErrorCode(t, c.Call(nil, "test_noArgsRets"), -32005)
+
+	// All connections from the same address share the bucket
+	other, err := DialHTTP(ts.URL)
+	require.NoError(t, err)
+	defer other.Close()
+	requireErrorCode(t, other.Call(nil, "test_noArgsRets"), -32005)
+}
+
+func TestRateLimitPerSubject(t *testing.T) {
+	secret := []byte("secret")
+	ts := newRateLimitedServer(t, &RateLimitConfig{
+		PerAddress: TokenBucket{RequestsPerSecond: 0.001, Burst: 1},
+		PerSubject: TokenBucket{RequestsPerSecond: 0.001, Burst: 2},
+	}, secret)
+	call := func(subject string, expiresAt *jwt.NumericDate) string {
+		request, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"test_noArgsRets"}`))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", contentType)
+		if subject != "" {
+			claims := jwt.RegisteredClaims{Subject: subject, ExpiresAt: expiresAt}
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	require.NotContains(t, call("", nil), "error")
+	require.Contains(t, call("", nil), "-32005")
+	for _, subject := range []string{"alice", "bob"} {
+		require.NotContains(t, call(subject, nil), "error")
+		require.NotContains(t, call(subject, nil), "error")
+		require.Contains(t, call(subject, nil), "-32005")
+	}
+
+	// An expired token does not get the bucket of its subject, it shares the one of the address
+	require.Contains(t, call("carol", jwt.NewNumericDate(time.Now().Add(-time.Minute))), "-32005")
+	require.NotContains(t, call("carol", jwt.NewNumericDate(time.Now().Add(time.Minute))), "error")
+}
+
+func TestRateLimitBatchLength(t *testing.T) {
+	ts := newRateLimitedServer(t, &RateLimitConfig{MaxBatchLength: 2}, nil)
+	c, err := DialHTTP(ts.URL)
+	require.NoError(t, err)
+	defer c.Close()
+
+	batch := []BatchElem{{Method: "test_noArgsRets"}, {Method: "test_noArgsRets"}}
+	require.NoError(t, c.BatchCall(batch))
+	for _, elem := range batch {
+		require.NoError(t, elem.Error)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`[
+		{"jsonrpc":"2.0","id":1,"method":"test_noArgsRets"},
+		{"jsonrpc":"2.0","id":2,"method":"test_noArgsRets"},
+		{"jsonrpc":"2.0","id":3,"method":"test_noArgsRets"}]`))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `"code":-32600`)
+	require.Contains(t, string(body), "batch too long")
+}
+
+func TestRateLimitResponseSize(t *testing.T) {
+	ts := newRateLimitedServer(t, &RateLimitConfig{MaxResponseSize: 500}, nil)
+	c, err := DialHTTP(ts.URL)
+	require.NoError(t, err)
+	defer c.Close()
+
+	var res string
+	requireErrorCode(t, c.Call(&res, "large_largeResp"), -32003)
+
+	var items []string
+	require.NoError(t, c.Call(&items, "stream_items", 10))
+	require.Len(t, items, 10)
+	requireErrorCode(t, c.Call(&items, "stream_items", 1000), -32003)
+
+	// The streamed response is cut short instead of being produced in full
+	request, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"stream_items","params":[1000000]}`))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Less(t, len(body), 1000)
+	require.True(t, json.Valid(body), string(body))
+	require.Contains(t, string(body), `"code":-32003`)
+
+	// The partial result of a method which does not close it is not sent
+	requireErrorCode(t, c.Call(&items, "stream_unclosed"), -32003)
+}
+
+func TestRateLimitConfigValidate(t *testing.T) {
+	require.NoError(t, (&RateLimitConfig{MethodCosts: map[string]int{"eth_getLogs": 100}}).Validate())
+	require.Error(t, (&RateLimitConfig{PerAddress: TokenBucket{RequestsPerSecond: 1}}).Validate())
+	require.Error(t, (&RateLimitConfig{
+		PerAddress:  TokenBucket{RequestsPerSecond: 1, Burst: 10},
+		MethodCosts: map[string]int{"eth_getLogs": 100},
+	}).Validate())
+}
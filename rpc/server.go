@@ -45,6 +45,7 @@ const (
 type Server struct {
 	services        serviceRegistry
 	methodAllowList AllowList
+	rateLimit       *rateLimiter
 	idgen           func() ID
 	run             int32
 	codecs          mapset.Set
@@ -69,6 +70,16 @@ func (s *Server) SetAllowList(allowList AllowList) {
 	s.methodAllowList = allowList
 }
 
+// SetRateLimit makes the server enforce cfg on its http and websocket clients. jwtSecret, if not nil, is used to
+// recognise the clients that identify themselves with the subject of a JWT.
+func (s *Server) SetRateLimit(cfg *RateLimitConfig, jwtSecret []byte) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	s.rateLimit = newRateLimiter(*cfg, jwtSecret)
+	return nil
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -83,6 +94,10 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 //
 // Note that codec options are no longer supported.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
+	s.serveCodec(codec, nil /* limits */)
+}
+
+func (s *Server) serveCodec(codec ServerCodec, limits *clientLimits) {
 	defer codec.close()
 
 	// Don't serve if server is stopped.
@@ -94,7 +109,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services)
+	c := initClient(codec, s.idgen, &s.services, limits)
 	<-codec.closed()
 	c.Close()
 }
@@ -102,13 +117,13 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 // serveSingleRequest reads and processes a single RPC request from the given codec. This
 // is used to serve HTTP connections. Subscriptions and reverse calls are not allowed in
 // this mode.
-func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec, stream *jsoniter.Stream) {
+func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec, stream *jsoniter.Stream, limits *clientLimits) {
 	// Don't serve if server is stopped.
 	if atomic.LoadInt32(&s.run) == 0 {
 		return
 	}
 
-	h := newHandler(ctx, codec, s.idgen, &s.services, s.methodAllowList, s.batchConcurrency, s.traceRequests)
+	h := newHandler(ctx, codec, s.idgen, &s.services, s.methodAllowList, limits, s.batchConcurrency, s.traceRequests)
 	h.allowSubscribe = false
 	defer h.close(io.EOF, nil)
 
@@ -0,0 +1,473 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package apitypes implements hashing of EIP-712 typed structured data,
+// as used by eth_signTypedData_v4.
+package apitypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/common/math"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+const domainTypeName = "EIP712Domain"
+
+var typedDataReferenceTypeRegexp = regexp.MustCompile(`^[A-Za-z](\w*)(\[\d*\])*$`)
+
+// Type is a single field of a struct type: its name and its solidity type
+type Type struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// isArray returns true if the field is an array (of fixed or dynamic length)
+func (t *Type) isArray() bool {
+	return strings.HasSuffix(t.Type, "]")
+}
+
+// Types are the struct definitions referenced by the typed data, keyed by struct name
+type Types map[string][]Type
+
+// TypedDataDomain represents the domain part of an EIP-712 message.
+type TypedDataDomain struct {
+	Name              string                `json:"name"`
+	Version           string                `json:"version"`
+	ChainId           *math.HexOrDecimal256 `json:"chainId"`
+	VerifyingContract string                `json:"verifyingContract"`
+	Salt              string                `json:"salt"`
+}
+
+// TypedData is a type to encapsulate EIP-712 typed messages
+type TypedData struct {
+	Types       Types                  `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      TypedDataDomain        `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// UnmarshalJSON accepts the chain id both as a JSON number and as a (hex or decimal) string,
+// wallets use either form
+func (domain *TypedDataDomain) UnmarshalJSON(input []byte) error {
+	type typedDataDomain TypedDataDomain
+	var dec struct {
+		typedDataDomain
+		ChainId json.RawMessage `json:"chainId"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*domain = TypedDataDomain(dec.typedDataDomain)
+	domain.ChainId = nil
+	raw := bytes.Trim(dec.ChainId, `"`)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	chainId := new(math.HexOrDecimal256)
+	if err := chainId.UnmarshalText(raw); err != nil {
+		return fmt.Errorf("invalid chainId: %w", err)
+	}
+	domain.ChainId = chainId
+	return nil
+}
+
+// Map is a helper function to generate a map version of the domain: only the fields
+// which are set take part in the domain separator
+func (domain *TypedDataDomain) Map() map[string]interface{} {
+	dataMap := map[string]interface{}{}
+	if domain.ChainId != nil {
+		dataMap["chainId"] = (*big.Int)(domain.ChainId)
+	}
+	if len(domain.Name) > 0 {
+		dataMap["name"] = domain.Name
+	}
+	if len(domain.Version) > 0 {
+		dataMap["version"] = domain.Version
+	}
+	if len(domain.VerifyingContract) > 0 {
+		dataMap["verifyingContract"] = domain.VerifyingContract
+	}
+	if len(domain.Salt) > 0 {
+		dataMap["salt"] = domain.Salt
+	}
+	return dataMap
+}
+
+// types returns the EIP712Domain definition matching the fields which are set,
+// used when the request does not declare the domain type explicitly
+func (domain *TypedDataDomain) types() []Type {
+	var fields []Type
+	if len(domain.Name) > 0 {
+		fields = append(fields, Type{Name: "name", Type: "string"})
+	}
+	if len(domain.Version) > 0 {
+		fields = append(fields, Type{Name: "version", Type: "string"})
+	}
+	if domain.ChainId != nil {
+		fields = append(fields, Type{Name: "chainId", Type: "uint256"})
+	}
+	if len(domain.VerifyingContract) > 0 {
+		fields = append(fields, Type{Name: "verifyingContract", Type: "address"})
+	}
+	if len(domain.Salt) > 0 {
+		fields = append(fields, Type{Name: "salt", Type: "bytes32"})
+	}
+	return fields
+}
+
+// TypedDataAndHash is a helper function that calculates a hash for typed data conforming to EIP-712.
+// This hash can then be safely used to calculate a signature.
+//
+// See https://eips.ethereum.org/EIPS/eip-712 for the full specification.
+//
+// This gives the context to the signed typed data and prevents signing of transactions.
+func TypedDataAndHash(typedData TypedData) ([]byte, string, error) {
+	domainSeparator, err := typedData.HashStruct(domainTypeName, typedData.Domain.Map())
+	if err != nil {
+		return nil, "", err
+	}
+	typedDataHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, "", err
+	}
+	rawData := fmt.Sprintf("\x19\x01%s%s", string(domainSeparator), string(typedDataHash))
+	return crypto.Keccak256([]byte(rawData)), rawData, nil
+}
+
+// HashStruct generates a keccak256 hash of the encoding of the provided data
+func (typedData *TypedData) HashStruct(primaryType string, data map[string]interface{}) (hexutil.Bytes, error) {
+	encodedData, err := typedData.EncodeData(primaryType, data)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(encodedData), nil
+}
+
+// fields returns the definition of the struct type, falling back to the domain fields for EIP712Domain
+func (typedData *TypedData) fields(primaryType string) ([]Type, bool) {
+	fields, ok := typedData.Types[primaryType]
+	if !ok && primaryType == domainTypeName {
+		return typedData.Domain.types(), true
+	}
+	return fields, ok
+}
+
+// Dependencies returns an array of custom types ordered by their hierarchical reference tree
+func (typedData *TypedData) Dependencies(primaryType string, found []string) []string {
+	if i := strings.IndexByte(primaryType, '['); i >= 0 {
+		primaryType = primaryType[:i]
+	}
+	for _, dep := range found {
+		if dep == primaryType {
+			return found
+		}
+	}
+	fields, ok := typedData.fields(primaryType)
+	if !ok {
+		return found
+	}
+	found = append(found, primaryType)
+	for _, field := range fields {
+		for _, dep := range typedData.Dependencies(field.Type, found) {
+			if !contains(found, dep) {
+				found = append(found, dep)
+			}
+		}
+	}
+	return found
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeType generates the following encoding:
+// `name ‖ "(" ‖ member₁ ‖ "," ‖ member₂ ‖ "," ‖ … ‖ memberₙ ")"`
+//
+// each member is written as `type ‖ " " ‖ name` encodings cascade down and are sorted by name
+func (typedData *TypedData) EncodeType(primaryType string) hexutil.Bytes {
+	// Get dependencies primary first, then alphabetical
+	deps := typedData.Dependencies(primaryType, []string{})
+	if len(deps) > 0 {
+		slicedDeps := deps[1:]
+		sort.Strings(slicedDeps)
+		deps = append([]string{primaryType}, slicedDeps...)
+	}
+
+	// Format as a string with fields
+	var buffer bytes.Buffer
+	for _, dep := range deps {
+		fields, _ := typedData.fields(dep)
+		buffer.WriteString(dep)
+		buffer.WriteString("(")
+		for i, obj := range fields {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			buffer.WriteString(obj.Type)
+			buffer.WriteString(" ")
+			buffer.WriteString(obj.Name)
+		}
+		buffer.WriteString(")")
+	}
+	return buffer.Bytes()
+}
+
+// TypeHash creates the keccak256 hash  of the data
+func (typedData *TypedData) TypeHash(primaryType string) hexutil.Bytes {
+	return crypto.Keccak256(typedData.EncodeType(primaryType))
+}
+
+// EncodeData generates the following encoding:
+// `enc(value₁) ‖ enc(value₂) ‖ … ‖ enc(valueₙ)`
+//
+// each encoded member is 32-byte long
+func (typedData *TypedData) EncodeData(primaryType string, data map[string]interface{}) (hexutil.Bytes, error) {
+	fields, ok := typedData.fields(primaryType)
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", primaryType)
+	}
+	if exp, got := len(fields), len(data); exp < got {
+		return nil, fmt.Errorf("there is extra data provided in the message (%d < %d)", exp, got)
+	}
+
+	buffer := bytes.Buffer{}
+	// Add typehash
+	buffer.Write(typedData.TypeHash(primaryType))
+
+	// Add field contents. Structs and arrays have special handlers.
+	for _, field := range fields {
+		encValue, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing value for field %q of type %s", field.Name, primaryType)
+		}
+		encoded, err := typedData.encodeValue(field, encValue)
+		if err != nil {
+			return nil, fmt.Errorf("field %q of type %s: %w", field.Name, primaryType, err)
+		}
+		buffer.Write(encoded)
+	}
+	return buffer.Bytes(), nil
+}
+
+func (typedData *TypedData) encodeValue(field Type, encValue interface{}) ([]byte, error) {
+	if field.isArray() {
+		arrayValue, ok := encValue.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected array, got %T", encValue)
+		}
+		elemType := Type{Name: field.Name, Type: field.Type[:strings.LastIndexByte(field.Type, '[')]}
+		var arrayBuffer bytes.Buffer
+		for _, item := range arrayValue {
+			encoded, err := typedData.encodeValue(elemType, item)
+			if err != nil {
+				return nil, err
+			}
+			arrayBuffer.Write(encoded)
+		}
+		return crypto.Keccak256(arrayBuffer.Bytes()), nil
+	}
+	if _, ok := typedData.Types[field.Type]; ok {
+		mapValue, ok := encValue.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected object, got %T", encValue)
+		}
+		encoded, err := typedData.EncodeData(field.Type, mapValue)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(encoded), nil
+	}
+	return typedData.EncodePrimitiveValue(field.Type, encValue)
+}
+
+// EncodePrimitiveValue deals with the primitive values found
+// while searching through the typed data
+func (typedData *TypedData) EncodePrimitiveValue(encType string, encValue interface{}) ([]byte, error) {
+	switch encType {
+	case "address":
+		stringValue, ok := encValue.(string)
+		if !ok || !common.IsHexAddress(stringValue) {
+			return nil, fmt.Errorf("invalid address value %v", encValue)
+		}
+		retval := make([]byte, 32)
+		copy(retval[12:], common.HexToAddress(stringValue).Bytes())
+		return retval, nil
+	case "bool":
+		boolValue, ok := encValue.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid bool value %v", encValue)
+		}
+		if boolValue {
+			return math.PaddedBigBytes(common.Big1, 32), nil
+		}
+		return math.PaddedBigBytes(common.Big0, 32), nil
+	case "string":
+		strVal, ok := encValue.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid string value %v", encValue)
+		}
+		return crypto.Keccak256([]byte(strVal)), nil
+	case "bytes":
+		bytesValue, err := parseBytes(encValue)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(bytesValue), nil
+	}
+	if strings.HasPrefix(encType, "bytes") {
+		length, err := strconv.Atoi(strings.TrimPrefix(encType, "bytes"))
+		if err != nil || length < 1 || length > 32 {
+			return nil, fmt.Errorf("invalid size on bytes: %s", encType)
+		}
+		bytesValue, err := parseBytes(encValue)
+		if err != nil {
+			return nil, err
+		}
+		if len(bytesValue) > length {
+			return nil, fmt.Errorf("%s value too long: %d bytes", encType, len(bytesValue))
+		}
+		return common.RightPadBytes(bytesValue, 32), nil
+	}
+	if strings.HasPrefix(encType, "int") || strings.HasPrefix(encType, "uint") {
+		b, err := parseInteger(encType, encValue)
+		if err != nil {
+			return nil, err
+		}
+		return math.U256Bytes(b), nil
+	}
+	return nil, fmt.Errorf("unrecognized type %q", encType)
+}
+
+func parseBytes(encValue interface{}) ([]byte, error) {
+	switch v := encValue.(type) {
+	case []byte:
+		return v, nil
+	case hexutil.Bytes:
+		return v, nil
+	case string:
+		return hexutil.Decode(v)
+	}
+	return nil, fmt.Errorf("invalid bytes value %v", encValue)
+}
+
+func parseInteger(encType string, encValue interface{}) (*big.Int, error) {
+	var (
+		length int
+		signed = strings.HasPrefix(encType, "int")
+		b      *big.Int
+	)
+	if encType == "int" || encType == "uint" {
+		length = 256
+	} else {
+		lengthStr := strings.TrimPrefix(strings.TrimPrefix(encType, "u"), "int")
+		atoiSize, err := strconv.Atoi(lengthStr)
+		if err != nil || atoiSize < 8 || atoiSize > 256 || atoiSize%8 != 0 {
+			return nil, fmt.Errorf("invalid size on integer: %s", encType)
+		}
+		length = atoiSize
+	}
+	switch v := encValue.(type) {
+	case *math.HexOrDecimal256:
+		b = (*big.Int)(v)
+	case *big.Int:
+		b = v
+	case string:
+		var ok bool
+		if b, ok = math.ParseBig256(v); !ok {
+			// ParseBig256 does not accept negative numbers
+			if b, ok = new(big.Int).SetString(v, 10); !ok {
+				return nil, fmt.Errorf("invalid integer value %v", v)
+			}
+		}
+	case json.Number:
+		var ok bool
+		if b, ok = new(big.Int).SetString(v.String(), 10); !ok {
+			return nil, fmt.Errorf("invalid integer value %v", v)
+		}
+	case float64:
+		// JSON parses non-strings as float64. Fail if we cannot
+		// convert it losslessly
+		if float64(int64(v)) != v {
+			return nil, fmt.Errorf("invalid float value %v for type %v", v, encType)
+		}
+		b = big.NewInt(int64(v))
+	default:
+		return nil, fmt.Errorf("invalid integer value %v/%v for type %v", encValue, encValue, encType)
+	}
+	if !signed {
+		if b.Sign() == -1 {
+			return nil, fmt.Errorf("invalid negative value for unsigned type %v", encType)
+		}
+		if b.BitLen() > length {
+			return nil, fmt.Errorf("integer larger than '%v'", encType)
+		}
+		return new(big.Int).Set(b), nil
+	}
+	// signed: -2^(length-1) <= b < 2^(length-1)
+	limit := new(big.Int).Lsh(common.Big1, uint(length-1))
+	if b.Cmp(limit) >= 0 || b.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return nil, fmt.Errorf("integer out of range for '%v'", encType)
+	}
+	return new(big.Int).Set(b), nil
+}
+
+// Validate checks that the typed data is well formed: all struct types are known, the
+// primary type is declared and field types are valid
+func (typedData *TypedData) Validate() error {
+	if typedData.PrimaryType == "" {
+		return errors.New("primaryType is not set")
+	}
+	if _, ok := typedData.Types[typedData.PrimaryType]; !ok {
+		return fmt.Errorf("primaryType %q is not declared in types", typedData.PrimaryType)
+	}
+	for typeKey, typeArr := range typedData.Types {
+		if len(typeKey) == 0 {
+			return errors.New("empty type key")
+		}
+		for i, typeObj := range typeArr {
+			if len(typeObj.Type) == 0 {
+				return fmt.Errorf("type %q:%d: empty Type", typeKey, i)
+			}
+			if len(typeObj.Name) == 0 {
+				return fmt.Errorf("type %q:%d: empty Name", typeKey, i)
+			}
+			if typeKey == typeObj.Type {
+				return fmt.Errorf("type %q cannot reference itself", typeObj.Type)
+			}
+			if !typedDataReferenceTypeRegexp.MatchString(typeObj.Type) {
+				return fmt.Errorf("type %q:%d: invalid type %q", typeKey, i, typeObj.Type)
+			}
+		}
+	}
+	return nil
+}
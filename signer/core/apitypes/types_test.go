@@ -0,0 +1,132 @@
+package apitypes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common/hexutil"
+)
+
+// Example from https://eips.ethereum.org/EIPS/eip-712
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestTypedDataHash(t *testing.T) {
+	var typedData TypedData
+	if err := json.Unmarshal([]byte(mailTypedData), &typedData); err != nil {
+		t.Fatal(err)
+	}
+	if err := typedData.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := string(typedData.EncodeType("Mail")), "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; have != want {
+		t.Fatalf("wrong type encoding: have %s, want %s", have, want)
+	}
+	if have, want := typedData.TypeHash("Mail").String(), "0xa0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"; have != want {
+		t.Fatalf("wrong type hash: have %s, want %s", have, want)
+	}
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := domainSeparator.String(), "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"; have != want {
+		t.Fatalf("wrong domain separator: have %s, want %s", have, want)
+	}
+	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := messageHash.String(), "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"; have != want {
+		t.Fatalf("wrong message hash: have %s, want %s", have, want)
+	}
+	sighash, _, err := TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := hexutil.Encode(sighash), "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; have != want {
+		t.Fatalf("wrong signing hash: have %s, want %s", have, want)
+	}
+
+	// Domain type may be omitted, it is then derived from the domain fields
+	delete(typedData.Types, "EIP712Domain")
+	sighash2, _, err := TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hexutil.Encode(sighash2) != hexutil.Encode(sighash) {
+		t.Fatalf("derived domain type gives a different hash")
+	}
+}
+
+func TestTypedDataEncodeErrors(t *testing.T) {
+	var typedData TypedData
+	if err := json.Unmarshal([]byte(mailTypedData), &typedData); err != nil {
+		t.Fatal(err)
+	}
+	typedData.Message["contents"] = 5
+	if _, _, err := TypedDataAndHash(typedData); err == nil {
+		t.Fatal("expected an error for a non-string value of a string field")
+	}
+	delete(typedData.Message, "contents")
+	if _, _, err := TypedDataAndHash(typedData); err == nil {
+		t.Fatal("expected an error for a missing field")
+	}
+
+	for _, tc := range []struct {
+		typ   string
+		value interface{}
+		ok    bool
+	}{
+		{"uint8", float64(255), true},
+		{"uint8", float64(256), false},
+		{"uint8", float64(-1), false},
+		{"int8", float64(-128), true},
+		{"int8", float64(128), false},
+		{"int8", "-129", false},
+		{"int16", "-32768", true},
+		{"uint256", "0xff", true},
+		{"uint7", float64(1), false},
+		{"bytes4", "0x01020304", true},
+		{"bytes4", "0x0102030405", false},
+		{"bytes33", "0x01", false},
+		{"bool", "true", false},
+		{"address", "0x01", false},
+	} {
+		_, err := typedData.EncodePrimitiveValue(tc.typ, tc.value)
+		if tc.ok && err != nil {
+			t.Errorf("%s %v: unexpected error %v", tc.typ, tc.value, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%s %v: expected an error", tc.typ, tc.value)
+		}
+	}
+}
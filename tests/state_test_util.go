@@ -184,7 +184,7 @@ func (t *StateTest) RunNoVerify(rules *params.Rules, tx kv.RwTx, subtest StateSu
 	if err != nil {
 		return nil, common.Hash{}, err
 	}
-	msg, err := txn.AsMessage(*types.MakeSigner(config, 0), baseFee, config.Rules(0))
+	msg, err := txn.AsMessage(*types.MakeSigner(config, 0), baseFee, config.Rules(0, t.json.Env.Timestamp))
 	if err != nil {
 		return nil, common.Hash{}, err
 	}
@@ -48,6 +48,7 @@ var snapshotCommand = cli.Command{
 				SnapshotFromFlag,
 				SnapshotToFlag,
 				SnapshotSegmentSizeFlag,
+				SnapshotReceiptsFlag,
 			}, debug.Flags...),
 		},
 		{
@@ -71,6 +72,7 @@ var snapshotCommand = cli.Command{
 				SnapshotFromFlag,
 				SnapshotToFlag,
 				SnapshotEveryFlag,
+				SnapshotReceiptsFlag,
 			}, debug.Flags...),
 		},
 		{
@@ -114,6 +116,10 @@ var (
 		Name:  "rebuild",
 		Usage: "Force rebuild",
 	}
+	SnapshotReceiptsFlag = cli.BoolFlag{
+		Name:  "receipts",
+		Usage: "Also create snapshots of the receipts and logs of the blocks",
+	}
 )
 
 func doIndicesCommand(cliCtx *cli.Context) error {
@@ -237,6 +243,7 @@ func doRetireCommand(cliCtx *cli.Context) error {
 	defer db.Close()
 
 	cfg := ethconfig.NewSnapCfg(true, true, true)
+	cfg.Receipts = cliCtx.Bool(SnapshotReceiptsFlag.Name)
 	snapshots := snapshotsync.NewRoSnapshots(cfg, dirs.Snap)
 	if err := snapshots.ReopenWithDB(db); err != nil {
 		return err
@@ -289,7 +296,8 @@ func doSnapshotCommand(cliCtx *cli.Context) error {
 	db := mdbx.NewMDBX(log.New()).Label(kv.ChainDB).Path(dirs.Chaindata).MustOpen()
 	defer db.Close()
 
-	if err := snapshotBlocks(ctx, db, fromBlock, toBlock, segmentSize, dirs.Snap, dirs.Tmp); err != nil {
+	withReceipts := cliCtx.Bool(SnapshotReceiptsFlag.Name)
+	if err := snapshotBlocks(ctx, db, fromBlock, toBlock, segmentSize, dirs.Snap, dirs.Tmp, withReceipts); err != nil {
 		log.Error("Error", "err", err)
 	}
 
@@ -314,7 +322,7 @@ func rebuildIndices(ctx context.Context, db kv.RoDB, cfg ethconfig.Snapshot, dir
 	return nil
 }
 
-func snapshotBlocks(ctx context.Context, db kv.RoDB, fromBlock, toBlock, blocksPerFile uint64, snapDir, tmpDir string) error {
+func snapshotBlocks(ctx context.Context, db kv.RoDB, fromBlock, toBlock, blocksPerFile uint64, snapDir, tmpDir string, withReceipts bool) error {
 	var last uint64
 
 	if toBlock > 0 {
@@ -349,7 +357,7 @@ func snapshotBlocks(ctx context.Context, db kv.RoDB, fromBlock, toBlock, blocksP
 
 	log.Info("Last body number", "last", last)
 	workers := cmp.Max(1, runtime.GOMAXPROCS(-1)-1)
-	if err := snapshotsync.DumpBlocks(ctx, fromBlock, last, blocksPerFile, tmpDir, snapDir, db, workers, log.LvlInfo); err != nil {
+	if err := snapshotsync.DumpBlocks(ctx, fromBlock, last, blocksPerFile, tmpDir, snapDir, db, workers, withReceipts, log.LvlInfo); err != nil {
 		return fmt.Errorf("DumpBlocks: %w", err)
 	}
 	return nil
@@ -9,14 +9,14 @@ import (
 	"github.com/ledgerwatch/log/v3"
 )
 
-type BlockBuilderFunc func(param *core.BlockBuilderParameters, interrupt *int32) (*types.Block, error)
+type BlockBuilderFunc func(param *core.BlockBuilderParameters, interrupt *int32) (*types.BlockWithReceipts, error)
 
 // BlockBuilder wraps a goroutine that builds Proof-of-Stake payloads (PoS "mining")
 type BlockBuilder struct {
 	emptyHeader *types.Header
 	interrupt   int32
 	syncCond    *sync.Cond
-	block       *types.Block
+	result      *types.BlockWithReceipts
 	err         error
 }
 
@@ -26,11 +26,11 @@ func NewBlockBuilder(build BlockBuilderFunc, param *core.BlockBuilderParameters,
 	b.syncCond = sync.NewCond(new(sync.Mutex))
 
 	go func() {
-		block, err := build(param, &b.interrupt)
+		result, err := build(param, &b.interrupt)
 
 		b.syncCond.L.Lock()
 		defer b.syncCond.L.Unlock()
-		b.block = block
+		b.result = result
 		b.err = err
 		b.syncCond.Broadcast()
 	}()
@@ -38,19 +38,23 @@ func NewBlockBuilder(build BlockBuilderFunc, param *core.BlockBuilderParameters,
 	return b
 }
 
-func (b *BlockBuilder) Stop() *types.Block {
+func (b *BlockBuilder) Stop() *types.BlockWithReceipts {
 	atomic.StoreInt32(&b.interrupt, 1)
 
 	b.syncCond.L.Lock()
 	defer b.syncCond.L.Unlock()
-	for b.block == nil && b.err == nil {
+	for b.result == nil && b.err == nil {
 		b.syncCond.Wait()
 	}
 
 	if b.err != nil {
 		log.Error("BlockBuilder", "err", b.err)
-		return types.NewBlock(b.emptyHeader, nil, nil, nil)
+		if b.emptyHeader.WithdrawalsHash != nil {
+			// post-Shanghai block must have a (possibly empty) list of withdrawals
+			return &types.BlockWithReceipts{Block: types.NewBlockWithWithdrawals(b.emptyHeader, nil, nil, nil, []*types.Withdrawal{})}
+		}
+		return &types.BlockWithReceipts{Block: types.NewBlock(b.emptyHeader, nil, nil, nil)}
 	}
 
-	return b.block
+	return b.result
 }
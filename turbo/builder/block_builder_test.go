@@ -0,0 +1,32 @@
+package builder
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopReturnsEmptyBlockOnError(t *testing.T) {
+	failing := func(param *core.BlockBuilderParameters, interrupt *int32) (*types.BlockWithReceipts, error) {
+		return nil, errors.New("failed")
+	}
+
+	preShanghai := &types.Header{Number: big.NewInt(1)}
+	block := NewBlockBuilder(failing, &core.BlockBuilderParameters{}, preShanghai).Stop().Block
+	assert.Nil(t, block.Withdrawals())
+	assert.Nil(t, block.Header().WithdrawalsHash)
+
+	postShanghai := &types.Header{Number: big.NewInt(1), WithdrawalsHash: new(common.Hash)}
+	*postShanghai.WithdrawalsHash = types.EmptyRootHash
+	block = NewBlockBuilder(failing, &core.BlockBuilderParameters{Withdrawals: []*types.Withdrawal{}}, postShanghai).Stop().Block
+	assert.NotNil(t, block.Withdrawals())
+	assert.Empty(t, block.Withdrawals())
+	require.NotNil(t, block.Header().WithdrawalsHash)
+	assert.Equal(t, types.EmptyRootHash, *block.Header().WithdrawalsHash)
+}
@@ -34,6 +34,7 @@ var DefaultFlags = []cli.Flag{
 	PruneCallTracesBeforeFlag,
 	BatchSizeFlag,
 	BlockDownloaderWindowFlag,
+	ReceiptsBackfillFlag,
 	DatabaseVerbosityFlag,
 	PrivateApiAddr,
 	PrivateApiRateLimit,
@@ -64,13 +65,23 @@ var DefaultFlags = []cli.Flag{
 	utils.RpcStreamingDisableFlag,
 	utils.DBReadConcurrencyFlag,
 	utils.RpcAccessListFlag,
+	utils.RpcRateLimitFlag,
+	utils.RpcRateLimitJWTSecretFlag,
 	utils.RpcTraceCompatFlag,
 	utils.RpcGasCapFlag,
+	utils.RpcMaxGetProofRewindBlockCountFlag,
+	utils.RpcMaxGetLogsBlockRangeFlag,
+	utils.RpcMaxGetLogsResultsFlag,
+	utils.RpcTraceWorkersFlag,
+	utils.RpcHealthMaxBlocksBehindFlag,
+	utils.KeyStoreDirFlag,
+	utils.LightKDFFlag,
 	utils.StarknetGrpcAddressFlag,
 	utils.TevmFlag,
 	utils.MemoryOverlayFlag,
 	utils.TxpoolApiAddrFlag,
 	utils.TraceMaxtracesFlag,
+	utils.OtsSearchMaxPageSizeFlag,
 	HTTPReadTimeoutFlag,
 	HTTPWriteTimeoutFlag,
 	HTTPIdleTimeoutFlag,
@@ -80,11 +91,13 @@ var DefaultFlags = []cli.Flag{
 
 	utils.SnapKeepBlocksFlag,
 	utils.SnapStopFlag,
+	utils.SnapReceiptsFlag,
 	utils.DbPageSizeFlag,
 	utils.TorrentPortFlag,
 	utils.TorrentMaxPeersFlag,
 	utils.TorrentConnsPerFileFlag,
 	utils.TorrentDownloadSlotsFlag,
+	utils.WebSeedsFlag,
 	utils.TorrentUploadRateFlag,
 	utils.TorrentDownloadRateFlag,
 	utils.TorrentVerbosityFlag,
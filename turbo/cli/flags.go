@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -44,6 +45,12 @@ var (
 		Usage: "Outstanding limit of block bodies being downloaded",
 		Value: ethconfig.Defaults.Sync.BlockDownloaderWindow,
 	}
+	ReceiptsBackfillFlag = cli.StringFlag{
+		Name: "receipts.backfill",
+		Usage: `Download the missing (pruned) receipts of the blocks <from>-<to> from the peers instead of re-executing the blocks.
+	Receipts are checked against the block headers, the backfilled blocks are not pruned again.
+	The range doesn't include <to>, example: --receipts.backfill=14000000-15000000`,
+	}
 
 	PrivateApiAddr = cli.StringFlag{
 		Name:  "private.api.addr",
@@ -223,6 +230,14 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 	cfg.StateStream = !ctx.GlobalBool(StateStreamDisableFlag.Name)
 	cfg.Sync.BlockDownloaderWindow = ctx.GlobalInt(BlockDownloaderWindowFlag.Name)
 
+	if v := ctx.GlobalString(ReceiptsBackfillFlag.Name); v != "" {
+		from, to, err := parseBlockRange(v)
+		if err != nil {
+			utils.Fatalf("Invalid %s provided: %v", ReceiptsBackfillFlag.Name, err)
+		}
+		cfg.Sync.ReceiptsBackfillFrom, cfg.Sync.ReceiptsBackfillTo = from, to
+	}
+
 	if ctx.GlobalString(SyncLoopThrottleFlag.Name) != "" {
 		syncLoopThrottle, err := time.ParseDuration(ctx.GlobalString(SyncLoopThrottleFlag.Name))
 		if err != nil {
@@ -242,6 +257,24 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 
 }
 
+// parseBlockRange parses "<from>-<to>", to is exclusive
+func parseBlockRange(v string) (from, to uint64, err error) {
+	parts := strings.Split(v, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected <from>-<to>, got %q", v)
+	}
+	if from, err = strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if to, err = strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if from >= to {
+		return 0, 0, fmt.Errorf("empty range %d-%d", from, to)
+	}
+	return from, to, nil
+}
+
 func ApplyFlagsForEthConfigCobra(f *pflag.FlagSet, cfg *ethconfig.Config) {
 	if v := f.String(PruneFlag.Name, PruneFlag.Value, PruneFlag.Usage); v != nil {
 		var experiments []string
@@ -315,6 +348,10 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config) {
 	if jwtSecretPath == "" {
 		jwtSecretPath = cfg.Dirs.DataDir + "/jwt.hex"
 	}
+	keystoreDir := ctx.GlobalString(utils.KeyStoreDirFlag.Name)
+	if keystoreDir == "" {
+		keystoreDir = cfg.Dirs.Keystore
+	}
 	c := &httpcfg.HttpCfg{
 		Enabled: ctx.GlobalBool(utils.HTTPEnabledFlag.Name),
 		Dirs:    cfg.Dirs,
@@ -348,6 +385,8 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config) {
 		RpcStreamingDisable:  ctx.GlobalBool(utils.RpcStreamingDisableFlag.Name),
 		DBReadConcurrency:    ctx.GlobalInt(utils.DBReadConcurrencyFlag.Name),
 		RpcAllowListFilePath: ctx.GlobalString(utils.RpcAccessListFlag.Name),
+		RpcRateLimitFilePath: ctx.GlobalString(utils.RpcRateLimitFlag.Name),
+		RpcRateLimitJWTPath:  ctx.GlobalString(utils.RpcRateLimitJWTSecretFlag.Name),
 		Gascap:               ctx.GlobalUint64(utils.RpcGasCapFlag.Name),
 		MaxTraces:            ctx.GlobalUint64(utils.TraceMaxtracesFlag.Name),
 		TraceCompatibility:   ctx.GlobalBool(utils.RpcTraceCompatFlag.Name),
@@ -356,6 +395,17 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config) {
 
 		TxPoolApiAddr: ctx.GlobalString(utils.TxpoolApiAddrFlag.Name),
 
+		MaxGetProofRewindBlockCount: ctx.GlobalUint64(utils.RpcMaxGetProofRewindBlockCountFlag.Name),
+		OtsMaxPageSize:              ctx.GlobalUint64(utils.OtsSearchMaxPageSizeFlag.Name),
+		MaxGetLogsBlockRange:        ctx.GlobalUint64(utils.RpcMaxGetLogsBlockRangeFlag.Name),
+		MaxGetLogsResults:           ctx.GlobalUint64(utils.RpcMaxGetLogsResultsFlag.Name),
+		TraceWorkers:                ctx.GlobalInt(utils.RpcTraceWorkersFlag.Name),
+		HealthMaxBlocksBehind:       ctx.GlobalUint64(utils.RpcHealthMaxBlocksBehindFlag.Name),
+
+		KeystoreDir:         keystoreDir,
+		KeystoreLightKDF:    ctx.GlobalBool(utils.LightKDFFlag.Name),
+		AllowInsecureUnlock: ctx.GlobalBool(utils.InsecureUnlockAllowedFlag.Name),
+
 		StateCache: kvcache.DefaultCoherentConfig,
 	}
 	if ctx.GlobalIsSet(utils.HttpCompressionFlag.Name) {
@@ -0,0 +1,17 @@
+package engineapi
+
+import (
+	"github.com/holiman/uint256"
+
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	"github.com/ledgerwatch/erigon/core/types"
+)
+
+// ExecutionPayloadV2 is an assembled payload as returned by engine_getPayloadV2.
+// The gRPC ExecutionPayload predates Shanghai, so withdrawals and the block value
+// (the priority fees paid to the fee recipient) are carried alongside it.
+type ExecutionPayloadV2 struct {
+	ExecutionPayload *types2.ExecutionPayload
+	Withdrawals      []*types.Withdrawal
+	BlockValue       *uint256.Int
+}
@@ -37,6 +37,11 @@ func (eri *ErigonNode) Serve() error {
 	return nil
 }
 
+// Backend is the Ethereum service of the node, e.g. to register custom stages in its StagedSync before Serve
+func (eri *ErigonNode) Backend() *eth.Ethereum {
+	return eri.backend
+}
+
 func (eri *ErigonNode) run() {
 	utils.StartNode(eri.stack)
 	// we don't have accounts locally and we don't do mining
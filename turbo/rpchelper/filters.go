@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +13,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/grpcutil"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
@@ -20,6 +22,7 @@ import (
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/filters"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/log/v3"
 	"google.golang.org/grpc"
@@ -31,6 +34,7 @@ type (
 	PendingLogsSubID  SubscriptionID
 	PendingBlockSubID SubscriptionID
 	PendingTxsSubID   SubscriptionID
+	SyncingSubID      SubscriptionID
 	LogsSubID         uint64
 )
 
@@ -43,6 +47,7 @@ type Filters struct {
 	pendingLogsSubs  map[PendingLogsSubID]chan types.Logs
 	pendingBlockSubs map[PendingBlockSubID]chan *types.Block
 	pendingTxsSubs   map[PendingTxsSubID]chan []types.Transaction
+	syncingSubs      map[SyncingSubID]chan *stages.SyncProgress
 	logsSubs         *LogsFilterAggregator
 	logsRequestor    atomic.Value
 	onNewSnapshot    func()
@@ -61,6 +66,7 @@ func New(ctx context.Context, ethBackend ApiBackend, txPool txpool.TxpoolClient,
 		pendingTxsSubs:     make(map[PendingTxsSubID]chan []types.Transaction),
 		pendingLogsSubs:    make(map[PendingLogsSubID]chan types.Logs),
 		pendingBlockSubs:   make(map[PendingBlockSubID]chan *types.Block),
+		syncingSubs:        make(map[SyncingSubID]chan *stages.SyncProgress),
 		logsSubs:           NewLogsFilterAggregator(),
 		onNewSnapshot:      onNewSnapshot,
 		logsStores:         make(map[LogsSubID][]*types.Log),
@@ -324,6 +330,25 @@ func (ff *Filters) UnsubscribeHeads(id HeadsSubID) bool {
 	return false
 }
 
+func (ff *Filters) SubscribeSyncing(out chan *stages.SyncProgress) SyncingSubID {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	id := SyncingSubID(generateSubscriptionID())
+	ff.syncingSubs[id] = out
+	return id
+}
+
+func (ff *Filters) UnsubscribeSyncing(id SyncingSubID) bool {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	if ch, ok := ff.syncingSubs[id]; ok {
+		close(ch)
+		delete(ff.syncingSubs, id)
+		return true
+	}
+	return false
+}
+
 func (ff *Filters) SubscribePendingLogs(c chan types.Logs) PendingLogsSubID {
 	ff.mu.Lock()
 	defer ff.mu.Unlock()
@@ -468,6 +493,16 @@ func (ff *Filters) OnNewEvent(event *remote.SubscribeReply) {
 		}
 	case remote.Event_NEW_SNAPSHOT:
 		ff.onNewSnapshot()
+	case remote.Event_SYNC_PROGRESS:
+		var progress stages.SyncProgress
+		if err := json.Unmarshal(event.Data, &progress); err != nil {
+			// ignoring what we can't unmarshal
+			log.Warn("OnNewEvent rpc filters (sync progress), unprocessable payload", "err", err)
+		} else {
+			for _, v := range ff.syncingSubs {
+				libcommon.PrioritizedSend(v, &progress)
+			}
+		}
 	//case remote.Event_PENDING_LOGS:
 	//	payload := event.Data
 	//	var logs types.Logs
@@ -10,6 +10,7 @@ import (
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/turbo/engineapi"
 )
 
 // ApiBackend - interface which must be used by API layer
@@ -27,6 +28,13 @@ type ApiBackend interface {
 	EngineNewPayloadV1(ctx context.Context, payload *types2.ExecutionPayload) (*remote.EnginePayloadStatus, error)
 	EngineForkchoiceUpdatedV1(ctx context.Context, request *remote.EngineForkChoiceUpdatedRequest) (*remote.EngineForkChoiceUpdatedReply, error)
 	EngineGetPayloadV1(ctx context.Context, payloadId uint64) (*types2.ExecutionPayload, error)
+	EngineNewPayloadV2(ctx context.Context, payload *types2.ExecutionPayload, withdrawals []*types.Withdrawal) (*remote.EnginePayloadStatus, error)
+	EngineForkchoiceUpdatedV2(ctx context.Context, request *remote.EngineForkChoiceUpdatedRequest, withdrawals []*types.Withdrawal) (*remote.EngineForkChoiceUpdatedReply, error)
+	EngineGetPayloadV2(ctx context.Context, payloadId uint64) (*engineapi.ExecutionPayloadV2, error)
+	EngineV2Available() bool
+	CliquePropose(ctx context.Context, address common.Address, auth bool) error
+	CliqueDiscard(ctx context.Context, address common.Address) error
+	CliqueProposals(ctx context.Context) (map[common.Address]bool, error)
 	NodeInfo(ctx context.Context, limit uint32) ([]p2p.NodeInfo, error)
 	Peers(ctx context.Context) ([]*p2p.PeerInfo, error)
 }
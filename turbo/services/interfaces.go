@@ -37,6 +37,12 @@ type TxnReader interface {
 	TxnLookup(ctx context.Context, tx kv.Getter, txnHash common.Hash) (uint64, bool, error)
 	TxnByIdxInBlock(ctx context.Context, tx kv.Getter, blockNum uint64, i int) (txn types.Transaction, err error)
 }
+
+// ReceiptsReader reads the receipts as they are stored: without the fields derived from the block and its transactions
+type ReceiptsReader interface {
+	RawReceipts(ctx context.Context, tx kv.Getter, blockNum uint64) (types.Receipts, error)
+}
+
 type HeaderAndCanonicalReader interface {
 	HeaderReader
 	CanonicalReader
@@ -54,4 +60,5 @@ type FullBlockReader interface {
 	HeaderReader
 	TxnReader
 	CanonicalReader
+	ReceiptsReader
 }
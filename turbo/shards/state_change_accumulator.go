@@ -161,3 +161,26 @@ func (a *Accumulator) ChangeStorage(address common.Address, incarnation uint64,
 	storageChange.Location = gointerfaces.ConvertHashToH256(location)
 	storageChange.Data = data
 }
+
+// RemoveLogs adds the logs of the unwound blocks to the latest (unwind) change, they are sent to the logs subscribers
+// again with Removed set
+func (a *Accumulator) RemoveLogs(logs []*remote.SubscribeLogsReply) {
+	for _, l := range logs {
+		l.Removed = true
+	}
+	a.latestChange.Logs = append(a.latestChange.Logs, logs...)
+}
+
+// RemovedLogs returns the logs of the blocks unwound by the accumulated changes
+func (a *Accumulator) RemovedLogs() []*remote.SubscribeLogsReply {
+	if a == nil {
+		return nil
+	}
+	var logs []*remote.SubscribeLogsReply
+	for _, change := range a.changes {
+		if change.Direction == remote.Direction_UNWIND {
+			logs = append(logs, change.Logs...)
+		}
+	}
+	return logs
+}
@@ -113,6 +113,10 @@ func (back *BlockReader) TxnByIdxInBlock(ctx context.Context, tx kv.Getter, bloc
 	return txn, nil
 }
 
+func (back *BlockReader) RawReceipts(ctx context.Context, tx kv.Getter, blockNum uint64) (types.Receipts, error) {
+	return rawdb.ReadRawReceipts(tx, blockNum), nil
+}
+
 type RemoteBlockReader struct {
 	client remote.ETHBACKENDClient
 }
@@ -164,6 +168,10 @@ func (back *RemoteBlockReader) TxnByIdxInBlock(ctx context.Context, tx kv.Getter
 	panic("not implemented")
 }
 
+func (back *RemoteBlockReader) RawReceipts(ctx context.Context, tx kv.Getter, blockNum uint64) (types.Receipts, error) {
+	return rawdb.ReadRawReceipts(tx, blockNum), nil
+}
+
 func (back *RemoteBlockReader) BlockWithSenders(ctx context.Context, _ kv.Getter, hash common.Hash, blockHeight uint64) (block *types.Block, senders []common.Address, err error) {
 	reply, err := back.client.Block(ctx, &remote.BlockRequest{BlockHash: gointerfaces.ConvertHashToH256(hash), BlockHeight: blockHeight})
 	if err != nil {
@@ -445,7 +453,7 @@ func (back *BlockReaderWithSnapshots) BlockWithSenders(ctx context.Context, tx k
 		}
 		if ok && b != nil {
 			if txsAmount == 0 {
-				block = types.NewBlockFromStorage(hash, h, nil, b.Uncles)
+				block = types.NewBlockFromStorage(hash, h, nil, b.Uncles).WithWithdrawals(b.Withdrawals)
 				if len(senders) != block.Transactions().Len() {
 					return block, senders, nil // no senders is fine - will recover them on the fly
 				}
@@ -465,7 +473,7 @@ func (back *BlockReaderWithSnapshots) BlockWithSenders(ctx context.Context, tx k
 				return nil, nil, err
 			}
 			if ok {
-				block = types.NewBlockFromStorage(hash, h, txs, b.Uncles)
+				block = types.NewBlockFromStorage(hash, h, txs, b.Uncles).WithWithdrawals(b.Withdrawals)
 				if len(senders) != block.Transactions().Len() {
 					return block, senders, nil // no senders is fine - will recover them on the fly
 				}
@@ -554,6 +562,7 @@ func (back *BlockReaderWithSnapshots) bodyFromSnapshot(blockHeight uint64, sn *B
 
 	body := new(types.Body)
 	body.Uncles = b.Uncles
+	body.Withdrawals = b.Withdrawals
 	var txsAmount uint32
 	if b.TxAmount >= 2 {
 		txsAmount = b.TxAmount - 2
@@ -750,6 +759,54 @@ func (back *BlockReaderWithSnapshots) TxnByIdxInBlock(ctx context.Context, tx kv
 	return txn, nil
 }
 
+// RawReceipts - the receipts segments are optional, the receipts which are not in them are read from the db
+func (back *BlockReaderWithSnapshots) RawReceipts(ctx context.Context, tx kv.Getter, blockNum uint64) (receipts types.Receipts, err error) {
+	var stored bool
+	ok, err := back.sn.ViewReceipts(blockNum, func(segment *ReceiptSegment) error {
+		receipts, stored, err = back.receiptsFromSnapshot(blockNum, segment, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ok && stored {
+		return receipts, nil
+	}
+	return rawdb.ReadRawReceipts(tx, blockNum), nil
+}
+
+func (back *BlockReaderWithSnapshots) receiptsFromSnapshot(blockNum uint64, sn *ReceiptSegment, buf []byte) (types.Receipts, bool, error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			panic(fmt.Errorf("%+v, snapshot: %d-%d, trace: %s", rec, sn.ranges.from, sn.ranges.to, dbg.Stack()))
+		}
+	}() // avoid crash because Erigon's core does many things
+
+	if sn.idxReceiptNumber == nil {
+		return nil, false, nil
+	}
+	receiptsOffset := sn.idxReceiptNumber.OrdinalLookup(blockNum - sn.idxReceiptNumber.BaseDataID())
+
+	gg := sn.seg.MakeGetter()
+	gg.Reset(receiptsOffset)
+	if !gg.HasNext() {
+		return nil, false, nil
+	}
+	buf, _ = gg.Next(buf[:0])
+	if len(buf) == 0 { // receipts of the block were not stored
+		return nil, false, nil
+	}
+	var storage types.ReceiptsForStorage
+	if err := rlp.DecodeBytes(buf, &storage); err != nil {
+		return nil, false, err
+	}
+	receipts := make(types.Receipts, len(storage))
+	for i, r := range storage {
+		receipts[i] = (*types.Receipt)(r)
+	}
+	return receipts, true, nil
+}
+
 // TxnLookup - find blockNumber and txnID by txnHash
 func (back *BlockReaderWithSnapshots) TxnLookup(ctx context.Context, tx kv.Getter, txnHash common.Hash) (uint64, bool, error) {
 	n, err := rawdb.ReadTxLookupEntry(tx, txnHash)
@@ -68,6 +68,12 @@ type TxnSegment struct {
 	ranges              Range
 }
 
+type ReceiptSegment struct {
+	seg              *compress.Decompressor // value: rlp(types.ReceiptsForStorage), empty - receipts of the block were not stored
+	idxReceiptNumber *recsplit.Index        // block_num_u64     -> receipts_segment_offset
+	ranges           Range
+}
+
 func (sn *HeaderSegment) closeIdx() {
 	if sn.idxHeaderHash != nil {
 		sn.idxHeaderHash.Close()
@@ -250,6 +256,57 @@ func (sn *TxnSegment) reopenIdxIfNeed(dir string, optimistic bool) (err error) {
 	return nil
 }
 
+func (sn *ReceiptSegment) closeSeg() {
+	if sn.seg != nil {
+		sn.seg.Close()
+		sn.seg = nil
+	}
+}
+func (sn *ReceiptSegment) closeIdx() {
+	if sn.idxReceiptNumber != nil {
+		sn.idxReceiptNumber.Close()
+		sn.idxReceiptNumber = nil
+	}
+}
+func (sn *ReceiptSegment) close() {
+	sn.closeSeg()
+	sn.closeIdx()
+}
+func (sn *ReceiptSegment) reopenSeg(dir string) (err error) {
+	sn.closeSeg()
+	fileName := snap.SegmentFileName(sn.ranges.from, sn.ranges.to, snap.Receipts)
+	sn.seg, err = compress.NewDecompressor(path.Join(dir, fileName))
+	if err != nil {
+		return fmt.Errorf("%w, fileName: %s", err, fileName)
+	}
+	return nil
+}
+func (sn *ReceiptSegment) reopenIdxIfNeed(dir string, optimistic bool) (err error) {
+	if sn.idxReceiptNumber != nil {
+		return nil
+	}
+	err = sn.reopenIdx(dir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			if optimistic {
+				log.Warn("[snapshots] open index", "err", err)
+			} else {
+				return err
+			}
+		}
+	}
+	return nil
+}
+func (sn *ReceiptSegment) reopenIdx(dir string) (err error) {
+	sn.closeIdx()
+	fileName := snap.IdxFileName(sn.ranges.from, sn.ranges.to, snap.Receipts.String())
+	sn.idxReceiptNumber, err = recsplit.OpenIndex(path.Join(dir, fileName))
+	if err != nil {
+		return fmt.Errorf("%w, fileName: %s", err, fileName)
+	}
+	return nil
+}
+
 type headerSegments struct {
 	lock     sync.RWMutex
 	segments []*HeaderSegment
@@ -316,6 +373,28 @@ func (s *txnSegments) ViewSegment(blockNum uint64, f func(*TxnSegment) error) (f
 	return false, nil
 }
 
+type receiptSegments struct {
+	lock     sync.RWMutex
+	segments []*ReceiptSegment
+}
+
+func (s *receiptSegments) View(f func([]*ReceiptSegment) error) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return f(s.segments)
+}
+func (s *receiptSegments) ViewSegment(blockNum uint64, f func(*ReceiptSegment) error) (found bool, err error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, seg := range s.segments {
+		if !(blockNum >= seg.ranges.from && blockNum < seg.ranges.to) {
+			continue
+		}
+		return true, f(seg)
+	}
+	return false, nil
+}
+
 type RoSnapshots struct {
 	indicesReady  atomic.Bool
 	segmentsReady atomic.Bool
@@ -324,9 +403,14 @@ type RoSnapshots struct {
 	Bodies  *bodySegments
 	Txs     *txnSegments
 
+	// Receipts are optional: produced only with --snap.receipts or `snapshots create --receipts`, and not counted by
+	// segmentsMax and idxMax
+	Receipts *receiptSegments
+
 	dir         string
 	segmentsMax atomic.Uint64 // all types of .seg files are available - up to this number
 	idxMax      atomic.Uint64 // all types of .idx files are available - up to this number
+	receiptsMax atomic.Uint64 // receipts .seg and .idx files are available - up to this number
 	cfg         ethconfig.Snapshot
 }
 
@@ -336,7 +420,7 @@ type RoSnapshots struct {
 //  - gaps are not allowed
 //  - segment have [from:to) semantic
 func NewRoSnapshots(cfg ethconfig.Snapshot, snapDir string) *RoSnapshots {
-	return &RoSnapshots{dir: snapDir, cfg: cfg, Headers: &headerSegments{}, Bodies: &bodySegments{}, Txs: &txnSegments{}}
+	return &RoSnapshots{dir: snapDir, cfg: cfg, Headers: &headerSegments{}, Bodies: &bodySegments{}, Txs: &txnSegments{}, Receipts: &receiptSegments{}}
 }
 
 func (s *RoSnapshots) Cfg() ethconfig.Snapshot { return s.cfg }
@@ -346,6 +430,11 @@ func (s *RoSnapshots) IndicesReady() bool      { return s.indicesReady.Load() }
 func (s *RoSnapshots) IndicesMax() uint64      { return s.idxMax.Load() }
 func (s *RoSnapshots) SegmentsMax() uint64     { return s.segmentsMax.Load() }
 func (s *RoSnapshots) BlocksAvailable() uint64 { return cmp.Min(s.segmentsMax.Load(), s.idxMax.Load()) }
+
+// ReceiptsAvailable - receipts of all the blocks up to this number are in the snapshots, 0 - none of them
+func (s *RoSnapshots) ReceiptsAvailable() uint64 {
+	return cmp.Min(s.receiptsMax.Load(), s.BlocksAvailable())
+}
 func (s *RoSnapshots) LogStat() {
 	var m runtime.MemStats
 	common2.ReadMemStats(&m)
@@ -385,6 +474,17 @@ func (s *RoSnapshots) idxAvailability() uint64 {
 	return cmp.Min(headers, cmp.Min(bodies, txs))
 }
 
+func (s *RoSnapshots) receiptsAvailability() uint64 {
+	var receipts, prevTo uint64
+	for _, seg := range s.Receipts.segments {
+		if seg.ranges.from != prevTo || seg.idxReceiptNumber == nil {
+			break
+		}
+		receipts, prevTo = seg.ranges.to-1, seg.ranges.to
+	}
+	return receipts
+}
+
 // OptimisticReopenWithDB - optimistically open snapshots (ignoring error), useful at App startup because:
 // - user must be able: delete any snapshot file and Erigon will self-heal by re-downloading
 // - RPC return Nil for historical blocks if snapshots are not open
@@ -405,6 +505,8 @@ func (s *RoSnapshots) Files() (list []string) {
 	defer s.Bodies.lock.RUnlock()
 	s.Txs.lock.RLock()
 	defer s.Txs.lock.RUnlock()
+	s.Receipts.lock.RLock()
+	defer s.Receipts.lock.RUnlock()
 	max := s.BlocksAvailable()
 	for _, seg := range s.Bodies.segments {
 		if seg.ranges.from > max {
@@ -427,6 +529,13 @@ func (s *RoSnapshots) Files() (list []string) {
 		_, fName := filepath.Split(seg.Seg.FilePath())
 		list = append(list, fName)
 	}
+	for _, seg := range s.Receipts.segments {
+		if seg.ranges.from > max {
+			continue
+		}
+		_, fName := filepath.Split(seg.seg.FilePath())
+		list = append(list, fName)
+	}
 	return list
 }
 
@@ -438,6 +547,8 @@ func (s *RoSnapshots) ReopenList(fileNames []string, optimistic bool) error {
 	defer s.Bodies.lock.Unlock()
 	s.Txs.lock.Lock()
 	defer s.Txs.lock.Unlock()
+	s.Receipts.lock.Lock()
+	defer s.Receipts.lock.Unlock()
 
 	s.closeWhatNotInList(fileNames)
 	var segmentsMax uint64
@@ -547,6 +658,31 @@ Loop:
 			if err := sn.reopenIdxIfNeed(s.dir, optimistic); err != nil {
 				return err
 			}
+		case snap.Receipts:
+			for _, sn := range s.Receipts.segments {
+				_, name := filepath.Split(sn.seg.FilePath())
+				if fName == name {
+					if err := sn.reopenIdxIfNeed(s.dir, optimistic); err != nil {
+						return err
+					}
+					continue Loop
+				}
+			}
+
+			sn := &ReceiptSegment{ranges: Range{f.From, f.To}}
+			if err := sn.reopenSeg(s.dir); err != nil {
+				if optimistic || errors.Is(err, os.ErrNotExist) {
+					log.Warn("[snapshots] open segment", "err", err)
+					continue Loop
+				}
+				return err
+			}
+			s.Receipts.segments = append(s.Receipts.segments, sn)
+			if err := sn.reopenIdxIfNeed(s.dir, optimistic); err != nil {
+				return err
+			}
+			// receipts are optional, they don't limit the blocks available
+			continue Loop
 		}
 
 		if f.To > 0 {
@@ -561,6 +697,7 @@ Loop:
 	}
 	s.segmentsReady.Store(true)
 	s.idxMax.Store(s.idxAvailability())
+	s.receiptsMax.Store(s.receiptsAvailability())
 	s.indicesReady.Store(true)
 
 	return nil
@@ -608,6 +745,8 @@ func (s *RoSnapshots) Close() {
 	defer s.Bodies.lock.Unlock()
 	s.Txs.lock.Lock()
 	defer s.Txs.lock.Unlock()
+	s.Receipts.lock.Lock()
+	defer s.Receipts.lock.Unlock()
 	s.closeWhatNotInList(nil)
 }
 
@@ -645,6 +784,17 @@ Loop3:
 		sn.close()
 		s.Txs.segments[i] = nil
 	}
+Loop4:
+	for i, sn := range s.Receipts.segments {
+		_, name := filepath.Split(sn.seg.FilePath())
+		for _, fName := range l {
+			if fName == name {
+				continue Loop4
+			}
+		}
+		sn.close()
+		s.Receipts.segments[i] = nil
+	}
 	var i int
 	for i = 0; i < len(s.Headers.segments) && s.Headers.segments[i] != nil; i++ {
 	}
@@ -678,6 +828,17 @@ Loop3:
 			tailC[i] = nil
 		}
 	}
+
+	for i = 0; i < len(s.Receipts.segments) && s.Receipts.segments[i] != nil; i++ {
+	}
+	tailD := s.Receipts.segments[i:]
+	s.Receipts.segments = s.Receipts.segments[:i]
+	for i = 0; i < len(tailD); i++ {
+		if tailD[i] != nil {
+			tailD[i].close()
+			tailD[i] = nil
+		}
+	}
 }
 
 func (s *RoSnapshots) PrintDebug() {
@@ -687,7 +848,9 @@ func (s *RoSnapshots) PrintDebug() {
 	defer s.Bodies.lock.RUnlock()
 	s.Txs.lock.RLock()
 	defer s.Txs.lock.RUnlock()
-	fmt.Printf("sn: %d, %d\n", s.segmentsMax.Load(), s.idxMax.Load())
+	s.Receipts.lock.RLock()
+	defer s.Receipts.lock.RUnlock()
+	fmt.Printf("sn: %d, %d, receipts: %d\n", s.segmentsMax.Load(), s.idxMax.Load(), s.receiptsMax.Load())
 	fmt.Println("    == Snapshots, Header")
 	for _, sn := range s.Headers.segments {
 		fmt.Printf("%d,  %t\n", sn.ranges.from, sn.idxHeaderHash == nil)
@@ -700,6 +863,10 @@ func (s *RoSnapshots) PrintDebug() {
 	for _, sn := range s.Txs.segments {
 		fmt.Printf("%d,  %t, %t\n", sn.ranges.from, sn.IdxTxnHash == nil, sn.IdxTxnHash2BlockNum == nil)
 	}
+	fmt.Println("    == Snapshots, Receipts")
+	for _, sn := range s.Receipts.segments {
+		fmt.Printf("%d,  %t\n", sn.ranges.from, sn.idxReceiptNumber == nil)
+	}
 }
 func (s *RoSnapshots) ViewHeaders(blockNum uint64, f func(sn *HeaderSegment) error) (found bool, err error) {
 	if !s.indicesReady.Load() || blockNum > s.BlocksAvailable() {
@@ -719,6 +886,12 @@ func (s *RoSnapshots) ViewTxs(blockNum uint64, f func(sn *TxnSegment) error) (fo
 	}
 	return s.Txs.ViewSegment(blockNum, f)
 }
+func (s *RoSnapshots) ViewReceipts(blockNum uint64, f func(sn *ReceiptSegment) error) (found bool, err error) {
+	if !s.indicesReady.Load() || blockNum > s.BlocksAvailable() {
+		return false, nil
+	}
+	return s.Receipts.ViewSegment(blockNum, f)
+}
 
 func buildIdx(ctx context.Context, sn snap.FileInfo, chainID uint256.Int, tmpDir string, lvl log.Lvl) error {
 	switch sn.T {
@@ -735,6 +908,10 @@ func buildIdx(ctx context.Context, sn snap.FileInfo, chainID uint256.Int, tmpDir
 		if err := TransactionsIdx(ctx, chainID, sn.From, sn.To, dir, tmpDir, lvl); err != nil {
 			return err
 		}
+	case snap.Receipts:
+		if err := ReceiptsIdx(ctx, sn.Path, sn.From, tmpDir, lvl); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -750,7 +927,7 @@ func BuildMissedIndices(ctx context.Context, dir string, chainID uint256.Int, tm
 	errs := make(chan error, 1024)
 	wg := &sync.WaitGroup{}
 	sem := semaphore.NewWeighted(int64(workers))
-	for _, t := range snap.AllSnapshotTypes {
+	for _, t := range append(snap.AllSnapshotTypes, snap.OptionalSnapshotTypes...) {
 		for _, sn := range segments {
 			if sn.T != t {
 				continue
@@ -936,6 +1113,17 @@ func Segments(dir string) (res []snap.FileInfo, missingSnapshots []Range, err er
 		l, _ = noGaps(noOverlaps(allTypeOfSegmentsMustExist(dir, l)))
 		res = append(res, l...)
 	}
+	{
+		var l []snap.FileInfo
+		for _, f := range list {
+			if f.T != snap.Receipts {
+				continue
+			}
+			l = append(l, f)
+		}
+		l, _ = noGaps(noOverlaps(l))
+		res = append(res, l...)
+	}
 
 	return res, missingSnapshots, nil
 }
@@ -1036,6 +1224,17 @@ func (br *BlockRetire) PruneAncientBlocks(tx kv.RwTx) error {
 	if err := rawdb.PruneTable(tx, kv.Senders, canDeleteTo, context.Background(), 100); err != nil {
 		return err
 	}
+	// receipts are deleted only once they can be read from the snapshots
+	if !br.snapshots.cfg.Receipts || br.snapshots.ReceiptsAvailable() == 0 {
+		return nil
+	}
+	receiptsTo := cmp.Min(canDeleteTo, br.snapshots.ReceiptsAvailable()+1)
+	if err := rawdb.PruneTable(tx, kv.Receipts, receiptsTo, context.Background(), 100); err != nil {
+		return err
+	}
+	if err := rawdb.PruneTable(tx, kv.Log, receiptsTo, context.Background(), 100); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1076,7 +1275,7 @@ type DBEventNotifier interface {
 func retireBlocks(ctx context.Context, blockFrom, blockTo uint64, chainID uint256.Int, tmpDir string, snapshots *RoSnapshots, db kv.RoDB, workers int, downloader proto_downloader.DownloaderClient, lvl log.Lvl, notifier DBEventNotifier) error {
 	log.Log(lvl, "[snapshots] Retire Blocks", "range", fmt.Sprintf("%dk-%dk", blockFrom/1000, blockTo/1000))
 	// in future we will do it in background
-	if err := DumpBlocks(ctx, blockFrom, blockTo, snap.DEFAULT_SEGMENT_SIZE, tmpDir, snapshots.Dir(), db, workers, lvl); err != nil {
+	if err := DumpBlocks(ctx, blockFrom, blockTo, snap.DEFAULT_SEGMENT_SIZE, tmpDir, snapshots.Dir(), db, workers, snapshots.Cfg().Receipts, lvl); err != nil {
 		return fmt.Errorf("DumpBlocks: %w", err)
 	}
 	if err := snapshots.ReopenFolder(); err != nil {
@@ -1107,25 +1306,36 @@ func retireBlocks(ctx context.Context, blockFrom, blockTo uint64, chainID uint25
 	for _, r := range rangesToMerge {
 		downloadRequest = append(downloadRequest, NewDownloadRequest(&r, "", ""))
 	}
+	// BuildProtoRequest asks for the required types only, the merged receipts are seeded by their file names
+	for _, r := range rangesToMerge {
+		if r.to-r.from != snap.DEFAULT_SEGMENT_SIZE {
+			continue
+		}
+		fileName := snap.SegmentFileName(r.from, r.to, snap.Receipts)
+		if common.FileExist(filepath.Join(snapshots.Dir(), fileName)) {
+			downloadRequest = append(downloadRequest, NewDownloadRequest(nil, fileName, ""))
+		}
+	}
 
 	return RequestSnapshotsDownload(ctx, downloadRequest, downloader)
 }
 
-func DumpBlocks(ctx context.Context, blockFrom, blockTo, blocksPerFile uint64, tmpDir, snapDir string, chainDB kv.RoDB, workers int, lvl log.Lvl) error {
+// DumpBlocks - [from, to), withReceipts - also dump the receipts of the blocks
+func DumpBlocks(ctx context.Context, blockFrom, blockTo, blocksPerFile uint64, tmpDir, snapDir string, chainDB kv.RoDB, workers int, withReceipts bool, lvl log.Lvl) error {
 	if blocksPerFile == 0 {
 		return nil
 	}
 	chainConfig := tool.ChainConfigFromDB(chainDB)
 	chainID, _ := uint256.FromBig(chainConfig.ChainID)
 	for i := blockFrom; i < blockTo; i = chooseSegmentEnd(i, blockTo, blocksPerFile) {
-		if err := dumpBlocksRange(ctx, i, chooseSegmentEnd(i, blockTo, blocksPerFile), tmpDir, snapDir, chainDB, *chainID, workers, lvl); err != nil {
+		if err := dumpBlocksRange(ctx, i, chooseSegmentEnd(i, blockTo, blocksPerFile), tmpDir, snapDir, chainDB, *chainID, workers, withReceipts, lvl); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func dumpBlocksRange(ctx context.Context, blockFrom, blockTo uint64, tmpDir, snapDir string, chainDB kv.RoDB, chainID uint256.Int, workers int, lvl log.Lvl) error {
+func dumpBlocksRange(ctx context.Context, blockFrom, blockTo uint64, tmpDir, snapDir string, chainDB kv.RoDB, chainID uint256.Int, workers int, withReceipts bool, lvl log.Lvl) error {
 	f, _ := snap.ParseFileName(snapDir, snap.SegmentFileName(blockFrom, blockTo, snap.Headers))
 	if err := DumpHeaders(ctx, chainDB, f.Path, tmpDir, blockFrom, blockTo, workers, lvl); err != nil {
 		return fmt.Errorf("DumpHeaders: %w", err)
@@ -1150,6 +1360,17 @@ func dumpBlocksRange(ctx context.Context, blockFrom, blockTo uint64, tmpDir, sna
 		return err
 	}
 
+	if !withReceipts {
+		return nil
+	}
+	f, _ = snap.ParseFileName(snapDir, snap.SegmentFileName(blockFrom, blockTo, snap.Receipts))
+	if err := DumpReceipts(ctx, chainDB, f.Path, tmpDir, blockFrom, blockTo, workers, lvl); err != nil {
+		return fmt.Errorf("DumpReceipts: %w", err)
+	}
+	if err := buildIdx(ctx, f, chainID, tmpDir, lvl); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1163,7 +1384,7 @@ func hasIdxFile(sn *snap.FileInfo) bool {
 			return false
 		}
 		_ = idx.Close()
-	case snap.Bodies:
+	case snap.Bodies, snap.Receipts:
 		idx, err := recsplit.OpenIndex(path.Join(dir, fName))
 		if err != nil {
 			return false
@@ -1485,6 +1706,73 @@ func DumpBodies(ctx context.Context, db kv.RoDB, segmentFilePath, tmpDir string,
 	return nil
 }
 
+// DumpReceipts - [from, to)
+// Format: rlp(types.ReceiptsForStorage) of every block, empty word if the receipts of the block are not in the db
+func DumpReceipts(ctx context.Context, db kv.RoDB, segmentFilePath, tmpDir string, blockFrom, blockTo uint64, workers int, lvl log.Lvl) error {
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+
+	f, err := compress.NewCompressor(ctx, "Snapshot Receipts", segmentFilePath, tmpDir, compress.MinPatternScore, workers, lvl)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	from := dbutils.EncodeBlockNumber(blockFrom)
+	var buf bytes.Buffer
+	if err := kv.BigChunks(db, kv.HeaderCanonical, from, func(tx kv.Tx, k, v []byte) (bool, error) {
+		blockNum := binary.BigEndian.Uint64(k)
+		if blockNum >= blockTo {
+			return false, nil
+		}
+		has, err := tx.Has(kv.Receipts, k)
+		if err != nil {
+			return false, err
+		}
+		if !has {
+			if err := f.AddWord(nil); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+
+		receipts := rawdb.ReadRawReceipts(tx, blockNum)
+		storage := make(types.ReceiptsForStorage, len(receipts))
+		for i, r := range receipts {
+			storage[i] = (*types.ReceiptForStorage)(r)
+		}
+		buf.Reset()
+		if err := rlp.Encode(&buf, storage); err != nil {
+			return false, err
+		}
+		if err := f.AddWord(buf.Bytes()); err != nil {
+			return false, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-logEvery.C:
+			var m runtime.MemStats
+			if lvl >= log.LvlInfo {
+				common2.ReadMemStats(&m)
+			}
+			log.Log(lvl, "[snapshots] Wrote into file", "block num", blockNum,
+				"alloc", common2.ByteCount(m.Alloc), "sys", common2.ByteCount(m.Sys),
+			)
+		default:
+		}
+		return true, nil
+	}); err != nil {
+		return err
+	}
+	if err := f.Compress(); err != nil {
+		return fmt.Errorf("compress: %w", err)
+	}
+
+	return nil
+}
+
 var EmptyTxHash = common.Hash{}
 
 func expectedTxsAmount(snapDir string, blockFrom, blockTo uint64) (firstTxID, expectedCount uint64, err error) {
@@ -1729,6 +2017,34 @@ func BodiesIdx(ctx context.Context, segmentFilePath string, firstBlockNumInSegme
 	return nil
 }
 
+func ReceiptsIdx(ctx context.Context, segmentFilePath string, firstBlockNumInSegment uint64, tmpDir string, lvl log.Lvl) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			_, fName := filepath.Split(segmentFilePath)
+			err = fmt.Errorf("ReceiptsIdx: at=%s, %v, %s", fName, rec, dbg.Stack())
+		}
+	}()
+
+	num := make([]byte, 8)
+
+	d, err := compress.NewDecompressor(segmentFilePath)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := Idx(ctx, d, firstBlockNumInSegment, tmpDir, log.LvlDebug, func(idx *recsplit.RecSplit, i, offset uint64, word []byte) error {
+		n := binary.PutUvarint(num, i)
+		if err := idx.AddKey(num[:n], offset); err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("ReceiptNumberIdx: %w", err)
+	}
+	return nil
+}
+
 type decompressItem struct {
 	i, offset uint64
 	word      []byte
@@ -1910,6 +2226,28 @@ func (m *Merger) filesByRange(snapshots *RoSnapshots, from, to uint64) (map[snap
 			})
 		})
 	})
+	if err != nil {
+		return toMerge, err
+	}
+	// receipts are merged only if their segments cover the whole range, otherwise they stay as they are
+	err = snapshots.Receipts.View(func(rSegments []*ReceiptSegment) error {
+		var receipts []string
+		prevTo := from
+		for _, sn := range rSegments {
+			if sn.ranges.from < from {
+				continue
+			}
+			if sn.ranges.to > to || sn.ranges.from != prevTo {
+				break
+			}
+			receipts = append(receipts, sn.seg.FilePath())
+			prevTo = sn.ranges.to
+		}
+		if prevTo == to {
+			toMerge[snap.Receipts] = receipts
+		}
+		return nil
+	})
 	return toMerge, err
 }
 
@@ -1926,7 +2264,10 @@ func (m *Merger) Merge(ctx context.Context, snapshots *RoSnapshots, mergeRanges
 		if err != nil {
 			return err
 		}
-		for _, t := range snap.AllSnapshotTypes {
+		for _, t := range append(snap.AllSnapshotTypes, snap.OptionalSnapshotTypes...) {
+			if len(toMerge[t]) == 0 {
+				continue
+			}
 			f, _ := snap.ParseFileName(snapDir, snap.SegmentFileName(r.from, r.to, t))
 			if err := m.merge(ctx, toMerge[t], f.Path, logEvery); err != nil {
 				return fmt.Errorf("mergeByAppendSegments: %w", err)
@@ -1945,7 +2286,7 @@ func (m *Merger) Merge(ctx context.Context, snapshots *RoSnapshots, mergeRanges
 			m.notifier.OnNewSnapshot()
 			time.Sleep(1 * time.Second) // i working on blocking API - to ensure client does not use old snapsthos - and then delete them
 		}
-		for _, t := range snap.AllSnapshotTypes {
+		for _, t := range append(snap.AllSnapshotTypes, snap.OptionalSnapshotTypes...) {
 			m.removeOldFiles(toMerge[t], snapDir)
 		}
 	}
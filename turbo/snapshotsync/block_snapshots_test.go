@@ -8,9 +8,13 @@ import (
 
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/compress"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
 	"github.com/ledgerwatch/erigon-lib/recsplit"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/math"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/params/networkname"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync/snap"
@@ -201,6 +205,59 @@ func TestOpenAllSnapshot(t *testing.T) {
 	require.NoError(err)
 }
 
+func TestReceiptsSnapshot(t *testing.T) {
+	dir, require := t.TempDir(), require.New(t)
+	ctx := context.Background()
+	db := memdb.NewTestDB(t)
+
+	receipt := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs:              []*types.Log{{Address: common.HexToAddress("0x1"), Topics: []common.Hash{{2}}, Data: []byte{3}}},
+	}
+	require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+		for i := uint64(0); i < 1_000; i++ {
+			if err := rawdb.WriteCanonicalHash(tx, common.Hash{byte(i), byte(i >> 8)}, i); err != nil {
+				return err
+			}
+		}
+		return rawdb.WriteReceipts(tx, 5, types.Receipts{receipt})
+	}))
+
+	for _, snT := range snap.AllSnapshotTypes {
+		createTestSegmentFile(t, 0, 1_000, snT, dir)
+	}
+	segmentPath := filepath.Join(dir, snap.SegmentFileName(0, 1_000, snap.Receipts))
+	require.NoError(DumpReceipts(ctx, db, segmentPath, dir, 0, 1_000, 1, log.LvlInfo))
+	require.NoError(ReceiptsIdx(ctx, segmentPath, 0, dir, log.LvlInfo))
+
+	s := NewRoSnapshots(ethconfig.Snapshot{Enabled: true, Receipts: true}, dir)
+	defer s.Close()
+	require.NoError(s.ReopenFolder())
+	require.Equal(1, len(s.Receipts.segments))
+	require.Equal(uint64(999), s.ReceiptsAvailable())
+
+	// the receipts are read from the snapshot once they are gone from the db
+	require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+		return rawdb.TruncateReceipts(tx, 0)
+	}))
+	reader := NewBlockReaderWithSnapshots(s)
+	require.NoError(db.View(ctx, func(tx kv.Tx) error {
+		receipts, err := reader.RawReceipts(ctx, tx, 5)
+		require.NoError(err)
+		require.Len(receipts, 1)
+		require.Equal(receipt.CumulativeGasUsed, receipts[0].CumulativeGasUsed)
+		require.Equal(receipt.Logs[0].Address, receipts[0].Logs[0].Address)
+		require.Equal(receipt.Logs[0].Data, receipts[0].Logs[0].Data)
+
+		// the blocks without the receipts in the db when the snapshot was created
+		receipts, err = reader.RawReceipts(ctx, tx, 6)
+		require.NoError(err)
+		require.Nil(receipts)
+		return nil
+	}))
+}
+
 func TestParseCompressedFileName(t *testing.T) {
 	require := require.New(t)
 	fs := fstest.MapFS{
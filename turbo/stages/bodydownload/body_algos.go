@@ -147,20 +147,22 @@ func (bd *BodyDownload) RequestMoreBodies(tx kv.RwTx, blockReader services.FullB
 				request = false
 			} else {
 				bd.deliveriesH[blockNum-bd.requestedLow] = header
-				if header.UncleHash != types.EmptyUncleHash || header.TxHash != types.EmptyRootHash {
+				if header.UncleHash != types.EmptyUncleHash || header.TxHash != types.EmptyRootHash ||
+					(header.WithdrawalsHash != nil && *header.WithdrawalsHash != types.EmptyRootHash) {
 					// Perhaps we already have this block
 					block = rawdb.ReadBlock(tx, hash, blockNum)
 					if block == nil {
-						var doubleHash DoubleHash
-						copy(doubleHash[:], header.UncleHash.Bytes())
-						copy(doubleHash[common.HashLength:], header.TxHash.Bytes())
-						bd.requestedMap[doubleHash] = blockNum
+						bd.requestedMap[bodyHashes(header.UncleHash, header.TxHash, header.WithdrawalsHash)] = blockNum
 					} else {
 						bd.deliveriesB[blockNum-bd.requestedLow] = block.RawBody()
 						request = false
 					}
 				} else {
-					bd.deliveriesB[blockNum-bd.requestedLow] = &types.RawBody{}
+					body := &types.RawBody{}
+					if header.WithdrawalsHash != nil {
+						body.Withdrawals = []*types.Withdrawal{}
+					}
+					bd.deliveriesB[blockNum-bd.requestedLow] = body
 					request = false
 				}
 			}
@@ -169,7 +171,7 @@ func (bd *BodyDownload) RequestMoreBodies(tx kv.RwTx, blockReader services.FullB
 			blockNums = append(blockNums, blockNum)
 			hashes = append(hashes, hash)
 		} else {
-			// Both uncleHash and txHash are empty (or block is prefetched), no need to request
+			// uncleHash, txHash and withdrawalsHash are all empty (or block is prefetched), no need to request
 			bd.delivered.Add(blockNum)
 		}
 	}
@@ -196,8 +198,8 @@ func (bd *BodyDownload) RequestSent(bodyReq *BodyRequest, timeWithTimeout uint64
 }
 
 // DeliverBodies takes the block body received from a peer and adds it to the various data structures
-func (bd *BodyDownload) DeliverBodies(txs *[][][]byte, uncles *[][]*types.Header, lenOfP2PMsg uint64, peerID [64]byte) {
-	bd.deliveryCh <- Delivery{txs: txs, uncles: uncles, lenOfP2PMessage: lenOfP2PMsg, peerID: peerID}
+func (bd *BodyDownload) DeliverBodies(txs *[][][]byte, uncles *[][]*types.Header, withdrawals *[][]*types.Withdrawal, lenOfP2PMsg uint64, peerID [64]byte) {
+	bd.deliveryCh <- Delivery{txs: txs, uncles: uncles, withdrawals: withdrawals, lenOfP2PMessage: lenOfP2PMsg, peerID: peerID}
 
 	select {
 	case bd.DeliveryNotify <- struct{}{}:
@@ -247,25 +249,30 @@ Loop:
 		if delivery.uncles == nil {
 			log.Warn("nil uncles delivered", "peer_id", delivery.peerID, "p2p_msg_len", delivery.lenOfP2PMessage)
 		}
-		if delivery.txs == nil || delivery.uncles == nil {
+		if delivery.txs == nil || delivery.uncles == nil || delivery.withdrawals == nil {
 			log.Debug("delivery body processing has been skipped due to nil tx|data")
 			continue
 		}
 
 		reqMap := make(map[uint64]*BodyRequest)
-		txs, uncles, lenOfP2PMessage, _ := *delivery.txs, *delivery.uncles, delivery.lenOfP2PMessage, delivery.peerID
+		txs, uncles, withdrawals, lenOfP2PMessage, _ := *delivery.txs, *delivery.uncles, *delivery.withdrawals, delivery.lenOfP2PMessage, delivery.peerID
 		var delivered, undelivered int
 
 		for i := range txs {
 			uncleHash := types.CalcUncleHash(uncles[i])
 			txHash := types.DeriveSha(RawTransactions(txs[i]))
-			var doubleHash DoubleHash
-			copy(doubleHash[:], uncleHash.Bytes())
-			copy(doubleHash[common.HashLength:], txHash.Bytes())
+			// Missing withdrawals of a post-Shanghai body, or withdrawals not matching the header's withdrawals root,
+			// as well as withdrawals of a pre-Shanghai body, result in hashes which were not requested
+			var withdrawalsHash *common.Hash
+			if withdrawals[i] != nil {
+				h := types.DeriveSha(types.Withdrawals(withdrawals[i]))
+				withdrawalsHash = &h
+			}
+			hashes := bodyHashes(uncleHash, txHash, withdrawalsHash)
 
-			// Block numbers are added to the bd.delivered bitmap here, only for blocks for which the body has been received, and their double hashes are present in the bd.requesredMap
+			// Block numbers are added to the bd.delivered bitmap here, only for blocks for which the body has been received, and their body hashes are present in the bd.requesredMap
 			// Also, block numbers can be added to bd.delivered for empty blocks, above
-			blockNum, ok := bd.requestedMap[doubleHash]
+			blockNum, ok := bd.requestedMap[hashes]
 			if !ok {
 				undelivered++
 				continue
@@ -276,9 +283,9 @@ Loop:
 					reqMap[req.BlockNums[0]] = req
 				}
 			}
-			delete(bd.requestedMap, doubleHash) // Delivered, cleaning up
+			delete(bd.requestedMap, hashes) // Delivered, cleaning up
 
-			bd.deliveriesB[blockNum-bd.requestedLow] = &types.RawBody{Transactions: txs[i], Uncles: uncles[i]}
+			bd.deliveriesB[blockNum-bd.requestedLow] = &types.RawBody{Transactions: txs[i], Uncles: uncles[i], Withdrawals: withdrawals[i]}
 			bd.delivered.Add(blockNum)
 			delivered++
 		}
@@ -297,6 +304,17 @@ Loop:
 	return nil
 }
 
+// bodyHashes builds the key of requested bodies, withdrawalsHash is nil for pre-Shanghai blocks
+func bodyHashes(uncleHash, txHash common.Hash, withdrawalsHash *common.Hash) BodyHashes {
+	var hashes BodyHashes
+	copy(hashes[:], uncleHash.Bytes())
+	copy(hashes[common.HashLength:], txHash.Bytes())
+	if withdrawalsHash != nil {
+		copy(hashes[2*common.HashLength:], withdrawalsHash.Bytes())
+	}
+	return hashes
+}
+
 func (bd *BodyDownload) DeliverySize(delivered float64, wasted float64) {
 	bd.deliveredCount += delivered
 	bd.wastedCount += wasted
@@ -387,6 +405,19 @@ func (bd *BodyDownload) AddToPrefetch(block *types.Block) {
 		log.Warn("Propagated block has invalid body", "have", hash, "exp", block.TxHash())
 		return
 	}
+	if exp := block.Header().WithdrawalsHash; exp != nil {
+		if block.Withdrawals() == nil {
+			log.Warn("Propagated block has no withdrawals", "exp", *exp)
+			return
+		}
+		if hash := types.DeriveSha(block.Withdrawals()); hash != *exp {
+			log.Warn("Propagated block has invalid withdrawals", "have", hash, "exp", *exp)
+			return
+		}
+	} else if block.Withdrawals() != nil {
+		log.Warn("Propagated pre-Shanghai block has withdrawals")
+		return
+	}
 	bd.prefetchedBlocks.Add(block)
 }
 
@@ -7,8 +7,9 @@ import (
 	"github.com/ledgerwatch/erigon/core/types"
 )
 
-// DoubleHash is type to be used for the mapping between TxHash and UncleHash to the block header
-type DoubleHash [2 * common.HashLength]byte
+// BodyHashes is type to be used for the mapping between UncleHash, TxHash and WithdrawalsHash to the block header.
+// WithdrawalsHash part is zero for pre-Shanghai blocks
+type BodyHashes [3 * common.HashLength]byte
 
 const MaxBodiesInRequest = 1024
 
@@ -16,13 +17,14 @@ type Delivery struct {
 	peerID          [64]byte
 	txs             *[][][]byte
 	uncles          *[][]*types.Header
+	withdrawals     *[][]*types.Withdrawal
 	lenOfP2PMessage uint64
 }
 
 // BodyDownload represents the state of body downloading process
 type BodyDownload struct {
 	peerMap          map[[64]byte]int
-	requestedMap     map[DoubleHash]uint64
+	requestedMap     map[BodyHashes]uint64
 	DeliveryNotify   chan struct{}
 	deliveryCh       chan Delivery
 	Engine           consensus.Engine
@@ -51,7 +53,7 @@ type BodyRequest struct {
 // NewBodyDownload create a new body download state object
 func NewBodyDownload(outstandingLimit int, engine consensus.Engine) *BodyDownload {
 	bd := &BodyDownload{
-		requestedMap:     make(map[DoubleHash]uint64),
+		requestedMap:     make(map[BodyHashes]uint64),
 		outstandingLimit: uint64(outstandingLimit),
 		delivered:        roaring64.New(),
 		deliveriesH:      make([]*types.Header, outstandingLimit+MaxBodiesInRequest),
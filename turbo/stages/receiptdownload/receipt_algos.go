@@ -0,0 +1,249 @@
+package receiptdownload
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
+	"github.com/ledgerwatch/erigon/turbo/stages/headerdownload"
+	"github.com/ledgerwatch/log/v3"
+)
+
+const (
+	logInterval        = 30 * time.Second
+	receiptsPerRequest = 128              // Peers serve up to 1024 blocks, but smaller responses are faster to validate and retry
+	requestTimeout     = 10 * time.Second // Time to wait for the response before asking another peer
+	retryInterval      = time.Second      // Pause after a request which brought nothing (no peers, timeout, bad response)
+)
+
+// DeliverReceipts is called for every incoming ReceiptsPacket66. Responses to the requests which were not sent by
+// Backfill, or which already timed out, are dropped, and false is returned
+func (rd *ReceiptDownload) DeliverReceipts(requestId uint64, receipts [][]*types.Receipt, peerID [64]byte) bool {
+	rd.lock.Lock()
+	delivery, ok := rd.requests[requestId]
+	delete(rd.requests, requestId)
+	rd.lock.Unlock()
+	if !ok {
+		return false
+	}
+	delivery <- &receiptsDelivery{peerID: peerID, receipts: receipts}
+	return true
+}
+
+// Backfill downloads the receipts of the canonical blocks [from, to) which are missing in the Receipts table.
+// Every set of receipts is checked against the ReceiptHash of its header before it is written, peers sending
+// receipts which don't match are penalized. The range is added to the ones recorded in the database before the download starts,
+// so that the pruning of the execution stage keeps the backfilled receipts (see rawdb.PruneReceipts)
+func (rd *ReceiptDownload) Backfill(
+	ctx context.Context,
+	from, to uint64,
+	receiptReqSend func(context.Context, *ReceiptRequest) ([64]byte, bool),
+	penalize func(context.Context, []headerdownload.PenaltyItem),
+) error {
+	if from >= to {
+		return fmt.Errorf("empty receipts backfill range [%d, %d)", from, to)
+	}
+	if err := rd.db.Update(ctx, func(tx kv.RwTx) error {
+		return rawdb.WriteReceiptsBackfill(tx, from, to)
+	}); err != nil {
+		return err
+	}
+	log.Info("[Receipts backfill] Started", "from", from, "to", to)
+
+	logEvery := time.NewTicker(logInterval)
+	defer logEvery.Stop()
+	var written int
+	for blockNum := from; blockNum < to; {
+		headers, next, err := rd.missingReceipts(ctx, blockNum, to)
+		if err != nil {
+			return err
+		}
+		blockNum = next
+		for len(headers) > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-logEvery.C:
+				log.Info("[Receipts backfill] Progress", "block", headers[0].Number.Uint64(), "to", to, "written", written)
+			default:
+			}
+			missing, err := rd.fetch(ctx, headers, receiptReqSend, penalize)
+			if err != nil {
+				return err
+			}
+			written += len(headers) - len(missing)
+			if len(missing) == len(headers) {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(retryInterval):
+				}
+			}
+			headers = missing
+		}
+	}
+	log.Info("[Receipts backfill] Done", "from", from, "to", to, "written", written)
+	return nil
+}
+
+// missingReceipts collects up to receiptsPerRequest canonical headers from [from, to) which blocks have no receipts
+// in the database. Receipts of the blocks without transactions are written right away, they don't need a peer.
+// Returns the block number to continue from
+func (rd *ReceiptDownload) missingReceipts(ctx context.Context, from, to uint64) ([]*types.Header, uint64, error) {
+	var headers, empty []*types.Header
+	blockNum := from
+	if err := rd.db.View(ctx, func(tx kv.Tx) error {
+		for ; blockNum < to && len(headers) < receiptsPerRequest; blockNum++ {
+			hash, err := rd.blockReader.CanonicalHash(ctx, tx, blockNum)
+			if err != nil {
+				return err
+			}
+			if hash == (common.Hash{}) {
+				return fmt.Errorf("block %d is not synced yet", blockNum)
+			}
+			if rawdb.HasReceipts(tx, hash, blockNum) {
+				continue
+			}
+			header, err := rd.blockReader.Header(ctx, tx, hash, blockNum)
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				return fmt.Errorf("header %d %x not found", blockNum, hash)
+			}
+			if header.ReceiptHash == types.EmptyRootHash {
+				empty = append(empty, header)
+				continue
+			}
+			headers = append(headers, header)
+		}
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	}
+	receipts := make([]types.Receipts, len(empty))
+	for i := range receipts {
+		receipts[i] = types.Receipts{}
+	}
+	if err := rd.write(ctx, empty, receipts); err != nil {
+		return nil, 0, err
+	}
+	return headers, blockNum, nil
+}
+
+// fetch requests the receipts of the headers from a peer and writes the ones which match the headers.
+// Returns the headers which are still missing receipts
+func (rd *ReceiptDownload) fetch(
+	ctx context.Context,
+	headers []*types.Header,
+	receiptReqSend func(context.Context, *ReceiptRequest) ([64]byte, bool),
+	penalize func(context.Context, []headerdownload.PenaltyItem),
+) ([]*types.Header, error) {
+	req := &ReceiptRequest{
+		RequestId: rand.Uint64(),
+		Hashes:    make([]common.Hash, len(headers)),
+		MinBlock:  headers[len(headers)-1].Number.Uint64(),
+	}
+	for i, header := range headers {
+		req.Hashes[i] = header.Hash()
+	}
+	delivery := make(chan *receiptsDelivery, 1)
+	rd.lock.Lock()
+	rd.requests[req.RequestId] = delivery
+	rd.lock.Unlock()
+	defer func() {
+		rd.lock.Lock()
+		delete(rd.requests, req.RequestId)
+		rd.lock.Unlock()
+	}()
+
+	if _, ok := receiptReqSend(ctx, req); !ok {
+		return headers, nil
+	}
+	timer := time.NewTimer(requestTimeout)
+	defer timer.Stop()
+	var d *receiptsDelivery
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return headers, nil
+	case d = <-delivery:
+	}
+
+	// Peers skip the blocks they don't have, so the response is matched to the request by the receipt roots
+	var found, missing []*types.Header
+	var receipts []types.Receipts
+	i := 0
+	for _, r := range d.receipts {
+		root := types.DeriveSha(types.Receipts(r))
+		for ; i < len(headers) && headers[i].ReceiptHash != root; i++ {
+			missing = append(missing, headers[i])
+		}
+		if i == len(headers) {
+			log.Debug("[Receipts backfill] Receipts don't match the requested blocks", "peer", fmt.Sprintf("%x", d.peerID[:8]), "root", root)
+			penalize(ctx, []headerdownload.PenaltyItem{{PeerID: d.peerID, Penalty: headerdownload.BadBlockPenalty}})
+			break
+		}
+		found = append(found, headers[i])
+		receipts = append(receipts, r)
+		i++
+	}
+	missing = append(missing, headers[i:]...)
+	if err := rd.write(ctx, found, receipts); err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
+
+func (rd *ReceiptDownload) write(ctx context.Context, headers []*types.Header, receipts []types.Receipts) error {
+	if len(headers) == 0 {
+		return nil
+	}
+	return rd.db.Update(ctx, func(tx kv.RwTx) error {
+		// The log index stage has already passed these blocks, so their logs are added to the index here
+		topics := map[string]*roaring.Bitmap{}
+		addresses := map[string]*roaring.Bitmap{}
+		for i, header := range headers {
+			blockNum := header.Number.Uint64()
+			if err := rawdb.WriteReceipts(tx, blockNum, receipts[i]); err != nil {
+				return err
+			}
+			for _, receipt := range receipts[i] {
+				for _, l := range receipt.Logs {
+					for _, topic := range l.Topics {
+						addToIndex(topics, topic.Bytes(), blockNum)
+					}
+					addToIndex(addresses, l.Address.Bytes(), blockNum)
+				}
+			}
+		}
+		for k, m := range topics {
+			if err := bitmapdb.Merge(tx, kv.LogTopicIndex, []byte(k), m); err != nil {
+				return err
+			}
+		}
+		for k, m := range addresses {
+			if err := bitmapdb.Merge(tx, kv.LogAddressIndex, []byte(k), m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func addToIndex(index map[string]*roaring.Bitmap, key []byte, blockNum uint64) {
+	m, ok := index[string(key)]
+	if !ok {
+		m = roaring.New()
+		index[string(key)] = m
+	}
+	m.Add(uint32(blockNum))
+}
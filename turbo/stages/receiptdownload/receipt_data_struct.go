@@ -0,0 +1,39 @@
+package receiptdownload
+
+import (
+	"sync"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/turbo/services"
+)
+
+// ReceiptRequest is the request of the receipts of several blocks, sent to a peer as GetReceiptsPacket66
+type ReceiptRequest struct {
+	RequestId uint64
+	Hashes    []common.Hash
+	MinBlock  uint64 // Peer has to have at least this block to answer
+}
+
+type receiptsDelivery struct {
+	peerID   [64]byte
+	receipts [][]*types.Receipt
+}
+
+// ReceiptDownload backfills the receipts which were pruned from the Receipts table (or never written) by downloading
+// them from the peers instead of re-executing the blocks
+type ReceiptDownload struct {
+	lock        sync.Mutex
+	requests    map[uint64]chan *receiptsDelivery // Outstanding requests by RequestId
+	db          kv.RwDB
+	blockReader services.HeaderAndCanonicalReader
+}
+
+func NewReceiptDownload(db kv.RwDB, blockReader services.HeaderAndCanonicalReader) *ReceiptDownload {
+	return &ReceiptDownload{
+		requests:    map[uint64]chan *receiptsDelivery{},
+		db:          db,
+		blockReader: blockReader,
+	}
+}
@@ -1,21 +1,29 @@
 package stages_test
 
 import (
+	"context"
 	"math/big"
 	"testing"
 
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
+	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/math"
 	"github.com/ledgerwatch/erigon/common/u256"
 	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/turbo/engineapi"
 	"github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/ledgerwatch/erigon/turbo/stages/headerdownload"
+	"github.com/ledgerwatch/erigon/turbo/stages/receiptdownload"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -682,3 +690,103 @@ func TestPoSSyncWithInvalidHeader(t *testing.T) {
 	assert.True(t, bad)
 	assert.Equal(t, lastValidHash, lastValidHeader.Hash())
 }
+
+func TestReceiptsBackfill(t *testing.T) {
+	require := require.New(t)
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	// The contract emits a log with topic 0x01 (PUSH1 1, PUSH1 0, PUSH1 0, LOG1)
+	contract, topic := common.Address{2}, common.Hash{31: 1}
+	m := stages.MockWithGenesis(t, &core.Genesis{
+		Config: params.AllEthashProtocolChanges,
+		Alloc: core.GenesisAlloc{
+			crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(params.Ether)},
+			contract:                              {Balance: common.Big0, Code: common.FromHex("60016000600060a100")},
+		},
+	}, key, false)
+
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 10, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(common.Address{1})
+		if i%2 == 0 {
+			tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(m.Address), contract, uint256.NewInt(10_000), 50_000, u256.Num1, nil), *types.LatestSignerForChainID(m.ChainConfig.ChainID), m.Key)
+			require.NoError(err)
+			gen.AddTx(tx)
+		}
+	}, false /* intermediateHashes */)
+	require.NoError(err)
+	require.NoError(m.InsertChain(chain))
+
+	// Drop the receipts and the log index, as if they were pruned
+	require.NoError(m.DB.Update(m.Ctx, func(tx kv.RwTx) error {
+		if err := tx.ClearBucket(kv.LogAddressIndex); err != nil {
+			return err
+		}
+		if err := tx.ClearBucket(kv.LogTopicIndex); err != nil {
+			return err
+		}
+		return rawdb.TruncateReceipts(tx, 1)
+	}))
+
+	receipts := map[common.Hash]types.Receipts{}
+	for i, block := range chain.Blocks {
+		receipts[block.Hash()] = chain.Receipts[i]
+	}
+	var requests, penalties int
+	send := func(_ context.Context, req *receiptdownload.ReceiptRequest) ([64]byte, bool) {
+		requests++
+		var packet eth.ReceiptsPacket
+		for _, hash := range req.Hashes {
+			packet = append(packet, receipts[hash])
+		}
+		if requests == 1 {
+			// The first peer sends a broken receipt, nothing of its response can be accepted
+			broken := *packet[0][0]
+			broken.CumulativeGasUsed++
+			packet[0] = types.Receipts{&broken}
+		}
+		b, err := rlp.EncodeToBytes(&eth.ReceiptsPacket66{RequestId: req.RequestId, ReceiptsPacket: packet})
+		require.NoError(err)
+		m.ReceiveWg.Add(1)
+		for _, err = range m.Send(&sentry.InboundMessage{Id: sentry.MessageId_RECEIPTS_66, Data: b, PeerId: m.PeerId}) {
+			require.NoError(err)
+		}
+		return [64]byte{1}, true
+	}
+	penalize := func(context.Context, []headerdownload.PenaltyItem) { penalties++ }
+
+	to := chain.TopBlock.NumberU64() + 1
+	require.NoError(m.ReceiptDownload().Backfill(m.Ctx, 1, to, send, penalize))
+	m.ReceiveWg.Wait()
+	require.Equal(2, requests)
+	require.Equal(1, penalties)
+
+	// Backfilled receipts survive the pruning
+	require.NoError(m.DB.Update(m.Ctx, func(tx kv.RwTx) error {
+		return rawdb.PruneReceipts(tx, to, m.Ctx)
+	}))
+	require.NoError(m.DB.View(m.Ctx, func(tx kv.Tx) error {
+		backfilled, err := rawdb.ReadReceiptsBackfill(tx)
+		require.NoError(err)
+		require.Equal([]rawdb.BlockRange{{From: 1, To: to}}, backfilled)
+
+		for i, block := range chain.Blocks {
+			got, err := rawdb.ReadReceiptsByHash(tx, block.Hash())
+			require.NoError(err)
+			require.Len(got, len(chain.Receipts[i]))
+			for j, r := range chain.Receipts[i] {
+				require.Equal(r.TxHash, got[j].TxHash)
+				require.Equal(r.Status, got[j].Status)
+				require.Equal(r.CumulativeGasUsed, got[j].CumulativeGasUsed)
+				require.Len(got[j].Logs, 1)
+			}
+		}
+
+		// The logs of the backfilled blocks are found by the log index
+		addresses, err := bitmapdb.Get(tx, kv.LogAddressIndex, contract[:], 0, math.MaxUint32)
+		require.NoError(err)
+		topics, err := bitmapdb.Get(tx, kv.LogTopicIndex, topic[:], 0, math.MaxUint32)
+		require.NoError(err)
+		require.Equal([]uint32{1, 3, 5, 7, 9}, addresses.ToArray())
+		require.Equal([]uint32{1, 3, 5, 7, 9}, topics.ToArray())
+		return nil
+	}))
+}
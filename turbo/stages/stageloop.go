@@ -212,9 +212,10 @@ func StageLoopStep(
 				if header.Number.Uint64() == 0 {
 					notifications.Accumulator.StartChange(0, header.Hash(), nil, false)
 				}
+				removedLogs := notifications.Accumulator.RemovedLogs()
 				notifications.Accumulator.SendAndReset(ctx, notifications.StateChangesConsumer, pendingBaseFee.Uint64(), header.GasLimit)
 
-				if err = stagedsync.NotifyNewHeaders(ctx, finishProgressBefore, head, sync.PrevUnwindPoint(), notifications.Events, rotx); err != nil {
+				if err = stagedsync.NotifyNewHeaders(ctx, finishProgressBefore, head, sync.PrevUnwindPoint(), removedLogs, notifications.Events, rotx); err != nil {
 					return headBlockHash, nil
 				}
 			}
@@ -258,7 +259,7 @@ func StateStep(ctx context.Context, batch kv.RwTx, stateSync *stagedsync.Sync, h
 	// Construct side fork if we have one
 	if unwindPoint > 0 {
 		// Run it through the unwind
-		stateSync.UnwindTo(unwindPoint, common.Hash{})
+		stateSync.UnwindTo(unwindPoint, common.Hash{}, nil)
 		if err = stateSync.RunUnwind(nil, batch); err != nil {
 			return err
 		}
@@ -351,7 +352,7 @@ func NewStagedSync(
 	// Hence we run it in the test mode.
 	runInTestMode := cfg.ImportMode
 	isBor := controlServer.ChainConfig.Bor != nil
-	return stagedsync.New(
+	stagedSync := stagedsync.New(
 		stagedsync.DefaultStages(ctx, cfg.Prune,
 			stagedsync.StageHeadersCfg(
 				db,
@@ -412,7 +413,10 @@ func NewStagedSync(
 			stagedsync.StageFinishCfg(db, tmpdir, logger, headCh, forkValidator), runInTestMode),
 		stagedsync.DefaultUnwindOrder,
 		stagedsync.DefaultPruneOrder,
-	), nil
+	)
+	// eth_subscribe("syncing")
+	stagedSync.OnProgress(notifications.Events.OnSyncProgress)
+	return stagedSync, nil
 }
 
 func NewInMemoryExecution(ctx context.Context, logger log.Logger, db kv.RwDB, cfg ethconfig.Config, controlServer *sentry.MultiClient, tmpdir string, notifications *stagedsync.Notifications, snapshots *snapshotsync.RoSnapshots) (*stagedsync.Sync, error) {
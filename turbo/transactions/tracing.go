@@ -19,6 +19,7 @@ import (
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/eth/tracers"
+	_ "github.com/ledgerwatch/erigon/eth/tracers/native"
 	"github.com/ledgerwatch/erigon/params"
 )
 
@@ -79,7 +80,8 @@ func ComputeTxEnv(ctx context.Context, block *types.Block, cfg *params.ChainConf
 
 // TraceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
-// be tracer dependent.
+// be tracer dependent. If tracing fails, the stream is left after a value as well:
+// the struct logs written so far are closed, other tracers write null.
 func TraceTx(
 	ctx context.Context,
 	message core.Message,
@@ -92,46 +94,64 @@ func TraceTx(
 ) error {
 	// Assemble the structured logger or the JavaScript tracer
 	var (
-		tracer vm.Tracer
-		err    error
+		tracer       vm.Tracer
+		resultTracer tracers.ResultTracer
+		err          error
 	)
 	var streaming bool
+	// Define a meaningful timeout of a single transaction trace, custom tracers are slow enough to always need one
+	var timeout time.Duration
+	if config != nil && config.Tracer != nil {
+		timeout = callTimeout
+	}
+	if config != nil && config.Timeout != nil {
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			stream.WriteNil()
+			return err
+		}
+	}
+	// Handle timeouts and RPC cancellations, within the transaction as well
+	var (
+		deadlineCtx context.Context
+		cancel      context.CancelFunc
+	)
+	if timeout > 0 {
+		deadlineCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		deadlineCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
 	switch {
 	case config != nil && config.Tracer != nil:
-		// Define a meaningful timeout of a single transaction trace
-		timeout := callTimeout
-		if config.Timeout != nil {
-			if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
-				stream.WriteNil()
-				return err
-			}
-		}
-		// Construct the JavaScript tracer to execute with
-		if tracer, err = tracers.New(*config.Tracer, &tracers.Context{
-			TxHash: txCtx.TxHash,
-		}); err != nil {
+		// Construct the native or JavaScript tracer to execute with
+		if resultTracer, err = tracers.NewTracer(*config.Tracer, &tracers.Context{
+			TxHash:   txCtx.TxHash,
+			GasLimit: message.Gas(),
+		}, config.TracerConfig); err != nil {
 			stream.WriteNil()
 			return err
 		}
-		// Handle timeouts and RPC cancellations
-		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+		tracer = resultTracer
 		go func() {
 			<-deadlineCtx.Done()
-			tracer.(*tracers.Tracer).Stop(errors.New("execution timeout"))
+			resultTracer.Stop(errors.New("execution timeout"))
 		}()
-		defer cancel()
 		streaming = false
 
 	case config == nil:
-		tracer = NewJsonStreamLogger(nil, ctx, stream)
+		tracer = NewJsonStreamLogger(nil, deadlineCtx, stream)
 		streaming = true
 
 	default:
-		tracer = NewJsonStreamLogger(config.LogConfig, ctx, stream)
+		tracer = NewJsonStreamLogger(config.LogConfig, deadlineCtx, stream)
 		streaming = true
 	}
 	// Run the transaction with tracing enabled.
 	vmenv := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vm.Config{Debug: true, Tracer: tracer})
+	go func() {
+		<-deadlineCtx.Done()
+		vmenv.Cancel()
+	}()
 	var refunds bool = true
 	if config != nil && config.NoRefunds != nil && *config.NoRefunds {
 		refunds = false
@@ -142,10 +162,17 @@ func TraceTx(
 		stream.WriteArrayStart()
 	}
 	result, err := core.ApplyMessage(vmenv, message, new(core.GasPool).AddGas(message.Gas()), refunds, false /* gasBailout */)
+	if err == nil && vmenv.Cancelled() {
+		if err = ctx.Err(); err == nil {
+			err = fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+	}
 	if err != nil {
 		if streaming {
 			stream.WriteArrayEnd()
 			stream.WriteObjectEnd()
+		} else {
+			stream.WriteNil()
 		}
 		return fmt.Errorf("tracing failed: %w", err)
 	}
@@ -168,9 +195,10 @@ func TraceTx(
 		stream.WriteString(returnVal)
 		stream.WriteObjectEnd()
 	} else {
-		if r, err1 := tracer.(*tracers.Tracer).GetResult(); err1 == nil {
+		if r, err1 := resultTracer.GetResult(); err1 == nil {
 			stream.Write(r)
 		} else {
+			stream.WriteNil()
 			return err1
 		}
 	}
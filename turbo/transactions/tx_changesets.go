@@ -0,0 +1,156 @@
+package transactions
+
+import (
+	"sort"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+)
+
+// TxChangeSets are the change sets of the transactions of one block. They are collected by replaying the block once,
+// after that the state before any of its transactions can be read on top of the state at the beginning of the block,
+// without replaying the transactions before it. Once collected, they can be read concurrently.
+type TxChangeSets struct {
+	accounts     map[common.Address][]accountChange
+	storage      map[storageSlot][]storageChange
+	incarnations map[common.Address][]accountChange // of the deleted accounts
+	codes        map[common.Hash][]byte
+}
+
+type accountChange struct {
+	txIndex int
+	account *accounts.Account // nil - deleted
+}
+
+type storageSlot struct {
+	address     common.Address
+	incarnation uint64
+	key         common.Hash
+}
+
+type storageChange struct {
+	txIndex int
+	value   uint256.Int
+}
+
+func NewTxChangeSets() *TxChangeSets {
+	return &TxChangeSets{
+		accounts:     map[common.Address][]accountChange{},
+		storage:      map[storageSlot][]storageChange{},
+		incarnations: map[common.Address][]accountChange{},
+		codes:        map[common.Hash][]byte{},
+	}
+}
+
+// Writer records the changes of the transaction txIndex, the transactions must be written in order
+func (c *TxChangeSets) Writer(txIndex int) state.StateWriter {
+	return &txChangeSetWriter{changes: c, txIndex: txIndex}
+}
+
+// Reader reads the state before the transaction txIndex, base is the state at the beginning of the block
+func (c *TxChangeSets) Reader(base state.StateReader, txIndex int) state.StateReader {
+	return &txChangeSetReader{changes: c, base: base, txIndex: txIndex}
+}
+
+// lastAccountChange is the latest change made before the transaction txIndex
+func lastAccountChange(changes []accountChange, txIndex int) (accountChange, bool) {
+	i := sort.Search(len(changes), func(i int) bool { return changes[i].txIndex >= txIndex })
+	if i == 0 {
+		return accountChange{}, false
+	}
+	return changes[i-1], true
+}
+
+func appendAccountChange(changes []accountChange, change accountChange) []accountChange {
+	if n := len(changes); n > 0 && changes[n-1].txIndex == change.txIndex {
+		changes[n-1] = change
+		return changes
+	}
+	return append(changes, change)
+}
+
+type txChangeSetWriter struct {
+	changes *TxChangeSets
+	txIndex int
+}
+
+func (w *txChangeSetWriter) UpdateAccountData(address common.Address, original, account *accounts.Account) error {
+	w.changes.accounts[address] = appendAccountChange(w.changes.accounts[address], accountChange{txIndex: w.txIndex, account: account.SelfCopy()})
+	return nil
+}
+
+func (w *txChangeSetWriter) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	w.changes.codes[codeHash] = common.CopyBytes(code)
+	return nil
+}
+
+func (w *txChangeSetWriter) DeleteAccount(address common.Address, original *accounts.Account) error {
+	w.changes.accounts[address] = appendAccountChange(w.changes.accounts[address], accountChange{txIndex: w.txIndex})
+	if original != nil && original.Incarnation > 0 {
+		w.changes.incarnations[address] = appendAccountChange(w.changes.incarnations[address], accountChange{txIndex: w.txIndex, account: original.SelfCopy()})
+	}
+	return nil
+}
+
+func (w *txChangeSetWriter) WriteAccountStorage(address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	slot := storageSlot{address: address, incarnation: incarnation, key: *key}
+	changes := w.changes.storage[slot]
+	if n := len(changes); n > 0 && changes[n-1].txIndex == w.txIndex {
+		changes[n-1].value = *value
+		return nil
+	}
+	w.changes.storage[slot] = append(changes, storageChange{txIndex: w.txIndex, value: *value})
+	return nil
+}
+
+func (w *txChangeSetWriter) CreateContract(address common.Address) error {
+	return nil
+}
+
+type txChangeSetReader struct {
+	changes *TxChangeSets
+	base    state.StateReader
+	txIndex int
+}
+
+func (r *txChangeSetReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	if change, ok := lastAccountChange(r.changes.accounts[address], r.txIndex); ok {
+		if change.account == nil {
+			return nil, nil
+		}
+		return change.account.SelfCopy(), nil
+	}
+	return r.base.ReadAccountData(address)
+}
+
+func (r *txChangeSetReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	changes := r.changes.storage[storageSlot{address: address, incarnation: incarnation, key: *key}]
+	i := sort.Search(len(changes), func(i int) bool { return changes[i].txIndex >= r.txIndex })
+	if i > 0 {
+		return changes[i-1].value.Bytes(), nil
+	}
+	return r.base.ReadAccountStorage(address, incarnation, key)
+}
+
+func (r *txChangeSetReader) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	if code, ok := r.changes.codes[codeHash]; ok {
+		return code, nil
+	}
+	return r.base.ReadAccountCode(address, incarnation, codeHash)
+}
+
+func (r *txChangeSetReader) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	if code, ok := r.changes.codes[codeHash]; ok {
+		return len(code), nil
+	}
+	return r.base.ReadAccountCodeSize(address, incarnation, codeHash)
+}
+
+func (r *txChangeSetReader) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	if change, ok := lastAccountChange(r.changes.incarnations[address], r.txIndex); ok {
+		return change.account.Incarnation, nil
+	}
+	return r.base.ReadAccountIncarnation(address)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/rlp"
 )
 
 // Prove constructs a merkle proof for key. The result contains all encoded nodes
@@ -95,3 +97,82 @@ func (t *Trie) Prove(key []byte, fromLevel int, storage bool) ([][]byte, error)
 	}
 	return proof, nil
 }
+
+// VerifyProof checks merkle proof for key, produced by Prove, against rootHash. It returns the value at key (for
+// accounts - RLP of the account as it's hashed), or nil if the proof proves absence of the key.
+func VerifyProof(rootHash common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	key = keybytesToHex(key)
+	wantHash := rootHash[:]
+	var node []byte // next node, nil - it's the next node of the proof
+	for i := 0; ; {
+		if node == nil {
+			if i >= len(proof) {
+				return nil, fmt.Errorf("proof node %d (hash %x) missing", i, wantHash)
+			}
+			node = proof[i]
+			if !bytes.Equal(crypto.Keccak256(node), wantHash) {
+				return nil, fmt.Errorf("bad proof node %d: hash mismatch", i)
+			}
+			i++
+		}
+		elems, _, err := rlp.SplitList(node)
+		if err != nil {
+			return nil, fmt.Errorf("bad proof node %d: %w", i, err)
+		}
+		count, err := rlp.CountValues(elems)
+		if err != nil {
+			return nil, fmt.Errorf("bad proof node %d: %w", i, err)
+		}
+		var child []byte
+		switch count {
+		case 2:
+			compactKey, rest, err := rlp.SplitString(elems)
+			if err != nil {
+				return nil, fmt.Errorf("bad proof node %d: %w", i, err)
+			}
+			nodeKey := compactToHex(compactKey)
+			if hasTerm(nodeKey) {
+				if !bytes.Equal(nodeKey, key) {
+					return nil, nil
+				}
+				value, _, err := rlp.SplitString(rest)
+				return value, err
+			}
+			if len(key) < len(nodeKey) || !bytes.Equal(nodeKey, key[:len(nodeKey)]) {
+				return nil, nil
+			}
+			key, child = key[len(nodeKey):], rest
+		case 17:
+			for j := byte(0); j < key[0]; j++ {
+				if _, _, elems, err = rlp.Split(elems); err != nil {
+					return nil, fmt.Errorf("bad proof node %d: %w", i, err)
+				}
+			}
+			if key[0] == 16 {
+				value, _, err := rlp.SplitString(elems)
+				return value, err
+			}
+			key, child = key[1:], elems
+		default:
+			return nil, fmt.Errorf("bad proof node %d: %d elements", i, count)
+		}
+
+		kind, content, after, err := rlp.Split(child)
+		if err != nil {
+			return nil, fmt.Errorf("bad proof node %d: %w", i, err)
+		}
+		switch {
+		case kind == rlp.List: // node is embedded into its parent
+			node = child[:len(child)-len(after)]
+			if i < len(proof) && bytes.Equal(proof[i], node) { // Prove lists embedded nodes too
+				i++
+			}
+		case len(content) == 0:
+			return nil, nil
+		case len(content) == common.HashLength:
+			wantHash, node = content, nil
+		default:
+			return nil, fmt.Errorf("bad proof node %d: child of %d bytes", i, len(content))
+		}
+	}
+}
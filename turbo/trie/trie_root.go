@@ -122,6 +122,10 @@ type RootHashAggregator struct {
 	a              accounts.Account
 	leafData       GenStructStepLeafData
 	accData        GenStructStepAccountData
+
+	retain    RetainDecider // if set - nodes on the paths to retained keys are built, not only hashed (used for proofs)
+	retainBuf []byte
+	rootNode  node
 }
 
 type StreamReceiver interface {
@@ -173,6 +177,27 @@ func (l *FlatDBTrieLoader) SetStreamReceiver(receiver StreamReceiver) {
 	l.receiver = receiver
 }
 
+// DefaultReceiver - returns aggregator which is used by loader when no custom receiver set,
+// custom receivers can wrap it to modify the stream of values
+func (l *FlatDBTrieLoader) DefaultReceiver() *RootHashAggregator {
+	return l.defaultReceiver
+}
+
+// SetProofRetainer makes loader keep structure of the trie (not only hashes) on the paths to the keys of `rd`.
+// Keys must be added in the same format as for RetainDecider passed to Reset: addrHash for accounts and
+// addrHash+incarnation+keyHash for storage. After CalcTrieRoot the result is available via ProofTrie.
+func (l *FlatDBTrieLoader) SetProofRetainer(rd RetainDecider) {
+	l.defaultReceiver.retain = rd
+}
+
+// ProofTrie - returns trie built by last CalcTrieRoot call, it has all nodes on the paths to
+// keys of ProofRetainer and hash nodes everywhere else. Can be used to produce Merkle proofs.
+func (l *FlatDBTrieLoader) ProofTrie() *Trie {
+	t := New(common.Hash{})
+	t.root = l.defaultReceiver.rootNode
+	return t
+}
+
 // CalcTrieRoot algo:
 //	for iterateIHOfAccounts {
 //		if canSkipState
@@ -327,6 +352,22 @@ func (r *RootHashAggregator) RetainNothing(_ []byte) bool {
 	return false
 }
 
+func (r *RootHashAggregator) retainAccount(prefix []byte) bool {
+	if r.retain == nil {
+		return false
+	}
+	return r.retain.Retain(prefix)
+}
+
+func (r *RootHashAggregator) retainStorage(prefix []byte) bool {
+	if r.retain == nil {
+		return false
+	}
+	hexutil.DecompressNibbles(r.currAccK, &r.retainBuf)
+	r.retainBuf = append(r.retainBuf, prefix...)
+	return r.retain.Retain(r.retainBuf)
+}
+
 func (r *RootHashAggregator) Reset(hc HashCollector2, shc StorageHashCollector2, trace bool) {
 	r.hc = hc
 	r.shc = shc
@@ -344,6 +385,7 @@ func (r *RootHashAggregator) Reset(hc HashCollector2, shc StorageHashCollector2,
 	r.valueStorage = nil
 	r.wasIHStorage = false
 	r.root = common.Hash{}
+	r.rootNode = nil
 	r.trace = trace
 	r.hb.trace = trace
 }
@@ -493,6 +535,9 @@ func (r *RootHashAggregator) Receive(itemType StreamItem,
 		}
 		if r.hb.hasRoot() {
 			r.root = r.hb.rootHash()
+			if r.retain != nil {
+				r.rootNode = r.hb.root()
+			}
 		} else {
 			r.root = EmptyRoot
 		}
@@ -563,7 +608,7 @@ func (r *RootHashAggregator) genStructStorage() error {
 		r.leafData.Value = rlphacks.RlpSerializableBytes(r.valueStorage)
 		data = &r.leafData
 	}
-	r.groupsStorage, r.hasTreeStorage, r.hasHashStorage, err = GenStructStep(r.RetainNothing, r.currStorage.Bytes(), r.succStorage.Bytes(), r.hb, func(keyHex []byte, hasState, hasTree, hasHash uint16, hashes, rootHash []byte) error {
+	r.groupsStorage, r.hasTreeStorage, r.hasHashStorage, err = GenStructStep(r.retainStorage, r.currStorage.Bytes(), r.succStorage.Bytes(), r.hb, func(keyHex []byte, hasState, hasTree, hasHash uint16, hashes, rootHash []byte) error {
 		if r.shc == nil {
 			return nil
 		}
@@ -631,7 +676,7 @@ func (r *RootHashAggregator) genStructAccount() error {
 	r.currStorage.Reset()
 	r.succStorage.Reset()
 	var err error
-	if r.groups, r.hasTree, r.hasHash, err = GenStructStep(r.RetainNothing, r.curr.Bytes(), r.succ.Bytes(), r.hb, func(keyHex []byte, hasState, hasTree, hasHash uint16, hashes, rootHash []byte) error {
+	if r.groups, r.hasTree, r.hasHash, err = GenStructStep(r.retainAccount, r.curr.Bytes(), r.succ.Bytes(), r.hb, func(keyHex []byte, hasState, hasTree, hasHash uint16, hashes, rootHash []byte) error {
 		if r.hc == nil {
 			return nil
 		}
@@ -692,3 +692,46 @@ func TestNextSubtreeHex(t *testing.T) {
 //		t.Fatal(err)
 //	}
 //}
+
+func TestVerifyProof(t *testing.T) {
+	trie := New(common.Hash{})
+	keys := make([][]byte, 0, 100)
+	for i := 0; i < 100; i++ {
+		key := crypto.Keccak256([]byte{byte(i)})
+		keys = append(keys, key)
+		trie.Update(key, []byte(fmt.Sprintf("value-%d", i)))
+	}
+	trie.Update(crypto.Keccak256([]byte("short")), []byte{1}) // embedded nodes
+	root := trie.Hash()
+
+	for i, key := range keys {
+		proof, err := trie.Prove(key, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := VerifyProof(root, key, proof)
+		if err != nil {
+			t.Fatalf("key %d: %v", i, err)
+		}
+		if want := fmt.Sprintf("value-%d", i); string(value) != want {
+			t.Errorf("key %d: got %q, want %q", i, value, want)
+		}
+		proof[len(proof)-1] = append(common.CopyBytes(proof[len(proof)-1]), 0)
+		if _, err = VerifyProof(root, key, proof); err == nil {
+			t.Errorf("key %d: expected error for the changed proof", i)
+		}
+	}
+
+	absent := crypto.Keccak256([]byte("absent"))
+	proof, err := trie.Prove(absent, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := VerifyProof(root, absent, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != nil {
+		t.Errorf("expected no value for the absent key, got %x", value)
+	}
+}